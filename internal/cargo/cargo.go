@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
-	"github.com/git-pkgs/registries/internal/urlparser"
 )
 
 const (
@@ -20,6 +19,10 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"categories", "crate_size", "downloads", "features", "id",
+		"published_by", "rust_version", "yank_message",
+	)
 }
 
 type Registry struct {
@@ -54,14 +57,16 @@ type crateResponse struct {
 }
 
 type crateInfo struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Homepage    string   `json:"homepage"`
-	Repository  string   `json:"repository"`
-	Keywords    []string `json:"keywords"`
-	Categories  []string `json:"categories"`
-	Downloads   int      `json:"downloads"`
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Homepage         string   `json:"homepage"`
+	Repository       string   `json:"repository"`
+	Keywords         []string `json:"keywords"`
+	Categories       []string `json:"categories"`
+	Downloads        int      `json:"downloads"`
+	MaxVersion       string   `json:"max_version"`
+	MaxStableVersion string   `json:"max_stable_version"`
 }
 
 type versionInfo struct {
@@ -95,10 +100,12 @@ type ownersResponse struct {
 }
 
 type ownerInfo struct {
-	ID    int    `json:"id"`
-	Login string `json:"login"`
-	Name  string `json:"name"`
-	URL   string `json:"url"`
+	ID     int    `json:"id"`
+	Login  string `json:"login"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Avatar string `json:"avatar"`
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
@@ -117,13 +124,20 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		licenses = resp.Versions[0].License
 	}
 
+	latest := resp.Crate.MaxStableVersion
+	if latest == "" {
+		latest = resp.Crate.MaxVersion
+	}
+
 	return &core.Package{
-		Name:        resp.Crate.ID,
-		Description: resp.Crate.Description,
-		Homepage:    resp.Crate.Homepage,
-		Repository:  urlparser.Parse(resp.Crate.Repository),
-		Licenses:    licenses,
-		Keywords:    resp.Crate.Keywords,
+		Name:          resp.Crate.ID,
+		Description:   resp.Crate.Description,
+		Homepage:      resp.Crate.Homepage,
+		Repository:    core.NormalizeRepository(resp.Crate.Repository),
+		Licenses:      licenses,
+		Keywords:      core.NormalizeKeywords(resp.Crate.Keywords),
+		LatestVersion: latest,
+		Downloads:     resp.Crate.Downloads,
 		Metadata: map[string]any{
 			"categories": resp.Crate.Categories,
 			"downloads":  resp.Crate.Downloads,
@@ -215,24 +229,34 @@ func mapScope(kind string) core.Scope {
 	}
 }
 
+// FetchMaintainers returns the crate's owners, both users and teams. Team
+// owners have Role "team" and a login like "github:org:team"; users have
+// Role "owner". An unknown crate returns no maintainers rather than an
+// error.
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	url := fmt.Sprintf("%s/api/v1/crates/%s/owner_user", r.baseURL, name)
+	url := fmt.Sprintf("%s/api/v1/crates/%s/owners", r.baseURL, name)
 
 	var resp ownersResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+			return nil, nil
 		}
 		return nil, err
 	}
 
 	maintainers := make([]core.Maintainer, len(resp.Users))
 	for i, u := range resp.Users {
+		role := "owner"
+		if u.Kind == "team" {
+			role = "team"
+		}
 		maintainers[i] = core.Maintainer{
-			UUID:  fmt.Sprintf("%d", u.ID),
-			Login: u.Login,
-			Name:  u.Name,
-			URL:   u.URL,
+			UUID:      fmt.Sprintf("%d", u.ID),
+			Login:     u.Login,
+			Name:      u.Name,
+			URL:       u.URL,
+			AvatarURL: u.Avatar,
+			Role:      role,
 		}
 	}
 
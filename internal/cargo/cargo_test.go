@@ -21,13 +21,15 @@ func TestFetchPackage(t *testing.T) {
 
 		resp := crateResponse{
 			Crate: crateInfo{
-				ID:          "serde",
-				Name:        "serde",
-				Description: "A generic serialization/deserialization framework",
-				Homepage:    "https://serde.rs",
-				Repository:  "https://github.com/serde-rs/serde",
-				Keywords:    []string{"serialization", "no_std"},
-				Categories:  []string{"encoding"},
+				ID:               "serde",
+				Name:             "serde",
+				Description:      "A generic serialization/deserialization framework",
+				Homepage:         "https://serde.rs",
+				Repository:       "https://github.com/serde-rs/serde",
+				Keywords:         []string{"serialization", "no_std"},
+				Categories:       []string{"encoding"},
+				MaxVersion:       "1.0.228",
+				MaxStableVersion: "1.0.228",
 			},
 			Versions: []versionInfo{
 				{
@@ -67,6 +69,9 @@ func TestFetchPackage(t *testing.T) {
 	if len(pkg.Keywords) != 2 {
 		t.Errorf("expected 2 keywords, got %d", len(pkg.Keywords))
 	}
+	if pkg.LatestVersion != "1.0.228" {
+		t.Errorf("expected latest version '1.0.228', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchPackageNotFound(t *testing.T) {
@@ -202,7 +207,7 @@ func TestFetchDependencies(t *testing.T) {
 
 func TestFetchMaintainers(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/api/v1/crates/serde/owner_user" {
+		if r.URL.Path != "/api/v1/crates/serde/owners" {
 			t.Errorf("unexpected path: %s", r.URL.Path)
 			w.WriteHeader(404)
 			return
@@ -210,7 +215,8 @@ func TestFetchMaintainers(t *testing.T) {
 
 		resp := ownersResponse{
 			Users: []ownerInfo{
-				{ID: 3618, Login: "dtolnay", Name: "David Tolnay", URL: "https://github.com/dtolnay"},
+				{ID: 3618, Login: "dtolnay", Kind: "user", Name: "David Tolnay", URL: "https://github.com/dtolnay", Avatar: "https://avatars.githubusercontent.com/u/1940490?v=4"},
+				{ID: 1037, Login: "github:serde-rs:publish", Kind: "team", Name: "publish", URL: "https://github.com/serde-rs"},
 			},
 		}
 
@@ -225,8 +231,8 @@ func TestFetchMaintainers(t *testing.T) {
 		t.Fatalf("FetchMaintainers failed: %v", err)
 	}
 
-	if len(maintainers) != 1 {
-		t.Fatalf("expected 1 maintainer, got %d", len(maintainers))
+	if len(maintainers) != 2 {
+		t.Fatalf("expected 2 maintainers, got %d", len(maintainers))
 	}
 
 	if maintainers[0].Login != "dtolnay" {
@@ -235,6 +241,31 @@ func TestFetchMaintainers(t *testing.T) {
 	if maintainers[0].Name != "David Tolnay" {
 		t.Errorf("expected name 'David Tolnay', got %q", maintainers[0].Name)
 	}
+	if maintainers[0].AvatarURL != "https://avatars.githubusercontent.com/u/1940490?v=4" {
+		t.Errorf("unexpected avatar URL: %q", maintainers[0].AvatarURL)
+	}
+	if maintainers[0].Role != "owner" {
+		t.Errorf("expected role 'owner', got %q", maintainers[0].Role)
+	}
+	if maintainers[1].Login != "github:serde-rs:publish" || maintainers[1].Role != "team" {
+		t.Errorf("unexpected team owner: %+v", maintainers[1])
+	}
+}
+
+func TestFetchMaintainersNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "missing")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if maintainers != nil {
+		t.Errorf("expected no maintainers, got %v", maintainers)
+	}
 }
 
 func TestURLBuilder(t *testing.T) {
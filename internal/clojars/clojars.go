@@ -19,6 +19,9 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"downloads", "downloads_total", "group_name", "jar_name",
+	)
 }
 
 type Registry struct {
@@ -113,20 +116,30 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		return nil, err
 	}
 
+	// Clojars only reports downloads per version, and only for recent
+	// versions, so the total undercounts packages with a long history.
+	var downloads int
+	for _, v := range resp.RecentVersions {
+		downloads += v.Downloads
+	}
+
 	pkg := &core.Package{
 		Name:        formatName(resp.GroupName, resp.JarName),
 		Description: resp.Description,
 		Homepage:    resp.Homepage,
 		Namespace:   resp.GroupName,
+		Downloads:   downloads,
 		Metadata: map[string]any{
-			"group_name": resp.GroupName,
-			"jar_name":   resp.JarName,
+			"group_name":      resp.GroupName,
+			"jar_name":        resp.JarName,
+			"downloads_total": downloads,
 		},
 	}
 
 	// Try to get more details from the latest version
 	if len(resp.RecentVersions) > 0 {
 		latestVersion := resp.RecentVersions[0].Version
+		pkg.LatestVersion = latestVersion
 		versionURL := fmt.Sprintf("%s/api/artifacts/%s/%s/versions/%s", r.baseURL, group, artifact, latestVersion)
 		var versionResp versionDetailResponse
 		if err := r.client.GetJSON(ctx, versionURL, &versionResp); err == nil {
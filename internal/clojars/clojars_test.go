@@ -41,6 +41,7 @@ func TestFetchPackage(t *testing.T) {
 			Homepage:    "https://github.com/ring-clojure/ring",
 			RecentVersions: []versionInfo{
 				{Version: "1.11.0", Downloads: 10000},
+				{Version: "1.10.0", Downloads: 2500},
 			},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
@@ -72,12 +73,21 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Namespace != "ring" {
 		t.Errorf("expected namespace 'ring', got %q", pkg.Namespace)
 	}
+	if pkg.Downloads != 12500 {
+		t.Errorf("expected 12500 downloads, got %d", pkg.Downloads)
+	}
+	if pkg.Metadata["downloads_total"] != 12500 {
+		t.Errorf("expected downloads_total 12500, got %v", pkg.Metadata["downloads_total"])
+	}
 	if pkg.Repository != "https://github.com/ring-clojure/ring" {
 		t.Errorf("unexpected repository: %q", pkg.Repository)
 	}
 	if pkg.Licenses != "MIT" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if pkg.LatestVersion != "1.11.0" {
+		t.Errorf("expected latest version '1.11.0', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchPackageSingleName(t *testing.T) {
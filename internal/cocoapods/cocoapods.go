@@ -4,6 +4,7 @@ package cocoapods
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -19,6 +20,9 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"deprecated", "deprecated_in_favor_of", "platform", "prerelease",
+	)
 }
 
 type Registry struct {
@@ -71,6 +75,57 @@ type podSpec struct {
 	Dependencies     map[string]interface{} `json:"dependencies"`
 	Platforms        map[string]string      `json:"platforms"`
 	SwiftVersions    interface{}            `json:"swift_versions"`
+	IOS              *platformSpec          `json:"ios,omitempty"`
+	OSX              *platformSpec          `json:"osx,omitempty"`
+	TVOS             *platformSpec          `json:"tvos,omitempty"`
+	WatchOS          *platformSpec          `json:"watchos,omitempty"`
+	VisionOS         *platformSpec          `json:"visionos,omitempty"`
+	Deprecated       bool                   `json:"deprecated"`
+	DeprecatedFor    string                 `json:"deprecated_in_favor_of"`
+}
+
+// isDeprecated reports whether the spec marks the pod deprecated. Naming
+// a replacement implies deprecation.
+func (s *podSpec) isDeprecated() bool {
+	return s.Deprecated || s.DeprecatedFor != ""
+}
+
+// latestSpec returns the most recent version that has a spec, or the most
+// recent version if none do.
+func (p *podResponse) latestSpec() (*podSpec, string) {
+	for i := len(p.Versions) - 1; i >= 0; i-- {
+		if p.Versions[i].Spec.Name != "" {
+			return &p.Versions[i].Spec, p.Versions[i].Name
+		}
+	}
+	if len(p.Versions) > 0 {
+		return &p.Versions[len(p.Versions)-1].Spec, p.Versions[len(p.Versions)-1].Name
+	}
+	return nil, ""
+}
+
+// platformSpec holds the attributes a podspec limits to one platform,
+// e.g. `ios.dependencies`.
+type platformSpec struct {
+	Dependencies map[string]interface{} `json:"dependencies"`
+}
+
+// platformSpecs returns the platform-specific sections of the spec that are
+// present, keyed by platform name.
+func (s *podSpec) platformSpecs() map[string]*platformSpec {
+	specs := make(map[string]*platformSpec)
+	for platform, ps := range map[string]*platformSpec{
+		"ios":      s.IOS,
+		"osx":      s.OSX,
+		"tvos":     s.TVOS,
+		"watchos":  s.WatchOS,
+		"visionos": s.VisionOS,
+	} {
+		if ps != nil {
+			specs[platform] = ps
+		}
+	}
+	return specs
 }
 
 type ownerInfo struct {
@@ -90,30 +145,30 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	// Get the latest version's spec
-	var latestSpec *podSpec
-	for i := len(resp.Versions) - 1; i >= 0; i-- {
-		if resp.Versions[i].Spec.Name != "" {
-			latestSpec = &resp.Versions[i].Spec
-			break
-		}
-	}
-
-	if latestSpec == nil && len(resp.Versions) > 0 {
-		latestSpec = &resp.Versions[len(resp.Versions)-1].Spec
-	}
+	latestSpec, latestVersion := resp.latestSpec()
 
 	pkg := &core.Package{
-		Name: resp.Name,
+		Name:          resp.Name,
+		LatestVersion: latestVersion,
 	}
 
 	if latestSpec != nil {
-		pkg.Description = latestSpec.Summary
+		pkg.Summary = latestSpec.Summary
+		pkg.Description = latestSpec.Description
 		if pkg.Description == "" {
-			pkg.Description = latestSpec.Description
+			pkg.Description = latestSpec.Summary
 		}
 		pkg.Homepage = latestSpec.Homepage
 		pkg.Repository = core.ExtractRepoURL(latestSpec.Source)
 		pkg.Licenses = core.ExtractLicense(latestSpec.License)
+
+		// `pod trunk deprecate` publishes the deprecation in the latest spec
+		if latestSpec.isDeprecated() {
+			pkg.Metadata = map[string]any{"deprecated": true}
+			if latestSpec.DeprecatedFor != "" {
+				pkg.Metadata["deprecated_in_favor_of"] = latestSpec.DeprecatedFor
+			}
+		}
 	}
 
 	return pkg, nil
@@ -130,6 +185,11 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		return nil, err
 	}
 
+	// A deprecated pod's latest spec deprecates every version; older specs
+	// may also have been deprecated on their own
+	latestSpec, _ := resp.latestSpec()
+	podDeprecated := latestSpec != nil && latestSpec.isDeprecated()
+
 	versions := make([]core.Version, len(resp.Versions))
 	for i, v := range resp.Versions {
 		versions[i] = core.Version{
@@ -137,11 +197,41 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 			PublishedAt: v.CreatedAt,
 			Licenses:    core.ExtractLicense(v.Spec.License),
 		}
+		spec := &v.Spec
+		if !spec.isDeprecated() && podDeprecated {
+			spec = latestSpec
+		}
+		if spec.isDeprecated() {
+			versions[i].Status = core.StatusDeprecated
+			if spec.DeprecatedFor != "" {
+				versions[i].Metadata = map[string]any{"deprecated_in_favor_of": spec.DeprecatedFor}
+			}
+		}
+		if core.IsPrerelease(ecosystem, v.Name) {
+			if versions[i].Metadata == nil {
+				versions[i].Metadata = make(map[string]any)
+			}
+			versions[i].Metadata["prerelease"] = true
+		}
 	}
 
+	sortVersions(versions)
 	return versions, nil
 }
 
+// sortVersions orders versions newest first. The API lists them in publish
+// order, which isn't version order once a fix is released for an older
+// line, so they are compared as semver, with the publish date deciding
+// between equal versions such as "1.0" and "1.0.0".
+func sortVersions(versions []core.Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		if c := core.CompareVersions(ecosystem, versions[i].Number, versions[j].Number); c != 0 {
+			return c > 0
+		}
+		return versions[i].PublishedAt.After(versions[j].PublishedAt)
+	})
+}
+
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
 	url := fmt.Sprintf("%s/api/v1/pods/%s", r.baseURL, name)
 
@@ -175,9 +265,37 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		})
 	}
 
+	// Platform-specific dependencies are listed once per platform. A pod
+	// that's also a top-level dependency applies everywhere already.
+	for platform, ps := range spec.platformSpecs() {
+		for depName, req := range ps.Dependencies {
+			if _, ok := spec.Dependencies[depName]; ok {
+				continue
+			}
+			deps = append(deps, core.Dependency{
+				Name:         depName,
+				Requirements: formatRequirement(req),
+				Scope:        core.Runtime,
+				Metadata:     map[string]any{"platform": platform},
+			})
+		}
+	}
+
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return platformOf(deps[i]) < platformOf(deps[j])
+	})
+
 	return deps, nil
 }
 
+func platformOf(d core.Dependency) string {
+	platform, _ := d.Metadata["platform"].(string)
+	return platform
+}
+
 func formatRequirement(req interface{}) string {
 	switch v := req.(type) {
 	case string:
@@ -29,6 +29,7 @@ func TestFetchPackage(t *testing.T) {
 						Name:        "Alamofire",
 						Version:     "5.8.0",
 						Summary:     "Elegant HTTP Networking in Swift",
+						Description: "Alamofire is an HTTP networking library written in Swift.",
 						Homepage:    "https://github.com/Alamofire/Alamofire",
 						License:     "MIT",
 						Source:      map[string]interface{}{"git": "https://github.com/Alamofire/Alamofire.git"},
@@ -51,7 +52,10 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Name != "Alamofire" {
 		t.Errorf("expected name 'Alamofire', got %q", pkg.Name)
 	}
-	if pkg.Description != "Elegant HTTP Networking in Swift" {
+	if pkg.Summary != "Elegant HTTP Networking in Swift" {
+		t.Errorf("unexpected summary: %q", pkg.Summary)
+	}
+	if pkg.Description != "Alamofire is an HTTP networking library written in Swift." {
 		t.Errorf("unexpected description: %q", pkg.Description)
 	}
 	if pkg.Repository != "https://github.com/Alamofire/Alamofire" {
@@ -60,6 +64,9 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Licenses != "MIT" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if pkg.LatestVersion != "5.8.0" {
+		t.Errorf("expected latest version '5.8.0', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchPackageWithMapLicense(t *testing.T) {
@@ -127,6 +134,116 @@ func TestFetchVersions(t *testing.T) {
 	}
 }
 
+func TestDeprecatedPod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"name": "OldPod",
+			"versions": [
+				{"name": "1.0.0", "spec": {"name": "OldPod", "version": "1.0.0"}},
+				{"name": "1.1.0", "spec": {"name": "OldPod", "version": "1.1.0", "deprecated_in_favor_of": "NewPod"}}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+
+	pkg, err := reg.FetchPackage(context.Background(), "OldPod")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Metadata["deprecated"] != true {
+		t.Errorf("expected the pod to be deprecated, got %v", pkg.Metadata)
+	}
+	if pkg.Metadata["deprecated_in_favor_of"] != "NewPod" {
+		t.Errorf("expected replacement NewPod, got %v", pkg.Metadata["deprecated_in_favor_of"])
+	}
+
+	versions, err := reg.FetchVersions(context.Background(), "OldPod")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	for _, v := range versions {
+		if v.Status != core.StatusDeprecated {
+			t.Errorf("%s: expected deprecated status, got %q", v.Number, v.Status)
+		}
+		if v.Metadata["deprecated_in_favor_of"] != "NewPod" {
+			t.Errorf("%s: expected replacement NewPod, got %v", v.Number, v.Metadata)
+		}
+	}
+}
+
+func TestDeprecatedVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"name": "SomePod",
+			"versions": [
+				{"name": "1.0.0", "spec": {"name": "SomePod", "version": "1.0.0", "deprecated": true}},
+				{"name": "2.0.0", "spec": {"name": "SomePod", "version": "2.0.0"}}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+
+	pkg, err := reg.FetchPackage(context.Background(), "SomePod")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if _, ok := pkg.Metadata["deprecated"]; ok {
+		t.Errorf("expected the pod not to be deprecated, got %v", pkg.Metadata)
+	}
+
+	versions, err := reg.FetchVersions(context.Background(), "SomePod")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if versions[1].Status != core.StatusDeprecated || versions[0].Status != core.StatusNone {
+		t.Errorf("expected only 1.0.0 to be deprecated, got %q and %q", versions[1].Status, versions[0].Status)
+	}
+}
+
+func TestFetchVersionsOrderAndPrereleases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"name": "Alamofire",
+			"versions": [
+				{"name": "5.0.0", "created_at": "2020-01-01T00:00:00Z"},
+				{"name": "5.1.0", "created_at": "2020-03-01T00:00:00Z"},
+				{"name": "6.0.0-beta.1", "created_at": "2020-05-01T00:00:00Z"},
+				{"name": "5.0.1", "created_at": "2020-06-01T00:00:00Z"},
+				{"name": "5.1", "created_at": "2020-02-01T00:00:00Z"}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "Alamofire")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	want := []string{"6.0.0-beta.1", "5.1.0", "5.1", "5.0.1", "5.0.0"}
+	if len(versions) != len(want) {
+		t.Fatalf("expected %d versions, got %d", len(want), len(versions))
+	}
+	for i, number := range want {
+		if versions[i].Number != number {
+			t.Errorf("position %d: expected %s, got %s", i, number, versions[i].Number)
+		}
+		prerelease, _ := versions[i].Metadata["prerelease"].(bool)
+		if prerelease != (number == "6.0.0-beta.1") {
+			t.Errorf("%s: unexpected prerelease flag %v", number, prerelease)
+		}
+	}
+
+	if stable := core.StableVersions(reg, versions); len(stable) == 0 || stable[0].Number != "5.1.0" {
+		t.Errorf("expected StableVersions to skip the beta, got %v", stable)
+	}
+}
+
 func TestFetchDependencies(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := podResponse{
@@ -172,6 +289,79 @@ func TestFetchDependencies(t *testing.T) {
 	}
 }
 
+func TestFetchDependenciesPlatforms(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := podResponse{
+			Name: "Kingfisher",
+			Versions: []versionInfo{
+				{
+					Name: "7.0.0",
+					Spec: podSpec{
+						Name:    "Kingfisher",
+						Version: "7.0.0",
+						Dependencies: map[string]interface{}{
+							"SwiftLint": []interface{}{},
+						},
+						IOS: &platformSpec{
+							Dependencies: map[string]interface{}{
+								"SDWebImage": "~> 5.0",
+								"SwiftLint":  "~> 0.50",
+							},
+						},
+						OSX: &platformSpec{
+							Dependencies: map[string]interface{}{
+								"SDWebImage": "~> 5.1",
+							},
+						},
+						TVOS: &platformSpec{},
+					},
+				},
+				{
+					Name: "6.0.0",
+					Spec: podSpec{Name: "Kingfisher", Version: "6.0.0"},
+				},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "Kingfisher", "7.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	expected := []struct {
+		name         string
+		requirements string
+		platform     any
+	}{
+		{"SDWebImage", "~> 5.0", "ios"},
+		{"SDWebImage", "~> 5.1", "osx"},
+		{"SwiftLint", "", nil},
+	}
+	if len(deps) != len(expected) {
+		t.Fatalf("expected %d dependencies, got %d: %+v", len(expected), len(deps), deps)
+	}
+	for i, want := range expected {
+		d := deps[i]
+		if d.Name != want.name || d.Requirements != want.requirements || d.Metadata["platform"] != want.platform {
+			t.Errorf("dependency %d: expected %s %q (%v), got %s %q (%v)",
+				i, want.name, want.requirements, want.platform, d.Name, d.Requirements, d.Metadata["platform"])
+		}
+	}
+
+	// A version without any dependencies isn't an error
+	deps, err = reg.FetchDependencies(context.Background(), "Kingfisher", "6.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 0 {
+		t.Errorf("expected no dependencies, got %d", len(deps))
+	}
+}
+
 func TestFetchMaintainers(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := podResponse{
@@ -4,7 +4,10 @@ package conda
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
@@ -12,22 +15,38 @@ import (
 )
 
 const (
-	DefaultURL     = "https://api.anaconda.org"
-	DefaultChannel = "conda-forge"
-	ecosystem      = "conda"
+	DefaultURL         = "https://api.anaconda.org"
+	DefaultChannel     = "conda-forge"
+	DefaultRepodataURL = "https://conda.anaconda.org"
+	DefaultPlatform    = "linux-64"
+	ecosystem          = "conda"
+
+	// Channels republish repodata as packages are uploaded, and each file
+	// can be hundreds of megabytes parsed, so the cache is kept short and
+	// small.
+	repodataCacheTTL     = 10 * time.Minute
+	repodataCacheEntries = 8
 )
 
 func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"build", "channel", "doc_url", "downloads", "license_url", "owner",
+		"size", "subdir",
+	)
 }
 
 type Registry struct {
-	baseURL string
-	channel string
-	client  *core.Client
-	urls    *URLs
+	baseURL     string
+	channel     string
+	client      *core.Client
+	urls        *URLs
+	repodataURL string
+	platform    string // empty unless versions/dependencies come from repodata
+	cache       *repodataCache
+	skipPython  bool
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -35,9 +54,11 @@ func New(baseURL string, client *core.Client) *Registry {
 		baseURL = DefaultURL
 	}
 	r := &Registry{
-		baseURL: strings.TrimSuffix(baseURL, "/"),
-		channel: DefaultChannel,
-		client:  client,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		channel:     DefaultChannel,
+		client:      client,
+		repodataURL: DefaultRepodataURL,
+		cache:       newRepodataCache(repodataCacheTTL, repodataCacheEntries),
 	}
 	r.urls = &URLs{baseURL: r.baseURL, channel: r.channel}
 	return r
@@ -45,12 +66,42 @@ func New(baseURL string, client *core.Client) *Registry {
 
 // WithChannel returns a new Registry configured to use the specified channel
 func (r *Registry) WithChannel(channel string) *Registry {
-	return &Registry{
-		baseURL: r.baseURL,
-		channel: channel,
-		client:  r.client,
-		urls:    &URLs{baseURL: r.baseURL, channel: channel},
+	copy := *r
+	copy.channel = channel
+	copy.urls = &URLs{baseURL: r.baseURL, channel: channel}
+	return &copy
+}
+
+// WithRepodata returns a new Registry that reads versions and dependencies
+// from the channel's repodata.json for the noarch subdir plus the given
+// platform (e.g. "linux-64", "osx-arm64") instead of the anaconda.org API.
+// This works against any conda channel server, including private ones.
+// An empty platform uses DefaultPlatform. repodataURL is the channel host
+// (DefaultRepodataURL if empty). Parsed repodata is cached on the registry
+// and shared with copies made from it, for up to ten minutes per file and
+// at most eight channel subdirs, dropping the oldest first.
+func (r *Registry) WithRepodata(repodataURL, platform string) *Registry {
+	if repodataURL == "" {
+		repodataURL = DefaultRepodataURL
 	}
+	if platform == "" {
+		platform = DefaultPlatform
+	}
+	copy := *r
+	copy.repodataURL = strings.TrimSuffix(repodataURL, "/")
+	copy.platform = platform
+	return &copy
+}
+
+// WithSkipPython returns a copy of the registry that, when enabled, leaves
+// the python dependency out of FetchDependencies. Nearly every Python
+// package depends on python itself, pinned to the interpreter versions it
+// was built for, which is noise when looking for the libraries a package
+// needs.
+func (r *Registry) WithSkipPython(skip bool) *Registry {
+	copy := *r
+	copy.skipPython = skip
+	return &copy
 }
 
 func (r *Registry) Ecosystem() string {
@@ -62,38 +113,39 @@ func (r *Registry) URLs() core.URLBuilder {
 }
 
 type packageResponse struct {
-	Name          string        `json:"name"`
-	Summary       string        `json:"summary"`
-	Description   string        `json:"description"`
-	License       string        `json:"license"`
-	LicenseURL    string        `json:"license_url"`
-	DevURL        string        `json:"dev_url"`
-	HomeURL       string        `json:"home"`
-	DocURL        string        `json:"doc_url"`
-	SourceURL     string        `json:"source_url"`
-	Versions      []string      `json:"versions"`
-	LatestVersion string        `json:"latest_version"`
-	Files         []fileInfo    `json:"files"`
-	Owner         string        `json:"owner"`
-	PublicAccess  bool          `json:"public_access"`
+	Name          string     `json:"name"`
+	Summary       string     `json:"summary"`
+	Description   string     `json:"description"`
+	License       string     `json:"license"`
+	LicenseURL    string     `json:"license_url"`
+	DevURL        string     `json:"dev_url"`
+	HomeURL       string     `json:"home"`
+	DocURL        string     `json:"doc_url"`
+	SourceURL     string     `json:"source_url"`
+	Versions      []string   `json:"versions"`
+	LatestVersion string     `json:"latest_version"`
+	Files         []fileInfo `json:"files"`
+	Owner         string     `json:"owner"`
+	PublicAccess  bool       `json:"public_access"`
 }
 
 type fileInfo struct {
-	Version   string            `json:"version"`
-	Basename  string            `json:"basename"`
-	Attrs     fileAttrs         `json:"attrs"`
-	UploadTime int64            `json:"upload_time"`
-	MD5       string            `json:"md5"`
-	SHA256    string            `json:"sha256"`
-	Size      int64             `json:"size"`
-	Ndownloads int64            `json:"ndownloads"`
+	Version    string    `json:"version"`
+	Basename   string    `json:"basename"`
+	Attrs      fileAttrs `json:"attrs"`
+	UploadTime int64     `json:"upload_time"`
+	MD5        string    `json:"md5"`
+	SHA256     string    `json:"sha256"`
+	Size       int64     `json:"size"`
+	Ndownloads int64     `json:"ndownloads"`
 }
 
 type fileAttrs struct {
-	Depends  []string `json:"depends"`
-	Arch     string   `json:"arch"`
-	Platform string   `json:"platform"`
-	BuildNumber int   `json:"build_number"`
+	Depends     []string `json:"depends"`
+	Constrains  []string `json:"constrains"`
+	Arch        string   `json:"arch"`
+	Platform    string   `json:"platform"`
+	BuildNumber int      `json:"build_number"`
 }
 
 // parsePackageName parses a package name that may include a channel prefix
@@ -127,7 +179,7 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		description = resp.Description
 	}
 
-	repository := urlparser.Parse(resp.DevURL)
+	repository := core.NormalizeRepository(resp.DevURL)
 	if repository == "" {
 		repository = urlparser.Parse(resp.SourceURL)
 	}
@@ -155,6 +207,10 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		channel = r.channel
 	}
 
+	if r.platform != "" {
+		return r.fetchVersionsFromRepodata(ctx, name, channel, pkgName)
+	}
+
 	url := fmt.Sprintf("%s/package/%s/%s", r.baseURL, channel, pkgName)
 
 	var resp packageResponse
@@ -212,6 +268,10 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		channel = r.channel
 	}
 
+	if r.platform != "" {
+		return r.fetchDependenciesFromRepodata(ctx, name, channel, pkgName, version)
+	}
+
 	url := fmt.Sprintf("%s/package/%s/%s", r.baseURL, channel, pkgName)
 
 	var resp packageResponse
@@ -223,39 +283,71 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 	}
 
 	// Find dependencies for the specific version
+	for _, f := range resp.Files {
+		if f.Version == version {
+			return r.dependenciesFromSpecs(f.Attrs.Depends, f.Attrs.Constrains), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// dependenciesFromSpecs converts a build's depends and constrains lists.
+// Constrains (run_constrained in the recipe) only restrict the versions of
+// packages that something else installs, so they are Optional. A build
+// string pin is kept in Metadata["build"].
+func (r *Registry) dependenciesFromSpecs(depends, constrains []string) []core.Dependency {
 	var deps []core.Dependency
 	seen := make(map[string]bool)
 
-	for _, f := range resp.Files {
-		if f.Version == version {
-			for _, d := range f.Attrs.Depends {
-				depName, requirements := parseDependency(d)
-				if depName == "" || seen[depName] {
-					continue
-				}
-				seen[depName] = true
+	add := func(specs []string, scope core.Scope) {
+		for _, d := range specs {
+			depName, requirements, build := parseDependency(d)
+			if depName == "" || seen[depName] || (r.skipPython && depName == "python") {
+				continue
+			}
+			seen[depName] = true
 
-				deps = append(deps, core.Dependency{
-					Name:         depName,
-					Requirements: requirements,
-					Scope:        core.Runtime,
-				})
+			dep := core.Dependency{
+				Name:         depName,
+				Requirements: requirements,
+				Scope:        scope,
+			}
+			if build != "" {
+				dep.Metadata = map[string]any{"build": build}
 			}
-			break
+			deps = append(deps, dep)
 		}
 	}
+	add(depends, core.Runtime)
+	add(constrains, core.Optional)
 
-	return deps, nil
+	return deps
 }
 
-func parseDependency(dep string) (name, requirements string) {
-	// Conda dependency format: "name version_constraint" or just "name"
-	// Examples: "python >=3.8", "numpy", "pandas >=1.0,<2.0"
-	dep = strings.TrimSpace(dep)
-	parts := strings.SplitN(dep, " ", 2)
-	name = parts[0]
-	if len(parts) > 1 {
-		requirements = strings.TrimSpace(parts[1])
+// constraintSpacing matches whitespace inside a version constraint, after
+// an operator or around a "," or "|" separator, as in "pandas >=1.0, <2.0".
+var constraintSpacing = regexp.MustCompile(`\s*([,|])\s*|([<>=!~])\s+`)
+
+func parseDependency(dep string) (name, requirements, build string) {
+	// Conda dependency format: "name [version_constraint [build_string]]"
+	// Examples: "python >=3.8", "numpy", "pandas >=1.0,<2.0",
+	// "python_abi 3.11.* *_cp311", "mkl * h8d4b97c_803"
+	fields := strings.Fields(constraintSpacing.ReplaceAllString(dep, "$1$2"))
+	if len(fields) == 0 {
+		return "", "", ""
+	}
+	name = fields[0]
+	// Specs written without a space, like "numpy>=1.20"
+	if i := strings.IndexAny(name, "<>=!~"); i > 0 {
+		fields = append([]string{name[:i], name[i:]}, fields[1:]...)
+		name = fields[0]
+	}
+	if len(fields) > 1 {
+		requirements = fields[1]
+	}
+	if len(fields) > 2 {
+		build = fields[2]
 	}
 	return
 }
@@ -285,6 +377,233 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 	}}, nil
 }
 
+// repodata is a channel subdir index, e.g. conda-forge/noarch/repodata.json.
+// Packages are keyed by filename; .conda files are listed separately from
+// .tar.bz2 ones.
+type repodata struct {
+	Packages      map[string]repodataRecord `json:"packages"`
+	CondaPackages map[string]repodataRecord `json:"packages.conda"`
+}
+
+type repodataRecord struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Build       string   `json:"build"`
+	BuildNumber int      `json:"build_number"`
+	Depends     []string `json:"depends"`
+	Constrains  []string `json:"constrains"`
+	License     string   `json:"license"`
+	MD5         string   `json:"md5"`
+	SHA256      string   `json:"sha256"`
+	Size        int64    `json:"size"`
+	Subdir      string   `json:"subdir"`
+	Timestamp   int64    `json:"timestamp"` // milliseconds
+}
+
+// repodataCache holds parsed repodata keyed by URL. The files run to
+// hundreds of megabytes for busy channels, so each is fetched once per ttl,
+// and only maxEntries are kept.
+type repodataCache struct {
+	mu         sync.Mutex
+	entries    map[string]cachedRepodata
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+}
+
+type cachedRepodata struct {
+	data      *repodata
+	fetchedAt time.Time
+}
+
+func newRepodataCache(ttl time.Duration, maxEntries int) *repodataCache {
+	return &repodataCache{
+		entries:    make(map[string]cachedRepodata),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// get returns the repodata cached for url, unless it has expired.
+func (c *repodataCache) get(url string) (*repodata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[url]
+	if !ok {
+		return nil, false
+	}
+	if c.now().Sub(cached.fetchedAt) >= c.ttl {
+		delete(c.entries, url)
+		return nil, false
+	}
+	return cached.data, true
+}
+
+// put caches data for url, first dropping expired entries and then the
+// oldest ones until there is room.
+func (c *repodataCache) put(url string, data *repodata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for key, cached := range c.entries {
+		if now.Sub(cached.fetchedAt) >= c.ttl {
+			delete(c.entries, key)
+		}
+	}
+	for len(c.entries) >= c.maxEntries {
+		var oldest string
+		for key, cached := range c.entries {
+			if oldest == "" || cached.fetchedAt.Before(c.entries[oldest].fetchedAt) {
+				oldest = key
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[url] = cachedRepodata{data: data, fetchedAt: now}
+}
+
+func (r *Registry) fetchRepodata(ctx context.Context, channel, subdir string) (*repodata, error) {
+	url := fmt.Sprintf("%s/%s/%s/repodata.json", r.repodataURL, channel, subdir)
+
+	if cached, ok := r.cache.get(url); ok {
+		return cached, nil
+	}
+
+	var data repodata
+	if err := r.client.GetJSONStream(ctx, url, &data); err != nil {
+		return nil, err
+	}
+	r.cache.put(url, &data)
+
+	return &data, nil
+}
+
+// repodataRecords returns every file record for pkgName across the noarch
+// and platform subdirs, ordered by filename so results are deterministic.
+func (r *Registry) repodataRecords(ctx context.Context, channel, pkgName string) ([]repodataRecord, error) {
+	var records []repodataRecord
+	found := false
+
+	for _, subdir := range []string{"noarch", r.platform} {
+		data, err := r.fetchRepodata(ctx, channel, subdir)
+		if err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+
+		for _, files := range []map[string]repodataRecord{data.Packages, data.CondaPackages} {
+			filenames := make([]string, 0, len(files))
+			for filename, rec := range files {
+				if rec.Name == pkgName {
+					filenames = append(filenames, filename)
+				}
+			}
+			sort.Strings(filenames)
+			for _, filename := range filenames {
+				records = append(records, files[filename])
+			}
+		}
+	}
+
+	if !found {
+		return nil, &core.HTTPError{StatusCode: 404, URL: fmt.Sprintf("%s/%s", r.repodataURL, channel)}
+	}
+	return records, nil
+}
+
+func (r *Registry) fetchVersionsFromRepodata(ctx context.Context, name, channel, pkgName string) ([]core.Version, error) {
+	records, err := r.repodataRecords(ctx, channel, pkgName)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+
+	// One version per version string, taken from its first file; the
+	// publish time is the earliest upload across its builds.
+	versionMap := make(map[string]*core.Version)
+	var order []string
+	for _, rec := range records {
+		var publishedAt time.Time
+		if rec.Timestamp > 0 {
+			publishedAt = time.UnixMilli(rec.Timestamp)
+		}
+
+		if v, exists := versionMap[rec.Version]; exists {
+			if !publishedAt.IsZero() && (v.PublishedAt.IsZero() || publishedAt.Before(v.PublishedAt)) {
+				v.PublishedAt = publishedAt
+			}
+			continue
+		}
+
+		var integrity string
+		if rec.SHA256 != "" {
+			integrity = "sha256-" + rec.SHA256
+		} else if rec.MD5 != "" {
+			integrity = "md5-" + rec.MD5
+		}
+
+		versionMap[rec.Version] = &core.Version{
+			Number:      rec.Version,
+			PublishedAt: publishedAt,
+			Integrity:   integrity,
+			Licenses:    rec.License,
+			Metadata: map[string]any{
+				"build":  rec.Build,
+				"subdir": rec.Subdir,
+				"size":   rec.Size,
+			},
+		}
+		order = append(order, rec.Version)
+	}
+
+	versions := make([]core.Version, len(order))
+	for i, v := range order {
+		versions[i] = *versionMap[v]
+	}
+
+	// Newest first
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].PublishedAt.After(versions[j].PublishedAt)
+	})
+
+	return versions, nil
+}
+
+func (r *Registry) fetchDependenciesFromRepodata(ctx context.Context, name, channel, pkgName, version string) ([]core.Dependency, error) {
+	records, err := r.repodataRecords(ctx, channel, pkgName)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+
+	// Builds of the same version can differ in their pins; use the highest build number
+	var best *repodataRecord
+	for i, rec := range records {
+		if rec.Version != version {
+			continue
+		}
+		if best == nil || rec.BuildNumber > best.BuildNumber {
+			best = &records[i]
+		}
+	}
+	if best == nil {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+	}
+
+	return r.dependenciesFromSpecs(best.Depends, best.Constrains), nil
+}
+
 type URLs struct {
 	baseURL string
 	channel string
@@ -315,13 +634,15 @@ func (u *URLs) Documentation(name, version string) string {
 	return fmt.Sprintf("https://anaconda.org/%s/%s", channel, pkgName)
 }
 
+// PURL returns a conda PURL with the channel as a qualifier, per the purl
+// spec: pkg:conda/numpy@1.26.0?channel=conda-forge
 func (u *URLs) PURL(name, version string) string {
 	channel, pkgName := parsePackageName(name)
 	if channel == "" {
 		channel = u.channel
 	}
 	if version != "" {
-		return fmt.Sprintf("pkg:conda/%s/%s@%s", channel, pkgName, version)
+		return fmt.Sprintf("pkg:conda/%s@%s?channel=%s", pkgName, version, channel)
 	}
-	return fmt.Sprintf("pkg:conda/%s/%s", channel, pkgName)
+	return fmt.Sprintf("pkg:conda/%s?channel=%s", pkgName, channel)
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
 )
@@ -224,21 +225,192 @@ func TestParseDependency(t *testing.T) {
 		input string
 		name  string
 		req   string
+		build string
 	}{
-		{"numpy", "numpy", ""},
-		{"python >=3.8", "python", ">=3.8"},
-		{"pandas >=1.0,<2.0", "pandas", ">=1.0,<2.0"},
+		{"numpy", "numpy", "", ""},
+		{"python >=3.8", "python", ">=3.8", ""},
+		{"pandas >=1.0,<2.0", "pandas", ">=1.0,<2.0", ""},
+		{"python 3.10.*", "python", "3.10.*", ""},
+		{"python_abi 3.11.* *_cp311", "python_abi", "3.11.*", "*_cp311"},
+		{"mkl * h8d4b97c_803", "mkl", "*", "h8d4b97c_803"},
+		{"numpy>=1.20,<2", "numpy", ">=1.20,<2", ""},
+		{"pandas >=1.0, <2.0", "pandas", ">=1.0,<2.0", ""},
+		{"python >= 3.8 , < 4", "python", ">=3.8,<4", ""},
+		{"openssl >=3.0,<4.0 h1234_0", "openssl", ">=3.0,<4.0", "h1234_0"},
 	}
 
 	for _, tt := range tests {
-		name, req := parseDependency(tt.input)
-		if name != tt.name || req != tt.req {
-			t.Errorf("parseDependency(%q) = (%q, %q), want (%q, %q)",
-				tt.input, name, req, tt.name, tt.req)
+		name, req, build := parseDependency(tt.input)
+		if name != tt.name || req != tt.req || build != tt.build {
+			t.Errorf("parseDependency(%q) = (%q, %q, %q), want (%q, %q, %q)",
+				tt.input, name, req, build, tt.name, tt.req, tt.build)
 		}
 	}
 }
 
+const noarchRepodata = `{
+  "info": {"subdir": "noarch"},
+  "packages": {
+    "tzdata-2024a-h0c530f3_0.tar.bz2": {"name": "tzdata", "version": "2024a", "build": "h0c530f3_0", "build_number": 0, "depends": [], "sha256": "aaa", "timestamp": 1707000000000, "subdir": "noarch"}
+  },
+  "packages.conda": {
+    "pandas-2.1.0-pyhd8ed1ab_0.conda": {"name": "pandas", "version": "2.1.0", "build": "pyhd8ed1ab_0", "build_number": 0, "depends": ["python >=3.9", "numpy >=1.22.4"], "license": "BSD-3-Clause", "sha256": "abc", "timestamp": 1693000000000, "subdir": "noarch"},
+    "pandas-2.1.0-pyhd8ed1ab_1.conda": {"name": "pandas", "version": "2.1.0", "build": "pyhd8ed1ab_1", "build_number": 1, "depends": ["python >=3.9", "numpy >=1.23", "pytz >=2020.1"], "license": "BSD-3-Clause", "sha256": "abd", "timestamp": 1694000000000, "subdir": "noarch"}
+  }
+}`
+
+const linuxRepodata = `{
+  "info": {"subdir": "linux-64"},
+  "packages": {
+    "pandas-1.5.3-py311h2872171_0.tar.bz2": {"name": "pandas", "version": "1.5.3", "build": "py311h2872171_0", "build_number": 0, "depends": ["python >=3.11,<3.12.0a0", "numpy >=1.21"], "license": "BSD-3-Clause", "md5": "def", "timestamp": 1675000000000, "subdir": "linux-64"}
+  },
+  "packages.conda": {}
+}`
+
+func TestFetchVersionsFromRepodata(t *testing.T) {
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/conda-forge/noarch/repodata.json":
+			_, _ = w.Write([]byte(noarchRepodata))
+		case "/conda-forge/linux-64/repodata.json":
+			_, _ = w.Write([]byte(linuxRepodata))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New("", core.DefaultClient()).WithRepodata(server.URL, "")
+
+	versions, err := reg.FetchVersions(context.Background(), "conda-forge/pandas")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if versions[0].Number != "2.1.0" || versions[1].Number != "1.5.3" {
+		t.Errorf("expected newest first, got %q, %q", versions[0].Number, versions[1].Number)
+	}
+	if versions[0].Integrity != "sha256-abc" {
+		t.Errorf("unexpected integrity: %q", versions[0].Integrity)
+	}
+	if versions[1].Integrity != "md5-def" {
+		t.Errorf("expected md5 integrity, got %q", versions[1].Integrity)
+	}
+	if versions[0].PublishedAt.UnixMilli() != 1693000000000 {
+		t.Errorf("expected earliest build timestamp, got %v", versions[0].PublishedAt)
+	}
+
+	deps, err := reg.FetchDependencies(context.Background(), "pandas", "2.1.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 3 {
+		t.Fatalf("expected 3 dependencies from highest build, got %d", len(deps))
+	}
+	reqMap := make(map[string]string)
+	for _, d := range deps {
+		reqMap[d.Name] = d.Requirements
+	}
+	if reqMap["numpy"] != ">=1.23" {
+		t.Errorf("unexpected numpy requirement: %q", reqMap["numpy"])
+	}
+
+	// repodata is cached across calls
+	for path, n := range requests {
+		if n != 1 {
+			t.Errorf("expected %s to be fetched once, got %d", path, n)
+		}
+	}
+
+	if _, err := reg.FetchVersions(context.Background(), "missing"); err == nil {
+		t.Error("expected not found error for missing package")
+	}
+}
+
+func TestRepodataCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := newRepodataCache(time.Minute, 2)
+	cache.now = func() time.Time { return now }
+
+	cache.put("a", &repodata{})
+	now = now.Add(time.Second)
+	cache.put("b", &repodata{})
+	now = now.Add(time.Second)
+	cache.put("c", &repodata{})
+
+	if _, ok := cache.get("a"); ok {
+		t.Error("expected the oldest entry to be evicted")
+	}
+	if _, ok := cache.get("b"); !ok {
+		t.Error("expected b to be cached")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.get("c"); ok {
+		t.Error("expected c to have expired")
+	}
+	if len(cache.entries) != 1 {
+		t.Errorf("expected expired entries to be dropped on access, got %d entries", len(cache.entries))
+	}
+}
+
+func TestRepodataDependencySpecs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/conda-forge/linux-64/repodata.json":
+			_, _ = w.Write([]byte(`{"packages.conda": {
+  "scipy-1.11.0-py311h64a7726_0.conda": {"name": "scipy", "version": "1.11.0", "build": "py311h64a7726_0", "build_number": 0,
+    "depends": ["python >=3.11,<3.12.0a0", "python_abi 3.11.* *_cp311", "libblas >=3.9.0,<4.0a0", "numpy >=1.23.5,<2.0a0"],
+    "constrains": ["libopenblas * *openmp*"], "subdir": "linux-64"}
+}}`))
+		default:
+			_, _ = w.Write([]byte(`{"packages": {}}`))
+		}
+	}))
+	defer server.Close()
+
+	reg := New("", core.DefaultClient()).WithRepodata(server.URL, "")
+
+	deps, err := reg.FetchDependencies(context.Background(), "scipy", "1.11.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 5 || deps[0].Name != "python" {
+		t.Fatalf("expected python and 4 other dependencies, got %v", deps)
+	}
+	byName := make(map[string]core.Dependency)
+	for _, d := range deps {
+		byName[d.Name] = d
+	}
+	if abi := byName["python_abi"]; abi.Requirements != "3.11.*" || abi.Metadata["build"] != "*_cp311" {
+		t.Errorf("expected the build string in metadata, got %+v", abi)
+	}
+	if numpy := byName["numpy"]; numpy.Requirements != ">=1.23.5,<2.0a0" || numpy.Metadata != nil {
+		t.Errorf("unexpected numpy dependency: %+v", numpy)
+	}
+	if blas := byName["libopenblas"]; blas.Scope != core.Optional || blas.Metadata["build"] != "*openmp*" {
+		t.Errorf("expected constrains to be optional, got %+v", blas)
+	}
+
+	deps, err = reg.WithSkipPython(true).FetchDependencies(context.Background(), "scipy", "1.11.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	for _, d := range deps {
+		if d.Name == "python" {
+			t.Error("expected python to be skipped")
+		}
+	}
+	if len(deps) != 4 {
+		t.Errorf("expected 4 dependencies without python, got %d", len(deps))
+	}
+}
+
 func TestURLBuilder(t *testing.T) {
 	reg := New("https://api.anaconda.org", nil)
 	urls := reg.URLs()
@@ -250,8 +422,9 @@ func TestURLBuilder(t *testing.T) {
 	}{
 		{"registry", func() string { return urls.Registry("numpy", "1.26.0") }, "https://anaconda.org/conda-forge/numpy/1.26.0"},
 		{"registry_with_channel", func() string { return urls.Registry("bioconda/samtools", "1.18") }, "https://anaconda.org/bioconda/samtools/1.18"},
-		{"purl", func() string { return urls.PURL("numpy", "1.26.0") }, "pkg:conda/conda-forge/numpy@1.26.0"},
-		{"purl_with_channel", func() string { return urls.PURL("bioconda/samtools", "1.18") }, "pkg:conda/bioconda/samtools@1.18"},
+		{"purl", func() string { return urls.PURL("numpy", "1.26.0") }, "pkg:conda/numpy@1.26.0?channel=conda-forge"},
+		{"purl_with_channel", func() string { return urls.PURL("bioconda/samtools", "1.18") }, "pkg:conda/samtools@1.18?channel=bioconda"},
+		{"purl_no_version", func() string { return urls.PURL("bioconda/samtools", "") }, "pkg:conda/samtools?channel=bioconda"},
 	}
 
 	for _, tt := range tests {
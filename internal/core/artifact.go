@@ -0,0 +1,35 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ArtifactSize returns the size in bytes of the artifact
+// URLBuilder.Download points at for a version of a package, read from the
+// Content-Length of a HEAD request, so callers can plan before
+// downloading. It returns ErrNoDownloadURL if the registry doesn't build
+// download URLs, an HTTPError if the artifact can't be found, and an error
+// if the server doesn't report a length. A nil client uses DefaultClient.
+func ArtifactSize(ctx context.Context, reg Registry, name, version string, client *Client) (int64, error) {
+	downloadURL := reg.URLs().Download(name, version)
+	if downloadURL == "" {
+		return 0, fmt.Errorf("%s: %s %s: %w", reg.Ecosystem(), name, version, ErrNoDownloadURL)
+	}
+
+	if client == nil {
+		client = DefaultClient()
+	}
+	resp, err := client.head(ctx, downloadURL)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, &HTTPError{StatusCode: resp.StatusCode, URL: downloadURL}
+	}
+	if resp.ContentLength < 0 {
+		return 0, fmt.Errorf("%s: no Content-Length for %s", reg.Ecosystem(), redactURL(downloadURL))
+	}
+	return resp.ContentLength, nil
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type artifactRegistry struct {
+	Registry
+	urls *BaseURLs
+}
+
+func (r artifactRegistry) Ecosystem() string { return "example" }
+
+func (r artifactRegistry) URLs() URLBuilder { return r.urls }
+
+func TestArtifactSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("expected HEAD, got %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/example-1.0.0.tgz":
+			w.Header().Set("Content-Length", "12345")
+		case "/chunked-1.0.0.tgz":
+			w.Header().Set("Transfer-Encoding", "chunked")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := artifactRegistry{urls: &BaseURLs{DownloadFn: func(name, version string) string {
+		return server.URL + "/" + name + "-" + version + ".tgz"
+	}}}
+
+	size, err := ArtifactSize(context.Background(), reg, "example", "1.0.0", nil)
+	if err != nil {
+		t.Fatalf("ArtifactSize failed: %v", err)
+	}
+	if size != 12345 {
+		t.Errorf("expected size 12345, got %d", size)
+	}
+
+	_, err = ArtifactSize(context.Background(), reg, "missing", "1.0.0", nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || !httpErr.IsNotFound() {
+		t.Errorf("expected a 404 HTTPError, got %v", err)
+	}
+
+	if _, err := ArtifactSize(context.Background(), reg, "chunked", "1.0.0", nil); err == nil {
+		t.Error("expected an error without a Content-Length")
+	}
+}
+
+func TestArtifactSizeNoDownloadURL(t *testing.T) {
+	reg := artifactRegistry{urls: &BaseURLs{}}
+	if _, err := ArtifactSize(context.Background(), reg, "example", "1.0.0", nil); !errors.Is(err, ErrNoDownloadURL) {
+		t.Errorf("expected ErrNoDownloadURL, got %v", err)
+	}
+}
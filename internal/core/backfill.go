@@ -0,0 +1,282 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/git-pkgs/registries/internal/urlparser"
+)
+
+const (
+	// maxTagPages caps how many pages of tags are read from a repository,
+	// so at most 1,000 tags are considered.
+	maxTagPages = 10
+	tagsPerPage = 100
+
+	// tagDateConcurrency limits the per-tag commit lookups made on GitHub.
+	tagDateConcurrency = 4
+)
+
+// BackfillDatesFromRepo returns a copy of versions in which any version
+// without a PublishedAt takes the commit date of the matching git tag in
+// the package's repository. It is meant for ecosystems whose registries
+// don't record publish dates (Julia, LuaRocks, Nimble and some Elm
+// packages), where FetchLatestVersion otherwise falls back to version
+// ordering.
+//
+// Tags match a version when they are equal to it after dropping a leading
+// "v" and any prefix up to the last "/" or "@", so "v1.2.3",
+// "release/1.2.3" and "pkg@1.2.3" all match "1.2.3". A version with a
+// numeric revision suffix, such as LuaRocks' "1.2.3-1", also matches the
+// tag for "1.2.3".
+//
+// Only GitHub and GitLab repositories are supported; for other hosts the
+// versions are returned unchanged. The cost is up to 10 requests to list
+// tags plus, on GitHub, one request per dated version, so unauthenticated
+// use quickly reaches GitHub's rate limit. Use WithCredentials for
+// api.github.com to raise it. An error is returned if the tags can't be
+// listed; versions whose commit can't be read are left undated.
+func BackfillDatesFromRepo(ctx context.Context, versions []Version, repoURL string, client *Client) ([]Version, error) {
+	filled := make([]Version, len(versions))
+	copy(filled, versions)
+
+	undated := false
+	for _, v := range filled {
+		if v.PublishedAt.IsZero() {
+			undated = true
+			break
+		}
+	}
+	if !undated {
+		return filled, nil
+	}
+
+	apiBase := urlparser.APIBaseURL(repoURL)
+	ownerRepo := urlparser.ExtractOwnerRepo(repoURL)
+	if ownerRepo == "" {
+		return filled, nil
+	}
+	if client == nil {
+		client = DefaultClient()
+	}
+
+	var dates map[string]time.Time
+	var err error
+	switch apiBase {
+	case githubAPI:
+		dates, err = githubTagDates(ctx, client, apiBase, ownerRepo, filled)
+	case gitlabAPI:
+		dates, err = gitlabTagDates(ctx, client, apiBase, ownerRepo)
+	default:
+		return filled, nil
+	}
+	if err != nil {
+		return filled, err
+	}
+
+	for i, v := range filled {
+		if !v.PublishedAt.IsZero() {
+			continue
+		}
+		if date, ok := lookupTag(dates, v.Number); ok {
+			filled[i].PublishedAt = date
+		}
+	}
+	return filled, nil
+}
+
+// RepoTags returns the tag names of the GitHub or GitLab repository at
+// repoURL in the order the host lists them, reading at most 1,000. Other
+// hosts return nil. It is for registries that are directories of git
+// repositories, where tags are the versions.
+func RepoTags(ctx context.Context, repoURL string, client *Client) ([]string, error) {
+	apiBase := urlparser.APIBaseURL(repoURL)
+	ownerRepo := urlparser.ExtractOwnerRepo(repoURL)
+	if ownerRepo == "" {
+		return nil, nil
+	}
+	if client == nil {
+		client = DefaultClient()
+	}
+
+	var names []string
+	switch apiBase {
+	case githubAPI:
+		tags, err := listGitHubTags(ctx, client, apiBase, ownerRepo)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+	case gitlabAPI:
+		tags, err := listGitLabTags(ctx, client, apiBase, ownerRepo)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+	}
+	return names, nil
+}
+
+const (
+	githubAPI = "https://api.github.com"
+	gitlabAPI = "https://gitlab.com/api/v4"
+)
+
+// tagVersion reduces a tag name to the version it names.
+func tagVersion(tag string) string {
+	if i := strings.LastIndexAny(tag, "/@"); i >= 0 {
+		tag = tag[i+1:]
+	}
+	if len(tag) > 1 && (tag[0] == 'v' || tag[0] == 'V') && tag[1] >= '0' && tag[1] <= '9' {
+		tag = tag[1:]
+	}
+	return tag
+}
+
+// lookupTag finds the entry in byVersion, keyed by tagVersion, for a
+// version number, retrying without a numeric revision suffix.
+func lookupTag[V any](byVersion map[string]V, version string) (V, bool) {
+	version = tagVersion(version)
+	if v, ok := byVersion[version]; ok {
+		return v, true
+	}
+	if base, revision, ok := cutLast(version, "-"); ok && isNumeric(revision) {
+		v, ok := byVersion[base]
+		return v, ok
+	}
+	var zero V
+	return zero, false
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+type githubTag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+type githubCommit struct {
+	Commit struct {
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+// githubTagDates lists the repository's tags and looks up the commit date
+// of each tag matching an undated version. GitHub's tag list doesn't carry
+// dates, hence the extra request per tag.
+func githubTagDates(ctx context.Context, client *Client, apiBase, ownerRepo string, versions []Version) (map[string]time.Time, error) {
+	tags, err := listGitHubTags(ctx, client, apiBase, ownerRepo)
+	if err != nil {
+		return nil, err
+	}
+	shas := make(map[string]string)
+	for _, tag := range tags {
+		if _, ok := shas[tagVersion(tag.Name)]; !ok {
+			shas[tagVersion(tag.Name)] = tag.Commit.SHA
+		}
+	}
+
+	wanted := make(map[string]bool)
+	var commitSHAs []string
+	for _, v := range versions {
+		if !v.PublishedAt.IsZero() {
+			continue
+		}
+		if sha, ok := lookupTag(shas, v.Number); ok && !wanted[sha] {
+			wanted[sha] = true
+			commitSHAs = append(commitSHAs, sha)
+		}
+	}
+
+	commitDates := ParallelMap(ctx, commitSHAs, tagDateConcurrency, func(ctx context.Context, sha string) (*time.Time, error) {
+		var commit githubCommit
+		if err := client.GetJSON(ctx, fmt.Sprintf("%s/repos/%s/commits/%s", apiBase, ownerRepo, sha), &commit); err != nil {
+			return nil, err
+		}
+		date := commit.Commit.Committer.Date
+		return &date, nil
+	})
+
+	dates := make(map[string]time.Time)
+	for version, sha := range shas {
+		if date, ok := commitDates[sha]; ok {
+			dates[version] = *date
+		}
+	}
+	return dates, nil
+}
+
+// listGitHubTags reads up to maxTagPages pages of a repository's tags.
+func listGitHubTags(ctx context.Context, client *Client, apiBase, ownerRepo string) ([]githubTag, error) {
+	var all []githubTag
+	for page := 1; page <= maxTagPages; page++ {
+		var tags []githubTag
+		tagsURL := fmt.Sprintf("%s/repos/%s/tags?per_page=%d&page=%d", apiBase, ownerRepo, tagsPerPage, page)
+		if err := client.GetJSON(ctx, tagsURL, &tags); err != nil {
+			return nil, err
+		}
+		all = append(all, tags...)
+		if len(tags) < tagsPerPage {
+			break
+		}
+	}
+	return all, nil
+}
+
+type gitlabTag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		CommittedDate time.Time `json:"committed_date"`
+	} `json:"commit"`
+}
+
+// gitlabTagDates lists the repository's tags, which include their commit
+// dates.
+func gitlabTagDates(ctx context.Context, client *Client, apiBase, ownerRepo string) (map[string]time.Time, error) {
+	tags, err := listGitLabTags(ctx, client, apiBase, ownerRepo)
+	if err != nil {
+		return nil, err
+	}
+	dates := make(map[string]time.Time)
+	for _, tag := range tags {
+		if _, ok := dates[tagVersion(tag.Name)]; !ok && !tag.Commit.CommittedDate.IsZero() {
+			dates[tagVersion(tag.Name)] = tag.Commit.CommittedDate
+		}
+	}
+	return dates, nil
+}
+
+// listGitLabTags reads up to maxTagPages pages of a project's tags.
+func listGitLabTags(ctx context.Context, client *Client, apiBase, ownerRepo string) ([]gitlabTag, error) {
+	var all []gitlabTag
+	for page := 1; page <= maxTagPages; page++ {
+		var tags []gitlabTag
+		tagsURL := fmt.Sprintf("%s/projects/%s/repository/tags?per_page=%d&page=%d",
+			apiBase, url.PathEscape(ownerRepo), tagsPerPage, page)
+		if err := client.GetJSON(ctx, tagsURL, &tags); err != nil {
+			return nil, err
+		}
+		all = append(all, tags...)
+		if len(tags) < tagsPerPage {
+			break
+		}
+	}
+	return all, nil
+}
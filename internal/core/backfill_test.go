@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackfillDatesFromRepoGitHub(t *testing.T) {
+	var commitRequests atomic.Int32
+	client := newRedirectClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/lunarmodules/luasocket/tags":
+			_, _ = w.Write([]byte(`[
+				{"name": "v3.1.0", "commit": {"sha": "aaa"}},
+				{"name": "v3.0.0", "commit": {"sha": "bbb"}},
+				{"name": "release/2.0", "commit": {"sha": "ccc"}}
+			]`))
+		case "/repos/lunarmodules/luasocket/commits/aaa":
+			commitRequests.Add(1)
+			_, _ = w.Write([]byte(`{"commit": {"committer": {"date": "2022-07-18T12:00:00Z"}}}`))
+		case "/repos/lunarmodules/luasocket/commits/ccc":
+			commitRequests.Add(1)
+			_, _ = w.Write([]byte(`{"commit": {"committer": {"date": "2009-04-10T08:00:00Z"}}}`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	known := time.Date(2013, 6, 14, 0, 0, 0, 0, time.UTC)
+	versions := []Version{
+		{Number: "3.1.0-1"},
+		{Number: "3.0.0-1", PublishedAt: known},
+		{Number: "2.0"},
+		{Number: "scm-3"},
+	}
+
+	filled, err := BackfillDatesFromRepo(context.Background(), versions, "https://github.com/lunarmodules/luasocket", client)
+	if err != nil {
+		t.Fatalf("BackfillDatesFromRepo failed: %v", err)
+	}
+
+	want := []time.Time{
+		time.Date(2022, 7, 18, 12, 0, 0, 0, time.UTC),
+		known,
+		time.Date(2009, 4, 10, 8, 0, 0, 0, time.UTC),
+		{},
+	}
+	for i, v := range filled {
+		if !v.PublishedAt.Equal(want[i]) {
+			t.Errorf("%s: expected published time %v, got %v", v.Number, want[i], v.PublishedAt)
+		}
+	}
+	if n := commitRequests.Load(); n != 2 {
+		t.Errorf("expected 2 commit lookups, got %d", n)
+	}
+	if !versions[0].PublishedAt.IsZero() {
+		t.Error("expected the input versions to be left unchanged")
+	}
+}
+
+func TestBackfillDatesFromRepoGitLab(t *testing.T) {
+	client := newRedirectClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/example%2Fwidget/repository/tags" {
+			t.Errorf("unexpected request: %s", r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"name": "widget@1.2.0", "commit": {"committed_date": "2024-02-01T10:00:00+01:00"}},
+			{"name": "1.1.0", "commit": {"committed_date": "2023-11-05T09:30:00Z"}}
+		]`))
+	}))
+
+	versions := []Version{{Number: "1.2.0"}, {Number: "1.1.0"}, {Number: "1.0.0"}}
+	filled, err := BackfillDatesFromRepo(context.Background(), versions, "https://gitlab.com/example/widget", client)
+	if err != nil {
+		t.Fatalf("BackfillDatesFromRepo failed: %v", err)
+	}
+
+	if want := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC); !filled[0].PublishedAt.Equal(want) {
+		t.Errorf("expected %v, got %v", want, filled[0].PublishedAt)
+	}
+	if want := time.Date(2023, 11, 5, 9, 30, 0, 0, time.UTC); !filled[1].PublishedAt.Equal(want) {
+		t.Errorf("expected %v, got %v", want, filled[1].PublishedAt)
+	}
+	if !filled[2].PublishedAt.IsZero() {
+		t.Errorf("expected untagged version to stay undated, got %v", filled[2].PublishedAt)
+	}
+}
+
+func TestBackfillDatesFromRepoUnsupportedHost(t *testing.T) {
+	client := newRedirectClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s", r.URL)
+	}))
+
+	versions := []Version{{Number: "1.0.0"}}
+	filled, err := BackfillDatesFromRepo(context.Background(), versions, "https://git.example.com/foo/bar", client)
+	if err != nil {
+		t.Fatalf("BackfillDatesFromRepo failed: %v", err)
+	}
+	if len(filled) != 1 || !filled[0].PublishedAt.IsZero() {
+		t.Errorf("expected versions to be returned unchanged, got %v", filled)
+	}
+}
+
+func TestBackfillDatesFromRepoTagError(t *testing.T) {
+	client := newRedirectClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	versions := []Version{{Number: "1.0.0"}}
+	filled, err := BackfillDatesFromRepo(context.Background(), versions, "https://github.com/o/r", client)
+	if err == nil {
+		t.Fatal("expected an error when tags can't be listed")
+	}
+	if len(filled) != 1 {
+		t.Errorf("expected the versions to be returned with the error, got %v", filled)
+	}
+}
+
+func TestRepoTags(t *testing.T) {
+	client := newRedirectClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/nim-lang/Nim/tags" {
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name": "v2.0.0", "commit": {"sha": "aaa"}}, {"name": "v1.6.14", "commit": {"sha": "bbb"}}]`))
+	}))
+
+	tags, err := RepoTags(context.Background(), "https://github.com/nim-lang/Nim.git", client)
+	if err != nil {
+		t.Fatalf("RepoTags failed: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "v2.0.0" || tags[1] != "v1.6.14" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+
+	if tags, err := RepoTags(context.Background(), "https://git.example.com/foo/bar", client); err != nil || tags != nil {
+		t.Errorf("expected no tags for an unsupported host, got %v, %v", tags, err)
+	}
+}
@@ -1,13 +1,20 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,115 +23,414 @@ type RateLimiter interface {
 	Wait(ctx context.Context) error
 }
 
+// Sleeper waits between retries. Sleep returns early with the context's
+// error if ctx is done first.
+type Sleeper interface {
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
 // Client is an HTTP client with retry logic for registry APIs.
+//
+// Set its fields before passing it to New. Each registry keeps its own copy
+// of the client, which records the ecosystem for DecodeError, so later
+// changes to fields such as RateLimiter, Logger, MaxRetries or UserAgent
+// don't reach registries that already exist. The copies still share the
+// HTTP client, request coalescing and Close state.
 type Client struct {
 	HTTPClient  *http.Client
 	UserAgent   string
 	MaxRetries  int
 	BaseDelay   time.Duration
 	RateLimiter RateLimiter
+
+	// Sleeper waits out retry delays. Nil uses a timer; tests can set one
+	// that records the delays instead of sleeping.
+	Sleeper Sleeper
+
+	// Logger receives debug logs for failed and retried requests, coalesced
+	// requests and entries dropped from bulk fetches. Nil disables logging.
+	Logger *slog.Logger
+
+	flights   *flightGroup           // coalesces concurrent GETs of the same URL, nil to disable
+	state     *clientState           // in-flight tracking for Close, nil to disable
+	auth      map[string]Credentials // credentials by lowercased host, see WithCredentials
+	ecosystem string                 // set by New for the registry's copy, reported in DecodeError
+	rawLimit  int                    // see WithRawResponses, 0 to disable
+}
+
+// Credentials authenticate requests to a private registry. If Token is set
+// it is sent as a bearer token; otherwise Username and Password are sent
+// with HTTP basic auth.
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
 }
 
 // DefaultClient returns a client with sensible defaults.
 func DefaultClient() *Client {
 	return &Client{
 		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:       30 * time.Second,
+			CheckRedirect: CheckRedirect,
 		},
 		UserAgent:  "registries/1.0",
 		MaxRetries: 5,
 		BaseDelay:  50 * time.Millisecond,
+		flights:    &flightGroup{},
+		state:      &clientState{},
+	}
+}
+
+// maxRedirects matches net/http's default redirect limit.
+const maxRedirects = 10
+
+// credentialHeaders are dropped when a redirect leaves the original host.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// CheckRedirect strips credentials when a redirect goes to a different host.
+// net/http only does this when the domain changes, so a redirect to another
+// port or to a subdomain (a CDN, say) would otherwise keep the header.
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
 	}
+	if !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+		for _, h := range credentialHeaders {
+			req.Header.Del(h)
+		}
+	}
+	return nil
 }
 
-// GetJSON fetches a URL and decodes the JSON response into v.
+// GetJSON fetches a URL and decodes the JSON response into v. A response
+// that isn't valid JSON for v returns a *DecodeError.
 func (c *Client) GetJSON(ctx context.Context, url string, v any) error {
 	body, err := c.GetBody(ctx, url)
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(body, v)
+	return DecodeJSON(c.ecosystem, url, body, v)
+}
+
+// GetJSONWithAccept is like GetJSON but sends accept as the Accept header,
+// for APIs that pick a response format by content negotiation, such as
+// PyPI's Simple index.
+func (c *Client) GetJSONWithAccept(ctx context.Context, url, accept string, v any) error {
+	body, err := c.get(ctx, url, accept)
+	if err != nil {
+		return err
+	}
+	return DecodeJSON(c.ecosystem, url, body, v)
+}
+
+// maxDecodeBody caps how much of a response a DecodeError keeps.
+const maxDecodeBody = 512
+
+// DecodeJSON unmarshals body, fetched from url, into v. Failures are
+// returned as a *DecodeError carrying the ecosystem, the URL and the start
+// of the body, for registries that fetch with GetBody and decode
+// themselves.
+func DecodeJSON(ecosystem, url string, body []byte, v any) error {
+	err := json.Unmarshal(body, v)
+	if err == nil {
+		return nil
+	}
+	snippet := body
+	if len(snippet) > maxDecodeBody {
+		snippet = snippet[:maxDecodeBody]
+	}
+	return &DecodeError{
+		Ecosystem: ecosystem,
+		URL:       url,
+		Body:      strings.ToValidUTF8(string(snippet), ""),
+		Err:       err,
+	}
+}
+
+// decodeJSONStream decodes a JSON value from r into v. Read errors are
+// returned as they are, so the request is retried; a response that isn't
+// valid JSON for v returns a *DecodeError with the start of the body.
+func decodeJSONStream(ecosystem, url string, r io.Reader, v any) error {
+	head := &headReader{r: r}
+	if err := json.NewDecoder(head).Decode(v); err != nil {
+		if head.err != nil {
+			return head.err
+		}
+		return &DecodeError{
+			Ecosystem: ecosystem,
+			URL:       url,
+			Body:      strings.ToValidUTF8(string(head.head), ""),
+			Err:       err,
+		}
+	}
+	return nil
+}
+
+// headReader keeps the first maxDecodeBody bytes read through it, and the
+// first read error other than io.EOF.
+type headReader struct {
+	r    io.Reader
+	head []byte
+	err  error
+}
+
+func (h *headReader) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	if room := maxDecodeBody - len(h.head); room > 0 {
+		h.head = append(h.head, p[:min(n, room)]...)
+	}
+	if err != nil && err != io.EOF && h.err == nil {
+		h.err = err
+	}
+	return n, err
 }
 
+// forEcosystem returns a copy of the client for a registry of ecosystem.
+// Like the copies made by WithUserAgent, it shares coalescing and Close
+// state with c.
+func (c *Client) forEcosystem(ecosystem string) *Client {
+	copy := *c
+	copy.ecosystem = ecosystem
+	return &copy
+}
+
+// defaultAccept is sent with every GET unless a caller asks for another
+// media type.
+const defaultAccept = "application/json"
+
 // GetBody fetches a URL and returns the response body.
+// Concurrent calls for the same URL share a single request.
 func (c *Client) GetBody(ctx context.Context, url string) ([]byte, error) {
+	return c.get(ctx, url, defaultAccept)
+}
+
+func (c *Client) get(ctx context.Context, url, accept string) ([]byte, error) {
+	body, err := c.getShared(ctx, url, accept)
+	if err == nil {
+		if rec := rawRecorderFrom(ctx); rec != nil {
+			rec.record(body)
+		}
+	}
+	return body, err
+}
+
+// getShared fetches url, sharing the request with concurrent callers
+// unless coalescing is disabled.
+func (c *Client) getShared(ctx context.Context, url, accept string) ([]byte, error) {
+	if c.isClosed() {
+		return nil, ErrClientClosed
+	}
+	if c.flights == nil {
+		return c.getBody(ctx, url, accept)
+	}
+
+	key := c.UserAgent + " " + url
+	if accept != defaultAccept {
+		key = accept + " " + key
+	}
+	body, shared, err := c.flights.do(ctx, key, func() ([]byte, error) {
+		return c.getBody(ctx, url, accept)
+	})
+	if !shared {
+		return body, err
+	}
+	c.debug(ctx, "request coalesced", "url", redactURL(url))
+
+	// The caller that made the request may have been cancelled while this
+	// one is still live, so fetch it ourselves rather than fail.
+	if err != nil && ctx.Err() == nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return c.getBody(ctx, url, accept)
+	}
+	return bytes.Clone(body), err
+}
+
+func (c *Client) getBody(ctx context.Context, url, accept string) ([]byte, error) {
+	var body []byte
+	err := c.withRetries(ctx, url, func() error {
+		return c.doRequest(ctx, url, accept, func(r io.Reader) error {
+			var err error
+			body, err = io.ReadAll(r)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// GetJSONStream is like GetJSON but decodes the response as it arrives
+// rather than reading it into memory first, which roughly halves peak
+// memory for large documents such as npm packuments and conda repodata.
+// Streamed requests aren't coalesced with concurrent ones for the same URL.
+// If an attempt fails partway through, v may be partly filled before the
+// retry decodes into it again.
+func (c *Client) GetJSONStream(ctx context.Context, url string, v any) error {
+	if c.isClosed() {
+		return ErrClientClosed
+	}
+	rec := rawRecorderFrom(ctx)
+	var capture *captureReader
+	err := c.withRetries(ctx, url, func() error {
+		return c.doRequest(ctx, url, defaultAccept, func(r io.Reader) error {
+			if rec != nil {
+				capture = &captureReader{r: r, limit: rec.limit}
+				r = capture
+			}
+			return decodeJSONStream(c.ecosystem, url, r, v)
+		})
+	})
+	if err == nil && capture != nil {
+		rec.record(capture.head)
+	}
+	return err
+}
+
+// withRetries runs attempt until it succeeds, fails with an error that
+// isn't worth retrying, or runs out of retries, waiting between attempts.
+func (c *Client) withRetries(ctx context.Context, url string, attempt func() error) error {
+	if err := c.state.begin(); err != nil {
+		return err
+	}
+	defer c.state.end()
+
 	var lastErr error
+	var retryAfter time.Duration
 
-	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
-		if attempt > 0 {
-			delay := c.BaseDelay * time.Duration(math.Pow(2, float64(attempt-1)))
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(delay):
+	for i := 0; i <= c.MaxRetries; i++ {
+		if i > 0 {
+			delay := c.BaseDelay * time.Duration(math.Pow(2, float64(i-1)))
+			if retryAfter > delay {
+				delay = retryAfter
+			}
+			if err := c.sleep(ctx, delay); err != nil {
+				return err
 			}
 		}
 
 		if c.RateLimiter != nil {
 			if err := c.RateLimiter.Wait(ctx); err != nil {
-				return nil, err
+				return err
 			}
 		}
 
-		body, err := c.doRequest(ctx, url)
+		err := attempt()
 		if err == nil {
-			return body, nil
+			return nil
 		}
 
 		lastErr = err
 
+		var decodeErr *DecodeError
+		if errors.As(err, &decodeErr) {
+			c.debug(ctx, "request failed", "url", redactURL(url), "error", err)
+			return err
+		}
+
+		var rateErr *RateLimitError
+		if errors.As(err, &rateErr) {
+			retryAfter = time.Duration(rateErr.RetryAfter) * time.Second
+			if retryAfter > maxRetryAfter {
+				c.debug(ctx, "request failed", "url", redactURL(url), "error", err)
+				return err
+			}
+		} else {
+			retryAfter = 0
+		}
+
 		var httpErr *HTTPError
 		if ok := isHTTPError(err, &httpErr); ok {
 			if httpErr.StatusCode == 404 {
-				return nil, err
+				return err
 			}
-			if httpErr.StatusCode == 429 || httpErr.StatusCode >= 500 {
-				continue
+			if httpErr.StatusCode != 429 && httpErr.StatusCode < 500 {
+				c.debug(ctx, "request failed", "url", redactURL(url), "error", err)
+				return err
 			}
-			return nil, err
+		}
+
+		if i < c.MaxRetries {
+			c.debug(ctx, "retrying request", "url", redactURL(url), "attempt", i+1, "error", err)
 		}
 	}
 
-	return nil, lastErr
+	c.debug(ctx, "request failed", "url", redactURL(url), "attempts", c.MaxRetries+1, "error", lastErr)
+	return lastErr
 }
 
-func (c *Client) doRequest(ctx context.Context, url string) ([]byte, error) {
+// doRequest makes a GET request and passes a successful response's body to
+// read. Error responses are read in full and returned as an *HTTPError or
+// *RateLimitError.
+func (c *Client) doRequest(ctx context.Context, url, accept string, read func(io.Reader) error) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	req.Header.Set("User-Agent", c.UserAgent)
-	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept", accept)
+	c.authorize(req)
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
 	if resp.StatusCode >= 400 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
 		httpErr := &HTTPError{
 			StatusCode: resp.StatusCode,
 			URL:        url,
 			Body:       string(body),
 		}
 		if resp.StatusCode == 429 {
-			if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
-				if seconds, err := strconv.Atoi(retryAfter); err == nil {
-					return nil, &RateLimitError{RetryAfter: seconds}
-				}
+			if seconds, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				return &RateLimitError{RetryAfter: seconds}
 			}
 		}
-		return nil, httpErr
+		return httpErr
 	}
 
-	return body, nil
+	return read(resp.Body)
+}
+
+// maxRetryAfter is the longest Retry-After the client waits out. A registry
+// asking for more gets its RateLimitError returned to the caller instead.
+const maxRetryAfter = time.Minute
+
+// parseRetryAfter reads a Retry-After header given either as seconds or as
+// an HTTP date, returning the number of seconds to wait.
+func parseRetryAfter(value string) (int, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(seconds, 0), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(int(math.Ceil(time.Until(at).Seconds())), 0), true
+	}
+	return 0, false
+}
+
+// sleep waits for d using the client's Sleeper, or a timer if it has none.
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	if c.Sleeper != nil {
+		return c.Sleeper.Sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 func isHTTPError(err error, target **HTTPError) bool {
@@ -146,20 +452,56 @@ func (c *Client) GetText(ctx context.Context, url string) (string, error) {
 
 // Head sends a HEAD request and returns the status code.
 func (c *Client) Head(ctx context.Context, url string) (int, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	resp, err := c.head(ctx, url)
 	if err != nil {
 		return 0, err
 	}
+	return resp.StatusCode, nil
+}
+
+// head sends a HEAD request and returns the response with its body closed.
+func (c *Client) head(ctx context.Context, url string) (*http.Response, error) {
+	if err := c.state.begin(); err != nil {
+		return nil, err
+	}
+	defer c.state.end()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	req.Header.Set("User-Agent", c.UserAgent)
+	c.authorize(req)
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	_ = resp.Body.Close()
 
-	return resp.StatusCode, nil
+	return resp, nil
+}
+
+// authorize adds the credentials configured for the request's host, if any.
+// A host registered with a port only matches that port; one registered
+// without a port matches any port.
+func (c *Client) authorize(req *http.Request) {
+	if len(c.auth) == 0 {
+		return
+	}
+	creds, ok := c.auth[strings.ToLower(req.URL.Host)]
+	if !ok {
+		creds, ok = c.auth[strings.ToLower(req.URL.Hostname())]
+	}
+	if !ok {
+		return
+	}
+	if creds.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+creds.Token)
+	} else {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
 }
 
 // WithRateLimiter returns a copy of the client with the given rate limiter.
@@ -193,6 +535,127 @@ func WithMaxRetries(n int) Option {
 	}
 }
 
+// WithSleeper replaces the timer the client waits on between retries, so
+// tests can check backoff and Retry-After delays without sleeping.
+func WithSleeper(s Sleeper) Option {
+	return func(c *Client) {
+		c.Sleeper = s
+	}
+}
+
+// WithLogger sets a logger for debug output about failed and retried
+// requests, coalesced requests and dropped bulk entries. URLs are logged
+// with any password redacted; headers are never logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Client) {
+		c.Logger = l
+	}
+}
+
+// WithCoalescing enables or disables sharing one request between concurrent
+// GETs of the same URL. It is enabled by default.
+func WithCoalescing(enabled bool) Option {
+	return func(c *Client) {
+		if enabled {
+			c.flights = &flightGroup{}
+		} else {
+			c.flights = nil
+		}
+	}
+}
+
+// WithProxy sends every request through the proxy at proxyURL, such as
+// "http://proxy.example.com:3128", instead of the one named by HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY. An empty URL connects directly, ignoring the
+// environment. An invalid URL makes requests fail with an error.
+//
+// Without this option the client uses net/http's default transport, which
+// already honors the environment variables. WithProxy installs a clone of
+// that transport, replacing any transport set on HTTPClient that isn't an
+// *http.Transport.
+func WithProxy(proxyURL string) Option {
+	return func(c *Client) {
+		var proxy func(*http.Request) (*url.URL, error)
+		if proxyURL != "" {
+			u, err := url.Parse(proxyURL)
+			if err == nil && u.Host == "" {
+				err = errors.New("missing host")
+			}
+			proxy = func(*http.Request) (*url.URL, error) {
+				if err != nil {
+					return nil, fmt.Errorf("invalid proxy URL: %w", err)
+				}
+				return u, nil
+			}
+		}
+
+		transport := cloneTransport(c)
+		transport.Proxy = proxy
+		c.HTTPClient.Transport = transport
+	}
+}
+
+// cloneTransport returns a copy of the client's *http.Transport to modify,
+// or of http.DefaultTransport if it has some other RoundTripper, so the
+// transport options compose in any order.
+func cloneTransport(c *Client) *http.Transport {
+	base, ok := c.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	return base.Clone()
+}
+
+// WithMinTLSVersion refuses TLS connections below version, such as
+// tls.VersionTLS12, for environments that must enforce a minimum. Like
+// WithProxy it installs a clone of the transport, keeping its proxy and
+// TLS settings.
+func WithMinTLSVersion(version uint16) Option {
+	return func(c *Client) {
+		transport := cloneTransport(c)
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.MinVersion = version
+		c.HTTPClient.Transport = transport
+	}
+}
+
+// WithHTTP2 enables or disables HTTP/2. It is enabled by default;
+// disabling it forces HTTP/1.1, which some proxies and load balancers
+// handle better. Like WithProxy it installs a clone of the transport.
+func WithHTTP2(enabled bool) Option {
+	return func(c *Client) {
+		transport := cloneTransport(c)
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(enabled)
+		transport.Protocols = protocols
+
+		// A TLS config offering h2 would still negotiate it
+		if !enabled && transport.TLSClientConfig != nil {
+			transport.TLSClientConfig.NextProtos = slices.DeleteFunc(
+				slices.Clone(transport.TLSClientConfig.NextProtos),
+				func(p string) bool { return p == "h2" })
+		}
+		c.HTTPClient.Transport = transport
+	}
+}
+
+// WithCredentials authenticates every request to host, which may include a
+// port, with creds. It can be given once per host. Credentials are never
+// sent to other hosts, and are dropped if a redirect leaves the host.
+func WithCredentials(host string, creds Credentials) Option {
+	return func(c *Client) {
+		auth := make(map[string]Credentials, len(c.auth)+1)
+		for h, cr := range c.auth {
+			auth[h] = cr
+		}
+		auth[strings.ToLower(host)] = creds
+		c.auth = auth
+	}
+}
+
 // NewClient creates a new client with the given options.
 func NewClient(opts ...Option) *Client {
 	c := DefaultClient()
@@ -0,0 +1,529 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedirectStripsCredentialsAcrossHosts(t *testing.T) {
+	var gotAuth, gotCookie string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotCookie = r.Header.Get("Cookie")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	// Both servers listen on 127.0.0.1, so only the port differs. net/http
+	// compares hostnames and would forward the header here.
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/file", http.StatusMovedPermanently)
+	}))
+	defer origin.Close()
+
+	req, err := http.NewRequest(http.MethodGet, origin.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Cookie", "session=secret")
+
+	resp, err := DefaultClient().HTTPClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if gotAuth != "" {
+		t.Errorf("expected Authorization to be dropped, got %q", gotAuth)
+	}
+	if gotCookie != "" {
+		t.Errorf("expected Cookie to be dropped, got %q", gotCookie)
+	}
+}
+
+func TestRedirectKeepsCredentialsOnSameHost(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/old", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+
+	resp, err := DefaultClient().HTTPClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if gotAuth != "Bearer secret" {
+		t.Errorf("expected Authorization to be kept, got %q", gotAuth)
+	}
+}
+
+func TestRedirectLimit(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, server.URL, http.StatusFound)
+	}))
+	defer server.Close()
+
+	if _, err := DefaultClient().GetBody(context.Background(), server.URL); err == nil {
+		t.Error("expected an error after too many redirects")
+	}
+}
+
+func TestGetJSONWithAccept(t *testing.T) {
+	var accepts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepts = append(accepts, r.Header.Get("Accept"))
+		_, _ = w.Write([]byte(`{"name": "example"}`))
+	}))
+	defer server.Close()
+
+	client := DefaultClient()
+	var v struct {
+		Name string `json:"name"`
+	}
+	if err := client.GetJSONWithAccept(context.Background(), server.URL, "application/vnd.pypi.simple.v1+json", &v); err != nil {
+		t.Fatalf("GetJSONWithAccept failed: %v", err)
+	}
+	if err := client.GetJSON(context.Background(), server.URL, &v); err != nil {
+		t.Fatalf("GetJSON failed: %v", err)
+	}
+
+	if v.Name != "example" {
+		t.Errorf("expected name %q, got %q", "example", v.Name)
+	}
+	if len(accepts) != 2 || accepts[0] != "application/vnd.pypi.simple.v1+json" || accepts[1] != "application/json" {
+		t.Errorf("unexpected Accept headers: %v", accepts)
+	}
+}
+
+func TestWithCredentials(t *testing.T) {
+	var gotAuth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	ctx := context.Background()
+
+	basic := NewClient(WithCredentials(host, Credentials{Username: "user", Password: "pass"}))
+	if _, err := basic.GetBody(ctx, server.URL); err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+	if _, err := basic.Head(ctx, server.URL); err != nil {
+		t.Fatalf("Head failed: %v", err)
+	}
+
+	token := NewClient(WithCredentials("127.0.0.1", Credentials{Token: "secret"}))
+	if _, err := token.GetBody(ctx, server.URL); err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+
+	other := NewClient(WithCredentials("registry.example.com", Credentials{Token: "secret"}))
+	if _, err := other.GetBody(ctx, server.URL); err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+
+	want := []string{"Basic dXNlcjpwYXNz", "Basic dXNlcjpwYXNz", "Bearer secret", ""}
+	if len(gotAuth) != len(want) {
+		t.Fatalf("expected %d requests, got %d", len(want), len(gotAuth))
+	}
+	for i := range want {
+		if gotAuth[i] != want[i] {
+			t.Errorf("request %d: expected Authorization %q, got %q", i, want[i], gotAuth[i])
+		}
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	var gotURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		_, _ = w.Write([]byte("proxied"))
+	}))
+	defer proxy.Close()
+
+	client := NewClient(WithProxy(proxy.URL), WithTimeout(5*time.Second))
+	body, err := client.GetBody(context.Background(), "http://registry.example.com/pkg")
+	if err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+	if string(body) != "proxied" {
+		t.Errorf("expected the proxy's response, got %q", body)
+	}
+	if gotURL != "http://registry.example.com/pkg" {
+		t.Errorf("expected the proxy to receive the target URL, got %q", gotURL)
+	}
+	if client.HTTPClient.Timeout != 5*time.Second {
+		t.Errorf("expected timeout to be kept, got %v", client.HTTPClient.Timeout)
+	}
+
+	invalid := NewClient(WithProxy("proxy.example.com:3128"), WithMaxRetries(0))
+	if _, err := invalid.GetBody(context.Background(), "http://registry.example.com/pkg"); err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+		t.Errorf("expected an invalid proxy error, got %v", err)
+	}
+}
+
+func TestWithMinTLSVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	newClient := func(opts ...Option) *Client {
+		client := NewClient(WithMaxRetries(0))
+		client.HTTPClient.Transport = server.Client().Transport
+		for _, opt := range opts {
+			opt(client)
+		}
+		return client
+	}
+
+	if _, err := newClient(WithMinTLSVersion(tls.VersionTLS12)).GetBody(context.Background(), server.URL); err != nil {
+		t.Errorf("expected TLS 1.2 to be accepted, got %v", err)
+	}
+	if _, err := newClient(WithMinTLSVersion(tls.VersionTLS13)).GetBody(context.Background(), server.URL); err == nil {
+		t.Error("expected a TLS 1.2 server to be refused when TLS 1.3 is required")
+	}
+
+	// Composes with WithProxy in either order, keeping the test server's CA
+	client := newClient(WithProxy(""), WithMinTLSVersion(tls.VersionTLS13))
+	transport := client.HTTPClient.Transport.(*http.Transport)
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS13 || transport.TLSClientConfig.RootCAs == nil {
+		t.Errorf("expected the minimum version to be added to the existing TLS config")
+	}
+	if transport.Proxy != nil {
+		t.Error("expected the proxy setting to be kept")
+	}
+}
+
+func TestWithHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	for _, tt := range []struct {
+		enabled bool
+		want    string
+	}{
+		{true, "HTTP/2.0"},
+		{false, "HTTP/1.1"},
+	} {
+		client := NewClient()
+		client.HTTPClient.Transport = server.Client().Transport
+		WithHTTP2(tt.enabled)(client)
+
+		body, err := client.GetBody(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("GetBody failed: %v", err)
+		}
+		if string(body) != tt.want {
+			t.Errorf("WithHTTP2(%v): expected %s, got %s", tt.enabled, tt.want, body)
+		}
+	}
+}
+
+// fakeSleeper records retry delays instead of sleeping.
+type fakeSleeper struct {
+	delays []time.Duration
+}
+
+func (s *fakeSleeper) Sleep(ctx context.Context, d time.Duration) error {
+	s.delays = append(s.delays, d)
+	return ctx.Err()
+}
+
+// scriptedTransport answers each request with the next of its responses.
+type scriptedTransport struct {
+	responses []*http.Response
+	requests  int
+}
+
+func (t *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.requests >= len(t.responses) {
+		return nil, fmt.Errorf("unexpected request %d to %s", t.requests+1, req.URL)
+	}
+	resp := t.responses[t.requests]
+	t.requests++
+	resp.Request = req
+	return resp, nil
+}
+
+func scriptedResponse(status int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader("{}")),
+	}
+}
+
+func newScriptedClient(sleeper Sleeper, responses ...*http.Response) (*Client, *scriptedTransport) {
+	transport := &scriptedTransport{responses: responses}
+	client := NewClient(WithSleeper(sleeper))
+	client.HTTPClient.Transport = transport
+	return client, transport
+}
+
+func TestRetryAfter(t *testing.T) {
+	sleeper := &fakeSleeper{}
+	client, transport := newScriptedClient(sleeper,
+		scriptedResponse(http.StatusTooManyRequests, http.Header{"Retry-After": {"3"}}),
+		scriptedResponse(http.StatusOK, nil),
+	)
+
+	if _, err := client.GetBody(context.Background(), "https://registry.example.com/pkg"); err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+	if transport.requests != 2 {
+		t.Errorf("expected 2 requests, got %d", transport.requests)
+	}
+	if len(sleeper.delays) != 1 || sleeper.delays[0] != 3*time.Second {
+		t.Errorf("expected a single 3s wait, got %v", sleeper.delays)
+	}
+}
+
+func TestRetryAfterHTTPDate(t *testing.T) {
+	sleeper := &fakeSleeper{}
+	at := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	client, _ := newScriptedClient(sleeper,
+		scriptedResponse(http.StatusTooManyRequests, http.Header{"Retry-After": {at}}),
+		scriptedResponse(http.StatusOK, nil),
+	)
+
+	if _, err := client.GetBody(context.Background(), "https://registry.example.com/pkg"); err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+	if len(sleeper.delays) != 1 || sleeper.delays[0] < 28*time.Second || sleeper.delays[0] > 31*time.Second {
+		t.Errorf("expected a wait of about 30s, got %v", sleeper.delays)
+	}
+}
+
+func TestRetryAfterTooLong(t *testing.T) {
+	sleeper := &fakeSleeper{}
+	client, transport := newScriptedClient(sleeper,
+		scriptedResponse(http.StatusTooManyRequests, http.Header{"Retry-After": {"3600"}}),
+	)
+
+	_, err := client.GetBody(context.Background(), "https://registry.example.com/pkg")
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) || rateErr.RetryAfter != 3600 {
+		t.Fatalf("expected a RateLimitError, got %v", err)
+	}
+	if transport.requests != 1 || len(sleeper.delays) != 0 {
+		t.Errorf("expected no retries, got %d requests and waits %v", transport.requests, sleeper.delays)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	sleeper := &fakeSleeper{}
+	client, transport := newScriptedClient(sleeper,
+		scriptedResponse(http.StatusServiceUnavailable, nil),
+		scriptedResponse(http.StatusTooManyRequests, nil),
+		scriptedResponse(http.StatusBadGateway, nil),
+		scriptedResponse(http.StatusOK, nil),
+	)
+
+	if _, err := client.GetBody(context.Background(), "https://registry.example.com/pkg"); err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+	if transport.requests != 4 {
+		t.Errorf("expected 4 requests, got %d", transport.requests)
+	}
+	want := []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond}
+	if !slices.Equal(sleeper.delays, want) {
+		t.Errorf("expected waits %v, got %v", want, sleeper.delays)
+	}
+}
+
+func TestRetryBackoffExceedsRetryAfter(t *testing.T) {
+	sleeper := &fakeSleeper{}
+	client, _ := newScriptedClient(sleeper,
+		scriptedResponse(http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}}),
+		scriptedResponse(http.StatusOK, nil),
+	)
+
+	if _, err := client.GetBody(context.Background(), "https://registry.example.com/pkg"); err != nil {
+		t.Fatalf("GetBody failed: %v", err)
+	}
+	if len(sleeper.delays) != 1 || sleeper.delays[0] != 50*time.Millisecond {
+		t.Errorf("expected the 50ms backoff, got %v", sleeper.delays)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	sleeper := &fakeSleeper{}
+	client, transport := newScriptedClient(sleeper,
+		scriptedResponse(http.StatusInternalServerError, nil),
+		scriptedResponse(http.StatusInternalServerError, nil),
+		scriptedResponse(http.StatusInternalServerError, nil),
+	)
+	client.MaxRetries = 2
+
+	_, err := client.GetBody(context.Background(), "https://registry.example.com/pkg")
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected the last HTTPError, got %v", err)
+	}
+	if transport.requests != 3 || len(sleeper.delays) != 2 {
+		t.Errorf("expected 3 requests and 2 waits, got %d and %v", transport.requests, sleeper.delays)
+	}
+}
+
+func TestRetryStopsWhenSleepFails(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client, transport := newScriptedClient(&fakeSleeper{},
+		scriptedResponse(http.StatusServiceUnavailable, nil),
+	)
+
+	if _, err := client.GetBody(ctx, "https://registry.example.com/pkg"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if transport.requests > 1 {
+		t.Errorf("expected no retries after cancellation, got %d requests", transport.requests)
+	}
+}
+
+func TestGetJSONDecodeError(t *testing.T) {
+	page := "<html>" + strings.Repeat("x", 1000) + "</html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	var v map[string]any
+	err := DefaultClient().forEcosystem("npm").GetJSON(context.Background(), server.URL+"/lodash", &v)
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected a DecodeError, got %v", err)
+	}
+	if decodeErr.Ecosystem != "npm" {
+		t.Errorf("expected ecosystem %q, got %q", "npm", decodeErr.Ecosystem)
+	}
+	if decodeErr.URL != server.URL+"/lodash" {
+		t.Errorf("expected URL %q, got %q", server.URL+"/lodash", decodeErr.URL)
+	}
+	if len(decodeErr.Body) != maxDecodeBody || !strings.HasPrefix(decodeErr.Body, "<html>") {
+		t.Errorf("expected the first %d bytes of the body, got %d: %q", maxDecodeBody, len(decodeErr.Body), decodeErr.Body[:10])
+	}
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("expected the json.SyntaxError to be wrapped, got %T", decodeErr.Err)
+	}
+	if !strings.HasPrefix(err.Error(), "npm: decoding response from "+server.URL) {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+}
+
+// failingReader returns data and then err instead of io.EOF.
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestGetJSONStream(t *testing.T) {
+	sleeper := &fakeSleeper{}
+	truncated := scriptedResponse(http.StatusOK, nil)
+	truncated.Body = io.NopCloser(&failingReader{data: `{"name": "lod`, err: io.ErrUnexpectedEOF})
+	ok := scriptedResponse(http.StatusOK, nil)
+	ok.Body = io.NopCloser(strings.NewReader(`{"name": "lodash", "versions": ["1.0.0", "2.0.0"]}`))
+
+	client, transport := newScriptedClient(sleeper,
+		scriptedResponse(http.StatusServiceUnavailable, nil),
+		truncated,
+		ok,
+	)
+
+	var v struct {
+		Name     string   `json:"name"`
+		Versions []string `json:"versions"`
+	}
+	if err := client.GetJSONStream(context.Background(), "https://registry.example.com/lodash", &v); err != nil {
+		t.Fatalf("GetJSONStream failed: %v", err)
+	}
+	if v.Name != "lodash" || len(v.Versions) != 2 {
+		t.Errorf("unexpected result: %+v", v)
+	}
+	if transport.requests != 3 {
+		t.Errorf("expected the error response and the broken body to be retried, got %d requests", transport.requests)
+	}
+}
+
+func TestGetJSONStreamDecodeError(t *testing.T) {
+	page := scriptedResponse(http.StatusOK, nil)
+	page.Body = io.NopCloser(strings.NewReader("<html>" + strings.Repeat("x", 1000)))
+
+	client, transport := newScriptedClient(&fakeSleeper{}, page)
+
+	var v map[string]any
+	err := client.forEcosystem("conda").GetJSONStream(context.Background(), "https://conda.example.com/repodata.json", &v)
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected a DecodeError, got %v", err)
+	}
+	if decodeErr.Ecosystem != "conda" || decodeErr.URL != "https://conda.example.com/repodata.json" {
+		t.Errorf("unexpected error context: %+v", decodeErr)
+	}
+	if !strings.HasPrefix(decodeErr.Body, "<html>") || len(decodeErr.Body) > maxDecodeBody {
+		t.Errorf("expected at most %d bytes from the start of the body, got %d", maxDecodeBody, len(decodeErr.Body))
+	}
+	if transport.requests != 1 {
+		t.Errorf("expected a malformed response not to be retried, got %d requests", transport.requests)
+	}
+}
+
+func TestGetJSONStreamNotFound(t *testing.T) {
+	client, _ := newScriptedClient(&fakeSleeper{}, scriptedResponse(http.StatusNotFound, nil))
+
+	var v map[string]any
+	err := client.GetJSONStream(context.Background(), "https://registry.example.com/missing", &v)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || !httpErr.IsNotFound() {
+		t.Errorf("expected a 404 HTTPError, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/git-pkgs/purl"
+)
+
+type ecosystemLimitsKey struct{}
+
+// WithEcosystemConcurrency returns a context that makes the bulk helpers
+// schedule each ecosystem's PURLs independently, with at most limits[eco]
+// fetches in flight for that ecosystem. Ecosystems not in limits use the
+// helper's own concurrency. This keeps a slow or rate-limited registry from
+// holding up the rest of a mixed batch, and keeps a large batch for one
+// registry from flooding it.
+func WithEcosystemConcurrency(ctx context.Context, limits map[string]int) context.Context {
+	return context.WithValue(ctx, ecosystemLimitsKey{}, limits)
+}
+
+func ecosystemLimits(ctx context.Context) map[string]int {
+	limits, _ := ctx.Value(ecosystemLimitsKey{}).(map[string]int)
+	return limits
+}
+
+// parallelMapByEcosystem runs parallelMap separately for each ecosystem's
+// PURLs, all at once, and merges the results. PURLs that don't parse are
+// grouped together and will fail in fn. Like parallelMap it returns
+// ctx.Err() if ctx was cancelled before every PURL was fetched.
+func parallelMapByEcosystem[V any](
+	ctx context.Context,
+	purls []string,
+	limits map[string]int,
+	concurrency int,
+	fn func(ctx context.Context, purl string) (*V, error),
+) (map[string]*V, error) {
+	groups := make(map[string][]string)
+	for _, p := range purls {
+		var eco string
+		if parsed, err := purl.Parse(p); err == nil {
+			eco = parsed.Type
+		}
+		groups[eco] = append(groups[eco], p)
+	}
+
+	results := make(map[string]*V)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+
+	for eco, group := range groups {
+		limit := concurrency
+		if l, ok := limits[eco]; ok && l > 0 {
+			limit = l
+		}
+
+		wg.Add(1)
+		go func(group []string, limit int) {
+			defer wg.Done()
+			groupResults, err := parallelMap(ctx, group, limit, false, fn)
+			mu.Lock()
+			for k, v := range groupResults {
+				results[k] = v
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			mu.Unlock()
+		}(group, limit)
+	}
+
+	wg.Wait()
+	return results, firstErr
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrencyTracker records the most fetches seen in flight at once, per
+// ecosystem and overall.
+type concurrencyTracker struct {
+	mu      sync.Mutex
+	current map[string]int
+	peak    map[string]int
+	total   int
+	peakAll int
+}
+
+func (c *concurrencyTracker) fetch(ctx context.Context, p string, client *Client) (*Package, error) {
+	eco := strings.TrimPrefix(strings.SplitN(p, "/", 2)[0], "pkg:")
+
+	c.mu.Lock()
+	c.current[eco]++
+	c.total++
+	c.peak[eco] = max(c.peak[eco], c.current[eco])
+	c.peakAll = max(c.peakAll, c.total)
+	c.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	c.mu.Lock()
+	c.current[eco]--
+	c.total--
+	c.mu.Unlock()
+
+	return &Package{Name: p}, nil
+}
+
+func TestBulkFetchEcosystemConcurrency(t *testing.T) {
+	var purls []string
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		purls = append(purls, "pkg:npm/"+name, "pkg:cargo/"+name)
+	}
+
+	tracker := &concurrencyTracker{current: map[string]int{}, peak: map[string]int{}}
+	ctx := WithEcosystemConcurrency(context.Background(), map[string]int{"npm": 1})
+
+	results, _ := bulkFetch(ctx, purls, DefaultClient(), 3, tracker.fetch)
+	if len(results) != len(purls) {
+		t.Fatalf("expected %d results, got %d", len(purls), len(results))
+	}
+
+	if tracker.peak["npm"] != 1 {
+		t.Errorf("expected at most 1 npm fetch at a time, saw %d", tracker.peak["npm"])
+	}
+	if tracker.peak["cargo"] > 3 {
+		t.Errorf("expected at most 3 cargo fetches at a time, saw %d", tracker.peak["cargo"])
+	}
+	// cargo has its own semaphore, so it isn't held up behind npm
+	if tracker.peakAll <= 1 {
+		t.Errorf("expected ecosystems to be fetched concurrently, peak was %d", tracker.peakAll)
+	}
+}
+
+func TestBulkFetchWithoutEcosystemLimits(t *testing.T) {
+	purls := []string{"pkg:npm/a", "pkg:npm/b", "pkg:npm/c", "pkg:cargo/a"}
+	tracker := &concurrencyTracker{current: map[string]int{}, peak: map[string]int{}}
+
+	bulkFetch(context.Background(), purls, DefaultClient(), 2, tracker.fetch)
+
+	if tracker.peakAll > 2 {
+		t.Errorf("expected the shared limit of 2 to apply, saw %d", tracker.peakAll)
+	}
+}
+
+func TestBulkFetchCancelledMidFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	fetch := func(ctx context.Context, p string, client *Client) (*Package, error) {
+		if calls.Add(1) <= 2 {
+			return &Package{Name: p}, nil
+		}
+		// Cancel, then ignore ctx to check bulkFetch doesn't wait for us
+		cancel()
+		<-release
+		return &Package{Name: p}, nil
+	}
+
+	var purls []string
+	for i := 0; i < 20; i++ {
+		purls = append(purls, fmt.Sprintf("pkg:npm/p%d", i))
+	}
+
+	type outcome struct {
+		results map[string]*Package
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		results, err := bulkFetch(ctx, purls, DefaultClient(), 1, fetch)
+		done <- outcome{results, err}
+	}()
+
+	var got outcome
+	select {
+	case got = <-done:
+	case <-time.After(5 * time.Second):
+		close(release)
+		t.Fatal("bulkFetch didn't return after cancellation")
+	}
+	close(release)
+
+	if !errors.Is(got.err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", got.err)
+	}
+	if len(got.results) != 2 {
+		t.Errorf("expected the 2 packages fetched before cancelling, got %d", len(got.results))
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("expected no fetches to start after cancelling, got %d calls", n)
+	}
+}
+
+func TestBulkFetchPartialComplete(t *testing.T) {
+	tracker := &concurrencyTracker{current: map[string]int{}, peak: map[string]int{}}
+	purls := []string{"pkg:npm/a", "pkg:cargo/b"}
+
+	ctx := WithEcosystemConcurrency(context.Background(), map[string]int{"npm": 1})
+	results, err := bulkFetch(ctx, purls, DefaultClient(), 2, tracker.fetch)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected 2 results, got %d", len(results))
+	}
+
+	client := DefaultClient()
+	_ = client.Close(context.Background())
+	if _, err := BulkFetchPackagesPartial(context.Background(), purls, client, 0); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed, got %v", err)
+	}
+}
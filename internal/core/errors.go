@@ -8,6 +8,25 @@ import (
 // ErrNotFound is returned when a package or version is not found.
 var ErrNotFound = errors.New("not found")
 
+// ErrClientClosed is returned for requests made after Client.Close.
+var ErrClientClosed = errors.New("client closed")
+
+// ErrReadmeUnsupported is returned by FetchReadme for registries that don't
+// serve READMEs.
+var ErrReadmeUnsupported = errors.New("readme not supported by registry")
+
+// ErrUnsupportedConstraint is returned by SatisfiesRequirement for
+// ecosystems and requirement syntax it can't evaluate.
+var ErrUnsupportedConstraint = errors.New("unsupported version constraint")
+
+// ErrNoDownloadURL is returned by ArtifactSize for registries that don't
+// build download URLs.
+var ErrNoDownloadURL = errors.New("no download URL")
+
+// ErrDependencyLimit is returned by FetchDependenciesRecursiveFlat, with
+// the dependencies found so far, when the walk reaches its node limit.
+var ErrDependencyLimit = errors.New("dependency limit reached")
+
 // HTTPError represents an HTTP error response.
 type HTTPError struct {
 	StatusCode int
@@ -16,7 +35,7 @@ type HTTPError struct {
 }
 
 func (e *HTTPError) Error() string {
-	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.URL)
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, redactURL(e.URL))
 }
 
 // IsNotFound returns true if the error represents a 404 response.
@@ -42,6 +61,28 @@ func (e *NotFoundError) Unwrap() error {
 	return ErrNotFound
 }
 
+// DecodeError is returned when a registry's response can't be decoded into
+// the shape the client expects, such as a JSON object where a list was
+// expected or an HTML error page served with a 200.
+type DecodeError struct {
+	Ecosystem string // empty if the client wasn't created for a registry
+	URL       string
+	Body      string // the start of the response, see maxDecodeBody
+	Err       error
+}
+
+func (e *DecodeError) Error() string {
+	msg := fmt.Sprintf("decoding response from %s: %v", redactURL(e.URL), e.Err)
+	if e.Ecosystem != "" {
+		msg = e.Ecosystem + ": " + msg
+	}
+	return msg
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
 // RateLimitError is returned when the registry rate limits requests.
 type RateLimitError struct {
 	RetryAfter int // seconds
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// packageLine is a single line of NDJSON output for a package.
+type packageLine struct {
+	PURL    string   `json:"purl"`
+	Package *Package `json:"package"`
+}
+
+// versionLine is a single line of NDJSON output for a version.
+type versionLine struct {
+	PURL    string   `json:"purl"`
+	Version *Version `json:"version"`
+}
+
+// WritePackagesNDJSON writes bulk package results as newline-delimited JSON,
+// one {"purl": ..., "package": {...}} object per line, sorted by PURL.
+func WritePackagesNDJSON(w io.Writer, results map[string]*Package) error {
+	enc := json.NewEncoder(w)
+	for _, purl := range sortedKeys(results) {
+		if err := enc.Encode(packageLine{PURL: purl, Package: results[purl]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteVersionsNDJSON writes bulk version results as newline-delimited JSON,
+// one {"purl": ..., "version": {...}} object per line, sorted by PURL.
+func WriteVersionsNDJSON(w io.Writer, results map[string]*Version) error {
+	enc := json.NewEncoder(w)
+	for _, purl := range sortedKeys(results) {
+		if err := enc.Encode(versionLine{PURL: purl, Version: results[purl]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
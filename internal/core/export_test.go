@@ -0,0 +1,72 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWritePackagesNDJSON(t *testing.T) {
+	results := map[string]*Package{
+		"pkg:npm/lodash":  {Name: "lodash", Licenses: "MIT"},
+		"pkg:cargo/serde": {Name: "serde", Licenses: "MIT OR Apache-2.0"},
+	}
+
+	var buf bytes.Buffer
+	if err := WritePackagesNDJSON(&buf, results); err != nil {
+		t.Fatalf("WritePackagesNDJSON failed: %v", err)
+	}
+
+	var lines []packageLine
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line packageLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if lines[0].PURL != "pkg:cargo/serde" || lines[0].Package.Name != "serde" {
+		t.Errorf("unexpected first line: %+v", lines[0])
+	}
+	if lines[1].PURL != "pkg:npm/lodash" || lines[1].Package.Licenses != "MIT" {
+		t.Errorf("unexpected second line: %+v", lines[1])
+	}
+}
+
+func TestWriteVersionsNDJSON(t *testing.T) {
+	results := map[string]*Version{
+		"pkg:cargo/serde@1.0.0": {Number: "1.0.0", Status: StatusYanked},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteVersionsNDJSON(&buf, results); err != nil {
+		t.Fatalf("WriteVersionsNDJSON failed: %v", err)
+	}
+
+	var line versionLine
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if line.PURL != "pkg:cargo/serde@1.0.0" || line.Version.Number != "1.0.0" || line.Version.Status != StatusYanked {
+		t.Errorf("unexpected line: %+v", line)
+	}
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Errorf("expected exactly one newline-terminated line, got %q", buf.String())
+	}
+}
+
+func TestWritePackagesNDJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePackagesNDJSON(&buf, nil); err != nil {
+		t.Fatalf("WritePackagesNDJSON failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+}
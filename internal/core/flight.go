@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup coalesces concurrent fetches of the same key so that only one
+// request is in flight at a time and every caller shares its result.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	body []byte
+	err  error
+	dups int
+}
+
+// do runs fn for key unless a call for the same key is already running, in
+// which case it waits for that call and returns its result. shared reports
+// whether the result came from another caller's fn. A waiting caller whose
+// context ends returns early without affecting the running call.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() ([]byte, error)) (body []byte, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		call.dups++
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.body, true, call.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+	call := &flightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.body, call.err = fn()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+
+	return call.body, false, call.err
+}
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForDups blocks until n callers are waiting on the in-flight call for key.
+func waitForDups(t *testing.T, g *flightGroup, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		call, ok := g.calls[key]
+		dups := 0
+		if ok {
+			dups = call.dups
+		}
+		g.mu.Unlock()
+		if dups >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d waiting callers", n)
+}
+
+func TestGetBodyCoalescesConcurrentRequests(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		_, _ = w.Write([]byte("shared body"))
+	}))
+	defer server.Close()
+
+	client := DefaultClient()
+	const callers = 10
+
+	var wg sync.WaitGroup
+	bodies := make([][]byte, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bodies[i], errs[i] = client.GetBody(context.Background(), server.URL)
+		}(i)
+	}
+
+	waitForDups(t, client.flights, client.UserAgent+" "+server.URL, callers-1)
+	close(release)
+	wg.Wait()
+
+	if got := hits.Load(); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Errorf("caller %d: unexpected error: %v", i, errs[i])
+		}
+		if string(bodies[i]) != "shared body" {
+			t.Errorf("caller %d: expected 'shared body', got %q", i, bodies[i])
+		}
+	}
+
+	// Each caller gets its own copy of the body
+	bodies[0][0] = 'X'
+	if bodies[1][0] == 'X' {
+		t.Error("expected callers not to share the same body slice")
+	}
+}
+
+func TestGetBodyWithoutCoalescing(t *testing.T) {
+	var hits atomic.Int32
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		arrived.Done()
+		// Hold both requests open so they are in flight together
+		arrived.Wait()
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithCoalescing(false))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = client.GetBody(context.Background(), server.URL)
+		}()
+	}
+	wg.Wait()
+
+	if got := hits.Load(); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestGetBodyCancelledLeader(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithMaxRetries(0))
+	key := client.UserAgent + " " + server.URL
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan struct{})
+	go func() {
+		defer close(leaderDone)
+		_, _ = client.GetBody(leaderCtx, server.URL)
+	}()
+
+	// Wait for the leader's request to be in flight
+	for hits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	followerDone := make(chan struct{})
+	var body []byte
+	var err error
+	go func() {
+		defer close(followerDone)
+		body, err = client.GetBody(context.Background(), server.URL)
+	}()
+
+	waitForDups(t, client.flights, key, 1)
+	cancel()
+	<-leaderDone
+	<-followerDone
+	close(release)
+
+	if err != nil {
+		t.Fatalf("follower failed: %v", err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("expected follower to refetch, got %q", body)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
+	"sync"
 
 	"github.com/git-pkgs/purl"
 )
@@ -27,7 +29,162 @@ func NewFromPURL(purlStr string, client *Client) (Registry, string, string, erro
 		return nil, "", "", err
 	}
 
-	return reg, p.FullName(), p.Version, nil
+	return reg, packageName(p), p.Version, nil
+}
+
+// PartitionPURLs checks a mixed list of PURLs before a bulk fetch. Valid
+// PURLs are grouped by ecosystem, in input order with duplicates dropped;
+// those that don't parse, or whose type has no registered registry, are
+// returned with the reason instead. Each group can then be fetched with
+// its own concurrency, as WithEcosystemConcurrency does for a single call.
+func PartitionPURLs(purls []string) (map[string][]string, map[string]error) {
+	groups := make(map[string][]string)
+	invalid := make(map[string]error)
+	seen := make(map[string]bool)
+
+	for _, purlStr := range purls {
+		if seen[purlStr] {
+			continue
+		}
+		seen[purlStr] = true
+
+		p, err := purl.Parse(purlStr)
+		if err != nil {
+			invalid[purlStr] = err
+			continue
+		}
+		if PURLType(p.Type) == "" {
+			invalid[purlStr] = fmt.Errorf("unknown ecosystem: %s", p.Type)
+			continue
+		}
+		groups[p.Type] = append(groups[p.Type], purlStr)
+	}
+	return groups, invalid
+}
+
+// packageName returns the name a registry expects for a PURL. This is the
+// PURL's full name, except for conda where the channel is carried as a
+// qualifier and is folded back into the "channel/name" form.
+func packageName(p *purl.PURL) string {
+	if p.Type == "conda" && p.Namespace == "" {
+		if channel := p.Qualifier("channel"); channel != "" {
+			return channel + "/" + p.Name
+		}
+	}
+	return p.FullName()
+}
+
+// purlNameFolding normalizes names for the PURL types whose spec folds
+// them, or whose registries spell out a default the name left implicit, so
+// ValidatePURL accepts a registry emitting the canonical form.
+var purlNameFolding = map[string]func(string) string{
+	"pypi":     foldPyPIName,
+	"npm":      strings.ToLower,
+	"composer": strings.ToLower,
+	"hex":      strings.ToLower,
+	"golang":   strings.ToLower,
+	"clojars": func(name string) string {
+		// A bare artifact is its own group.
+		if !strings.Contains(name, "/") {
+			return name + "/" + name
+		}
+		return name
+	},
+	"conda": func(name string) string {
+		// Names without a channel come from conda-forge.
+		if !strings.Contains(name, "/") {
+			return "conda-forge/" + name
+		}
+		return name
+	},
+}
+
+// foldPyPIName applies PEP 503 normalization: lowercase, with runs of "-",
+// "_" and "." replaced by a single "-".
+func foldPyPIName(name string) string {
+	name = strings.ToLower(name)
+	var b strings.Builder
+	sep := false
+	for _, r := range name {
+		if r == '-' || r == '_' || r == '.' {
+			sep = true
+			continue
+		}
+		if sep && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		sep = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// purlVersionRequired lists the PURL types whose spec requires a version,
+// so a versionless PURL for them doesn't parse.
+var purlVersionRequired = map[string]bool{
+	"cran": true,
+}
+
+// ValidatePURL checks that the PURL the ecosystem's registry builds for a
+// package round-trips: it must parse, have the ecosystem as its type and
+// version as its version, and lead NewFromPURL back to name. Names are
+// compared after the folding the PURL spec requires for the type, such as
+// PyPI's and Go's lowercasing. The version may be empty, except that for
+// types requiring one there is no versionless PURL to check, and nil is
+// returned.
+func ValidatePURL(ecosystem, name, version string) error {
+	reg, err := New(ecosystem, "", nil)
+	if err != nil {
+		return err
+	}
+	if version == "" && purlVersionRequired[ecosystem] {
+		return nil
+	}
+	purlStr := reg.URLs().PURL(name, version)
+
+	p, err := purl.Parse(purlStr)
+	if err != nil {
+		return fmt.Errorf("%s: PURL %q for %s doesn't parse: %w", ecosystem, purlStr, name, err)
+	}
+	if p.Type != ecosystem {
+		return fmt.Errorf("%s: PURL %q has type %q", ecosystem, purlStr, p.Type)
+	}
+	if p.Name == "" {
+		return fmt.Errorf("%s: PURL %q has no name", ecosystem, purlStr)
+	}
+	if p.Version != version {
+		return fmt.Errorf("%s: PURL %q has version %q, want %q", ecosystem, purlStr, p.Version, version)
+	}
+
+	got, want := packageName(p), name
+	if fold, ok := purlNameFolding[ecosystem]; ok {
+		got, want = fold(got), fold(want)
+	}
+	if got != want {
+		return fmt.Errorf("%s: PURL %q names %q, want %q", ecosystem, purlStr, packageName(p), name)
+	}
+	return nil
+}
+
+// ResolveURLs returns the registry, download, documentation and PURL URLs
+// for a PURL without making any requests. The version is optional; without
+// one, the URLs point at the package rather than a release.
+func ResolveURLs(purlStr string) (*URLSet, error) {
+	reg, name, version, err := NewFromPURL(purlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := reg.URLs()
+	return &URLSet{
+		Ecosystem:     reg.Ecosystem(),
+		Name:          name,
+		Version:       version,
+		Registry:      urls.Registry(name, version),
+		Download:      urls.Download(name, version),
+		Documentation: urls.Documentation(name, version),
+		PURL:          urls.PURL(name, version),
+	}, nil
 }
 
 // FetchPackageFromPURL fetches package metadata using a PURL.
@@ -41,7 +198,10 @@ func FetchPackageFromPURL(ctx context.Context, purlStr string, client *Client) (
 }
 
 // FetchVersionFromPURL fetches a specific version's metadata using a PURL.
-// Returns an error if the PURL doesn't include a version.
+// Returns an error if the PURL doesn't include a version. A version that
+// matches no version number is resolved as a tag through ResolveTag, so
+// pkg:npm/react@next and pkg:maven/junit/junit@RELEASE find the versions
+// those tags point to.
 func FetchVersionFromPURL(ctx context.Context, purlStr string, client *Client) (*Version, error) {
 	p, err := purl.Parse(purlStr)
 	if err != nil {
@@ -58,24 +218,41 @@ func FetchVersionFromPURL(ctx context.Context, purlStr string, client *Client) (
 		return nil, err
 	}
 
-	versions, err := reg.FetchVersions(ctx, p.FullName())
+	versions, err := reg.FetchVersions(ctx, packageName(p))
 	if err != nil {
 		return nil, err
 	}
 
-	for _, v := range versions {
-		if v.Number == p.Version {
-			return &v, nil
+	if v := findVersion(versions, p.Version); v != nil {
+		return v, nil
+	}
+
+	resolved, err := ResolveTag(ctx, reg, packageName(p), p.Version)
+	if err != nil {
+		return nil, err
+	}
+	if resolved != p.Version {
+		if v := findVersion(versions, resolved); v != nil {
+			return v, nil
 		}
 	}
 
 	return nil, &NotFoundError{
 		Ecosystem: p.Type,
-		Name:      p.FullName(),
+		Name:      packageName(p),
 		Version:   p.Version,
 	}
 }
 
+func findVersion(versions []Version, number string) *Version {
+	for _, v := range versions {
+		if v.Number == number {
+			return &v
+		}
+	}
+	return nil
+}
+
 // FetchDependenciesFromPURL fetches dependencies for a specific version using a PURL.
 // Returns an error if the PURL doesn't include a version.
 func FetchDependenciesFromPURL(ctx context.Context, purlStr string, client *Client) ([]Dependency, error) {
@@ -94,7 +271,7 @@ func FetchDependenciesFromPURL(ctx context.Context, purlStr string, client *Clie
 		return nil, err
 	}
 
-	return reg.FetchDependencies(ctx, p.FullName(), p.Version)
+	return reg.FetchDependencies(ctx, packageName(p), p.Version)
 }
 
 // FetchMaintainersFromPURL fetches maintainer information using a PURL.
@@ -110,6 +287,19 @@ func FetchMaintainersFromPURL(ctx context.Context, purlStr string, client *Clien
 // FetchLatestVersion returns the latest non-yanked/retracted/deprecated version.
 // Returns nil if no valid versions exist.
 func FetchLatestVersion(ctx context.Context, reg Registry, name string) (*Version, error) {
+	return fetchLatestVersion(ctx, reg, name, false)
+}
+
+// FetchLatestVersionIncludingDeprecated is like FetchLatestVersion but also
+// considers deprecated versions. Some registries flag every release of a
+// package as deprecated (abandoned Packagist packages, deprecated Homebrew
+// formulae), where the strict form would return nil. Yanked and retracted
+// versions are still skipped.
+func FetchLatestVersionIncludingDeprecated(ctx context.Context, reg Registry, name string) (*Version, error) {
+	return fetchLatestVersion(ctx, reg, name, true)
+}
+
+func fetchLatestVersion(ctx context.Context, reg Registry, name string, includeDeprecated bool) (*Version, error) {
 	versions, err := reg.FetchVersions(ctx, name)
 	if err != nil {
 		return nil, err
@@ -119,10 +309,10 @@ func FetchLatestVersion(ctx context.Context, reg Registry, name string) (*Versio
 		return nil, nil
 	}
 
-	// Filter out yanked/retracted/deprecated versions
+	// Filter out yanked/retracted (and usually deprecated) versions
 	var valid []Version
 	for _, v := range versions {
-		if v.Status == StatusNone {
+		if v.Status == StatusNone || (includeDeprecated && v.Status == StatusDeprecated) {
 			valid = append(valid, v)
 		}
 	}
@@ -132,7 +322,7 @@ func FetchLatestVersion(ctx context.Context, reg Registry, name string) (*Versio
 	}
 
 	// Sort by PublishedAt descending (newest first)
-	// If PublishedAt is zero, fall back to assuming the list order is correct
+	// If PublishedAt is zero, fall back to the ecosystem's version ordering
 	hasTimestamps := false
 	for _, v := range valid {
 		if !v.PublishedAt.IsZero() {
@@ -145,6 +335,8 @@ func FetchLatestVersion(ctx context.Context, reg Registry, name string) (*Versio
 		sort.Slice(valid, func(i, j int) bool {
 			return valid[i].PublishedAt.After(valid[j].PublishedAt)
 		})
+	} else {
+		valid = SortedVersions(reg, valid)
 	}
 
 	return &valid[0], nil
@@ -159,6 +351,120 @@ func FetchLatestVersionFromPURL(ctx context.Context, purl string, client *Client
 	return FetchLatestVersion(ctx, reg, name)
 }
 
+// LatestStable returns the highest stable version of a package, given its
+// ecosystem and name rather than a PURL. Prereleases (by the ecosystem's
+// conventions) and yanked, deprecated or retracted versions are skipped, as
+// in StableVersions. Unlike FetchLatestVersion this goes by version order
+// rather than publish date, so a backport released after a newer major
+// version isn't picked. Returns nil if there is no stable version. A nil
+// client uses DefaultClient.
+func LatestStable(ctx context.Context, ecosystem, name string, client *Client) (*Version, error) {
+	reg, err := New(ecosystem, "", client)
+	if err != nil {
+		return nil, err
+	}
+	return latestStable(ctx, reg, name)
+}
+
+func latestStable(ctx context.Context, reg Registry, name string) (*Version, error) {
+	versions, err := reg.FetchVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	stable := StableVersions(reg, versions)
+	if len(stable) == 0 {
+		return nil, nil
+	}
+	return &SortedVersions(reg, stable)[0], nil
+}
+
+// ResolveVersion returns the highest version of name that satisfies
+// constraint, a requirement in the ecosystem's syntax as accepted by
+// SatisfiesRequirement, such as "^1.2" for npm or "~> 7.1" for gem. An
+// empty constraint matches any version. Prereleases and yanked, deprecated
+// or retracted versions are passed over; prereleases are only considered
+// when no stable version satisfies the constraint, as for "^2.0.0-beta".
+// Yanked, deprecated and retracted versions never are.
+//
+// It returns a NotFoundError with the constraint as the version when
+// nothing matches, and an error wrapping ErrUnsupportedConstraint when the
+// ecosystem or constraint can't be evaluated.
+func ResolveVersion(ctx context.Context, reg Registry, name, constraint string) (*Version, error) {
+	versions, err := reg.FetchVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	isPrerelease := prereleaseChecker(reg.Ecosystem())
+	var prereleases []Version
+	for _, v := range versions {
+		if v.Status == StatusNone && isPrerelease(v.Number) {
+			prereleases = append(prereleases, v)
+		}
+	}
+
+	for _, candidates := range [][]Version{StableVersions(reg, versions), prereleases} {
+		for _, v := range SortedVersions(reg, candidates) {
+			if constraint == "" {
+				return &v, nil
+			}
+			ok, err := SatisfiesRequirement(reg.Ecosystem(), v.Number, constraint)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return &v, nil
+			}
+		}
+	}
+	return nil, &NotFoundError{Ecosystem: reg.Ecosystem(), Name: name, Version: constraint}
+}
+
+// FetchAll fetches a package, its versions and its maintainers concurrently.
+// Only a failure to fetch the package itself is returned as an error; if the
+// versions or maintainers can't be fetched (some registries don't publish
+// maintainers) those fields are left nil and the error is recorded on the
+// PackageDetail instead.
+func FetchAll(ctx context.Context, reg Registry, name string) (*PackageDetail, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var detail PackageDetail
+	var pkgErr error
+	var wg sync.WaitGroup
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		detail.Package, pkgErr = reg.FetchPackage(ctx, name)
+		// The rest is no use without the package
+		if pkgErr != nil {
+			cancel()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		detail.Versions, detail.VersionsErr = reg.FetchVersions(ctx, name)
+	}()
+	go func() {
+		defer wg.Done()
+		detail.Maintainers, detail.MaintainersErr = reg.FetchMaintainers(ctx, name)
+	}()
+	wg.Wait()
+
+	if pkgErr != nil {
+		return nil, pkgErr
+	}
+	if detail.VersionsErr != nil {
+		detail.Versions = nil
+	}
+	if detail.MaintainersErr != nil {
+		detail.Maintainers = nil
+	}
+	return &detail, nil
+}
+
 // BulkFetchPackages fetches package metadata for multiple PURLs in parallel.
 // Individual fetch errors are silently ignored - those PURLs are omitted from results.
 // Returns a map of PURL to Package.
@@ -168,9 +474,18 @@ func BulkFetchPackages(ctx context.Context, purls []string, client *Client) map[
 
 // BulkFetchPackagesWithConcurrency fetches packages with a custom concurrency limit.
 func BulkFetchPackagesWithConcurrency(ctx context.Context, purls []string, client *Client, concurrency int) map[string]*Package {
-	return ParallelMap(ctx, purls, concurrency, func(ctx context.Context, p string) (*Package, error) {
-		return FetchPackageFromPURL(ctx, p, client)
-	})
+	results, _ := bulkFetch(ctx, purls, client, concurrency, FetchPackageFromPURL)
+	return results
+}
+
+// BulkFetchPackagesPartial is BulkFetchPackagesWithConcurrency, also
+// reporting why the results may be incomplete. If ctx is cancelled it
+// stops starting fetches and returns at once with the packages fetched so
+// far and ctx.Err(); if the client is closed meanwhile the error is
+// ErrClientClosed. A concurrency below 1 uses the default. Errors for
+// individual PURLs are still not reported.
+func BulkFetchPackagesPartial(ctx context.Context, purls []string, client *Client, concurrency int) (map[string]*Package, error) {
+	return bulkFetch(ctx, purls, client, concurrencyOrDefault(concurrency), FetchPackageFromPURL)
 }
 
 // BulkFetchVersions fetches version metadata for multiple versioned PURLs in parallel.
@@ -183,9 +498,14 @@ func BulkFetchVersions(ctx context.Context, purls []string, client *Client) map[
 
 // BulkFetchVersionsWithConcurrency fetches versions with a custom concurrency limit.
 func BulkFetchVersionsWithConcurrency(ctx context.Context, purls []string, client *Client, concurrency int) map[string]*Version {
-	return ParallelMap(ctx, purls, concurrency, func(ctx context.Context, p string) (*Version, error) {
-		return FetchVersionFromPURL(ctx, p, client)
-	})
+	results, _ := bulkFetch(ctx, purls, client, concurrency, FetchVersionFromPURL)
+	return results
+}
+
+// BulkFetchVersionsPartial is BulkFetchVersionsWithConcurrency with the
+// error reporting of BulkFetchPackagesPartial.
+func BulkFetchVersionsPartial(ctx context.Context, purls []string, client *Client, concurrency int) (map[string]*Version, error) {
+	return bulkFetch(ctx, purls, client, concurrencyOrDefault(concurrency), FetchVersionFromPURL)
 }
 
 // BulkFetchLatestVersions fetches the latest version for multiple PURLs in parallel.
@@ -196,7 +516,52 @@ func BulkFetchLatestVersions(ctx context.Context, purls []string, client *Client
 
 // BulkFetchLatestVersionsWithConcurrency fetches latest versions with a custom concurrency limit.
 func BulkFetchLatestVersionsWithConcurrency(ctx context.Context, purls []string, client *Client, concurrency int) map[string]*Version {
-	return ParallelMap(ctx, purls, concurrency, func(ctx context.Context, p string) (*Version, error) {
-		return FetchLatestVersionFromPURL(ctx, p, client)
-	})
+	results, _ := bulkFetch(ctx, purls, client, concurrency, FetchLatestVersionFromPURL)
+	return results
+}
+
+// BulkFetchLatestVersionsPartial is BulkFetchLatestVersionsWithConcurrency
+// with the error reporting of BulkFetchPackagesPartial.
+func BulkFetchLatestVersionsPartial(ctx context.Context, purls []string, client *Client, concurrency int) (map[string]*Version, error) {
+	return bulkFetch(ctx, purls, client, concurrencyOrDefault(concurrency), FetchLatestVersionFromPURL)
+}
+
+func concurrencyOrDefault(concurrency int) int {
+	if concurrency < 1 {
+		return defaultConcurrency
+	}
+	return concurrency
+}
+
+// bulkFetch runs fetch for each PURL in parallel, stopping once the client
+// is closed or ctx is cancelled. Entries that fail or have no result are
+// left out of the map and logged at debug level. The error is ctx.Err() if
+// ctx was cancelled before every PURL was fetched, or ErrClientClosed if
+// the client was closed; the map holds what was fetched either way. If ctx
+// carries per-ecosystem limits from WithEcosystemConcurrency, each
+// ecosystem is scheduled separately.
+func bulkFetch[V any](ctx context.Context, purls []string, client *Client, concurrency int, fetch func(context.Context, string, *Client) (*V, error)) (map[string]*V, error) {
+	fn := func(ctx context.Context, p string) (*V, error) {
+		var result *V
+		err := ErrClientClosed
+		if !client.isClosed() {
+			result, err = fetch(ctx, p, client)
+		}
+		if err != nil || result == nil {
+			client.debug(ctx, "bulk fetch dropped entry", "purl", p, "error", err)
+		}
+		return result, err
+	}
+
+	var results map[string]*V
+	var err error
+	if limits := ecosystemLimits(ctx); limits != nil {
+		results, err = parallelMapByEcosystem(ctx, purls, limits, concurrency, fn)
+	} else {
+		results, err = parallelMap(ctx, purls, concurrency, false, fn)
+	}
+	if err == nil && client.isClosed() && len(results) < len(purls) {
+		err = ErrClientClosed
+	}
+	return results, err
 }
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/git-pkgs/purl"
+)
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		purl string
+		want string
+	}{
+		{"pkg:cargo/serde@1.0.0", "serde"},
+		{"pkg:npm/%40babel/core@7.24.0", "@babel/core"},
+		{"pkg:maven/org.apache.commons/commons-lang3@3.12.0", "org.apache.commons:commons-lang3"},
+		{"pkg:conda/samtools@1.18?channel=bioconda", "bioconda/samtools"},
+		{"pkg:conda/numpy@1.26.0", "numpy"},
+		{"pkg:conda/bioconda/samtools@1.18", "bioconda/samtools"},
+	}
+
+	for _, tt := range tests {
+		p, err := purl.Parse(tt.purl)
+		if err != nil {
+			t.Fatalf("purl.Parse(%q) failed: %v", tt.purl, err)
+		}
+		if got := packageName(p); got != tt.want {
+			t.Errorf("packageName(%q) = %q, want %q", tt.purl, got, tt.want)
+		}
+	}
+}
+
+func TestPURLNameFolding(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		emitted   string
+		parsed    string
+	}{
+		{"golang", "github.com/Azure/go-autorest", "github.com/azure/go-autorest"},
+		{"pypi", "zope.interface", "zope-interface"},
+		{"clojars", "hiccup", "hiccup/hiccup"},
+	}
+
+	for _, tt := range tests {
+		fold := purlNameFolding[tt.ecosystem]
+		if fold == nil {
+			t.Fatalf("%s: no name folding", tt.ecosystem)
+		}
+		if fold(tt.emitted) != fold(tt.parsed) {
+			t.Errorf("%s: %q and %q fold differently", tt.ecosystem, tt.emitted, tt.parsed)
+		}
+	}
+}
+
+func TestPartitionPURLs(t *testing.T) {
+	groups, invalid := PartitionPURLs([]string{
+		"pkg:rawtest/b@1.0.0",
+		"pkg:rawtest/a",
+		"pkg:bitnami/redis@7.0.0",
+		"not-a-purl",
+		"pkg:rawtest/b@1.0.0",
+	})
+
+	if got := groups["rawtest"]; len(got) != 2 || got[0] != "pkg:rawtest/b@1.0.0" || got[1] != "pkg:rawtest/a" {
+		t.Errorf("expected both rawtest PURLs in input order, got %v", got)
+	}
+	if len(groups) != 1 {
+		t.Errorf("expected only the rawtest group, got %v", groups)
+	}
+
+	if len(invalid) != 2 {
+		t.Fatalf("expected 2 invalid PURLs, got %v", invalid)
+	}
+	if err := invalid["pkg:bitnami/redis@7.0.0"]; err == nil || err.Error() != "unknown ecosystem: bitnami" {
+		t.Errorf("expected an unknown ecosystem error, got %v", err)
+	}
+	if invalid["not-a-purl"] == nil {
+		t.Error("expected a parse error for not-a-purl")
+	}
+}
+
+type detailRegistry struct {
+	Registry
+	pkgErr        error
+	versionsErr   error
+	maintainerErr error
+}
+
+func (r detailRegistry) FetchPackage(ctx context.Context, name string) (*Package, error) {
+	if r.pkgErr != nil {
+		return nil, r.pkgErr
+	}
+	return &Package{Name: name}, nil
+}
+
+func (r detailRegistry) FetchVersions(ctx context.Context, name string) ([]Version, error) {
+	if r.versionsErr != nil {
+		return nil, r.versionsErr
+	}
+	return []Version{{Number: "1.1.0"}, {Number: "1.0.0"}}, nil
+}
+
+func (r detailRegistry) FetchMaintainers(ctx context.Context, name string) ([]Maintainer, error) {
+	if r.maintainerErr != nil {
+		return nil, r.maintainerErr
+	}
+	return []Maintainer{{Login: "alice"}}, nil
+}
+
+func TestFetchAll(t *testing.T) {
+	detail, err := FetchAll(context.Background(), detailRegistry{}, "example")
+	if err != nil {
+		t.Fatalf("FetchAll failed: %v", err)
+	}
+	if detail.Package == nil || detail.Package.Name != "example" {
+		t.Errorf("unexpected package: %+v", detail.Package)
+	}
+	if len(detail.Versions) != 2 {
+		t.Errorf("expected 2 versions, got %d", len(detail.Versions))
+	}
+	if len(detail.Maintainers) != 1 {
+		t.Errorf("expected 1 maintainer, got %d", len(detail.Maintainers))
+	}
+	if detail.VersionsErr != nil || detail.MaintainersErr != nil {
+		t.Errorf("unexpected errors: %v, %v", detail.VersionsErr, detail.MaintainersErr)
+	}
+}
+
+func TestFetchAllPartialFailure(t *testing.T) {
+	unsupported := errors.New("maintainers not supported")
+	reg := detailRegistry{maintainerErr: unsupported}
+
+	detail, err := FetchAll(context.Background(), reg, "example")
+	if err != nil {
+		t.Fatalf("FetchAll failed: %v", err)
+	}
+	if detail.Package == nil {
+		t.Fatal("expected package to be returned")
+	}
+	if len(detail.Versions) != 2 {
+		t.Errorf("expected 2 versions, got %d", len(detail.Versions))
+	}
+	if detail.Maintainers != nil {
+		t.Errorf("expected nil maintainers, got %v", detail.Maintainers)
+	}
+	if !errors.Is(detail.MaintainersErr, unsupported) {
+		t.Errorf("expected maintainers error to be recorded, got %v", detail.MaintainersErr)
+	}
+}
+
+func TestFetchAllPackageError(t *testing.T) {
+	notFound := &NotFoundError{Ecosystem: "test", Name: "missing"}
+	reg := detailRegistry{pkgErr: notFound}
+
+	detail, err := FetchAll(context.Background(), reg, "missing")
+	if !errors.Is(err, notFound) {
+		t.Errorf("expected package error, got %v", err)
+	}
+	if detail != nil {
+		t.Errorf("expected nil detail, got %+v", detail)
+	}
+}
@@ -0,0 +1,22 @@
+package core
+
+import "strings"
+
+// NormalizeKeywords cleans up the keywords, tags or categories a registry
+// reports for a package: each is trimmed and lowercased, empty ones are
+// dropped and duplicates are removed, keeping the first occurrence's
+// position. It returns nil if no keywords remain. Registries call it when
+// filling Package.Keywords so "JSON", " json" and "json" index as one.
+func NormalizeKeywords(keywords []string) []string {
+	var normalized []string
+	seen := make(map[string]bool, len(keywords))
+	for _, k := range keywords {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		normalized = append(normalized, k)
+	}
+	return normalized
+}
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeKeywords(t *testing.T) {
+	tests := []struct {
+		input []string
+		want  []string
+	}{
+		{[]string{"HTTP", " web ", "http", "Client"}, []string{"http", "web", "client"}},
+		{[]string{"", "  ", "json"}, []string{"json"}},
+		{[]string{"", " "}, nil},
+		{nil, nil},
+	}
+
+	for _, tt := range tests {
+		got := NormalizeKeywords(tt.input)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("NormalizeKeywords(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+		if tt.want == nil && got != nil {
+			t.Errorf("NormalizeKeywords(%q) = %#v, want nil", tt.input, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// clientState tracks in-flight requests for a Client and every copy made
+// from it, so a long-running service can drain them before exiting.
+type clientState struct {
+	mu       sync.Mutex
+	closed   bool
+	inFlight int
+	wg       sync.WaitGroup
+}
+
+// begin registers a request, or returns ErrClientClosed once Close has
+// been called. Every successful begin must be paired with end.
+func (s *clientState) begin() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClientClosed
+	}
+	s.inFlight++
+	s.wg.Add(1)
+	return nil
+}
+
+func (s *clientState) end() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+	s.wg.Done()
+}
+
+func (s *clientState) isClosed() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// InFlight returns the number of requests currently being made by this
+// client and its copies.
+func (c *Client) InFlight() int {
+	if c == nil || c.state == nil {
+		return 0
+	}
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	return c.state.inFlight
+}
+
+// Close stops the client from starting new requests and waits for the ones
+// already in flight to finish. Requests made after Close fail with
+// ErrClientClosed, and bulk fetches stop scheduling new work. If ctx ends
+// before the client has drained, Close returns ctx.Err(); in-flight requests
+// are left to finish on their own.
+//
+// Copies made with WithUserAgent, WithRateLimiter and similar share the
+// same lifecycle, so closing any of them closes all of them.
+func (c *Client) Close(ctx context.Context) error {
+	if c == nil || c.state == nil {
+		return nil
+	}
+
+	c.state.mu.Lock()
+	c.state.closed = true
+	c.state.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		c.state.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isClosed reports whether Close has been called. Nil clients are never
+// closed, since a fresh default client is created for them.
+func (c *Client) isClosed() bool {
+	return c != nil && c.state.isClosed()
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientClose(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := DefaultClient()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GetBody(context.Background(), server.URL)
+		done <- err
+	}()
+
+	<-started
+	if got := client.InFlight(); got != 1 {
+		t.Errorf("expected 1 request in flight, got %d", got)
+	}
+
+	// Close gives up when its context ends before the request finishes
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	// New requests are refused, including from copies of the client
+	if _, err := client.WithUserAgent("other").GetBody(context.Background(), server.URL+"/other"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed, got %v", err)
+	}
+
+	close(release)
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("expected clean drain, got %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("in-flight request should complete, got %v", err)
+	}
+	if got := client.InFlight(); got != 0 {
+		t.Errorf("expected 0 requests in flight, got %d", got)
+	}
+}
+
+func TestClientCloseWithoutState(t *testing.T) {
+	client := &Client{HTTPClient: http.DefaultClient}
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+	if got := client.InFlight(); got != 0 {
+		t.Errorf("expected 0 requests in flight, got %d", got)
+	}
+}
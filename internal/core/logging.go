@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+)
+
+// discardLogger is used when no Logger is configured, so logging calls
+// never need a nil check.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+func (c *Client) logger() *slog.Logger {
+	if c == nil || c.Logger == nil {
+		return discardLogger
+	}
+	return c.Logger
+}
+
+type logAttrsKey struct{}
+
+// WithLogAttrs returns a context whose requests are logged with attrs, so a
+// shared client can tag the work it does for each caller with a request ID
+// or tenant. Attributes added to a context that already has some are
+// appended to them.
+//
+// Logging calls also receive the context itself, so handlers that read
+// their own values from it keep working. A request coalesced with one that
+// was already in flight is retried and logged under the first caller's
+// context.
+func WithLogAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := logAttrs(ctx)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, logAttrsKey{}, merged)
+}
+
+func logAttrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(logAttrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// debug logs msg with ctx, adding any attributes set with WithLogAttrs.
+func (c *Client) debug(ctx context.Context, msg string, args ...any) {
+	logger := c.logger()
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	for _, attr := range logAttrs(ctx) {
+		args = append(args, attr)
+	}
+	logger.DebugContext(ctx, msg, args...)
+}
+
+// redactURL hides any password in rawURL's userinfo so URLs can be logged
+// and shown in errors. Request headers, including Authorization, are never
+// logged.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
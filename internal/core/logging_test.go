@@ -0,0 +1,143 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncBuffer lets concurrent log writes from bulk fetches share a buffer.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestLogger(buf *syncBuffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestLoggerRetriesAndFailures(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	var buf syncBuffer
+	client := NewClient(WithLogger(newTestLogger(&buf)))
+	client.BaseDelay = 0
+
+	// Credentials in the URL must not end up in the log
+	url := strings.Replace(server.URL, "http://", "http://user:hunter2@", 1)
+	if _, err := client.GetBody(context.Background(), url); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "retrying request") {
+		t.Errorf("expected a retry to be logged, got %q", out)
+	}
+	if !strings.Contains(out, "request failed") {
+		t.Errorf("expected the failure to be logged, got %q", out)
+	}
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("log output contains the password: %q", out)
+	}
+}
+
+func TestLoggerIncludesContextAttrs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	var buf syncBuffer
+	client := NewClient(WithLogger(newTestLogger(&buf)))
+
+	ctx := WithLogAttrs(context.Background(), slog.String("request_id", "abc123"))
+	ctx = WithLogAttrs(ctx, slog.String("tenant", "acme"))
+	if _, err := client.GetBody(ctx, server.URL); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "request_id=abc123") || !strings.Contains(out, "tenant=acme") {
+		t.Errorf("expected context attributes in the log, got %q", out)
+	}
+}
+
+func TestBulkFetchLogsWithCallerContext(t *testing.T) {
+	var buf syncBuffer
+	client := NewClient(WithLogger(newTestLogger(&buf)))
+
+	fetch := func(ctx context.Context, p string, client *Client) (*Package, error) {
+		return nil, errors.New("not found")
+	}
+
+	ctx := WithLogAttrs(context.Background(), slog.String("request_id", "bulk1"))
+	bulkFetch(ctx, []string{"pkg:npm/a", "pkg:cargo/b"}, client, 2, fetch)
+	bulkFetch(WithEcosystemConcurrency(ctx, map[string]int{"npm": 1}), []string{"pkg:npm/c"}, client, 2, fetch)
+
+	out := buf.String()
+	if got := strings.Count(out, "request_id=bulk1"); got != 3 {
+		t.Errorf("expected 3 tagged log lines, got %d: %q", got, out)
+	}
+}
+
+func TestLoggerDefaultsToDiscard(t *testing.T) {
+	if DefaultClient().logger() != discardLogger {
+		t.Error("expected the default client to discard logs")
+	}
+	var c *Client
+	if c.logger() != discardLogger {
+		t.Error("expected a nil client to discard logs")
+	}
+}
+
+func TestBulkFetchLogsDroppedEntries(t *testing.T) {
+	var buf syncBuffer
+	client := NewClient(WithLogger(newTestLogger(&buf)))
+
+	fetch := func(ctx context.Context, p string, client *Client) (*Package, error) {
+		if p == "pkg:npm/missing" {
+			return nil, errors.New("not found")
+		}
+		return &Package{Name: p}, nil
+	}
+
+	results, _ := bulkFetch(context.Background(), []string{"pkg:npm/ok", "pkg:npm/missing"}, client, 2, fetch)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "bulk fetch dropped entry") || !strings.Contains(out, "pkg:npm/missing") {
+		t.Errorf("expected the dropped entry to be logged, got %q", out)
+	}
+	if strings.Contains(out, "pkg:npm/ok") {
+		t.Errorf("successful entries should not be logged, got %q", out)
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import "strings"
+
+// MergeMaintainers removes duplicate maintainers from registries that list
+// people from more than one source, such as an owner who is also listed
+// as a contributor. Two entries are the same person if they have the same
+// email, or failing that the same login, or failing that the same name,
+// compared case-insensitively; an identifier only decides when both
+// entries have it, so differing emails keep two people with the same name
+// apart. Duplicates are merged into the position of the first: the entry
+// with more fields set wins where they disagree, and the other fills in
+// its blanks. Entries with no email, login or name are kept as they are.
+func MergeMaintainers(maintainers []Maintainer) []Maintainer {
+	var merged []Maintainer
+	for _, m := range maintainers {
+		i := indexMaintainer(merged, m)
+		if i < 0 {
+			merged = append(merged, m)
+			continue
+		}
+		if maintainerFields(m) > maintainerFields(merged[i]) {
+			merged[i] = fillMaintainer(m, merged[i])
+		} else {
+			merged[i] = fillMaintainer(merged[i], m)
+		}
+	}
+	return merged
+}
+
+// indexMaintainer returns the position in merged of the entry that is the
+// same person as m, or -1.
+func indexMaintainer(merged []Maintainer, m Maintainer) int {
+	if m.Email == "" && m.Login == "" && m.Name == "" {
+		return -1
+	}
+	for i, other := range merged {
+		if sameMaintainer(m, other) {
+			return i
+		}
+	}
+	return -1
+}
+
+func sameMaintainer(a, b Maintainer) bool {
+	for _, key := range [][2]string{{a.Email, b.Email}, {a.Login, b.Login}, {a.Name, b.Name}} {
+		x, y := strings.TrimSpace(key[0]), strings.TrimSpace(key[1])
+		if x != "" && y != "" {
+			return strings.EqualFold(x, y)
+		}
+	}
+	return false
+}
+
+func maintainerFields(m Maintainer) int {
+	n := 0
+	for _, field := range []string{m.UUID, m.Login, m.Name, m.Email, m.URL, m.AvatarURL, m.Role} {
+		if field != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// fillMaintainer returns m with its empty fields taken from other.
+func fillMaintainer(m, other Maintainer) Maintainer {
+	for _, f := range []struct{ dst, src *string }{
+		{&m.UUID, &other.UUID},
+		{&m.Login, &other.Login},
+		{&m.Name, &other.Name},
+		{&m.Email, &other.Email},
+		{&m.URL, &other.URL},
+		{&m.AvatarURL, &other.AvatarURL},
+		{&m.Role, &other.Role},
+	} {
+		if *f.dst == "" {
+			*f.dst = *f.src
+		}
+	}
+	return m
+}
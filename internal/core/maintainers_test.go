@@ -0,0 +1,93 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeMaintainers(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []Maintainer
+		want  []Maintainer
+	}{
+		{
+			name: "same email",
+			input: []Maintainer{
+				{Name: "Jane Doe", Email: "jane@example.com"},
+				{Login: "jane", Email: "JANE@example.com", URL: "https://example.com/jane"},
+			},
+			want: []Maintainer{
+				{Login: "jane", Name: "Jane Doe", Email: "JANE@example.com", URL: "https://example.com/jane"},
+			},
+		},
+		{
+			name: "login when email is missing",
+			input: []Maintainer{
+				{Login: "owner", Role: "owner"},
+				{Login: "other", Role: "contributor"},
+				{Login: "Owner", Role: "contributor"},
+			},
+			want: []Maintainer{
+				{Login: "owner", Role: "owner"},
+				{Login: "other", Role: "contributor"},
+			},
+		},
+		{
+			name: "name when email and login are missing",
+			input: []Maintainer{
+				{Name: "Jane Doe"},
+				{Name: "Jane Doe", Email: "jane@example.com", Role: "author"},
+			},
+			want: []Maintainer{
+				{Name: "Jane Doe", Email: "jane@example.com", Role: "author"},
+			},
+		},
+		{
+			name: "different emails are different people",
+			input: []Maintainer{
+				{Name: "Jane Doe", Email: "jane@example.com"},
+				{Name: "Jane Doe", Email: "jane@example.org"},
+			},
+			want: []Maintainer{
+				{Name: "Jane Doe", Email: "jane@example.com"},
+				{Name: "Jane Doe", Email: "jane@example.org"},
+			},
+		},
+		{
+			name: "richer record wins conflicts",
+			input: []Maintainer{
+				{Login: "jane", Role: "contributor"},
+				{Login: "jane", Name: "Jane Doe", Role: "owner"},
+			},
+			want: []Maintainer{
+				{Login: "jane", Name: "Jane Doe", Role: "owner"},
+			},
+		},
+		{
+			name: "unidentified entries are kept",
+			input: []Maintainer{
+				{URL: "https://example.com/a"},
+				{URL: "https://example.com/a"},
+			},
+			want: []Maintainer{
+				{URL: "https://example.com/a"},
+				{URL: "https://example.com/a"},
+			},
+		},
+		{
+			name:  "empty",
+			input: nil,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeMaintainers(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
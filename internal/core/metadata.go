@@ -0,0 +1,45 @@
+package core
+
+import "sort"
+
+var metadataKeys = make(map[string]map[string]bool)
+
+// RegisterMetadataKeys records Metadata keys an ecosystem's registry may
+// set, for MetadataKeys. Registries call it from init alongside Register,
+// listing every key they write into a Package, Version or Dependency, and
+// should update the list along with the code that sets them. Calling it
+// again adds to the keys already recorded.
+func RegisterMetadataKeys(ecosystem string, keys ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	set := metadataKeys[ecosystem]
+	if set == nil {
+		set = make(map[string]bool, len(keys))
+		metadataKeys[ecosystem] = set
+	}
+	for _, key := range keys {
+		set[key] = true
+	}
+}
+
+// MetadataKeys returns the Metadata keys an ecosystem's registry may set on
+// the Packages, Versions and Dependencies it returns, sorted, or nil if it
+// sets none or isn't registered. A key is listed if any of the three can
+// carry it, and whether a given result does depends on the package and on
+// the registry's options. Keys added outside the registry, "_raw" from
+// WithRawResponses and those FetchDependenciesRecursiveFlat records, aren't
+// listed.
+func MetadataKeys(ecosystem string) []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	set := metadataKeys[ecosystem]
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
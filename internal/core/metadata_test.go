@@ -0,0 +1,26 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetadataKeys(t *testing.T) {
+	RegisterMetadataKeys("metadatatest", "size", "downloads")
+	RegisterMetadataKeys("metadatatest", "build", "size")
+
+	want := []string{"build", "downloads", "size"}
+	keys := MetadataKeys("metadatatest")
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected %v, got %v", want, keys)
+	}
+
+	keys[0] = "changed"
+	if got := MetadataKeys("metadatatest"); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected a copy to be returned, got %v", got)
+	}
+
+	if keys := MetadataKeys("unknown"); keys != nil {
+		t.Errorf("expected nil for an unregistered ecosystem, got %v", keys)
+	}
+}
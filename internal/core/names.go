@@ -0,0 +1,54 @@
+package core
+
+import "strings"
+
+// SplitName splits a package name into its namespace and bare name using
+// the naming rules of ecosystem:
+//
+//   - maven: "group:artifact" (or "group/artifact")
+//   - clojars: "group/artifact", where a bare "artifact" is its own group
+//   - npm, deno: "@scope/name", with the namespace returned without the "@"
+//   - composer, elm, conda, luarocks: "namespace/name"
+//   - terraform: "namespace/name/provider", with "name/provider" as the name
+//   - golang: everything before the last path element, so
+//     "github.com/gorilla/mux" is ("github.com/gorilla", "mux")
+//
+// Other ecosystems have flat names, so namespace is empty and bare is name.
+func SplitName(ecosystem, name string) (namespace, bare string) {
+	switch ecosystem {
+	case "maven":
+		if ns, n, ok := strings.Cut(name, ":"); ok {
+			bare, _, _ = strings.Cut(n, ":")
+			return ns, bare
+		}
+		return cutNamespace(name)
+	case "clojars":
+		if ns, n, ok := strings.Cut(name, "/"); ok {
+			return ns, n
+		}
+		return name, name
+	case "npm", "deno":
+		if strings.HasPrefix(name, "@") {
+			if ns, n, ok := strings.Cut(name[1:], "/"); ok {
+				return ns, n
+			}
+		}
+		return "", name
+	case "composer", "elm", "conda", "luarocks", "terraform":
+		return cutNamespace(name)
+	case "golang":
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			return name[:i], name[i+1:]
+		}
+		return "", name
+	}
+	return "", name
+}
+
+// cutNamespace splits name at its first "/".
+func cutNamespace(name string) (namespace, bare string) {
+	if ns, n, ok := strings.Cut(name, "/"); ok {
+		return ns, n
+	}
+	return "", name
+}
@@ -0,0 +1,40 @@
+package core
+
+import "testing"
+
+func TestSplitName(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		name      string
+		namespace string
+		bare      string
+	}{
+		{"maven", "org.apache.commons:commons-lang3", "org.apache.commons", "commons-lang3"},
+		{"maven", "org.apache.commons:commons-lang3:3.14.0", "org.apache.commons", "commons-lang3"},
+		{"maven", "org.apache.commons/commons-lang3", "org.apache.commons", "commons-lang3"},
+		{"clojars", "ring/ring-core", "ring", "ring-core"},
+		{"clojars", "compojure", "compojure", "compojure"},
+		{"npm", "@babel/core", "babel", "core"},
+		{"npm", "lodash", "", "lodash"},
+		{"deno", "@std/path", "std", "path"},
+		{"composer", "symfony/console", "symfony", "console"},
+		{"elm", "elm/json", "elm", "json"},
+		{"conda", "conda-forge/numpy", "conda-forge", "numpy"},
+		{"luarocks", "kikito/inspect", "kikito", "inspect"},
+		{"luarocks", "inspect", "", "inspect"},
+		{"terraform", "hashicorp/consul/aws", "hashicorp", "consul/aws"},
+		{"golang", "github.com/gorilla/mux", "github.com/gorilla", "mux"},
+		{"golang", "golang.org/x/net", "golang.org/x", "net"},
+		{"cargo", "serde", "", "serde"},
+		{"pypi", "requests", "", "requests"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ecosystem+"/"+tt.name, func(t *testing.T) {
+			namespace, bare := SplitName(tt.ecosystem, tt.name)
+			if namespace != tt.namespace || bare != tt.bare {
+				t.Errorf("expected (%q, %q), got (%q, %q)", tt.namespace, tt.bare, namespace, bare)
+			}
+		})
+	}
+}
@@ -2,34 +2,67 @@ package core
 
 import (
 	"context"
+	"maps"
 	"sync"
 )
 
 // ParallelMap executes fn for each input in parallel with bounded concurrency.
 // Results are collected into a map keyed by the input. If fn returns an error
-// or nil result, that input is omitted from the results.
+// or nil result, that input is omitted from the results. If ctx is cancelled
+// no further calls are started, and ParallelMap waits for the calls already
+// running before returning what they collected, so no call to fn outlives
+// it.
 func ParallelMap[K comparable, V any](
 	ctx context.Context,
 	inputs []K,
 	concurrency int,
 	fn func(ctx context.Context, input K) (*V, error),
 ) map[K]*V {
+	results, _ := parallelMap(ctx, inputs, concurrency, true, fn)
+	return results
+}
+
+// parallelMap is ParallelMap, also returning ctx.Err() if ctx was
+// cancelled before every input was processed. Calls are only started once
+// a slot is free, so nothing is left waiting on the semaphore after
+// cancellation. If wait is false it returns as soon as ctx is cancelled:
+// calls already running see the cancelled ctx and their results are
+// discarded. The Partial helpers use this to return promptly.
+func parallelMap[K comparable, V any](
+	ctx context.Context,
+	inputs []K,
+	concurrency int,
+	wait bool,
+	fn func(ctx context.Context, input K) (*V, error),
+) (map[K]*V, error) {
 	results := make(map[K]*V)
 	var mu sync.Mutex
-	sem := make(chan struct{}, concurrency)
+	sem := make(chan struct{}, max(concurrency, 1))
 	var wg sync.WaitGroup
 
+	snapshot := func() (map[K]*V, error) {
+		if wait {
+			wg.Wait()
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return maps.Clone(results), ctx.Err()
+	}
+
 	for _, input := range inputs {
+		if ctx.Err() != nil {
+			return snapshot()
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return snapshot()
+		}
+
 		wg.Add(1)
 		go func(k K) {
 			defer wg.Done()
-
-			select {
-			case sem <- struct{}{}:
-				defer func() { <-sem }()
-			case <-ctx.Done():
-				return
-			}
+			defer func() { <-sem }()
 
 			result, err := fn(ctx, k)
 			if err == nil && result != nil {
@@ -40,6 +73,99 @@ func ParallelMap[K comparable, V any](
 		}(input)
 	}
 
-	wg.Wait()
-	return results
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return results, nil
+	case <-ctx.Done():
+		// Everything may have finished just as ctx was cancelled
+		select {
+		case <-done:
+			return results, nil
+		default:
+			return snapshot()
+		}
+	}
+}
+
+// ParallelSlice executes fn for each input in parallel with bounded
+// concurrency, like ParallelMap, but returns results and errors aligned
+// with inputs: results[i] and errs[i] are what fn returned for inputs[i].
+// Inputs need not be comparable or distinct. If ctx is cancelled no
+// further calls are started, and ParallelSlice returns at once with
+// ctx.Err() in errs for every input whose call hadn't finished.
+func ParallelSlice[K any, V any](
+	ctx context.Context,
+	inputs []K,
+	concurrency int,
+	fn func(ctx context.Context, input K) (*V, error),
+) ([]*V, []error) {
+	results := make([]*V, len(inputs))
+	errs := make([]error, len(inputs))
+	finished := make([]bool, len(inputs))
+	var mu sync.Mutex
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+
+	// Calls still running after a cancellation must not write to the
+	// slices handed back to the caller.
+	snapshot := func() ([]*V, []error) {
+		mu.Lock()
+		defer mu.Unlock()
+		r := make([]*V, len(inputs))
+		e := make([]error, len(inputs))
+		for i := range inputs {
+			if finished[i] {
+				r[i], e[i] = results[i], errs[i]
+			} else {
+				e[i] = ctx.Err()
+			}
+		}
+		return r, e
+	}
+
+	for i, input := range inputs {
+		if ctx.Err() != nil {
+			return snapshot()
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return snapshot()
+		}
+
+		wg.Add(1)
+		go func(i int, k K) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := fn(ctx, k)
+			mu.Lock()
+			results[i], errs[i], finished[i] = result, err, true
+			mu.Unlock()
+		}(i, input)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return results, errs
+	case <-ctx.Done():
+		select {
+		case <-done:
+			return results, errs
+		default:
+			return snapshot()
+		}
+	}
 }
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelMapCancelledWaits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	inputs := []int{1, 2, 3, 4}
+	var running atomic.Int32
+	started := make(chan struct{}, len(inputs))
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	ParallelMap(ctx, inputs, 2, func(ctx context.Context, n int) (*int, error) {
+		running.Add(1)
+		defer running.Add(-1)
+		started <- struct{}{}
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return &n, nil
+	})
+
+	if n := running.Load(); n != 0 {
+		t.Errorf("expected no calls still running after ParallelMap returned, got %d", n)
+	}
+}
+
+func TestParallelSlice(t *testing.T) {
+	inputs := []int{3, 1, 2, 1, 0}
+	errOdd := errors.New("odd")
+
+	results, errs := ParallelSlice(context.Background(), inputs, 2, func(ctx context.Context, n int) (*int, error) {
+		// Larger inputs take longer, so results arrive out of input order
+		time.Sleep(time.Duration(n) * time.Millisecond)
+		if n%2 == 1 {
+			return nil, errOdd
+		}
+		doubled := n * 2
+		return &doubled, nil
+	})
+
+	if len(results) != len(inputs) || len(errs) != len(inputs) {
+		t.Fatalf("expected %d results and errors, got %d and %d", len(inputs), len(results), len(errs))
+	}
+	for i, n := range inputs {
+		if n%2 == 1 {
+			if !errors.Is(errs[i], errOdd) || results[i] != nil {
+				t.Errorf("input %d (%d): expected errOdd, got %v, %v", i, n, results[i], errs[i])
+			}
+			continue
+		}
+		if errs[i] != nil || results[i] == nil || *results[i] != n*2 {
+			t.Errorf("input %d (%d): expected %d, got %v, %v", i, n, n*2, results[i], errs[i])
+		}
+	}
+}
+
+func TestParallelSliceCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+
+	inputs := []string{"a", "b", "c"}
+	started := make(chan struct{}, len(inputs))
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	results, errs := ParallelSlice(ctx, inputs, 1, func(ctx context.Context, s string) (*string, error) {
+		started <- struct{}{}
+		<-release
+		return &s, nil
+	})
+
+	for i := range inputs {
+		if results[i] != nil || !errors.Is(errs[i], context.Canceled) {
+			t.Errorf("input %d: expected context.Canceled, got %v, %v", i, results[i], errs[i])
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// rawMetadataKey is the Package.Metadata key WithRawResponses fills.
+const rawMetadataKey = "_raw"
+
+// WithRawResponses makes registries created with the client through New
+// (and so NewFromPURL and the bulk helpers) keep the first response body
+// read by each FetchPackage call, cut to limit bytes, in
+// Package.Metadata["_raw"]. It is a diagnostic for when a registry's
+// responses change shape and the parsed Package no longer shows what came
+// back, and is off by default since it bloats every result. A limit of
+// zero or less turns it off.
+//
+// Registries made this way are wrapped, so they can't be type-asserted to
+// an ecosystem's own Registry type. FetchReadme and ResolveTag still reach
+// registries that implement them.
+func WithRawResponses(limit int) Option {
+	return func(c *Client) {
+		c.rawLimit = max(limit, 0)
+	}
+}
+
+type rawRecorderKey struct{}
+
+// rawRecorder keeps the first response body read under a context.
+type rawRecorder struct {
+	limit int
+	mu    sync.Mutex
+	body  []byte
+	set   bool
+}
+
+func (r *rawRecorder) record(body []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.set {
+		return
+	}
+	r.body = append([]byte(nil), body[:min(len(body), r.limit)]...)
+	r.set = true
+}
+
+func rawRecorderFrom(ctx context.Context) *rawRecorder {
+	rec, _ := ctx.Value(rawRecorderKey{}).(*rawRecorder)
+	return rec
+}
+
+// captureReader keeps the first limit bytes read through it.
+type captureReader struct {
+	r     io.Reader
+	limit int
+	head  []byte
+}
+
+func (c *captureReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if room := c.limit - len(c.head); room > 0 {
+		c.head = append(c.head, p[:min(n, room)]...)
+	}
+	return n, err
+}
+
+// rawRegistry records FetchPackage's first response body, see
+// WithRawResponses.
+type rawRegistry struct {
+	Registry
+	limit int
+}
+
+func (r rawRegistry) FetchPackage(ctx context.Context, name string) (*Package, error) {
+	rec := &rawRecorder{limit: r.limit}
+	pkg, err := r.Registry.FetchPackage(context.WithValue(ctx, rawRecorderKey{}, rec), name)
+	if err != nil || pkg == nil {
+		return pkg, err
+	}
+
+	rec.mu.Lock()
+	body, ok := rec.body, rec.set
+	rec.mu.Unlock()
+	if ok {
+		if pkg.Metadata == nil {
+			pkg.Metadata = make(map[string]any)
+		}
+		pkg.Metadata[rawMetadataKey] = strings.ToValidUTF8(string(body), "")
+	}
+	return pkg, nil
+}
+
+func (r rawRegistry) FetchReadme(ctx context.Context, name, version string) (string, error) {
+	return FetchReadme(ctx, r.Registry, name, version)
+}
+
+func (r rawRegistry) ResolveTag(ctx context.Context, name, tag string) (string, error) {
+	return ResolveTag(ctx, r.Registry, name, tag)
+}
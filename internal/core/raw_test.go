@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// rawTestRegistry fetches packages with GetJSON, or GetJSONStream for names
+// starting with "stream-".
+type rawTestRegistry struct {
+	Registry
+	baseURL string
+	client  *Client
+}
+
+func (r *rawTestRegistry) FetchPackage(ctx context.Context, name string) (*Package, error) {
+	var resp struct {
+		Name string `json:"name"`
+	}
+	url := r.baseURL + "/" + name
+	get := r.client.GetJSON
+	if strings.HasPrefix(name, "stream-") {
+		get = r.client.GetJSONStream
+	}
+	if err := get(ctx, url, &resp); err != nil {
+		return nil, err
+	}
+	return &Package{Name: resp.Name}, nil
+}
+
+func init() {
+	Register("rawtest", "", func(baseURL string, client *Client) Registry {
+		return &rawTestRegistry{baseURL: baseURL, client: client}
+	})
+}
+
+func TestWithRawResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name": "example", "unexpected": true}`))
+	}))
+	defer server.Close()
+
+	reg, err := New("rawtest", server.URL, NewClient(WithRawResponses(16)))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for _, name := range []string{"example", "stream-example"} {
+		pkg, err := reg.FetchPackage(context.Background(), name)
+		if err != nil {
+			t.Fatalf("FetchPackage(%s) failed: %v", name, err)
+		}
+		if pkg.Name != "example" {
+			t.Errorf("%s: expected name example, got %q", name, pkg.Name)
+		}
+		if raw := pkg.Metadata["_raw"]; raw != `{"name": "exampl` {
+			t.Errorf("%s: expected the truncated body, got %q", name, raw)
+		}
+	}
+
+	if _, err := FetchReadme(context.Background(), reg, "example", ""); !errors.Is(err, ErrReadmeUnsupported) {
+		t.Errorf("expected ErrReadmeUnsupported through the wrapper, got %v", err)
+	}
+
+	plain, err := New("rawtest", server.URL, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, ok := plain.(*rawTestRegistry); !ok {
+		t.Errorf("expected an unwrapped registry without the option, got %T", plain)
+	}
+	pkg, err := plain.FetchPackage(context.Background(), "example")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if _, ok := pkg.Metadata["_raw"]; ok {
+		t.Error("expected no raw body without the option")
+	}
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// ReadmeFetcher is implemented by registries that serve a package's README
+// themselves, such as npm and PyPI. RubyGems and Hex don't: their APIs carry
+// only a one-line description and the README ships inside the package
+// archive, so FetchReadmeWithRepositoryFallback reads it from the
+// repository instead.
+type ReadmeFetcher interface {
+	// FetchReadme returns the README for a version of a package, or for the
+	// latest version if version is empty. It returns an empty string when
+	// the package has no README.
+	FetchReadme(ctx context.Context, name, version string) (string, error)
+}
+
+// readmeNames are the files tried, in order, when reading a README from the
+// package's repository.
+var readmeNames = []string{"README.md", "README", "README.rst", "README.txt", "readme.md"}
+
+// FetchReadme returns the README for a package from its registry. It
+// returns ErrReadmeUnsupported if the registry doesn't serve READMEs.
+func FetchReadme(ctx context.Context, reg Registry, name, version string) (string, error) {
+	fetcher, ok := reg.(ReadmeFetcher)
+	if !ok {
+		return "", ErrReadmeUnsupported
+	}
+	return fetcher.FetchReadme(ctx, name, version)
+}
+
+// FetchReadmeWithRepositoryFallback is like FetchReadme, but when the
+// registry doesn't serve READMEs, or has none for the package, it reads the
+// README from the default branch of the package's repository on GitHub,
+// GitLab, Bitbucket or Codeberg. This costs a FetchPackage call and up to one request
+// per README file name tried. When the repository has none of those files
+// it returns what FetchReadme did: ErrReadmeUnsupported if the registry
+// doesn't serve READMEs, or an empty string if it does but has none.
+func FetchReadmeWithRepositoryFallback(ctx context.Context, reg Registry, name, version string, client *Client) (string, error) {
+	readme, err := FetchReadme(ctx, reg, name, version)
+	if err != nil && !errors.Is(err, ErrReadmeUnsupported) {
+		return "", err
+	}
+	if readme != "" {
+		return readme, nil
+	}
+
+	pkg, pkgErr := reg.FetchPackage(ctx, name)
+	if pkgErr != nil {
+		return "", pkgErr
+	}
+	if RawFileURL(pkg.Repository, readmeNames[0]) == "" {
+		return "", err
+	}
+
+	if client == nil {
+		client = DefaultClient()
+	}
+	for _, file := range readmeNames {
+		body, getErr := client.GetBody(ctx, RawFileURL(pkg.Repository, file))
+		if getErr == nil {
+			return string(body), nil
+		}
+		if httpErr, ok := getErr.(*HTTPError); !ok || !httpErr.IsNotFound() {
+			return "", getErr
+		}
+	}
+	return "", err
+}
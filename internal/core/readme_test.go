@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+type readmeRegistry struct {
+	Registry
+	repository string
+	readme     string
+}
+
+func (r readmeRegistry) FetchPackage(ctx context.Context, name string) (*Package, error) {
+	return &Package{Name: name, Repository: r.repository}, nil
+}
+
+type readmeFetcherRegistry struct {
+	readmeRegistry
+}
+
+func (r readmeFetcherRegistry) FetchReadme(ctx context.Context, name, version string) (string, error) {
+	return r.readme, nil
+}
+
+// redirectTransport sends every request to a test server, keeping the path.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newRedirectClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+
+	client := DefaultClient()
+	client.HTTPClient = &http.Client{Transport: redirectTransport{target: target}}
+	client.MaxRetries = 0
+	return client
+}
+
+func TestFetchReadme(t *testing.T) {
+	reg := readmeFetcherRegistry{readmeRegistry{readme: "# Example"}}
+	readme, err := FetchReadme(context.Background(), reg, "example", "")
+	if err != nil {
+		t.Fatalf("FetchReadme failed: %v", err)
+	}
+	if readme != "# Example" {
+		t.Errorf("expected %q, got %q", "# Example", readme)
+	}
+
+	if _, err := FetchReadme(context.Background(), readmeRegistry{}, "example", ""); !errors.Is(err, ErrReadmeUnsupported) {
+		t.Errorf("expected ErrReadmeUnsupported, got %v", err)
+	}
+}
+
+func TestFetchReadmeWithRepositoryFallback(t *testing.T) {
+	var paths []string
+	client := newRedirectClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/example/widget/HEAD/README.rst" {
+			_, _ = w.Write([]byte("Widget\n======"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	reg := readmeRegistry{repository: "https://github.com/example/widget"}
+	readme, err := FetchReadmeWithRepositoryFallback(context.Background(), reg, "widget", "1.0.0", client)
+	if err != nil {
+		t.Fatalf("FetchReadmeWithRepositoryFallback failed: %v", err)
+	}
+	if readme != "Widget\n======" {
+		t.Errorf("unexpected readme: %q", readme)
+	}
+	expected := []string{"/example/widget/HEAD/README.md", "/example/widget/HEAD/README", "/example/widget/HEAD/README.rst"}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected requests %v, got %v", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("expected request %d to be %q, got %q", i, expected[i], paths[i])
+		}
+	}
+}
+
+func TestFetchReadmeWithRepositoryFallbackMissing(t *testing.T) {
+	client := newRedirectClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	reg := readmeRegistry{repository: "https://github.com/example/widget"}
+	_, err := FetchReadmeWithRepositoryFallback(context.Background(), reg, "widget", "", client)
+	if !errors.Is(err, ErrReadmeUnsupported) {
+		t.Errorf("expected ErrReadmeUnsupported, got %v", err)
+	}
+}
+
+func TestFetchReadmeWithRepositoryFallbackPrefersRegistry(t *testing.T) {
+	requests := 0
+	client := newRedirectClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	reg := readmeFetcherRegistry{readmeRegistry{repository: "https://github.com/example/widget", readme: "# Widget"}}
+	readme, err := FetchReadmeWithRepositoryFallback(context.Background(), reg, "widget", "", client)
+	if err != nil {
+		t.Fatalf("FetchReadmeWithRepositoryFallback failed: %v", err)
+	}
+	if readme != "# Widget" || requests != 0 {
+		t.Errorf("expected the registry README without requests, got %q after %d requests", readme, requests)
+	}
+}
+
+func TestFetchReadmeWithRepositoryFallbackUnknownHost(t *testing.T) {
+	reg := readmeRegistry{repository: "https://git.example.com/example/widget"}
+	_, err := FetchReadmeWithRepositoryFallback(context.Background(), reg, "widget", "", nil)
+	if !errors.Is(err, ErrReadmeUnsupported) {
+		t.Errorf("expected ErrReadmeUnsupported, got %v", err)
+	}
+
+	// A registry that serves READMEs but has none for the package isn't an error
+	supported := readmeFetcherRegistry{readmeRegistry{repository: "https://git.example.com/example/widget"}}
+	readme, err := FetchReadmeWithRepositoryFallback(context.Background(), supported, "widget", "", nil)
+	if err != nil || readme != "" {
+		t.Errorf("expected an empty README and no error, got %q, %v", readme, err)
+	}
+}
@@ -48,6 +48,9 @@ func Register(ecosystem string, defaultURL string, factory Factory) {
 
 // New creates a new registry for the given ecosystem.
 // If baseURL is empty, the default registry URL is used.
+// The registry gets a copy of client that records the ecosystem for
+// DecodeError; it shares the client's HTTP client and Close state, but
+// fields such as RateLimiter set on client afterwards don't reach it.
 func New(ecosystem string, baseURL string, client *Client) (Registry, error) {
 	mu.RLock()
 	factory, ok := factories[ecosystem]
@@ -66,7 +69,11 @@ func New(ecosystem string, baseURL string, client *Client) (Registry, error) {
 		client = DefaultClient()
 	}
 
-	return factory(baseURL, client), nil
+	reg := factory(baseURL, client.forEcosystem(ecosystem))
+	if client.rawLimit > 0 {
+		return rawRegistry{Registry: reg, limit: client.rawLimit}, nil
+	}
+	return reg, nil
 }
 
 // SupportedEcosystems returns all registered ecosystem types.
@@ -87,3 +94,16 @@ func DefaultURL(ecosystem string) string {
 	defer mu.RUnlock()
 	return defaults[ecosystem]
 }
+
+// PURLType returns the PURL type of a registered ecosystem's packages, or
+// empty string if the ecosystem isn't registered. Ecosystems are registered
+// under their PURL type, so this is the ecosystem itself, but callers can
+// use it to reject a PURL whose type no registry handles before fetching.
+func PURLType(ecosystem string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if _, ok := factories[ecosystem]; !ok {
+		return ""
+	}
+	return ecosystem
+}
@@ -1,9 +1,188 @@
 package core
 
 import (
+	"net/url"
+	"sort"
+	"strings"
+
 	"github.com/git-pkgs/registries/internal/urlparser"
 )
 
+// NormalizeRepository returns a consistent form of a URL that a registry
+// declares as the package's source repository.
+//
+// URLs on known hosts (GitHub, GitLab, Bitbucket, ...) are reduced to
+// https://host/owner/repo, dropping schemes like git+ssh, .git suffixes and
+// trailing /tree/... paths. Self-hosted URLs are cleaned the same way but keep
+// their full path, since subgroups or /viewvc-style layouts can't be
+// inferred. Don't use this for homepage fallbacks: an arbitrary website
+// isn't a repository and would be kept as-is.
+func NormalizeRepository(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if urlparser.IsKnownHost(rawURL) {
+		return urlparser.Parse(rawURL)
+	}
+	return urlparser.Normalize(rawURL)
+}
+
+// docsHostSuffixes are hosts that serve project documentation sites, so a
+// homepage on one of them is taken to be the package's documentation.
+var docsHostSuffixes = []string{
+	".github.io",
+	".gitlab.io",
+	".readthedocs.io",
+	".readthedocs.org",
+	".readthedocs-hosted.com",
+	".pages.dev",
+	".netlify.app",
+}
+
+// DocumentationURL returns a documentation URL for a version of pkg,
+// preferring the one the registry builds. When the registry has none it
+// falls back to DocumentationFallback. Registries' own Documentation URLs
+// are unchanged.
+func DocumentationURL(reg Registry, pkg *Package, version string) string {
+	if pkg == nil {
+		return ""
+	}
+	if docs := reg.URLs().Documentation(pkg.Name, version); docs != "" {
+		return docs
+	}
+	return DocumentationFallback(pkg)
+}
+
+// DocumentationFallback guesses a documentation URL for pkg without making
+// any requests. A homepage hosted on GitHub Pages, GitLab Pages, Read the
+// Docs or a similar docs host is used as is. Otherwise a repository on a
+// known host (GitHub, GitLab, ...) is returned, since its README is
+// rendered there. Returns empty string if neither applies.
+func DocumentationFallback(pkg *Package) string {
+	if pkg == nil {
+		return ""
+	}
+	if isDocsSite(pkg.Homepage) {
+		return pkg.Homepage
+	}
+	return urlparser.CanonicalURL(pkg.Repository)
+}
+
+// RawFileURL returns the URL serving the raw contents of path on the
+// default branch of the repository at repoURL, using the host's HEAD ref or
+// equivalent so the branch name needn't be known. GitHub, GitLab,
+// Bitbucket and Codeberg are supported; other hosts return "".
+func RawFileURL(repoURL, path string) string {
+	return urlparser.RawFileURL(repoURL, "HEAD", path)
+}
+
+// CanonicalHomepage returns a homepage link for a package that always
+// resolves when anything is known about it: the homepage the registry
+// reports, or else the package's repository as an https URL, or else its
+// page on the registry from urls. name and version are passed to
+// urls.Registry; an empty name means pkg.Name. pkg and urls may be nil.
+// The package is not modified.
+func CanonicalHomepage(pkg *Package, urls URLBuilder, name, version string) string {
+	if pkg != nil {
+		if homepage := strings.TrimSpace(pkg.Homepage); homepage != "" {
+			return homepage
+		}
+		if repo := NormalizeRepository(pkg.Repository); repo != "" {
+			return repo
+		}
+		if name == "" {
+			name = pkg.Name
+		}
+	}
+	if urls == nil || name == "" {
+		return ""
+	}
+	return urls.Registry(name, version)
+}
+
+// projectURLLabels are the ProjectURLs labels, normalized by
+// projectURLLabel, that ApplyProjectURLs reads, in order of preference.
+var projectURLLabels = struct {
+	repository, homepage []string
+}{
+	repository: []string{"repository", "source", "sourcecode", "code"},
+	homepage:   []string{"homepage", "home"},
+}
+
+// ApplyProjectURLs fills pkg's Repository and Homepage from its ProjectURLs
+// when they are empty. Labels are matched ignoring case, spaces, hyphens
+// and underscores, so "Source Code" and "source_code" are the same.
+// Repository is taken from the first of Repository, Source, Source Code and
+// Code that parses as a repository, then from any other link that does
+// except sponsor pages; Homepage from Homepage or Home.
+func ApplyProjectURLs(pkg *Package) {
+	if pkg == nil || len(pkg.ProjectURLs) == 0 {
+		return
+	}
+
+	labels := make([]string, 0, len(pkg.ProjectURLs))
+	byLabel := make(map[string]string, len(pkg.ProjectURLs))
+	for label := range pkg.ProjectURLs {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		key := projectURLLabel(label)
+		if _, ok := byLabel[key]; !ok && strings.TrimSpace(pkg.ProjectURLs[label]) != "" {
+			byLabel[key] = strings.TrimSpace(pkg.ProjectURLs[label])
+		}
+	}
+
+	if pkg.Repository == "" {
+		for _, key := range projectURLLabels.repository {
+			if repo := urlparser.Parse(byLabel[key]); repo != "" {
+				pkg.Repository = repo
+				break
+			}
+		}
+	}
+	if pkg.Repository == "" {
+		for _, label := range labels {
+			link := pkg.ProjectURLs[label]
+			if strings.Contains(link, "/sponsors") {
+				continue
+			}
+			if repo := urlparser.Parse(link); repo != "" {
+				pkg.Repository = repo
+				break
+			}
+		}
+	}
+
+	if pkg.Homepage == "" {
+		for _, key := range projectURLLabels.homepage {
+			if link := byLabel[key]; link != "" {
+				pkg.Homepage = link
+				break
+			}
+		}
+	}
+}
+
+func projectURLLabel(label string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(label)))
+}
+
+func isDocsSite(rawURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, suffix := range docsHostSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
 // ExtractRepoURL extracts a repository URL from various API response formats.
 // Handles:
 //   - Plain string: "https://github.com/user/repo"
@@ -45,12 +224,17 @@ func extractRepoURL(v interface{}) string {
 				return parsed
 			}
 		}
+
+	case issueTracker:
+		return repoFromIssueTracker(r.value)
 	}
 
 	return ""
 }
 
 // ExtractRepoURLWithFallback tries multiple values and returns the first valid repo URL.
+// Values wrapped with IssueTracker are only used for a repository on a
+// known host, so list them after the repository fields.
 func ExtractRepoURLWithFallback(values ...interface{}) string {
 	for _, v := range values {
 		if url := ExtractRepoURL(v); url != "" {
@@ -59,3 +243,65 @@ func ExtractRepoURLWithFallback(values ...interface{}) string {
 	}
 	return ""
 }
+
+type issueTracker struct {
+	value interface{}
+}
+
+// IssueTracker marks a bug tracker URL, as a string or a map with a url or
+// web key like npm's bugs field, for ExtractRepoURL and
+// ExtractRepoURLWithFallback. Packages often leave the repository unset
+// but point their bug tracker at the repository's issues, so an issues
+// URL on a known host (GitHub, GitLab, ...), such as
+// https://github.com/o/r/issues or https://gitlab.com/o/r/-/issues/12,
+// gives the repository. Other tracker URLs, like rt.cpan.org queues, give
+// nothing, since their paths don't name a repository.
+func IssueTracker(v interface{}) interface{} {
+	return issueTracker{value: v}
+}
+
+// issuePathSuffixes end the repository part of an issue tracker URL.
+var issuePathSuffixes = []string{"/-/issues", "/issues", "/bugs"}
+
+func repoFromIssueTracker(v interface{}) string {
+	switch r := v.(type) {
+	case string:
+		return repoFromIssuesURL(r)
+	case map[string]interface{}:
+		for _, key := range []string{"url", "web"} {
+			if url, ok := r[key].(string); ok {
+				if repo := repoFromIssuesURL(url); repo != "" {
+					return repo
+				}
+			}
+		}
+	case map[string]string:
+		for _, key := range []string{"url", "web"} {
+			if repo := repoFromIssuesURL(r[key]); repo != "" {
+				return repo
+			}
+		}
+	}
+	return ""
+}
+
+func repoFromIssuesURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" || !urlparser.IsKnownHost(rawURL) {
+		return ""
+	}
+	rawURL, _, _ = strings.Cut(rawURL, "?")
+	rawURL, _, _ = strings.Cut(rawURL, "#")
+	rawURL = strings.TrimSuffix(rawURL, "/")
+
+	for _, suffix := range issuePathSuffixes {
+		i := strings.Index(rawURL, suffix)
+		if i < 0 {
+			continue
+		}
+		if rest := rawURL[i+len(suffix):]; rest == "" || rest[0] == '/' {
+			return urlparser.Parse(rawURL[:i])
+		}
+	}
+	return ""
+}
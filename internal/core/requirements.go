@@ -0,0 +1,484 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// constraint is a single comparison such as ">=1.2.0". A requirement is
+// parsed into a list of constraint sets: a version satisfies it if it
+// satisfies every constraint of any one set.
+type constraint struct {
+	op      string // "=", "!=", ">", ">=", "<", "<=" or "<release"
+	version string
+}
+
+func (c constraint) matches(compare func(a, b string) int, version string) bool {
+	cmp := compare(version, c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "<release":
+		// RubyGems compares the upper bound of ~> against the version with
+		// its prerelease segments dropped, so prereleases of the bound are
+		// excluded along with the bound itself.
+		return compare(strings.Join(gemRelease(version), "."), c.version) < 0
+	}
+	return false
+}
+
+// requirementGrammar parses an ecosystem's requirement syntax.
+type requirementGrammar struct {
+	parse func(requirement string) ([][]constraint, error)
+
+	// strictPrereleases applies npm's rule that a prerelease only satisfies
+	// a range that names a prerelease of the same major.minor.patch, so
+	// "^1.0.0" doesn't match "1.1.0-beta".
+	strictPrereleases bool
+}
+
+// requirementGrammars holds the ecosystems whose requirement syntax
+// SatisfiesRequirement understands.
+var requirementGrammars = map[string]requirementGrammar{
+	"npm":       {parse: parseNPMRange, strictPrereleases: true},
+	"composer":  {parse: parseComposerConstraint},
+	"gem":       {parse: parseGemRequirement},
+	"cocoapods": {parse: parseGemRequirement},
+	"maven":     {parse: parseMavenRange},
+	"clojars":   {parse: parseMavenRange},
+}
+
+// SatisfiesRequirement reports whether version satisfies requirement, a
+// dependency requirement string as found in Dependency.Requirements, using
+// the constraint grammar and version ordering of ecosystem:
+//
+//   - npm: semver ranges with ^, ~, x-ranges, hyphen ranges and ||
+//   - composer: the same operators, with Composer's ~ (~1.2 allows 1.x) and
+//     wildcards like 1.0.*; stability flags such as @dev are ignored
+//   - gem and cocoapods: comma-separated comparisons and ~>
+//   - maven and clojars: bracket ranges such as [1.0,2.0); a bare version
+//     is treated as an exact match rather than Maven's soft requirement
+//
+// It returns an error wrapping ErrUnsupportedConstraint for other
+// ecosystems and for requirements it can't parse, such as npm dist-tags or
+// Composer branch constraints.
+func SatisfiesRequirement(ecosystem, version, requirement string) (bool, error) {
+	grammar, ok := requirementGrammars[ecosystem]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrUnsupportedConstraint, ecosystem)
+	}
+	sets, err := grammar.parse(strings.TrimSpace(requirement))
+	if err != nil {
+		return false, err
+	}
+
+	compare := versionComparator(ecosystem)
+	version = strings.TrimSpace(version)
+	for _, set := range sets {
+		if grammar.strictPrereleases && isSemverPrerelease(version) && !allowsPrerelease(set, version) {
+			continue
+		}
+		if satisfiesAll(set, compare, version) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func satisfiesAll(set []constraint, compare func(a, b string) int, version string) bool {
+	for _, c := range set {
+		if !c.matches(compare, version) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowsPrerelease reports whether set names a prerelease with the same
+// release part as version.
+func allowsPrerelease(set []constraint, version string) bool {
+	release := semverRelease(version)
+	for _, c := range set {
+		if isSemverPrerelease(c.version) && semverRelease(c.version) == release {
+			return true
+		}
+	}
+	return false
+}
+
+func semverRelease(v string) string {
+	v, _, _ = strings.Cut(strings.TrimPrefix(v, "v"), "+")
+	release, _, _ := strings.Cut(v, "-")
+	return release
+}
+
+func unsupportedRequirement(requirement string) error {
+	return fmt.Errorf("%w: %q", ErrUnsupportedConstraint, requirement)
+}
+
+// partialVersion is a version that may leave out trailing parts, or give
+// them as x or *, as in "1.2" or "1.x".
+type partialVersion struct {
+	parts []int  // the numeric parts given, at most three
+	pre   string // prerelease suffix without the "-", only for full versions
+}
+
+func parsePartialVersion(s string) (partialVersion, bool) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "="), "v")
+	s, _, _ = strings.Cut(s, "+")
+	release, pre, _ := strings.Cut(s, "-")
+
+	var pv partialVersion
+	if release == "" {
+		return pv, false
+	}
+	fields := strings.Split(release, ".")
+	if len(fields) > 3 {
+		return pv, false
+	}
+	for _, f := range fields {
+		if f == "x" || f == "X" || f == "*" {
+			break
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return pv, false
+		}
+		pv.parts = append(pv.parts, n)
+	}
+	if pre != "" && len(pv.parts) < 3 {
+		return pv, false
+	}
+	pv.pre = pre
+	return pv, true
+}
+
+// version fills any missing parts with zeros.
+func (pv partialVersion) version() string {
+	parts := [3]int{}
+	copy(parts[:], pv.parts)
+	v := fmt.Sprintf("%d.%d.%d", parts[0], parts[1], parts[2])
+	if pv.pre != "" {
+		v += "-" + pv.pre
+	}
+	return v
+}
+
+// bump returns the lowest version above every version matching the first n
+// parts of pv, e.g. bump(1) of 1.2.3 is 2.0.0. The "-0" suffix keeps
+// prereleases of that version out of the range.
+func (pv partialVersion) bump(n int) string {
+	parts := [3]int{}
+	copy(parts[:], pv.parts)
+	parts[n-1]++
+	for i := n; i < 3; i++ {
+		parts[i] = 0
+	}
+	return fmt.Sprintf("%d.%d.%d-0", parts[0], parts[1], parts[2])
+}
+
+func (pv partialVersion) full() bool {
+	return len(pv.parts) == 3
+}
+
+// parseNPMRange parses an npm semver range.
+func parseNPMRange(requirement string) ([][]constraint, error) {
+	var sets [][]constraint
+	for _, part := range strings.Split(requirement, "||") {
+		set, err := parseSemverSet(part, false)
+		if err != nil {
+			return nil, unsupportedRequirement(requirement)
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// parseComposerConstraint parses a Composer version constraint. "|" is an
+// older spelling of "||", and comparisons may be joined with commas.
+func parseComposerConstraint(requirement string) ([][]constraint, error) {
+	requirement = strings.ReplaceAll(requirement, "||", "|")
+	var sets [][]constraint
+	for _, part := range strings.Split(requirement, "|") {
+		var fields []string
+		for _, f := range strings.Fields(strings.ReplaceAll(part, ",", " ")) {
+			f, _, _ = strings.Cut(f, "@")
+			if strings.HasPrefix(f, "dev-") || strings.HasSuffix(f, "-dev") {
+				return nil, unsupportedRequirement(requirement)
+			}
+			if f != "" {
+				fields = append(fields, f)
+			}
+		}
+		set, err := parseSemverSet(strings.Join(fields, " "), true)
+		if err != nil {
+			return nil, unsupportedRequirement(requirement)
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// parseSemverSet parses space-separated comparators that must all hold,
+// or a hyphen range "1.0 - 2.0". composer selects Composer's tilde rule.
+func parseSemverSet(s string, composer bool) ([]constraint, error) {
+	s = strings.TrimSpace(s)
+	if low, high, ok := strings.Cut(s, " - "); ok {
+		return parseHyphenRange(strings.TrimSpace(low), strings.TrimSpace(high))
+	}
+
+	// Join operators written apart from their version, as in ">= 1.0".
+	var tokens []string
+	pending := ""
+	for _, f := range strings.Fields(s) {
+		if strings.Trim(f, "<>=!~^") == "" {
+			pending += f
+			continue
+		}
+		tokens = append(tokens, pending+f)
+		pending = ""
+	}
+	if pending != "" {
+		return nil, fmt.Errorf("dangling operator %q", pending)
+	}
+
+	set := []constraint{}
+	for _, tok := range tokens {
+		cs, err := parseComparator(tok, composer)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, cs...)
+	}
+	return set, nil
+}
+
+func parseHyphenRange(low, high string) ([]constraint, error) {
+	lo, ok := parsePartialVersion(low)
+	if !ok {
+		return nil, fmt.Errorf("invalid version %q", low)
+	}
+	hi, ok := parsePartialVersion(high)
+	if !ok {
+		return nil, fmt.Errorf("invalid version %q", high)
+	}
+	set := []constraint{{">=", lo.version()}}
+	switch {
+	case hi.full():
+		set = append(set, constraint{"<=", hi.version()})
+	case len(hi.parts) > 0:
+		set = append(set, constraint{"<", hi.bump(len(hi.parts))})
+	}
+	return set, nil
+}
+
+// comparatorOps are the operator prefixes, longest first so ">=" isn't
+// read as ">".
+var comparatorOps = []string{"~>", ">=", "<=", "!=", ">", "<", "=", "^", "~"}
+
+func parseComparator(tok string, composer bool) ([]constraint, error) {
+	op := ""
+	for _, candidate := range comparatorOps {
+		if strings.HasPrefix(tok, candidate) {
+			op = candidate
+			break
+		}
+	}
+	pv, ok := parsePartialVersion(strings.TrimPrefix(tok, op))
+	if !ok {
+		return nil, fmt.Errorf("invalid version %q", tok)
+	}
+	n := len(pv.parts)
+
+	switch op {
+	case "", "=":
+		switch {
+		case pv.full():
+			return []constraint{{"=", pv.version()}}, nil
+		case n == 0:
+			return []constraint{}, nil
+		}
+		return []constraint{{">=", pv.version()}, {"<", pv.bump(n)}}, nil
+	case "!=":
+		if !pv.full() {
+			return nil, fmt.Errorf("partial version in %q", tok)
+		}
+		return []constraint{{"!=", pv.version()}}, nil
+	case ">":
+		if pv.full() {
+			return []constraint{{">", pv.version()}}, nil
+		}
+		if n == 0 {
+			return []constraint{{"<", "0.0.0-0"}}, nil
+		}
+		return []constraint{{">=", pv.bump(n)}}, nil
+	case ">=":
+		return []constraint{{">=", pv.version()}}, nil
+	case "<":
+		if pv.full() {
+			return []constraint{{"<", pv.version()}}, nil
+		}
+		return []constraint{{"<", pv.version() + "-0"}}, nil
+	case "<=":
+		if pv.full() || n == 0 {
+			return []constraint{{"<=", pv.version()}}, nil
+		}
+		return []constraint{{"<", pv.bump(n)}}, nil
+	case "^":
+		if n == 0 {
+			return []constraint{}, nil
+		}
+		// Allow changes that don't modify the leftmost non-zero part, or
+		// the last part given if they are all zero, so ^0.2.3 allows 0.2.x
+		// and ^0.0 allows 0.0.x.
+		bumpAt := n
+		for i, p := range pv.parts {
+			if p != 0 {
+				bumpAt = i + 1
+				break
+			}
+		}
+		return []constraint{{">=", pv.version()}, {"<", pv.bump(bumpAt)}}, nil
+	case "~", "~>":
+		if n == 0 {
+			return []constraint{}, nil
+		}
+		// npm's ~1.2.3 and ~1.2 allow patch changes; Composer's ~1.2 allows
+		// minor ones, like Ruby's ~>.
+		bumpAt := min(n, 2)
+		if composer && n > 1 {
+			bumpAt = n - 1
+		}
+		return []constraint{{">=", pv.version()}, {"<", pv.bump(bumpAt)}}, nil
+	}
+	return nil, fmt.Errorf("unknown operator in %q", tok)
+}
+
+// parseGemRequirement parses a RubyGems requirement such as "~> 3.0" or
+// ">= 1.2, < 2". Gem versions can have any number of segments, so they are
+// compared as written rather than padded to three.
+func parseGemRequirement(requirement string) ([][]constraint, error) {
+	set := []constraint{}
+	for _, part := range strings.Split(requirement, ",") {
+		part = strings.TrimSpace(part)
+		op := "="
+		for _, candidate := range comparatorOps {
+			if candidate != "^" && candidate != "~" && strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(strings.TrimPrefix(part, candidate))
+				break
+			}
+		}
+		if part == "" || strings.ContainsAny(part, " <>=!~") {
+			return nil, unsupportedRequirement(requirement)
+		}
+		if op != "~>" {
+			set = append(set, constraint{op, part})
+			continue
+		}
+		upper, ok := bumpGemVersion(part)
+		if !ok {
+			return nil, unsupportedRequirement(requirement)
+		}
+		set = append(set, constraint{">=", part}, constraint{"<release", upper})
+	}
+	return [][]constraint{set}, nil
+}
+
+// bumpGemVersion follows Gem::Version#bump: prerelease segments are
+// dropped, then the last segment if more than one remains, and the new last
+// segment is incremented, so "~> 3.0.1" allows up to but not including 3.1.
+func bumpGemVersion(v string) (string, bool) {
+	segs := gemRelease(v)
+	if len(segs) == 0 {
+		return "", false
+	}
+	if len(segs) > 1 {
+		segs = segs[:len(segs)-1]
+	}
+	last, err := strconv.Atoi(segs[len(segs)-1])
+	if err != nil {
+		return "", false
+	}
+	segs[len(segs)-1] = strconv.Itoa(last + 1)
+	return strings.Join(segs, "."), true
+}
+
+// gemRelease returns the segments of a gem version before its first
+// prerelease segment, as Gem::Version#release does.
+func gemRelease(v string) []string {
+	var segs []string
+	for _, seg := range tokenizeVersion(v) {
+		if !isNumeric(seg) {
+			break
+		}
+		segs = append(segs, seg)
+	}
+	return segs
+}
+
+// parseMavenRange parses a Maven version range: one or more comma-separated
+// intervals such as "[1.0,2.0)" or "(,1.0]", or an exact "[1.0]". A bare
+// version is taken as an exact match.
+func parseMavenRange(requirement string) ([][]constraint, error) {
+	if !strings.HasPrefix(requirement, "[") && !strings.HasPrefix(requirement, "(") {
+		if requirement == "" || strings.ContainsAny(requirement, "[](), ") {
+			return nil, unsupportedRequirement(requirement)
+		}
+		return [][]constraint{{{"=", requirement}}}, nil
+	}
+
+	var sets [][]constraint
+	rest := requirement
+	for rest != "" {
+		end := strings.IndexAny(rest, "])")
+		if end < 0 || (rest[0] != '[' && rest[0] != '(') {
+			return nil, unsupportedRequirement(requirement)
+		}
+		interval := rest[1:end]
+		lowInclusive, highInclusive := rest[0] == '[', rest[end] == ']'
+		rest = strings.TrimSpace(rest[end+1:])
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimSpace(rest[1:])
+		}
+
+		low, high, isRange := strings.Cut(interval, ",")
+		low, high = strings.TrimSpace(low), strings.TrimSpace(high)
+		if !isRange {
+			if low == "" || !lowInclusive || !highInclusive {
+				return nil, unsupportedRequirement(requirement)
+			}
+			sets = append(sets, []constraint{{"=", low}})
+			continue
+		}
+
+		set := []constraint{}
+		if low != "" {
+			op := ">"
+			if lowInclusive {
+				op = ">="
+			}
+			set = append(set, constraint{op, low})
+		}
+		if high != "" {
+			op := "<"
+			if highInclusive {
+				op = "<="
+			}
+			set = append(set, constraint{op, high})
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSatisfiesRequirement(t *testing.T) {
+	tests := []struct {
+		ecosystem   string
+		version     string
+		requirement string
+		want        bool
+	}{
+		{"npm", "1.2.3", "^1.2.3", true},
+		{"npm", "1.9.0", "^1.2.3", true},
+		{"npm", "2.0.0", "^1.2.3", false},
+		{"npm", "1.2.2", "^1.2.3", false},
+		{"npm", "0.2.9", "^0.2.3", true},
+		{"npm", "0.3.0", "^0.2.3", false},
+		{"npm", "0.0.3", "^0.0.3", true},
+		{"npm", "0.0.4", "^0.0.3", false},
+		{"npm", "0.0.9", "^0.0", true},
+		{"npm", "0.1.0", "^0.0", false},
+		{"npm", "1.2.9", "~1.2.3", true},
+		{"npm", "1.3.0", "~1.2.3", false},
+		{"npm", "1.2.0", "~1.2", true},
+		{"npm", "1.3.0", "~1.2", false},
+		{"npm", "1.5.0", ">=1.0 <2", true},
+		{"npm", "2.0.0", ">=1.0 <2", false},
+		{"npm", "1.5.0", ">= 1.0.0", true},
+		{"npm", "1.4.2", "1.x", true},
+		{"npm", "2.0.0", "1.x", false},
+		{"npm", "1.2.7", "1.2.*", true},
+		{"npm", "3.1.0", "1.2.3 - 2.3.4", false},
+		{"npm", "2.3.4", "1.2.3 - 2.3.4", true},
+		{"npm", "2.3.9", "1.2.3 - 2.3", true},
+		{"npm", "3.0.0", "^1.0.0 || ^3.0.0", true},
+		{"npm", "2.0.0", "^1.0.0 || ^3.0.0", false},
+		{"npm", "4.0.0", "*", true},
+		{"npm", "4.0.0", "", true},
+		{"npm", "1.2.3", "=1.2.3", true},
+		{"npm", "1.2.4", "1.2.3", false},
+		{"npm", "1.2.4", ">1.2", false},
+		{"npm", "1.3.0", ">1.2", true},
+		{"npm", "1.2.9", "<=1.2", true},
+		{"npm", "1.1.0-beta", "^1.0.0", false},
+		{"npm", "1.0.0-rc.2", ">=1.0.0-rc.1 <2", true},
+		{"npm", "1.1.0-rc.2", ">=1.0.0-rc.1 <2", false},
+		{"npm", "4.0.0-beta", "*", false},
+
+		{"composer", "1.9.0", "~1.2", true},
+		{"composer", "2.0.0", "~1.2", false},
+		{"composer", "1.2.9", "~1.2.3", true},
+		{"composer", "1.3.0", "~1.2.3", false},
+		{"composer", "v2.4.1", "^2.0", true},
+		{"composer", "1.0.5", "1.0.*", true},
+		{"composer", "1.1.0", "1.0.*", false},
+		{"composer", "7.4.0", ">=7.2,<8.0", true},
+		{"composer", "8.0.0", ">=7.2,<8.0", false},
+		{"composer", "3.0.0", "^1.0 | ^3.0", true},
+		{"composer", "1.5.0", "^1.0@dev", true},
+		{"composer", "1.0.1", "!=1.0.0", true},
+
+		{"gem", "3.2.1", "~> 3.0", true},
+		{"gem", "4.0", "~> 3.0", false},
+		{"gem", "4.0.0.pre", "~> 3.0", false},
+		{"gem", "3.1.0.rc1", "~> 3.0", true},
+		{"gem", "3.0.9", "~> 3.0.1", true},
+		{"gem", "3.1.0", "~> 3.0.1", false},
+		{"gem", "1.5", ">= 1.2, < 2", true},
+		{"gem", "2.0", ">= 1.2, < 2", false},
+		{"gem", "1.0", "1.0.0", true},
+		{"gem", "1.0.1", "!= 1.0.1", false},
+
+		{"maven", "1.5", "[1.0,2.0)", true},
+		{"maven", "2.0", "[1.0,2.0)", false},
+		{"maven", "1.0", "(1.0,2.0]", false},
+		{"maven", "2.0", "(1.0,2.0]", true},
+		{"maven", "0.9", "(,1.0]", true},
+		{"maven", "3.5", "[1.0,2.0),[3.0,)", true},
+		{"maven", "2.5", "[1.0,2.0),[3.0,)", false},
+		{"maven", "1.2", "[1.2]", true},
+		{"maven", "1.2.1", "[1.2]", false},
+		{"maven", "1.0.0", "1.0", true},
+		{"maven", "1.0-SNAPSHOT", "[1.0,)", false},
+	}
+
+	for _, tt := range tests {
+		got, err := SatisfiesRequirement(tt.ecosystem, tt.version, tt.requirement)
+		if err != nil {
+			t.Errorf("%s: SatisfiesRequirement(%q, %q) error: %v", tt.ecosystem, tt.version, tt.requirement, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: SatisfiesRequirement(%q, %q) = %v, want %v", tt.ecosystem, tt.version, tt.requirement, got, tt.want)
+		}
+	}
+}
+
+func TestSatisfiesRequirementUnsupported(t *testing.T) {
+	tests := []struct {
+		ecosystem   string
+		requirement string
+	}{
+		{"pypi", ">=1.0"},
+		{"npm", "latest"},
+		{"npm", ">="},
+		{"composer", "dev-main"},
+		{"gem", "~> beta"},
+		{"maven", "[1.0,2.0"},
+	}
+
+	for _, tt := range tests {
+		_, err := SatisfiesRequirement(tt.ecosystem, "1.0.0", tt.requirement)
+		if !errors.Is(err, ErrUnsupportedConstraint) {
+			t.Errorf("%s: SatisfiesRequirement(%q) = %v, want ErrUnsupportedConstraint", tt.ecosystem, tt.requirement, err)
+		}
+	}
+}
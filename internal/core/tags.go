@@ -0,0 +1,31 @@
+package core
+
+import "context"
+
+// TagResolver is implemented by registries whose packages have symbolic
+// versions that point at a real one, such as npm's dist-tags ("latest",
+// "next") and Maven's LATEST and RELEASE.
+type TagResolver interface {
+	// ResolveTag returns the version tag currently points to for a
+	// package, or an empty string if tag isn't one of its tags.
+	ResolveTag(ctx context.Context, name, tag string) (string, error)
+}
+
+// ResolveTag returns the version a symbolic tag points to for a package.
+// It returns tag unchanged if the registry doesn't implement TagResolver or
+// tag isn't one of the package's tags, so the result can always be used as
+// a version number.
+func ResolveTag(ctx context.Context, reg Registry, name, tag string) (string, error) {
+	resolver, ok := reg.(TagResolver)
+	if !ok {
+		return tag, nil
+	}
+	version, err := resolver.ResolveTag(ctx, name, tag)
+	if err != nil {
+		return "", err
+	}
+	if version == "" {
+		return tag, nil
+	}
+	return version, nil
+}
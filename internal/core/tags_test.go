@@ -0,0 +1,84 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// tagTestRegistry serves two versions of every package, with "next"
+// pointing at the newer one.
+type tagTestRegistry struct {
+	Registry
+}
+
+func (r tagTestRegistry) FetchVersions(ctx context.Context, name string) ([]Version, error) {
+	return []Version{{Number: "1.0.0"}, {Number: "2.0.0-rc.1"}}, nil
+}
+
+func (r tagTestRegistry) ResolveTag(ctx context.Context, name, tag string) (string, error) {
+	if tag == "broken" {
+		return "", errors.New("tags unavailable")
+	}
+	return map[string]string{"next": "2.0.0-rc.1", "gone": "0.1.0"}[tag], nil
+}
+
+func init() {
+	Register("tagtest", "", func(baseURL string, client *Client) Registry {
+		return tagTestRegistry{}
+	})
+}
+
+func TestResolveTag(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		reg  Registry
+		tag  string
+		want string
+	}{
+		{tagTestRegistry{}, "next", "2.0.0-rc.1"},
+		{tagTestRegistry{}, "1.0.0", "1.0.0"},
+		{staticVersionsRegistry{ecosystemRegistry: ecosystemRegistry{ecosystem: "npm"}}, "next", "next"},
+	}
+	for _, tt := range tests {
+		got, err := ResolveTag(ctx, tt.reg, "example", tt.tag)
+		if err != nil {
+			t.Fatalf("ResolveTag(%T, %s) failed: %v", tt.reg, tt.tag, err)
+		}
+		if got != tt.want {
+			t.Errorf("ResolveTag(%T, %s) = %q, want %q", tt.reg, tt.tag, got, tt.want)
+		}
+	}
+
+	if _, err := ResolveTag(ctx, tagTestRegistry{}, "example", "broken"); err == nil {
+		t.Error("expected the resolver's error to be returned")
+	}
+}
+
+func TestFetchVersionFromPURLTag(t *testing.T) {
+	ctx := context.Background()
+
+	for purl, want := range map[string]string{
+		"pkg:tagtest/example@1.0.0": "1.0.0",
+		"pkg:tagtest/example@next":  "2.0.0-rc.1",
+	} {
+		v, err := FetchVersionFromPURL(ctx, purl, nil)
+		if err != nil {
+			t.Fatalf("FetchVersionFromPURL(%s) failed: %v", purl, err)
+		}
+		if v.Number != want {
+			t.Errorf("%s: expected %s, got %s", purl, want, v.Number)
+		}
+	}
+
+	for _, purl := range []string{"pkg:tagtest/example@beta", "pkg:tagtest/example@gone"} {
+		_, err := FetchVersionFromPURL(ctx, purl, nil)
+		var notFound *NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("%s: expected NotFoundError, got %v", purl, err)
+		}
+		if notFound.Version != purl[len("pkg:tagtest/example@"):] {
+			t.Errorf("%s: expected the tag in the error, got %q", purl, notFound.Version)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package core
+
+import (
+	"context"
+	"sort"
+)
+
+const (
+	// defaultMaxDepth and defaultMaxNodes bound FetchDependenciesRecursiveFlat
+	// when the caller passes zero.
+	defaultMaxDepth = 10
+	defaultMaxNodes = 1000
+
+	// transitiveConcurrency limits the registry requests made for each level
+	// of the walk.
+	transitiveConcurrency = 8
+)
+
+// FetchDependenciesRecursiveFlat returns the set of packages a version of a
+// package depends on, directly or transitively, with each package listed
+// once. It is meant for checks that need every package but not the shape
+// of the graph, such as license scanning.
+//
+// The walk is breadth-first and follows runtime dependencies only, since
+// development, test and build dependencies aren't installed alongside a
+// package. Each dependency is resolved to the highest stable version
+// satisfying its requirement when SatisfiesRequirement understands the
+// ecosystem's syntax, and to the latest stable version otherwise; the
+// first, and so shallowest, occurrence of a package decides its version.
+// An empty version starts from the package's latest stable version.
+//
+// Each returned Dependency records in Metadata:
+//
+//	"version"  the resolved version, absent if none could be resolved
+//	"depth"    1 for direct dependencies, 2 for theirs and so on
+//	"path"     the "name@version" chain from the root to the dependent
+//	"error"    why the dependency's own dependencies couldn't be read
+//
+// Dependencies on git repositories (see TagVCSDependency) are listed but
+// not followed. The walk stops at maxDepth levels and maxNodes packages,
+// 10 and 1,000 when zero; reaching maxNodes returns what was found with
+// ErrDependencyLimit. If ctx is cancelled the dependencies found so far are
+// returned with ctx.Err().
+func FetchDependenciesRecursiveFlat(ctx context.Context, reg Registry, name, version string, maxDepth, maxNodes int) ([]Dependency, error) {
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+	if maxNodes <= 0 {
+		maxNodes = defaultMaxNodes
+	}
+
+	if version == "" {
+		latest, err := latestStable(ctx, reg, name)
+		if err != nil {
+			return nil, err
+		}
+		if latest == nil {
+			return nil, &NotFoundError{Ecosystem: reg.Ecosystem(), Name: name}
+		}
+		version = latest.Number
+	}
+
+	rootDeps, err := reg.FetchDependencies(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{name: true}
+	var flat []Dependency
+
+	// pending holds the dependencies read for the previous level, keyed by
+	// the dependent's index in flat, or -1 for the root; paths holds the
+	// chain leading to and including each dependent.
+	pending := map[int][]Dependency{-1: rootDeps}
+	paths := map[int][]string{-1: {name + "@" + version}}
+
+	for depth := 1; depth <= maxDepth && len(pending) > 0; depth++ {
+		var found []Dependency
+		var foundPaths [][]string
+		for _, parent := range sortedIndexes(pending) {
+			for _, dep := range pending[parent] {
+				if dep.Scope != Runtime || seen[dep.Name] {
+					continue
+				}
+				seen[dep.Name] = true
+				found = append(found, dep)
+				foundPaths = append(foundPaths, paths[parent])
+			}
+		}
+
+		limited := len(flat)+len(found) > maxNodes
+		if limited {
+			found, foundPaths = found[:maxNodes-len(flat)], foundPaths[:maxNodes-len(flat)]
+		}
+
+		resolved, err := resolveRequirements(ctx, reg, found)
+
+		var next []int
+		nextPaths := make(map[int][]string)
+		for i, dep := range found {
+			dep.Metadata = copyMetadata(dep.Metadata)
+			dep.Metadata["depth"] = depth
+			dep.Metadata["path"] = foundPaths[i]
+			if !TagVCSDependency(&dep) {
+				if r, ok := resolved[dep.Name]; ok && r.err != nil {
+					dep.Metadata["error"] = r.err.Error()
+				} else if ok && r.value != "" {
+					dep.Metadata["version"] = r.value
+					next = append(next, len(flat))
+					nextPaths[len(flat)] = append(append([]string(nil), foundPaths[i]...), dep.Name+"@"+r.value)
+				}
+			}
+			flat = append(flat, dep)
+		}
+		if err != nil {
+			return flat, err
+		}
+		if limited {
+			return flat, ErrDependencyLimit
+		}
+		if depth == maxDepth {
+			break
+		}
+
+		// Calls still running after a cancellation must not touch flat,
+		// which is handed back to the caller.
+		versions := make(map[int]string, len(next))
+		for _, i := range next {
+			versions[i] = flat[i].Metadata["version"].(string)
+		}
+		names := make(map[int]string, len(next))
+		for _, i := range next {
+			names[i] = flat[i].Name
+		}
+		fetched, err := parallelMap(ctx, next, transitiveConcurrency, false, func(ctx context.Context, i int) (*fetchResult[[]Dependency], error) {
+			deps, err := reg.FetchDependencies(ctx, names[i], versions[i])
+			return &fetchResult[[]Dependency]{value: deps, err: err}, nil
+		})
+		pending = make(map[int][]Dependency, len(fetched))
+		paths = nextPaths
+		for i, r := range fetched {
+			if r.err != nil {
+				flat[i].Metadata["error"] = r.err.Error()
+				continue
+			}
+			pending[i] = r.value
+		}
+		if err != nil {
+			return flat, err
+		}
+	}
+	return flat, nil
+}
+
+// fetchResult carries a value or the error fetching it through parallelMap,
+// which would otherwise drop the error.
+type fetchResult[V any] struct {
+	value V
+	err   error
+}
+
+// resolveRequirements picks a version for each dependency, reading each
+// package's versions concurrently. A dependency with no suitable version
+// has an empty version; one whose versions couldn't be read has the error.
+func resolveRequirements(ctx context.Context, reg Registry, deps []Dependency) (map[string]*fetchResult[string], error) {
+	requirements := make(map[string]string, len(deps))
+	names := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		if _, _, ok := ParseVCSReference(dep.Requirements); ok {
+			continue
+		}
+		requirements[dep.Name] = dep.Requirements
+		names = append(names, dep.Name)
+	}
+
+	return parallelMap(ctx, names, transitiveConcurrency, false, func(ctx context.Context, name string) (*fetchResult[string], error) {
+		v, err := resolveRequirement(ctx, reg, name, requirements[name])
+		return &fetchResult[string]{value: v, err: err}, nil
+	})
+}
+
+// resolveRequirement returns the highest stable version of name that
+// satisfies requirement, or the latest stable version if the requirement
+// can't be evaluated. It returns "" if no version fits.
+func resolveRequirement(ctx context.Context, reg Registry, name, requirement string) (string, error) {
+	versions, err := reg.FetchVersions(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	stable := SortedVersions(reg, StableVersions(reg, versions))
+
+	for _, v := range stable {
+		ok, err := SatisfiesRequirement(reg.Ecosystem(), v.Number, requirement)
+		if err != nil {
+			return stable[0].Number, nil
+		}
+		if ok {
+			return v.Number, nil
+		}
+	}
+	return "", nil
+}
+
+func copyMetadata(m map[string]any) map[string]any {
+	copied := make(map[string]any, len(m)+3)
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+func sortedIndexes[V any](m map[int]V) []int {
+	indexes := make([]int, 0, len(m))
+	for i := range m {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// graphRegistry serves versions and dependencies from memory, keyed by
+// package name and then "name@version".
+type graphRegistry struct {
+	Registry
+	ecosystem string
+	versions  map[string][]string
+	deps      map[string][]Dependency
+}
+
+func (r graphRegistry) Ecosystem() string { return r.ecosystem }
+
+func (r graphRegistry) FetchVersions(ctx context.Context, name string) ([]Version, error) {
+	numbers, ok := r.versions[name]
+	if !ok {
+		return nil, &NotFoundError{Ecosystem: r.ecosystem, Name: name}
+	}
+	versions := make([]Version, len(numbers))
+	for i, n := range numbers {
+		versions[i] = Version{Number: n}
+	}
+	return versions, nil
+}
+
+func (r graphRegistry) FetchDependencies(ctx context.Context, name, version string) ([]Dependency, error) {
+	deps, ok := r.deps[name+"@"+version]
+	if !ok {
+		return nil, &NotFoundError{Ecosystem: r.ecosystem, Name: name, Version: version}
+	}
+	return deps, nil
+}
+
+func TestFetchDependenciesRecursiveFlat(t *testing.T) {
+	reg := graphRegistry{
+		ecosystem: "npm",
+		versions: map[string][]string{
+			"app":    {"1.0.0"},
+			"left":   {"1.0.0", "1.2.0", "2.0.0"},
+			"right":  {"3.1.0", "4.0.0-beta"},
+			"shared": {"1.0.0", "1.5.0"},
+		},
+		deps: map[string][]Dependency{
+			"app@1.0.0": {
+				{Name: "left", Requirements: "^1.0.0", Scope: Runtime},
+				{Name: "right", Requirements: "^3.0.0", Scope: Runtime},
+				{Name: "jest", Requirements: "^29.0.0", Scope: Development},
+				{Name: "forked", Requirements: "github:someone/forked#main", Scope: Runtime},
+			},
+			"left@1.2.0":   {{Name: "shared", Requirements: "~1.0.0", Scope: Runtime}},
+			"right@3.1.0":  {{Name: "shared", Requirements: "^1.0.0", Scope: Runtime}, {Name: "app", Requirements: "*", Scope: Runtime}},
+			"shared@1.0.0": {},
+		},
+	}
+
+	deps, err := FetchDependenciesRecursiveFlat(context.Background(), reg, "app", "1.0.0", 0, 0)
+	if err != nil {
+		t.Fatalf("FetchDependenciesRecursiveFlat failed: %v", err)
+	}
+
+	want := []struct {
+		name    string
+		version any
+		depth   int
+		path    []string
+	}{
+		{"left", "1.2.0", 1, []string{"app@1.0.0"}},
+		{"right", "3.1.0", 1, []string{"app@1.0.0"}},
+		{"forked", nil, 1, []string{"app@1.0.0"}},
+		{"shared", "1.0.0", 2, []string{"app@1.0.0", "left@1.2.0"}},
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("expected %d dependencies, got %d: %v", len(want), len(deps), deps)
+	}
+	for i, w := range want {
+		d := deps[i]
+		if d.Name != w.name {
+			t.Errorf("dependency %d: expected %s, got %s", i, w.name, d.Name)
+			continue
+		}
+		if d.Metadata["version"] != w.version {
+			t.Errorf("%s: expected version %v, got %v", d.Name, w.version, d.Metadata["version"])
+		}
+		if d.Metadata["depth"] != w.depth {
+			t.Errorf("%s: expected depth %d, got %v", d.Name, w.depth, d.Metadata["depth"])
+		}
+		if !reflect.DeepEqual(d.Metadata["path"], w.path) {
+			t.Errorf("%s: expected path %v, got %v", d.Name, w.path, d.Metadata["path"])
+		}
+	}
+	if deps[2].Metadata["vcs_url"] != "https://github.com/someone/forked" {
+		t.Errorf("expected the git dependency to be tagged, got %v", deps[2].Metadata)
+	}
+}
+
+func TestFetchDependenciesRecursiveFlatLimits(t *testing.T) {
+	reg := graphRegistry{
+		ecosystem: "pypi",
+		versions: map[string][]string{
+			"a": {"1.0"}, "b": {"1.0", "2.0rc1"}, "c": {"1.0"}, "d": {"1.0"},
+		},
+		deps: map[string][]Dependency{
+			"a@1.0": {{Name: "b", Requirements: ">=1.0", Scope: Runtime}},
+			"b@1.0": {{Name: "c", Requirements: ">=1.0", Scope: Runtime}},
+			"c@1.0": {{Name: "d", Requirements: ">=1.0", Scope: Runtime}},
+		},
+	}
+
+	deps, err := FetchDependenciesRecursiveFlat(context.Background(), reg, "a", "", 2, 0)
+	if err != nil {
+		t.Fatalf("FetchDependenciesRecursiveFlat failed: %v", err)
+	}
+	if len(deps) != 2 || deps[0].Name != "b" || deps[1].Name != "c" {
+		t.Errorf("expected b and c within depth 2, got %v", deps)
+	}
+	if deps[0].Metadata["version"] != "1.0" {
+		t.Errorf("expected the latest stable version of b, got %v", deps[0].Metadata["version"])
+	}
+
+	deps, err = FetchDependenciesRecursiveFlat(context.Background(), reg, "a", "1.0", 0, 1)
+	if !errors.Is(err, ErrDependencyLimit) {
+		t.Errorf("expected ErrDependencyLimit, got %v", err)
+	}
+	if len(deps) != 1 {
+		t.Errorf("expected 1 dependency before the limit, got %d", len(deps))
+	}
+}
+
+func TestFetchDependenciesRecursiveFlatErrors(t *testing.T) {
+	reg := graphRegistry{
+		ecosystem: "npm",
+		versions:  map[string][]string{"present": {"1.0.0"}},
+		deps: map[string][]Dependency{
+			"app@1.0.0": {
+				{Name: "present", Requirements: "^1.0.0", Scope: Runtime},
+				{Name: "gone", Requirements: "^1.0.0", Scope: Runtime},
+			},
+		},
+	}
+
+	deps, err := FetchDependenciesRecursiveFlat(context.Background(), reg, "app", "1.0.0", 0, 0)
+	if err != nil {
+		t.Fatalf("FetchDependenciesRecursiveFlat failed: %v", err)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("expected 2 dependencies, got %d", len(deps))
+	}
+	for _, d := range deps {
+		if _, ok := d.Metadata["error"]; !ok {
+			t.Errorf("%s: expected an error to be recorded, got %v", d.Name, d.Metadata)
+		}
+	}
+
+	if _, err := FetchDependenciesRecursiveFlat(context.Background(), reg, "missing", "1.0.0", 0, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for the root, got %v", err)
+	}
+}
@@ -1,19 +1,29 @@
 // Package core provides shared types and the registry system.
 package core
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+)
 
 // Package represents metadata about a package from a registry.
 type Package struct {
 	Name          string
-	Description   string
+	Summary       string // one-line summary, when the registry keeps one apart from Description
+	Description   string // long description if there is one, otherwise the summary
 	Homepage      string
 	Repository    string
 	Licenses      string
 	Keywords      []string
-	Namespace     string         // @scope for npm, groupId for maven
-	LatestVersion string         // latest version if returned by registry
-	Metadata      map[string]any // registry-specific data
+	Namespace     string            // @scope for npm, groupId for maven
+	LatestVersion string            // latest version if returned by registry
+	Downloads     int               // total downloads, 0 if the registry doesn't report them
+	ProjectURLs   map[string]string // labeled links as the registry reports them, e.g. "Changelog"
+	Metadata      map[string]any    // registry-specific data
 }
 
 // Version represents a specific version of a package.
@@ -26,6 +36,59 @@ type Version struct {
 	Metadata    map[string]any
 }
 
+// Equal reports whether p and other hold the same metadata, for detecting
+// changes between two fetches of a package. Every field is compared,
+// including Downloads, so callers polling for metadata changes may want to
+// clear download counts first. Metadata is compared by value as JSON would
+// encode it, so key order, int versus float64 and []string versus []any
+// don't matter; nil and empty Keywords, ProjectURLs and Metadata are equal
+// too. Two nil packages are equal.
+func (p *Package) Equal(other *Package) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	return p.Name == other.Name &&
+		p.Summary == other.Summary &&
+		p.Description == other.Description &&
+		p.Homepage == other.Homepage &&
+		p.Repository == other.Repository &&
+		p.Licenses == other.Licenses &&
+		slices.Equal(p.Keywords, other.Keywords) &&
+		p.Namespace == other.Namespace &&
+		p.LatestVersion == other.LatestVersion &&
+		p.Downloads == other.Downloads &&
+		maps.Equal(p.ProjectURLs, other.ProjectURLs) &&
+		metadataEqual(p.Metadata, other.Metadata)
+}
+
+// Equal reports whether v and other describe the same release in the same
+// state. PublishedAt is compared as an instant, ignoring its time zone, and
+// Metadata as in Package.Equal.
+func (v Version) Equal(other Version) bool {
+	return v.Number == other.Number &&
+		v.PublishedAt.Equal(other.PublishedAt) &&
+		v.Licenses == other.Licenses &&
+		v.Integrity == other.Integrity &&
+		v.Status == other.Status &&
+		metadataEqual(v.Metadata, other.Metadata)
+}
+
+// metadataEqual compares two Metadata maps by their JSON encoding, which
+// sorts keys and erases the differences between numeric and slice types
+// that a decode/encode round trip introduces. Values that can't be encoded
+// fall back to reflect.DeepEqual.
+func metadataEqual(a, b map[string]any) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	if aErr != nil || bErr != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return bytes.Equal(aJSON, bJSON)
+}
+
 // VersionStatus represents the status of a package version.
 type VersionStatus string
 
@@ -42,6 +105,16 @@ type Dependency struct {
 	Requirements string
 	Scope        Scope
 	Optional     bool
+	Metadata     map[string]any // registry-specific data (git source, platform, etc.)
+}
+
+// IsBundled reports whether the dependency ships inside the package's own
+// artifact, like npm's bundledDependencies, rather than being resolved and
+// installed separately. Registries mark these with Metadata["bundled"] set
+// to true and keep the scope the dependency would otherwise have.
+func (d Dependency) IsBundled() bool {
+	bundled, _ := d.Metadata["bundled"].(bool)
+	return bundled
 }
 
 // Scope indicates when a dependency is required.
@@ -54,14 +127,41 @@ const (
 	Test        Scope = "test"
 	Build       Scope = "build"
 	Optional    Scope = "optional"
+	Peer        Scope = "peer"
+	Provided    Scope = "provided"
 )
 
 // Maintainer represents a package maintainer.
 type Maintainer struct {
-	UUID  string
-	Login string
-	Name  string
-	Email string
-	URL   string
-	Role  string
+	UUID      string
+	Login     string
+	Name      string
+	Email     string
+	URL       string
+	AvatarURL string
+	Role      string
+}
+
+// URLSet holds every URL a registry's URLBuilder produces for a package
+// version, as returned by ResolveURLs. URLs the ecosystem doesn't have are
+// empty.
+type URLSet struct {
+	Ecosystem     string
+	Name          string
+	Version       string
+	Registry      string
+	Download      string
+	Documentation string
+	PURL          string
+}
+
+// PackageDetail combines a package with its versions and maintainers, as
+// returned by FetchAll. VersionsErr and MaintainersErr record why those
+// parts are missing, if they are.
+type PackageDetail struct {
+	Package        *Package
+	Versions       []Version
+	Maintainers    []Maintainer
+	VersionsErr    error
+	MaintainersErr error
 }
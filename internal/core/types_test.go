@@ -0,0 +1,106 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDependencyIsBundled(t *testing.T) {
+	tests := []struct {
+		metadata map[string]any
+		expected bool
+	}{
+		{nil, false},
+		{map[string]any{"platform": "ios"}, false},
+		{map[string]any{"bundled": false}, false},
+		{map[string]any{"bundled": true}, true},
+		{map[string]any{"bundled": "yes"}, false},
+	}
+
+	for _, tt := range tests {
+		d := Dependency{Name: "dep", Metadata: tt.metadata}
+		if got := d.IsBundled(); got != tt.expected {
+			t.Errorf("IsBundled() with %v = %v, expected %v", tt.metadata, got, tt.expected)
+		}
+	}
+}
+
+func TestPackageEqual(t *testing.T) {
+	base := func() *Package {
+		return &Package{
+			Name:          "lodash",
+			Description:   "Lodash modular utilities.",
+			Keywords:      []string{"modules", "stdlib"},
+			LatestVersion: "4.17.21",
+			Metadata: map[string]any{
+				"downloads":   50000000,
+				"maintainers": []string{"jdalton", "mathias"},
+				"dist_tags":   map[string]any{"latest": "4.17.21", "next": "5.0.0-rc.1"},
+			},
+		}
+	}
+
+	// The same metadata built in a different order and with the types a
+	// JSON round trip produces
+	decoded := base()
+	decoded.Metadata = map[string]any{
+		"dist_tags":   map[string]any{"next": "5.0.0-rc.1", "latest": "4.17.21"},
+		"maintainers": []any{"jdalton", "mathias"},
+		"downloads":   float64(50000000),
+	}
+	if !base().Equal(decoded) {
+		t.Error("expected packages with reordered, decoded metadata to be equal")
+	}
+
+	changed := base()
+	changed.LatestVersion = "4.17.22"
+	if base().Equal(changed) {
+		t.Error("expected packages with different latest versions to differ")
+	}
+
+	changed = base()
+	changed.Metadata["dist_tags"] = map[string]any{"latest": "4.17.21"}
+	if base().Equal(changed) {
+		t.Error("expected packages with different metadata to differ")
+	}
+
+	changed = base()
+	changed.ProjectURLs = map[string]string{"Changelog": "https://lodash.com/changelog"}
+	if base().Equal(changed) {
+		t.Error("expected packages with different project URLs to differ")
+	}
+
+	empty, nilFields := &Package{Name: "a", Keywords: []string{}, ProjectURLs: map[string]string{}, Metadata: map[string]any{}}, &Package{Name: "a"}
+	if !empty.Equal(nilFields) {
+		t.Error("expected empty and nil keywords, project URLs and metadata to be equal")
+	}
+
+	var none *Package
+	if !none.Equal(nil) || none.Equal(base()) || base().Equal(nil) {
+		t.Error("expected only two nil packages to be equal")
+	}
+}
+
+func TestVersionEqual(t *testing.T) {
+	published := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := Version{
+		Number:      "1.0.0",
+		PublishedAt: published,
+		Integrity:   "sha256-abc",
+		Metadata:    map[string]any{"size": 1024, "yanked_reason": nil},
+	}
+	b := Version{
+		Number:      "1.0.0",
+		PublishedAt: published.In(time.FixedZone("CET", 3600)),
+		Integrity:   "sha256-abc",
+		Metadata:    map[string]any{"yanked_reason": nil, "size": 1024.0},
+	}
+	if !a.Equal(b) {
+		t.Error("expected versions with reordered metadata and zoned times to be equal")
+	}
+
+	b.Status = StatusYanked
+	if a.Equal(b) {
+		t.Error("expected versions with different statuses to differ")
+	}
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/git-pkgs/registries/internal/urlparser"
+)
+
+// vcsShorthands are the "host:owner/repo" prefixes npm accepts for
+// repositories on the big hosts.
+var vcsShorthands = map[string]string{
+	"github:":    "https://github.com/",
+	"gitlab:":    "https://gitlab.com/",
+	"bitbucket:": "https://bitbucket.org/",
+}
+
+// archiveSuffixes mark an http(s) requirement as a tarball rather than a
+// repository, even on a known git host.
+var archiveSuffixes = []string{".tgz", ".tar.gz", ".tar", ".zip"}
+
+// ParseVCSReference reports whether a dependency requirement points at a
+// git repository instead of a registry version, and if so returns the
+// repository URL, normalized like NormalizeRepository, and the ref it
+// pins, if any. GitLab URLs keep their full path, since GitLab projects can
+// sit in nested subgroups (gitlab.com/group/subgroup/project). It
+// recognizes:
+//
+//	git+https://github.com/o/r.git#v1.0.0   git+ssh://, git+http://, git://
+//	git@github.com:o/r.git                  scp-style ssh
+//	github:o/r#main                         also gitlab: and bitbucket:
+//	o/r#main                                npm's GitHub shorthand
+//	https://github.com/o/r.git              http(s) ending in .git or on a known host
+//	git:https://github.com/o/r.git          pub's formatted git requirement
+//	git+https://host/r?rev=abc123           Cargo-style rev, tag or branch
+//
+// The ref is the fragment, with npm's "semver:" ranges kept verbatim, or
+// else the rev, tag or branch query parameter. Tarball URLs, file paths
+// and version constraints are not references.
+func ParseVCSReference(requirement string) (repoURL, ref string, ok bool) {
+	s := strings.TrimSpace(requirement)
+	if s == "" || strings.ContainsAny(s, " \t") {
+		return "", "", false
+	}
+	if strings.HasPrefix(s, "git:") && !strings.HasPrefix(s, "git://") {
+		s = strings.TrimPrefix(s, "git:")
+	}
+
+	s, ref, _ = strings.Cut(s, "#")
+	s, query, _ := strings.Cut(s, "?")
+	if ref == "" && query != "" {
+		values, _ := url.ParseQuery(query)
+		for _, key := range []string{"rev", "tag", "branch"} {
+			if v := values.Get(key); v != "" {
+				ref = v
+				break
+			}
+		}
+	}
+
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasPrefix(lower, "git+"), strings.HasPrefix(lower, "git://"):
+	case strings.HasPrefix(lower, "git@"):
+	case strings.HasPrefix(lower, "http://"), strings.HasPrefix(lower, "https://"):
+		for _, suffix := range archiveSuffixes {
+			if strings.HasSuffix(lower, suffix) {
+				return "", "", false
+			}
+		}
+		if !strings.HasSuffix(lower, ".git") && !urlparser.IsKnownHost(s) {
+			return "", "", false
+		}
+	default:
+		for prefix, base := range vcsShorthands {
+			if strings.HasPrefix(lower, prefix) {
+				s = base + s[len(prefix):]
+				break
+			}
+		}
+		if !strings.Contains(s, "://") {
+			if !isRepoShorthand(s) {
+				return "", "", false
+			}
+			s = "https://github.com/" + s
+		}
+	}
+
+	repoURL = NormalizeRepository(s)
+	if path := gitlabProjectPath(s); path != "" {
+		repoURL = "https://gitlab.com/" + path
+	}
+	if urlparser.ExtractOwnerRepo(repoURL) == "" && urlparser.ExtractPath(repoURL) == "" {
+		return "", "", false
+	}
+	return repoURL, ref, true
+}
+
+// gitlabProjectPath returns the path of a gitlab.com project nested in
+// subgroups, which NormalizeRepository would cut down to its first two
+// segments, or an empty string for any other URL.
+func gitlabProjectPath(s string) string {
+	if !strings.EqualFold(urlparser.ExtractHost(s), "gitlab.com") {
+		return ""
+	}
+	path, _, _ := strings.Cut(urlparser.ExtractPath(s), "/-/")
+	if strings.Count(path, "/") < 2 {
+		return ""
+	}
+	return path
+}
+
+// isRepoShorthand reports whether s looks like npm's "owner/repo" GitHub
+// shorthand rather than a path or a scoped package name.
+func isRepoShorthand(s string) bool {
+	owner, repo, ok := strings.Cut(s, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return false
+	}
+	if strings.ContainsAny(owner, ".@~:") || strings.ContainsAny(repo, ":") {
+		return false
+	}
+	return true
+}
+
+// TagVCSDependency records where dep comes from when its Requirements is a
+// git reference as understood by ParseVCSReference, or its Name is a full
+// repository URL, as in ecosystems that name dependencies by URL. Names
+// aren't matched against the "owner/repo" shorthand, which would catch
+// vendor-prefixed package names. The normalized repository is stored as
+// Metadata["vcs_url"] and the ref, when there is one, as Metadata["vcs_ref"],
+// so graph tooling can follow the dependency to its source instead of a
+// registry. It reports whether dep was tagged; other dependencies are left
+// as they are.
+//
+// npm and pub tag their dependencies. Packagist and the Go proxy have
+// nothing to tag: Composer declares git sources in a project's
+// repositories rather than in a requirement, so a requirement like
+// "dev-main" names a branch of a package that still resolves through the
+// registry, and Go module paths such as github.com/o/r are resolved through
+// the module proxy, not cloned.
+func TagVCSDependency(dep *Dependency) bool {
+	repoURL, ref, ok := ParseVCSReference(dep.Requirements)
+	if !ok && (strings.Contains(dep.Name, "://") || strings.HasPrefix(dep.Name, "git@")) {
+		repoURL, ref, ok = ParseVCSReference(dep.Name)
+	}
+	if !ok {
+		return false
+	}
+	if dep.Metadata == nil {
+		dep.Metadata = make(map[string]any)
+	}
+	dep.Metadata["vcs_url"] = repoURL
+	if ref != "" {
+		dep.Metadata["vcs_ref"] = ref
+	}
+	return true
+}
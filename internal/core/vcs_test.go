@@ -0,0 +1,79 @@
+package core
+
+import "testing"
+
+func TestParseVCSReference(t *testing.T) {
+	tests := []struct {
+		requirement string
+		wantURL     string
+		wantRef     string
+		wantOK      bool
+	}{
+		{"git+https://github.com/npm/cli.git#v10.2.0", "https://github.com/npm/cli", "v10.2.0", true},
+		{"git+ssh://git@github.com/npm/cli.git", "https://github.com/npm/cli", "", true},
+		{"git://github.com/npm/cli.git#main", "https://github.com/npm/cli", "main", true},
+		{"git@gitlab.com:group/project.git#abc123", "https://gitlab.com/group/project", "abc123", true},
+		{"git+https://gitlab.com/group/sub/project.git#v2.0.0", "https://gitlab.com/group/sub/project", "v2.0.0", true},
+		{"gitlab:group/sub/project", "https://gitlab.com/group/sub/project", "", true},
+		{"github:expressjs/express#semver:^4.0.0", "https://github.com/expressjs/express", "semver:^4.0.0", true},
+		{"bitbucket:team/repo", "https://bitbucket.org/team/repo", "", true},
+		{"expressjs/express#4.18.2", "https://github.com/expressjs/express", "4.18.2", true},
+		{"https://github.com/expressjs/express", "https://github.com/expressjs/express", "", true},
+		{"https://git.example.com/tools/widget.git", "https://git.example.com/tools/widget", "", true},
+		{"git:https://github.com/dart-lang/async.git", "https://github.com/dart-lang/async", "", true},
+		{"git+https://github.com/rust-lang/regex?rev=abc123", "https://github.com/rust-lang/regex", "abc123", true},
+		{"git+https://github.com/rust-lang/regex?branch=main#def456", "https://github.com/rust-lang/regex", "def456", true},
+
+		{"^1.2.3", "", "", false},
+		{">=1.0 <2", "", "", false},
+		{"latest", "", "", false},
+		{"", "", "", false},
+		{"file:../local", "", "", false},
+		{"./local/pkg", "", "", false},
+		{"npm:@scope/pkg@^1.0.0", "", "", false},
+		{"https://registry.example.com/pkg-1.0.0.tgz", "", "", false},
+		{"https://github.com/o/r/archive/v1.0.0.tar.gz", "", "", false},
+		{"https://example.com/download", "", "", false},
+	}
+
+	for _, tt := range tests {
+		gotURL, gotRef, ok := ParseVCSReference(tt.requirement)
+		if ok != tt.wantOK || gotURL != tt.wantURL || gotRef != tt.wantRef {
+			t.Errorf("ParseVCSReference(%q) = %q, %q, %v, want %q, %q, %v",
+				tt.requirement, gotURL, gotRef, ok, tt.wantURL, tt.wantRef, tt.wantOK)
+		}
+	}
+}
+
+func TestTagVCSDependency(t *testing.T) {
+	dep := Dependency{Name: "cli", Requirements: "git+https://github.com/npm/cli.git#v10.2.0", Metadata: map[string]any{"bundled": true}}
+	if !TagVCSDependency(&dep) {
+		t.Fatal("expected git dependency to be tagged")
+	}
+	if dep.Metadata["vcs_url"] != "https://github.com/npm/cli" {
+		t.Errorf("expected vcs_url, got %v", dep.Metadata["vcs_url"])
+	}
+	if dep.Metadata["vcs_ref"] != "v10.2.0" {
+		t.Errorf("expected vcs_ref, got %v", dep.Metadata["vcs_ref"])
+	}
+	if dep.Metadata["bundled"] != true {
+		t.Error("expected existing metadata to be kept")
+	}
+
+	byName := Dependency{Name: "https://github.com/apple/swift-nio.git", Requirements: ">=2.0.0"}
+	if !TagVCSDependency(&byName) || byName.Metadata["vcs_url"] != "https://github.com/apple/swift-nio" {
+		t.Errorf("expected dependency named by URL to be tagged, got %v", byName.Metadata)
+	}
+	if _, ok := byName.Metadata["vcs_ref"]; ok {
+		t.Error("expected no vcs_ref without a ref")
+	}
+
+	for _, dep := range []Dependency{
+		{Name: "lodash", Requirements: "^4.17.21"},
+		{Name: "symfony/console", Requirements: "^6.0"},
+	} {
+		if TagVCSDependency(&dep) || dep.Metadata != nil {
+			t.Errorf("expected %s to be left untagged, got %v", dep.Name, dep.Metadata)
+		}
+	}
+}
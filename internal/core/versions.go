@@ -0,0 +1,673 @@
+package core
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// versionComparators holds the ecosystems whose version strings don't follow
+// semver closely enough for compareSemver. Each returns -1, 0 or 1.
+var versionComparators = map[string]func(a, b string) int{
+	"maven":    compareMaven,
+	"clojars":  compareMaven,
+	"nuget":    compareNuGet,
+	"cran":     compareCRAN,
+	"pypi":     comparePyPI,
+	"gem":      compareGem,
+	"luarocks": compareLuaRocks,
+	"composer": compareComposer,
+	"dub":      compareDub,
+}
+
+// SortedVersions returns a copy of versions ordered newest first using the
+// version ordering of reg's ecosystem. Ecosystems without their own rules are
+// compared as semver. The input slice is not modified.
+func SortedVersions(reg Registry, versions []Version) []Version {
+	compare := versionComparator(reg.Ecosystem())
+
+	sorted := make([]Version, len(versions))
+	copy(sorted, versions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compare(sorted[i].Number, sorted[j].Number) > 0
+	})
+	return sorted
+}
+
+// prereleaseCheckers holds the ecosystems whose prerelease conventions
+// differ from semver's "-suffix".
+var prereleaseCheckers = map[string]func(v string) bool{
+	"maven":     isMavenPrerelease,
+	"clojars":   isMavenPrerelease,
+	"pypi":      isPyPIPrerelease,
+	"gem":       isGemPrerelease,
+	"cocoapods": isGemPrerelease,
+	"composer":  isComposerPrerelease,
+	"dub":       isDubPrerelease,
+	"cpan":      isCPANPrerelease,
+	"luarocks":  isLuaRocksPrerelease,
+	"conda":     hasPrereleaseLabel,
+	"cran":      func(string) bool { return false },
+	"hackage":   func(string) bool { return false },
+}
+
+// StableVersions returns the versions of reg's ecosystem that are real
+// releases: not prereleases (by the ecosystem's own conventions) and not
+// yanked, deprecated or retracted. Order is preserved.
+func StableVersions(reg Registry, versions []Version) []Version {
+	isPrerelease := prereleaseChecker(reg.Ecosystem())
+
+	var stable []Version
+	for _, v := range versions {
+		if v.Status == StatusNone && !isPrerelease(v.Number) {
+			stable = append(stable, v)
+		}
+	}
+	return stable
+}
+
+// ExcludeVersions returns versions without those whose Status is one of
+// statuses, or without every yanked, deprecated and retracted version when
+// no statuses are given. Order is preserved. Registries' FetchVersions
+// include such versions with their Status set rather than dropping them,
+// except where the registry itself doesn't serve them (RubyGems' compact
+// index leaves out yanked versions), so this is for callers that only want
+// what can still be installed; pass
+// StatusYanked and StatusRetracted to keep deprecated versions, which
+// usually still can be. Unlike StableVersions, prereleases are kept.
+func ExcludeVersions(versions []Version, statuses ...VersionStatus) []Version {
+	var kept []Version
+	for _, v := range versions {
+		if v.Status == StatusNone || (len(statuses) > 0 && !slices.Contains(statuses, v.Status)) {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// CompareVersions compares two version strings using the ordering rules of
+// ecosystem, returning -1 if a is older than b, 1 if it is newer and 0 if
+// they are equivalent. Ecosystems without their own rules compare as semver.
+func CompareVersions(ecosystem, a, b string) int {
+	return versionComparator(ecosystem)(a, b)
+}
+
+// IsPrerelease reports whether version is a prerelease under the
+// conventions of ecosystem: a semver suffix, a Maven qualifier such as
+// SNAPSHOT, a PEP 440 dev release, a Composer branch and so on.
+func IsPrerelease(ecosystem, version string) bool {
+	return prereleaseChecker(ecosystem)(version)
+}
+
+// prereleaseLabels are the version segments that mark a prerelease in most
+// ecosystems, used when the ecosystem isn't known.
+var prereleaseLabels = map[string]bool{
+	"alpha": true, "beta": true,
+	"rc": true, "cr": true, "milestone": true,
+	"pre": true, "preview": true,
+	"dev": true, "snapshot": true,
+}
+
+// shortPrereleaseLabels are the single letters PEP 440 (1.0a1, 1.0b2,
+// 1.0c1) and Maven (2.0-M3) use for prereleases. They only count when a
+// number follows, since a bare trailing letter is usually a patch level,
+// as in OpenSSL's 1.1.1b.
+var shortPrereleaseLabels = map[string]bool{"a": true, "b": true, "c": true, "m": true}
+
+// IsPrerelease makes a best-effort guess at whether the version is a
+// prerelease without knowing its ecosystem. It looks for common labels
+// (alpha, beta, rc, dev, SNAPSHOT, ...) and branch versions like "dev-main"
+// or "~master", and so misses free-form semver suffixes such as
+// "1.0.0-canary". Use the package-level IsPrerelease when the ecosystem is
+// known.
+func (v Version) IsPrerelease() bool {
+	number := strings.ToLower(v.Number)
+	if strings.HasPrefix(number, "~") || strings.HasPrefix(number, "dev-") || strings.HasSuffix(number, "-dev") {
+		return true
+	}
+	segs := tokenizeVersion(number)
+	for i, seg := range segs {
+		if prereleaseLabels[seg] {
+			return true
+		}
+		if shortPrereleaseLabels[seg] && i+1 < len(segs) && isNumeric(segs[i+1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func prereleaseChecker(ecosystem string) func(v string) bool {
+	if check, ok := prereleaseCheckers[ecosystem]; ok {
+		return check
+	}
+	return isSemverPrerelease
+}
+
+// isSemverPrerelease reports whether v has a semver prerelease suffix, which
+// also covers Go pseudo-versions.
+func isSemverPrerelease(v string) bool {
+	v, _, _ = strings.Cut(v, "+")
+	return strings.Contains(v, "-")
+}
+
+// isMavenPrerelease reports whether v has a qualifier ranked below a
+// release, such as alpha, beta, milestone, rc or SNAPSHOT. Unknown
+// qualifiers like "jre" or "android" are treated as releases.
+func isMavenPrerelease(v string) bool {
+	for _, seg := range tokenizeVersion(strings.ToLower(v)) {
+		if rank, ok := mavenQualifiers[seg]; ok && rank < mavenQualifiers[""] {
+			return true
+		}
+	}
+	return false
+}
+
+// isPyPIPrerelease reports whether v is a PEP 440 pre or dev release. Post
+// releases are final.
+func isPyPIPrerelease(v string) bool {
+	v, _, _ = strings.Cut(strings.ToLower(v), "+")
+	m := pep440Regex.FindStringSubmatch(v)
+	if m == nil {
+		return isSemverPrerelease(v)
+	}
+	return m[3] != "" || m[8] != ""
+}
+
+// isGemPrerelease follows Gem::Version#prerelease?: any letter makes a
+// prerelease.
+func isGemPrerelease(v string) bool {
+	return strings.IndexFunc(v, unicode.IsLetter) >= 0
+}
+
+// composerStabilities are the stability flags Composer treats as below
+// stable. "patch", "pl" and "p" suffixes are stable.
+var composerStabilities = []string{"dev", "alpha", "a", "beta", "b", "rc"}
+
+// isComposerPrerelease reports whether v is a branch ("dev-main",
+// "2.x-dev") or carries an unstable stability flag ("1.0.0-beta1").
+func isComposerPrerelease(v string) bool {
+	v = strings.ToLower(v)
+	if strings.HasPrefix(v, "dev-") || strings.HasSuffix(v, "-dev") {
+		return true
+	}
+	for _, seg := range tokenizeVersion(v) {
+		for _, s := range composerStabilities {
+			if seg == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isDubPrerelease treats "~branch" versions as prereleases, and otherwise
+// uses semver.
+func isDubPrerelease(v string) bool {
+	return strings.HasPrefix(v, "~") || isSemverPrerelease(v)
+}
+
+// isCPANPrerelease reports whether v is a CPAN developer release, which
+// PAUSE marks with an underscore ("1.23_01").
+func isCPANPrerelease(v string) bool {
+	return strings.Contains(v, "_") || strings.Contains(strings.ToLower(v), "-trial")
+}
+
+// isLuaRocksPrerelease ignores the trailing rockspec revision ("1.0-1") and
+// treats any letters left ("scm", "dev", "1.0rc1") as a prerelease.
+func isLuaRocksPrerelease(v string) bool {
+	v, _ = splitRockRevision(v)
+	return strings.IndexFunc(v, unicode.IsLetter) >= 0
+}
+
+// hasPrereleaseLabel reports whether any segment of v is a common
+// prerelease label, for ecosystems without stricter rules.
+func hasPrereleaseLabel(v string) bool {
+	for _, seg := range tokenizeVersion(strings.ToLower(v)) {
+		switch seg {
+		case "a", "alpha", "b", "beta", "rc", "pre", "preview", "dev":
+			return true
+		}
+	}
+	return false
+}
+
+func versionComparator(ecosystem string) func(a, b string) int {
+	if cmp, ok := versionComparators[ecosystem]; ok {
+		return cmp
+	}
+	return compareSemver
+}
+
+// compareSemver orders versions by semver precedence. It is lenient about
+// a leading "v" and about the number of release segments, so "1.2" and
+// "1.2.0.1" are both accepted.
+func compareSemver(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+
+	aRelease, aPre, _ := strings.Cut(a, "-")
+	bRelease, bPre, _ := strings.Cut(b, "-")
+
+	if c := compareSegments(strings.Split(aRelease, "."), strings.Split(bRelease, ".")); c != 0 {
+		return c
+	}
+
+	// A release sorts above any of its prereleases
+	switch {
+	case aPre == "" && bPre == "":
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+
+	aIDs, bIDs := strings.Split(aPre, "."), strings.Split(bPre, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		if c := compareIdentifier(aIDs[i], bIDs[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(aIDs), len(bIDs))
+}
+
+// compareNuGet follows NuGet's SemVer 2.0 rules, which match semver apart
+// from prerelease labels being case-insensitive.
+func compareNuGet(a, b string) int {
+	return compareSemver(strings.ToLower(a), strings.ToLower(b))
+}
+
+// compareCRAN orders R package versions, which are integers separated by
+// "." or "-" (e.g. "1.2-3").
+func compareCRAN(a, b string) int {
+	split := func(r rune) bool { return r == '.' || r == '-' }
+	return compareSegments(strings.FieldsFunc(a, split), strings.FieldsFunc(b, split))
+}
+
+// compareGem follows Gem::Version: segments are split on dots and between
+// digits and letters, and any letter segment makes a prerelease, so
+// "1.0.a" < "1.0".
+func compareGem(a, b string) int {
+	aSegs, bSegs := tokenizeVersion(a), tokenizeVersion(b)
+	for i := 0; i < len(aSegs) || i < len(bSegs); i++ {
+		x, y := "0", "0"
+		if i < len(aSegs) {
+			x = aSegs[i]
+		}
+		if i < len(bSegs) {
+			y = bSegs[i]
+		}
+		xNum, yNum := isNumeric(x), isNumeric(y)
+		switch {
+		case xNum && yNum:
+			if c := compareNumeric(x, y); c != 0 {
+				return c
+			}
+		case xNum:
+			return 1
+		case yNum:
+			return -1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// compareLuaRocks orders rock versions ("1.2.3-1") by version and then by
+// rockspec revision. The "scm" and "dev" pseudo-versions track a branch
+// rather than a release, so they sort below every release.
+func compareLuaRocks(a, b string) int {
+	aVersion, aRevision := splitRockRevision(a)
+	bVersion, bRevision := splitRockRevision(b)
+
+	aDev, bDev := isRockDevVersion(aVersion), isRockDevVersion(bVersion)
+	switch {
+	case aDev && !bDev:
+		return -1
+	case bDev && !aDev:
+		return 1
+	}
+
+	if c := compareGem(aVersion, bVersion); c != 0 {
+		return c
+	}
+	return compareNumeric(orZero(aRevision), orZero(bRevision))
+}
+
+func splitRockRevision(v string) (version, revision string) {
+	if i := strings.LastIndex(v, "-"); i >= 0 && isNumeric(v[i+1:]) {
+		return v[:i], v[i+1:]
+	}
+	return v, ""
+}
+
+func isRockDevVersion(version string) bool {
+	version = strings.ToLower(version)
+	return version == "scm" || version == "dev"
+}
+
+// composerVersionRegex matches a Composer release: a numeric version with
+// an optional stability flag and number ("1.0.0-beta2", "2.1-RC1", "1.0p1").
+var composerVersionRegex = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)(?:[._-]?(patch|pl|p|rc|beta|b|alpha|a|dev)(?:[._-]?(\d+))?)?$`)
+
+// composerStabilityRanks orders Composer's stability flags, with an
+// unflagged version as stable and patch releases above it.
+var composerStabilityRanks = map[string]int{
+	"dev": 0, "alpha": 1, "a": 1, "beta": 2, "b": 2, "rc": 3, "": 4,
+	"patch": 5, "pl": 5, "p": 5,
+}
+
+// compareComposer orders Composer versions by release and then stability,
+// so "1.0-beta1" < "1.0-RC1" < "1.0" < "1.0-p1". Branches ("dev-main",
+// "2.x-dev") track moving code rather than a release, so they sort below
+// every release.
+func compareComposer(a, b string) int {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	aBranch, bBranch := isComposerBranch(a), isComposerBranch(b)
+	switch {
+	case aBranch && bBranch:
+		return compareSemver(a, b)
+	case aBranch:
+		return -1
+	case bBranch:
+		return 1
+	}
+
+	am, bm := composerVersionRegex.FindStringSubmatch(a), composerVersionRegex.FindStringSubmatch(b)
+	if am == nil || bm == nil {
+		return compareSemver(a, b)
+	}
+	if c := compareSegments(strings.Split(am[1], "."), strings.Split(bm[1], ".")); c != 0 {
+		return c
+	}
+	if c := compareInt(composerStabilityRanks[am[2]], composerStabilityRanks[bm[2]]); c != 0 {
+		return c
+	}
+	return compareNumeric(orZero(am[3]), orZero(bm[3]))
+}
+
+// isComposerBranch reports whether v names a branch: "dev-" followed by
+// the branch name, or a wildcard branch alias like "1.x-dev". A "-dev"
+// suffix on a full version ("1.0.0-dev") is a stability flag instead.
+func isComposerBranch(v string) bool {
+	if strings.HasPrefix(v, "dev-") {
+		return true
+	}
+	release, ok := strings.CutSuffix(v, "-dev")
+	return ok && strings.ContainsAny(release, "x*")
+}
+
+// compareDub orders dub versions as semver, with "~branch" versions below
+// every release.
+func compareDub(a, b string) int {
+	aBranch, bBranch := strings.HasPrefix(a, "~"), strings.HasPrefix(b, "~")
+	switch {
+	case aBranch && bBranch:
+		return strings.Compare(a, b)
+	case aBranch:
+		return -1
+	case bBranch:
+		return 1
+	}
+	return compareSemver(a, b)
+}
+
+// mavenQualifiers ranks the well-known Maven qualifiers. Unknown qualifiers
+// sort after all of these, alphabetically.
+var mavenQualifiers = map[string]int{
+	"alpha":     1,
+	"a":         1,
+	"beta":      2,
+	"b":         2,
+	"milestone": 3,
+	"m":         3,
+	"rc":        4,
+	"cr":        4,
+	"snapshot":  5,
+	"":          6,
+	"ga":        6,
+	"final":     6,
+	"release":   6,
+	"sp":        7,
+}
+
+// compareMaven is a simplified form of Maven's ComparableVersion. Numbers
+// sort numerically and above qualifiers, and qualifiers follow
+// alpha < beta < milestone < rc < snapshot < release < sp.
+func compareMaven(a, b string) int {
+	aSegs := tokenizeVersion(strings.ToLower(a))
+	bSegs := tokenizeVersion(strings.ToLower(b))
+	for i := 0; i < len(aSegs) || i < len(bSegs); i++ {
+		var x, y string
+		if i < len(aSegs) {
+			x = aSegs[i]
+		}
+		if i < len(bSegs) {
+			y = bSegs[i]
+		}
+		// A missing segment is padded with whatever is neutral for the other side
+		if x == "" && isNumeric(y) {
+			x = "0"
+		}
+		if y == "" && isNumeric(x) {
+			y = "0"
+		}
+
+		xNum, yNum := isNumeric(x), isNumeric(y)
+		switch {
+		case xNum && yNum:
+			if c := compareNumeric(x, y); c != 0 {
+				return c
+			}
+		case xNum:
+			return 1
+		case yNum:
+			return -1
+		default:
+			if c := compareMavenQualifier(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func compareMavenQualifier(a, b string) int {
+	aRank, aKnown := mavenQualifiers[a]
+	bRank, bKnown := mavenQualifiers[b]
+	switch {
+	case aKnown && bKnown:
+		return compareInt(aRank, bRank)
+	case aKnown:
+		return -1
+	case bKnown:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// pep440Regex matches the public part of a PEP 440 version, including the
+// alternate spellings it allows. Groups: epoch, release, pre label, pre
+// number, implicit post number, post label, post number, dev label, dev number.
+var pep440Regex = regexp.MustCompile(`^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d*))?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d*))?` +
+	`(?:[-_.]?(dev)[-_.]?(\d*))?$`)
+
+// pep440PreReleases ranks the prerelease spellings PEP 440 accepts.
+var pep440PreReleases = map[string]int{
+	"a": 0, "alpha": 0,
+	"b": 1, "beta": 1,
+	"c": 2, "rc": 2, "pre": 2, "preview": 2,
+}
+
+// comparePyPI orders versions per PEP 440, so "1.0.dev1" < "1.0a1" <
+// "1.0rc1" < "1.0" < "1.0.post1". Local version labels are ignored.
+// Versions that aren't valid PEP 440 fall back to semver ordering.
+func comparePyPI(a, b string) int {
+	a, _, _ = strings.Cut(strings.ToLower(a), "+")
+	b, _, _ = strings.Cut(strings.ToLower(b), "+")
+	am, bm := pep440Regex.FindStringSubmatch(a), pep440Regex.FindStringSubmatch(b)
+	if am == nil || bm == nil {
+		return compareSemver(a, b)
+	}
+
+	if c := compareNumeric(orZero(am[1]), orZero(bm[1])); c != 0 {
+		return c
+	}
+	if c := compareSegments(strings.Split(am[2], "."), strings.Split(bm[2], ".")); c != 0 {
+		return c
+	}
+
+	hasPost := func(m []string) bool { return m[5] != "" || m[6] != "" }
+	hasDev := func(m []string) bool { return m[8] != "" }
+
+	// A bare dev release ("1.0.dev1") sorts before every prerelease of the
+	// same version, and a final release after them
+	phase := func(m []string) int {
+		switch {
+		case m[3] != "":
+			return pep440PreReleases[m[3]]
+		case hasDev(m) && !hasPost(m):
+			return -1
+		}
+		return 3
+	}
+	if c := compareInt(phase(am), phase(bm)); c != 0 {
+		return c
+	}
+	if c := compareNumeric(orZero(am[4]), orZero(bm[4])); c != 0 {
+		return c
+	}
+
+	// No post release sorts before any post release
+	switch {
+	case hasPost(am) && hasPost(bm):
+		if c := compareNumeric(orZero(am[5]+am[7]), orZero(bm[5]+bm[7])); c != 0 {
+			return c
+		}
+	case hasPost(am):
+		return 1
+	case hasPost(bm):
+		return -1
+	}
+
+	// A dev release sorts before the same version without one
+	switch {
+	case hasDev(am) && hasDev(bm):
+		return compareNumeric(orZero(am[9]), orZero(bm[9]))
+	case hasDev(am):
+		return -1
+	case hasDev(bm):
+		return 1
+	}
+	return 0
+}
+
+// tokenizeVersion splits a version on ".", "-", "_" and "+", and between
+// runs of digits and letters, so "1.0rc1" becomes ["1", "0", "rc", "1"].
+func tokenizeVersion(v string) []string {
+	var segs []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			segs = append(segs, cur.String())
+			cur.Reset()
+		}
+	}
+	var prevDigit bool
+	for i, r := range v {
+		if r == '.' || r == '-' || r == '_' || r == '+' {
+			flush()
+			continue
+		}
+		digit := unicode.IsDigit(r)
+		if i > 0 && cur.Len() > 0 && digit != prevDigit {
+			flush()
+		}
+		cur.WriteRune(r)
+		prevDigit = digit
+	}
+	flush()
+	return segs
+}
+
+// compareSegments compares dotted release segments, treating missing
+// segments as zero so "1.2" equals "1.2.0".
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := "0", "0"
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := compareIdentifier(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareIdentifier compares two version identifiers using semver rules:
+// numeric identifiers compare numerically and sort below alphanumeric ones.
+func compareIdentifier(a, b string) int {
+	aNum, bNum := isNumeric(a), isNumeric(b)
+	switch {
+	case aNum && bNum:
+		return compareNumeric(a, b)
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// compareNumeric compares two digit strings without converting them, so
+// arbitrarily long numbers (date-based versions) don't overflow.
+func compareNumeric(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if c := compareInt(len(a), len(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,441 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type ecosystemRegistry struct {
+	Registry
+	ecosystem string
+}
+
+func (r ecosystemRegistry) Ecosystem() string { return r.ecosystem }
+
+func TestVersionComparators(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		older     string
+		newer     string
+	}{
+		{"npm", "1.2.3", "1.10.0"},
+		{"npm", "1.0.0-beta.2", "1.0.0-beta.11"},
+		{"npm", "1.0.0-rc.1", "1.0.0"},
+		{"npm", "1.0.0-alpha", "1.0.0-alpha.1"},
+		{"golang", "v0.9.0", "v0.10.0"},
+		{"luarocks", "1.0.0-1", "1.0.0-2"},
+		{"luarocks", "1.9-1", "1.10-1"},
+		{"luarocks", "1.0rc1-1", "1.0-1"},
+		{"luarocks", "scm-1", "0.1-1"},
+		{"luarocks", "dev-1", "0.0.1-1"},
+		{"maven", "1.0-alpha-1", "1.0-beta"},
+		{"maven", "1.0-RC1", "1.0-SNAPSHOT"},
+		{"maven", "1.0-SNAPSHOT", "1.0"},
+		{"maven", "1.0", "1.0-sp1"},
+		{"maven", "1.0.Final", "1.0.1"},
+		{"maven", "2.9", "2.10"},
+		{"nuget", "1.0.0-Beta", "1.0.0-rc"},
+		{"nuget", "1.0.0.1", "1.0.0.2"},
+		{"cran", "1.2-9", "1.2-10"},
+		{"cran", "0.9.1", "1.0-0"},
+		{"pypi", "1.0.dev1", "1.0a1"},
+		{"pypi", "1.0a2", "1.0b1"},
+		{"pypi", "1.0rc1", "1.0"},
+		{"pypi", "1.0", "1.0.post1"},
+		{"pypi", "1.0.post1.dev1", "1.0.post1"},
+		{"pypi", "1!0.1", "2!0.0"},
+		{"gem", "1.0.0.rc1", "1.0.0"},
+		{"gem", "1.0.0.beta", "1.0.0.rc"},
+		{"gem", "2.9.0", "2.10.0"},
+		{"composer", "1.0.0-beta2", "1.0.0-RC1"},
+		{"composer", "1.0.0-RC1", "1.0.0"},
+		{"composer", "1.0.0", "1.0.0-p1"},
+		{"composer", "1.0.0-dev", "1.0.0-alpha1"},
+		{"composer", "v1.9.0", "v1.10.0"},
+		{"composer", "dev-master", "0.0.1"},
+		{"composer", "1.x-dev", "1.0.0"},
+		{"composer", "1.x-dev", "2.x-dev"},
+		{"dub", "~master", "0.0.1"},
+		{"dub", "1.0.0-rc.1", "1.0.0"},
+	}
+
+	for _, tt := range tests {
+		cmp := versionComparator(tt.ecosystem)
+		if got := cmp(tt.older, tt.newer); got != -1 {
+			t.Errorf("%s: compare(%q, %q) = %d, want -1", tt.ecosystem, tt.older, tt.newer, got)
+		}
+		if got := cmp(tt.newer, tt.older); got != 1 {
+			t.Errorf("%s: compare(%q, %q) = %d, want 1", tt.ecosystem, tt.newer, tt.older, got)
+		}
+	}
+}
+
+func TestVersionComparatorsEqual(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		a, b      string
+	}{
+		{"npm", "1.2", "1.2.0"},
+		{"npm", "1.0.0+build.1", "1.0.0+build.2"},
+		{"maven", "1.0", "1.0.0"},
+		{"maven", "1.0-ga", "1.0"},
+		{"nuget", "1.0.0-RC", "1.0.0-rc"},
+		{"pypi", "1.0.0", "1.0"},
+		{"pypi", "1.0-1", "1.0.post1"},
+	}
+
+	for _, tt := range tests {
+		if got := versionComparator(tt.ecosystem)(tt.a, tt.b); got != 0 {
+			t.Errorf("%s: compare(%q, %q) = %d, want 0", tt.ecosystem, tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestSortedVersionsBranches(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		versions  []string
+		want      []string
+	}{
+		{"composer", []string{"dev-master", "6.0.0-RC1", "1.x-dev", "5.4.2", "6.0.0", "v5.10.0"}, []string{"6.0.0", "6.0.0-RC1", "v5.10.0", "5.4.2", "dev-master", "1.x-dev"}},
+		{"dub", []string{"~master", "0.9.0", "1.0.0", "~stable", "1.0.0-beta.1"}, []string{"1.0.0", "1.0.0-beta.1", "0.9.0", "~stable", "~master"}},
+	}
+
+	for _, tt := range tests {
+		var versions []Version
+		for _, n := range tt.versions {
+			versions = append(versions, Version{Number: n})
+		}
+		var got []string
+		for _, v := range SortedVersions(ecosystemRegistry{ecosystem: tt.ecosystem}, versions) {
+			got = append(got, v.Number)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.ecosystem, tt.want, got)
+		}
+	}
+}
+
+func TestSortedVersions(t *testing.T) {
+	reg := ecosystemRegistry{ecosystem: "maven"}
+	versions := []Version{
+		{Number: "1.0"},
+		{Number: "1.10.0"},
+		{Number: "1.2.0-SNAPSHOT"},
+		{Number: "1.2.0"},
+		{Number: "1.0-beta"},
+	}
+
+	sorted := SortedVersions(reg, versions)
+
+	want := []string{"1.10.0", "1.2.0", "1.2.0-SNAPSHOT", "1.0", "1.0-beta"}
+	for i, v := range sorted {
+		if v.Number != want[i] {
+			t.Errorf("position %d: expected %q, got %q", i, want[i], v.Number)
+		}
+	}
+
+	if versions[0].Number != "1.0" {
+		t.Error("SortedVersions should not modify its input")
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	tests := []struct {
+		ecosystem  string
+		version    string
+		prerelease bool
+	}{
+		{"npm", "1.0.0", false},
+		{"npm", "1.0.0-beta.1", true},
+		{"npm", "1.0.0+build.1", false},
+		{"golang", "v0.0.0-20230101000000-abcdef123456", true},
+		{"maven", "1.0-SNAPSHOT", true},
+		{"maven", "2.0.0-M1", true},
+		{"maven", "1.0.RC2", true},
+		{"maven", "32.1.0-jre", false},
+		{"maven", "5.3.0.Final", false},
+		{"maven", "1.0-sp1", false},
+		{"pypi", "2.0.0rc1", true},
+		{"pypi", "2.0.0.dev3", true},
+		{"pypi", "2.0.0.post1", false},
+		{"pypi", "2.0.0", false},
+		{"gem", "7.1.0.beta1", true},
+		{"gem", "7.1.0", false},
+		{"composer", "dev-main", true},
+		{"composer", "2.x-dev", true},
+		{"composer", "1.0.0-beta2", true},
+		{"composer", "v1.0.0-RC1", true},
+		{"composer", "1.0.0-p1", false},
+		{"composer", "1.0.0", false},
+		{"dub", "~master", true},
+		{"dub", "1.0.0", false},
+		{"cpan", "1.23_01", true},
+		{"cpan", "1.23", false},
+		{"luarocks", "3.9.2-1", false},
+		{"luarocks", "scm-1", true},
+		{"luarocks", "1.0rc1-1", true},
+		{"conda", "1.0.0rc1", true},
+		{"conda", "1.0.0", false},
+		{"cran", "1.2-3", false},
+		{"cargo", "1.0.0-alpha.1", true},
+		{"cargo", "1.0.0", false},
+		{"nuget", "6.0.0-preview.7.21377.19", true},
+		{"nuget", "13.0.3", false},
+		{"hex", "1.7.0-rc.0", true},
+		{"pub", "3.0.0-dev.1", true},
+		{"elm", "1.0.5", false},
+		{"deno", "0.200.0", false},
+		{"terraform", "5.0.0-beta1", true},
+		{"cocoapods", "5.0.0.beta.1", true},
+		{"cocoapods", "5.0.0-beta.1", true},
+		{"julia", "1.0.0", false},
+		{"nimble", "0.1.0", false},
+		{"haxelib", "4.0.0-rc.5", true},
+		{"brew", "1.2.3", false},
+		{"hackage", "1.2.3.4", false},
+		{"clojars", "1.11.0-alpha1", true},
+		{"clojars", "1.11.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPrerelease(tt.ecosystem, tt.version); got != tt.prerelease {
+			t.Errorf("%s: IsPrerelease(%q) = %v, want %v", tt.ecosystem, tt.version, got, tt.prerelease)
+		}
+	}
+}
+
+func TestVersionIsPrerelease(t *testing.T) {
+	tests := []struct {
+		version    string
+		prerelease bool
+	}{
+		{"1.0.0", false},
+		{"1.0.0-beta.1", true},
+		{"1.0-SNAPSHOT", true},
+		{"2.0.0-M3", true},
+		{"2.0.0rc1", true},
+		{"2.0.0.dev1", true},
+		{"7.1.0.alpha", true},
+		{"dev-main", true},
+		{"2.x-dev", true},
+		{"~master", true},
+		{"32.1.0-jre", false},
+		{"1.2-3", false},
+		{"5.3.0.Final", false},
+		{"1.0a1", true},
+		{"2.1b2", true},
+		{"1.1.1b", false},
+		{"1.0.2k", false},
+	}
+
+	for _, tt := range tests {
+		if got := (Version{Number: tt.version}).IsPrerelease(); got != tt.prerelease {
+			t.Errorf("Version{%q}.IsPrerelease() = %v, want %v", tt.version, got, tt.prerelease)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		a, b      string
+		want      int
+	}{
+		{"npm", "1.10.0", "1.9.0", 1},
+		{"npm", "1.0.0-rc.1", "1.0.0", -1},
+		{"maven", "1.0-SNAPSHOT", "1.0", -1},
+		{"pypi", "1.0", "1.0.0", 0},
+		{"gem", "1.0.a", "1.0", -1},
+		{"cran", "1.2-10", "1.2-9", 1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.ecosystem, tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: CompareVersions(%q, %q) = %d, want %d", tt.ecosystem, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestStableVersions(t *testing.T) {
+	reg := ecosystemRegistry{ecosystem: "maven"}
+	versions := []Version{
+		{Number: "2.0.0-SNAPSHOT"},
+		{Number: "1.2.0"},
+		{Number: "1.1.0", Status: StatusYanked},
+		{Number: "1.1.0-rc1"},
+		{Number: "1.0.0", Status: StatusDeprecated},
+		{Number: "0.9.0"},
+	}
+
+	stable := StableVersions(reg, versions)
+
+	want := []string{"1.2.0", "0.9.0"}
+	if len(stable) != len(want) {
+		t.Fatalf("expected %d stable versions, got %d", len(want), len(stable))
+	}
+	for i, v := range stable {
+		if v.Number != want[i] {
+			t.Errorf("position %d: expected %q, got %q", i, want[i], v.Number)
+		}
+	}
+}
+
+type staticVersionsRegistry struct {
+	ecosystemRegistry
+	versions []Version
+}
+
+func (r staticVersionsRegistry) FetchVersions(ctx context.Context, name string) ([]Version, error) {
+	return r.versions, nil
+}
+
+func TestExcludeVersions(t *testing.T) {
+	versions := []Version{
+		{Number: "4.0.0-rc.1"},
+		{Number: "3.0.0", Status: StatusRetracted},
+		{Number: "2.0.0", Status: StatusDeprecated},
+		{Number: "1.1.0", Status: StatusYanked},
+		{Number: "1.0.0"},
+	}
+
+	numbers := func(versions []Version) []string {
+		var n []string
+		for _, v := range versions {
+			n = append(n, v.Number)
+		}
+		return n
+	}
+
+	if got, want := numbers(ExcludeVersions(versions)), []string{"4.0.0-rc.1", "1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExcludeVersions() = %v, want %v", got, want)
+	}
+	if got, want := numbers(ExcludeVersions(versions, StatusYanked, StatusRetracted)), []string{"4.0.0-rc.1", "2.0.0", "1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExcludeVersions(yanked, retracted) = %v, want %v", got, want)
+	}
+	if len(versions) != 5 {
+		t.Error("expected the input to be left alone")
+	}
+}
+
+func TestFetchLatestVersionWithoutTimestamps(t *testing.T) {
+	reg := staticVersionsRegistry{
+		ecosystemRegistry: ecosystemRegistry{ecosystem: "cargo"},
+		versions: []Version{
+			{Number: "0.9.0"},
+			{Number: "0.10.0"},
+			{Number: "0.11.0-rc.1"},
+			{Number: "0.11.0", Status: StatusYanked},
+		},
+	}
+
+	latest, err := FetchLatestVersion(context.Background(), reg, "example")
+	if err != nil {
+		t.Fatalf("FetchLatestVersion failed: %v", err)
+	}
+	if latest.Number != "0.11.0-rc.1" {
+		t.Errorf("expected latest '0.11.0-rc.1', got %q", latest.Number)
+	}
+}
+
+func TestLatestStable(t *testing.T) {
+	reg := staticVersionsRegistry{
+		ecosystemRegistry: ecosystemRegistry{ecosystem: "pypi"},
+		versions: []Version{
+			{Number: "4.0.1", PublishedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{Number: "3.2.9", PublishedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+			{Number: "4.1.0rc1", PublishedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+			{Number: "4.0.2", Status: StatusYanked},
+		},
+	}
+
+	latest, err := latestStable(context.Background(), reg, "example")
+	if err != nil {
+		t.Fatalf("latestStable failed: %v", err)
+	}
+	if latest == nil || latest.Number != "4.0.1" {
+		t.Errorf("expected latest stable '4.0.1', got %v", latest)
+	}
+
+	reg.versions = []Version{{Number: "1.0.0b1"}}
+	latest, err = latestStable(context.Background(), reg, "example")
+	if err != nil || latest != nil {
+		t.Errorf("expected nil without stable versions, got %v, %v", latest, err)
+	}
+
+	if _, err := LatestStable(context.Background(), "unknown", "example", nil); err == nil {
+		t.Error("expected an error for an unknown ecosystem")
+	}
+}
+
+func TestResolveVersion(t *testing.T) {
+	reg := staticVersionsRegistry{
+		ecosystemRegistry: ecosystemRegistry{ecosystem: "npm"},
+		versions: []Version{
+			{Number: "1.2.0"},
+			{Number: "1.4.1"},
+			{Number: "1.5.0", Status: StatusYanked},
+			{Number: "1.6.0-beta.1"},
+			{Number: "2.0.0"},
+			{Number: "3.0.0-rc.1"},
+		},
+	}
+
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{"^1.2", "1.4.1"},
+		{"~1.2.0", "1.2.0"},
+		{"", "2.0.0"},
+		{">=1.0.0", "2.0.0"},
+		{"^3.0.0-rc.0", "3.0.0-rc.1"},
+	}
+	for _, tt := range tests {
+		v, err := ResolveVersion(context.Background(), reg, "example", tt.constraint)
+		if err != nil {
+			t.Errorf("ResolveVersion(%q) failed: %v", tt.constraint, err)
+			continue
+		}
+		if v.Number != tt.want {
+			t.Errorf("ResolveVersion(%q) = %q, want %q", tt.constraint, v.Number, tt.want)
+		}
+	}
+
+	if _, err := ResolveVersion(context.Background(), reg, "example", "^4.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound when nothing matches, got %v", err)
+	}
+	if _, err := ResolveVersion(context.Background(), reg, "example", "latest"); !errors.Is(err, ErrUnsupportedConstraint) {
+		t.Errorf("expected ErrUnsupportedConstraint for a dist-tag, got %v", err)
+	}
+}
+
+func TestFetchLatestVersionIncludingDeprecated(t *testing.T) {
+	reg := staticVersionsRegistry{
+		ecosystemRegistry: ecosystemRegistry{ecosystem: "composer"},
+		versions: []Version{
+			{Number: "1.0.0", Status: StatusDeprecated},
+			{Number: "1.1.0", Status: StatusDeprecated},
+			{Number: "1.2.0", Status: StatusYanked},
+		},
+	}
+
+	latest, err := FetchLatestVersion(context.Background(), reg, "example")
+	if err != nil {
+		t.Fatalf("FetchLatestVersion failed: %v", err)
+	}
+	if latest != nil {
+		t.Errorf("expected nil from the strict form, got %q", latest.Number)
+	}
+
+	latest, err = FetchLatestVersionIncludingDeprecated(context.Background(), reg, "example")
+	if err != nil {
+		t.Fatalf("FetchLatestVersionIncludingDeprecated failed: %v", err)
+	}
+	if latest == nil || latest.Number != "1.1.0" {
+		t.Errorf("expected latest '1.1.0', got %v", latest)
+	}
+}
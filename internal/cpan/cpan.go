@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
-	"github.com/git-pkgs/registries/internal/urlparser"
 )
 
 const (
@@ -20,6 +19,9 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"author", "bugtracker",
+	)
 }
 
 type Registry struct {
@@ -116,10 +118,15 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 
 	var repository string
 	if resp.Resources.Repository.Web != "" {
-		repository = urlparser.Parse(resp.Resources.Repository.Web)
+		repository = core.NormalizeRepository(resp.Resources.Repository.Web)
 	}
 	if repository == "" && resp.Resources.Repository.URL != "" {
-		repository = urlparser.Parse(resp.Resources.Repository.URL)
+		repository = core.NormalizeRepository(resp.Resources.Repository.URL)
+	}
+	if repository == "" {
+		// Many distributions only set a bugtracker, which for GitHub
+		// projects is the repository's issues page
+		repository = core.ExtractRepoURL(core.IssueTracker(resp.Resources.Bugtracker.Web))
 	}
 
 	var licenses string
@@ -128,11 +135,13 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Abstract,
-		Homepage:    resp.Resources.Homepage,
-		Repository:  repository,
-		Licenses:    licenses,
+		Name:          resp.Name,
+		Summary:       resp.Abstract,
+		Description:   resp.Abstract,
+		Homepage:      resp.Resources.Homepage,
+		Repository:    repository,
+		Licenses:      licenses,
+		LatestVersion: resp.Version,
 		Metadata: map[string]any{
 			"author":     resp.Author,
 			"bugtracker": resp.Resources.Bugtracker.Web,
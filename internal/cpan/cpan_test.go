@@ -69,6 +69,30 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Licenses != "perl_5" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if pkg.LatestVersion != "2.2201" {
+		t.Errorf("expected latest version '2.2201', got %q", pkg.LatestVersion)
+	}
+}
+
+func TestFetchPackageBugtrackerRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"name": "Try-Tiny",
+			"version": "0.31",
+			"resources": {"bugtracker": {"web": "https://github.com/p5sagit/Try-Tiny/issues"}}
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "Try::Tiny")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Repository != "https://github.com/p5sagit/Try-Tiny" {
+		t.Errorf("expected the repository from the bugtracker, got %q", pkg.Repository)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -114,6 +138,9 @@ func TestFetchVersions(t *testing.T) {
 	if versions[1].Status != core.StatusYanked {
 		t.Errorf("expected yanked status for backpan version, got %q", versions[1].Status)
 	}
+	if kept := core.ExcludeVersions(versions); len(kept) != len(versions)-1 {
+		t.Errorf("expected only the backpan version to be excluded, got %v", kept)
+	}
 }
 
 func TestFetchDependencies(t *testing.T) {
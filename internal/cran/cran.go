@@ -22,6 +22,10 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"archived", "author", "bug_reports", "maintainer",
+		"needs_compilation", "r_version", "removed",
+	)
 }
 
 type Registry struct {
@@ -86,16 +90,18 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	repository := extractRepository(desc.URL)
 
 	return &core.Package{
-		Name:        desc.Package,
-		Description: desc.Title,
-		Homepage:    getFirstURL(desc.URL),
-		Repository:  repository,
-		Licenses:    desc.License,
+		Name:          desc.Package,
+		Description:   desc.Title,
+		Homepage:      getFirstURL(desc.URL),
+		Repository:    repository,
+		Licenses:      desc.License,
+		LatestVersion: desc.Version,
 		Metadata: map[string]any{
 			"author":       desc.Author,
 			"maintainer":   desc.Maintainer,
 			"bug_reports":  desc.BugReports,
 			"needs_compilation": desc.NeedsCompilation,
+			"r_version":    rRequirement(desc.Depends),
 		},
 	}, nil
 }
@@ -193,14 +199,18 @@ func getFirstURL(urlField string) string {
 	return ""
 }
 
+// FetchVersions returns the current version from the DESCRIPTION file and
+// the older ones from the package's directory in the source archive, dated
+// by their tarballs' modification times. A package that has been removed
+// from CRAN has no DESCRIPTION, so its versions come from the archive
+// alone and are all marked yanked: they can still be installed from there
+// but are no longer offered.
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
-	// CRAN only keeps the current version, but we can get archived versions
-	// First get current version from DESCRIPTION
 	descURL := fmt.Sprintf("%s/web/packages/%s/DESCRIPTION", r.baseURL, name)
 	body, err := r.client.GetBody(ctx, descURL)
 	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+			return r.fetchRemovedVersions(ctx, name)
 		}
 		return nil, err
 	}
@@ -219,37 +229,97 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		Number:      desc.Version,
 		PublishedAt: publishedAt,
 		Licenses:    desc.License,
+		Metadata:    map[string]any{"archived": false},
 	})
 
-	// Try to get archived versions
-	archiveURL := fmt.Sprintf("%s/src/contrib/Archive/%s/", r.baseURL, name)
-	archiveBody, err := r.client.GetBody(ctx, archiveURL)
-	if err == nil {
-		// Parse the HTML directory listing to extract version numbers
-		archivedVersions := parseArchiveVersions(string(archiveBody), name)
-		for _, v := range archivedVersions {
-			if v != desc.Version {
-				versions = append(versions, core.Version{
-					Number: v,
-				})
-			}
+	// Older versions are optional; a package with one release has no archive
+	archived, _ := r.fetchArchive(ctx, name)
+	for _, v := range archived {
+		if v.Number != desc.Version {
+			versions = append(versions, v)
 		}
 	}
 
 	return versions, nil
 }
 
-func parseArchiveVersions(html, pkgName string) []string {
-	var versions []string
+// fetchRemovedVersions reads the versions of a package that only exists in
+// the archive.
+func (r *Registry) fetchRemovedVersions(ctx context.Context, name string) ([]core.Version, error) {
+	versions, err := r.fetchArchive(ctx, name)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+	for i := range versions {
+		versions[i].Status = core.StatusYanked
+		versions[i].Metadata["removed"] = true
+	}
+	return versions, nil
+}
+
+// fetchArchive lists the tarballs in /src/contrib/Archive/<name>/ as
+// archived versions.
+func (r *Registry) fetchArchive(ctx context.Context, name string) ([]core.Version, error) {
+	archiveURL := fmt.Sprintf("%s/src/contrib/Archive/%s/", r.baseURL, name)
+	body, err := r.client.GetBody(ctx, archiveURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []core.Version
+	for _, entry := range parseArchiveListing(string(body), name) {
+		versions = append(versions, core.Version{
+			Number:      entry.version,
+			PublishedAt: entry.modified,
+			Metadata:    map[string]any{"archived": true},
+		})
+	}
+	return versions, nil
+}
+
+type archiveEntry struct {
+	version  string
+	modified time.Time
+}
+
+// archiveDate matches the modification time column of an Apache directory
+// listing, either "2023-11-17 10:42" or the older "17-Nov-2023 10:42".
+var archiveDate = regexp.MustCompile(`\b(\d{4}-\d{2}-\d{2} \d{2}:\d{2}|\d{2}-[A-Z][a-z]{2}-\d{4} \d{2}:\d{2})\b`)
+
+// parseArchiveListing extracts the versions from an archive directory
+// listing, each dated by the modification time on the same line if there
+// is one. CRAN's server reports these in its local time, Europe/Vienna,
+// but they're read as UTC since the listing doesn't say.
+func parseArchiveListing(html, pkgName string) []archiveEntry {
+	var entries []archiveEntry
 	// Match patterns like: pkgname_1.2.3.tar.gz
 	pattern := regexp.MustCompile(regexp.QuoteMeta(pkgName) + `_([0-9]+\.[0-9]+[0-9.-]*)\.tar\.gz`)
-	matches := pattern.FindAllStringSubmatch(html, -1)
-	for _, m := range matches {
-		if len(m) > 1 {
-			versions = append(versions, m[1])
+	// Each file shows up in both the href and the link text
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(html, "\n") {
+		var modified time.Time
+		if d := archiveDate.FindString(line); d != "" {
+			for _, layout := range []string{"2006-01-02 15:04", "02-Jan-2006 15:04"} {
+				if t, err := time.Parse(layout, d); err == nil {
+					modified = t
+					break
+				}
+			}
+		}
+		for _, m := range pattern.FindAllStringSubmatch(line, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				entries = append(entries, archiveEntry{version: m[1], modified: modified})
+			}
 		}
 	}
-	return versions
+	return entries
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
@@ -290,6 +360,13 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 	return deps, nil
 }
 
+// depRegex matches a single DESCRIPTION dependency entry.
+// Example: "scales (>= 1.2.0)"
+var depRegex = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9.]*)\s*(?:\(([^)]*)\))?`)
+
+// constraintRegex splits a version constraint into operator and version.
+var constraintRegex = regexp.MustCompile(`^(>=|<=|==|!=|>|<|=)?\s*(.*)$`)
+
 func parseDependencyList(depString string, scope core.Scope) []core.Dependency {
 	var deps []core.Dependency
 	if depString == "" {
@@ -298,8 +375,6 @@ func parseDependencyList(depString string, scope core.Scope) []core.Dependency {
 
 	// Dependencies are comma-separated, with optional version constraints in parens
 	// Example: "R (>= 3.5.0), methods, utils"
-	depRegex := regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9.]*)\s*(\([^)]+\))?`)
-
 	parts := strings.Split(depString, ",")
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
@@ -315,17 +390,11 @@ func parseDependencyList(depString string, scope core.Scope) []core.Dependency {
 				continue
 			}
 
-			requirements := ""
-			if len(matches) > 2 && matches[2] != "" {
-				// Remove parens from version constraint
-				requirements = strings.Trim(matches[2], "()")
-			}
-
 			optional := scope == core.Optional
 
 			deps = append(deps, core.Dependency{
 				Name:         name,
-				Requirements: requirements,
+				Requirements: normalizeConstraint(matches[2]),
 				Scope:        scope,
 				Optional:     optional,
 			})
@@ -335,6 +404,32 @@ func parseDependencyList(depString string, scope core.Scope) []core.Dependency {
 	return deps
 }
 
+// normalizeConstraint tidies a constraint that may have been wrapped across
+// continuation lines, so "(>=\n    1.2.0)" and "(>=1.2.0)" both become ">= 1.2.0".
+func normalizeConstraint(c string) string {
+	c = strings.Join(strings.Fields(c), " ")
+	if c == "" {
+		return ""
+	}
+	m := constraintRegex.FindStringSubmatch(c)
+	if m[1] == "" {
+		return m[2]
+	}
+	return m[1] + " " + m[2]
+}
+
+// rRequirement returns the R version constraint from a Depends field,
+// which parseDependencyList drops.
+func rRequirement(depends string) string {
+	for _, part := range strings.Split(depends, ",") {
+		matches := depRegex.FindStringSubmatch(strings.TrimSpace(part))
+		if len(matches) > 1 && matches[1] == "R" {
+			return normalizeConstraint(matches[2])
+		}
+	}
+	return ""
+}
+
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
 	descURL := fmt.Sprintf("%s/web/packages/%s/DESCRIPTION", r.baseURL, name)
 	body, err := r.client.GetBody(ctx, descURL)
@@ -2,9 +2,11 @@ package cran
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
 )
@@ -63,6 +65,12 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Homepage != "https://ggplot2.tidyverse.org" {
 		t.Errorf("unexpected homepage: %q", pkg.Homepage)
 	}
+	if pkg.Metadata["r_version"] != ">= 3.3" {
+		t.Errorf("expected r_version '>= 3.3', got %v", pkg.Metadata["r_version"])
+	}
+	if pkg.LatestVersion != "3.4.4" {
+		t.Errorf("expected latest version '3.4.4', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -79,11 +87,11 @@ Published: 2023-11-17
 	})
 
 	mux.HandleFunc("/src/contrib/Archive/dplyr/", func(w http.ResponseWriter, r *http.Request) {
-		html := `<html><body>
-<a href="dplyr_1.1.3.tar.gz">dplyr_1.1.3.tar.gz</a>
-<a href="dplyr_1.1.2.tar.gz">dplyr_1.1.2.tar.gz</a>
-<a href="dplyr_1.0.0.tar.gz">dplyr_1.0.0.tar.gz</a>
-</body></html>`
+		html := `<html><body><table>
+<tr><td><a href="dplyr_1.1.3.tar.gz">dplyr_1.1.3.tar.gz</a></td><td align="right">2023-09-03 12:10  </td><td align="right">1.1M</td></tr>
+<tr><td><a href="dplyr_1.1.2.tar.gz">dplyr_1.1.2.tar.gz</a></td><td align="right">2023-04-20 18:42  </td><td align="right">1.1M</td></tr>
+<tr><td><a href="dplyr_1.0.0.tar.gz">dplyr_1.0.0.tar.gz</a></td><td align="right">2020-05-29 15:00  </td><td align="right">1.0M</td></tr>
+</table></body></html>`
 		_, _ = w.Write([]byte(html))
 	})
 
@@ -106,6 +114,63 @@ Published: 2023-11-17
 	if versions[0].PublishedAt.IsZero() {
 		t.Error("expected non-zero published time for current version")
 	}
+	if versions[0].Metadata["archived"] != false {
+		t.Errorf("expected current version not archived, got %v", versions[0].Metadata["archived"])
+	}
+
+	if len(versions) != 4 {
+		t.Fatalf("expected 4 versions, got %d", len(versions))
+	}
+	for _, v := range versions[1:] {
+		if v.Metadata["archived"] != true {
+			t.Errorf("expected %s to be archived, got %v", v.Number, v.Metadata["archived"])
+		}
+		if v.Status != core.StatusNone {
+			t.Errorf("expected %s to have no status, got %q", v.Number, v.Status)
+		}
+	}
+	if want := time.Date(2023, 9, 3, 12, 10, 0, 0, time.UTC); !versions[1].PublishedAt.Equal(want) {
+		t.Errorf("expected %s to be dated %v, got %v", versions[1].Number, want, versions[1].PublishedAt)
+	}
+}
+
+func TestFetchVersionsRemovedPackage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/src/contrib/Archive/oldpkg/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<pre>
+<a href="oldpkg_0.9.tar.gz">oldpkg_0.9.tar.gz</a>     14-Mar-2012 09:15   12K
+<a href="oldpkg_1.0.tar.gz">oldpkg_1.0.tar.gz</a>     02-Feb-2015 17:30   13K
+</pre>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "oldpkg")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	for _, v := range versions {
+		if v.Status != core.StatusYanked {
+			t.Errorf("expected %s to be yanked, got %q", v.Number, v.Status)
+		}
+		if v.Metadata["removed"] != true || v.Metadata["archived"] != true {
+			t.Errorf("unexpected metadata for %s: %v", v.Number, v.Metadata)
+		}
+	}
+	if want := time.Date(2015, 2, 2, 17, 30, 0, 0, time.UTC); !versions[1].PublishedAt.Equal(want) {
+		t.Errorf("expected %v, got %v", want, versions[1].PublishedAt)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "missing")
+	var notFound *core.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError for a package without an archive, got %v", err)
+	}
 }
 
 func TestFetchDependencies(t *testing.T) {
@@ -208,6 +273,42 @@ func TestParseDependencyList(t *testing.T) {
 	}
 }
 
+func TestParseDependencyListConstraints(t *testing.T) {
+	deps := parseDependencyList("Rcpp (>=1.0.5), cpp11 (>=\n    0.4.0), BH", core.Build)
+	if len(deps) != 3 {
+		t.Fatalf("expected 3 dependencies, got %d", len(deps))
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Rcpp", ">= 1.0.5"},
+		{"cpp11", ">= 0.4.0"},
+		{"BH", ""},
+	}
+	for i, tt := range tests {
+		if deps[i].Name != tt.name {
+			t.Errorf("expected name %q, got %q", tt.name, deps[i].Name)
+		}
+		if deps[i].Requirements != tt.want {
+			t.Errorf("%s: expected requirement %q, got %q", tt.name, tt.want, deps[i].Requirements)
+		}
+		if deps[i].Scope != core.Build {
+			t.Errorf("%s: expected build scope, got %q", tt.name, deps[i].Scope)
+		}
+	}
+}
+
+func TestRRequirement(t *testing.T) {
+	if got := rRequirement("R (>= 3.3), methods"); got != ">= 3.3" {
+		t.Errorf("expected '>= 3.3', got %q", got)
+	}
+	if got := rRequirement("methods"); got != "" {
+		t.Errorf("expected empty requirement, got %q", got)
+	}
+}
+
 func TestURLBuilder(t *testing.T) {
 	reg := New("https://cran.r-project.org", nil)
 	urls := reg.URLs()
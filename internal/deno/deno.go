@@ -12,18 +12,27 @@ import (
 )
 
 const (
-	DefaultURL = "https://apiland.deno.dev"
-	ecosystem  = "deno"
+	DefaultURL    = "https://apiland.deno.dev"
+	DefaultCDNURL = "https://cdn.deno.land"
+	DefaultJSRURL = "https://api.jsr.io"
+	ecosystem     = "deno"
+
+	jsrSiteURL = "https://jsr.io"
 )
 
 func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"registry", "score", "upload_type",
+	)
 }
 
 type Registry struct {
 	baseURL string
+	cdnURL  string
+	jsrURL  string
 	client  *core.Client
 	urls    *URLs
 }
@@ -34,12 +43,40 @@ func New(baseURL string, client *core.Client) *Registry {
 	}
 	r := &Registry{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
+		cdnURL:  DefaultCDNURL,
+		jsrURL:  DefaultJSRURL,
 		client:  client,
 	}
 	r.urls = &URLs{baseURL: r.baseURL}
 	return r
 }
 
+// WithCDNURL returns a copy of the registry that reads deno.land/x version
+// lists from the given CDN instead of cdn.deno.land.
+func (r *Registry) WithCDNURL(cdnURL string) *Registry {
+	copy := *r
+	copy.cdnURL = strings.TrimSuffix(cdnURL, "/")
+	return &copy
+}
+
+// WithJSRURL returns a copy of the registry that queries the given JSR API
+// instead of api.jsr.io.
+func (r *Registry) WithJSRURL(jsrURL string) *Registry {
+	copy := *r
+	copy.jsrURL = strings.TrimSuffix(jsrURL, "/")
+	return &copy
+}
+
+// splitJSRName splits a JSR package name like "@std/path" into its scope
+// and package name. ok is false for deno.land/x module names.
+func splitJSRName(name string) (scope, pkg string, ok bool) {
+	if !strings.HasPrefix(name, "@") {
+		return "", "", false
+	}
+	scope, pkg, ok = strings.Cut(name[1:], "/")
+	return scope, pkg, ok && scope != "" && pkg != ""
+}
+
 func (r *Registry) Ecosystem() string {
 	return ecosystem
 }
@@ -69,6 +106,36 @@ type uploadOptions struct {
 	Ref        string `json:"ref"`
 }
 
+type versionsResponse struct {
+	Latest   string   `json:"latest"`
+	Versions []string `json:"versions"`
+}
+
+type jsrPackageResponse struct {
+	Scope            string `json:"scope"`
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	LatestVersion    string `json:"latestVersion"`
+	Score            int    `json:"score"`
+	GitHubRepository *struct {
+		Owner string `json:"owner"`
+		Name  string `json:"name"`
+	} `json:"githubRepository"`
+}
+
+type jsrVersion struct {
+	Version   string    `json:"version"`
+	Yanked    bool      `json:"yanked"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type jsrDependency struct {
+	Kind       string `json:"kind"` // "jsr" or "npm"
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
+	Path       string `json:"path"`
+}
+
 type versionResponse struct {
 	Version     string    `json:"version"`
 	UploadedAt  time.Time `json:"uploaded_at"`
@@ -87,6 +154,10 @@ type directoryEntry struct {
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	if scope, pkg, ok := splitJSRName(name); ok {
+		return r.fetchJSRPackage(ctx, name, scope, pkg)
+	}
+
 	url := fmt.Sprintf("%s/v2/modules/%s", r.baseURL, name)
 
 	var resp moduleInfoResponse
@@ -114,10 +185,44 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}, nil
 }
 
+func (r *Registry) fetchJSRPackage(ctx context.Context, name, scope, pkg string) (*core.Package, error) {
+	url := fmt.Sprintf("%s/scopes/%s/packages/%s", r.jsrURL, scope, pkg)
+
+	var resp jsrPackageResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	repository := ""
+	if gh := resp.GitHubRepository; gh != nil && gh.Owner != "" && gh.Name != "" {
+		repository = urlparser.Parse(fmt.Sprintf("https://github.com/%s/%s", gh.Owner, gh.Name))
+	}
+
+	return &core.Package{
+		Name:          name,
+		Description:   resp.Description,
+		Homepage:      fmt.Sprintf("%s/@%s/%s", jsrSiteURL, scope, pkg),
+		Repository:    repository,
+		Namespace:     scope,
+		LatestVersion: resp.LatestVersion,
+		Metadata: map[string]any{
+			"registry": "jsr",
+			"score":    resp.Score,
+		},
+	}, nil
+}
+
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
-	url := fmt.Sprintf("%s/v2/modules/%s", r.baseURL, name)
+	if scope, pkg, ok := splitJSRName(name); ok {
+		return r.fetchJSRVersions(ctx, name, scope, pkg)
+	}
 
-	var resp moduleInfoResponse
+	url := fmt.Sprintf("%s/%s/meta/versions.json", r.cdnURL, name)
+
+	var resp versionsResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
@@ -135,11 +240,73 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 	return versions, nil
 }
 
+func (r *Registry) fetchJSRVersions(ctx context.Context, name, scope, pkg string) ([]core.Version, error) {
+	url := fmt.Sprintf("%s/scopes/%s/packages/%s/versions", r.jsrURL, scope, pkg)
+
+	var resp []jsrVersion
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	versions := make([]core.Version, 0, len(resp))
+	for _, v := range resp {
+		status := core.StatusNone
+		if v.Yanked {
+			status = core.StatusYanked
+		}
+		versions = append(versions, core.Version{
+			Number:      v.Version,
+			PublishedAt: v.CreatedAt,
+			Status:      status,
+		})
+	}
+
+	return versions, nil
+}
+
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
-	// Deno modules use URL imports, not a manifest file
-	// Dependencies are determined by analyzing the source code
-	// The API doesn't expose a dependency list directly
-	return nil, nil
+	scope, pkg, ok := splitJSRName(name)
+	if !ok {
+		// deno.land/x modules use URL imports, not a manifest file.
+		// Dependencies are determined by analyzing the source code and
+		// the API doesn't expose a dependency list directly.
+		return nil, nil
+	}
+
+	url := fmt.Sprintf("%s/scopes/%s/packages/%s/versions/%s/dependencies", r.jsrURL, scope, pkg, version)
+
+	var resp []jsrDependency
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+
+	// JSR lists one entry per imported path, so the same package can repeat
+	seen := make(map[string]bool)
+	var deps []core.Dependency
+	for _, d := range resp {
+		depName := d.Name
+		if d.Kind == "npm" {
+			depName = "npm:" + d.Name
+		}
+		if seen[depName] {
+			continue
+		}
+		seen[depName] = true
+
+		deps = append(deps, core.Dependency{
+			Name:         depName,
+			Requirements: d.Constraint,
+			Scope:        core.Runtime,
+		})
+	}
+
+	return deps, nil
 }
 
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
@@ -153,6 +320,12 @@ type URLs struct {
 }
 
 func (u *URLs) Registry(name, version string) string {
+	if scope, pkg, ok := splitJSRName(name); ok {
+		if version != "" {
+			return fmt.Sprintf("%s/@%s/%s@%s", jsrSiteURL, scope, pkg, version)
+		}
+		return fmt.Sprintf("%s/@%s/%s", jsrSiteURL, scope, pkg)
+	}
 	if version != "" {
 		return fmt.Sprintf("https://deno.land/x/%s@%s", name, version)
 	}
@@ -163,16 +336,28 @@ func (u *URLs) Download(name, version string) string {
 	if version == "" {
 		return ""
 	}
+	if _, _, ok := splitJSRName(name); ok {
+		// JSR serves individual files, not archives
+		return ""
+	}
 	return fmt.Sprintf("https://deno.land/x/%s@%s/mod.ts", name, version)
 }
 
 func (u *URLs) Documentation(name, version string) string {
+	if scope, pkg, ok := splitJSRName(name); ok {
+		if version != "" {
+			return fmt.Sprintf("%s/@%s/%s@%s/doc", jsrSiteURL, scope, pkg, version)
+		}
+		return fmt.Sprintf("%s/@%s/%s/doc", jsrSiteURL, scope, pkg)
+	}
 	if version != "" {
 		return fmt.Sprintf("https://deno.land/x/%s@%s", name, version)
 	}
 	return fmt.Sprintf("https://deno.land/x/%s", name)
 }
 
+// PURL returns pkg:deno/<mod> for deno.land/x modules. JSR packages keep
+// their scope as the namespace, like npm: pkg:deno/@std/path.
 func (u *URLs) PURL(name, version string) string {
 	if version != "" {
 		return fmt.Sprintf("pkg:deno/%s@%s", name, version)
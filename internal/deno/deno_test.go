@@ -54,15 +54,20 @@ func TestFetchPackage(t *testing.T) {
 
 func TestFetchVersions(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		resp := moduleInfoResponse{
-			Name:     "std",
+		if r.URL.Path != "/std/meta/versions.json" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		resp := versionsResponse{
+			Latest:   "0.210.0",
 			Versions: []string{"0.210.0", "0.209.0", "0.208.0", "0.207.0"},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
 	}))
 	defer server.Close()
 
-	reg := New(server.URL, core.DefaultClient())
+	reg := New(server.URL, core.DefaultClient()).WithCDNURL(server.URL)
 	versions, err := reg.FetchVersions(context.Background(), "std")
 	if err != nil {
 		t.Fatalf("FetchVersions failed: %v", err)
@@ -77,6 +82,116 @@ func TestFetchVersions(t *testing.T) {
 	}
 }
 
+func TestFetchJSRPackage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/scopes/std/packages/path" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"scope": "std",
+			"name": "path",
+			"description": "Utilities for working with file system paths",
+			"latestVersion": "1.0.8",
+			"score": 100,
+			"githubRepository": {"owner": "denoland", "name": "std"}
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New("", core.DefaultClient()).WithJSRURL(server.URL)
+	pkg, err := reg.FetchPackage(context.Background(), "@std/path")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "@std/path" {
+		t.Errorf("expected name '@std/path', got %q", pkg.Name)
+	}
+	if pkg.Namespace != "std" {
+		t.Errorf("expected namespace 'std', got %q", pkg.Namespace)
+	}
+	if pkg.Description != "Utilities for working with file system paths" {
+		t.Errorf("unexpected description: %q", pkg.Description)
+	}
+	if pkg.Repository != "https://github.com/denoland/std" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	if pkg.Homepage != "https://jsr.io/@std/path" {
+		t.Errorf("unexpected homepage: %q", pkg.Homepage)
+	}
+	if pkg.LatestVersion != "1.0.8" {
+		t.Errorf("expected latest version '1.0.8', got %q", pkg.LatestVersion)
+	}
+}
+
+func TestFetchJSRVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/scopes/std/packages/path/versions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"version": "1.0.8", "yanked": false, "createdAt": "2024-10-29T12:00:00Z"},
+			{"version": "1.0.7", "yanked": true, "createdAt": "2024-10-20T12:00:00Z"}
+		]`))
+	}))
+	defer server.Close()
+
+	reg := New("", core.DefaultClient()).WithJSRURL(server.URL)
+	versions, err := reg.FetchVersions(context.Background(), "@std/path")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if versions[0].Number != "1.0.8" || versions[0].PublishedAt.IsZero() {
+		t.Errorf("unexpected first version: %+v", versions[0])
+	}
+	if versions[1].Status != core.StatusYanked {
+		t.Errorf("expected 1.0.7 to be yanked, got %q", versions[1].Status)
+	}
+}
+
+func TestFetchJSRDependencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/scopes/std/packages/http/versions/1.0.9/dependencies" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"kind": "jsr", "name": "@std/path", "constraint": "^1.0.8", "path": "posix/join"},
+			{"kind": "jsr", "name": "@std/path", "constraint": "^1.0.8", "path": "windows/join"},
+			{"kind": "npm", "name": "chalk", "constraint": "^5.3.0", "path": ""}
+		]`))
+	}))
+	defer server.Close()
+
+	reg := New("", core.DefaultClient()).WithJSRURL(server.URL)
+	deps, err := reg.FetchDependencies(context.Background(), "@std/http", "1.0.9")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	if len(deps) != 2 {
+		t.Fatalf("expected 2 dependencies, got %d", len(deps))
+	}
+	if deps[0].Name != "@std/path" || deps[0].Requirements != "^1.0.8" {
+		t.Errorf("unexpected first dependency: %+v", deps[0])
+	}
+	if deps[1].Name != "npm:chalk" {
+		t.Errorf("expected npm dependency 'npm:chalk', got %q", deps[1].Name)
+	}
+	if deps[1].Scope != core.Runtime {
+		t.Errorf("expected runtime scope, got %q", deps[1].Scope)
+	}
+}
+
 func TestFetchDependencies(t *testing.T) {
 	reg := New("", core.DefaultClient())
 	deps, err := reg.FetchDependencies(context.Background(), "oak", "12.6.1")
@@ -116,6 +231,10 @@ func TestURLBuilder(t *testing.T) {
 		{"download", func() string { return urls.Download("oak", "12.6.1") }, "https://deno.land/x/oak@12.6.1/mod.ts"},
 		{"documentation", func() string { return urls.Documentation("oak", "12.6.1") }, "https://deno.land/x/oak@12.6.1"},
 		{"purl", func() string { return urls.PURL("oak", "12.6.1") }, "pkg:deno/oak@12.6.1"},
+		{"jsr_registry", func() string { return urls.Registry("@std/path", "1.0.8") }, "https://jsr.io/@std/path@1.0.8"},
+		{"jsr_download", func() string { return urls.Download("@std/path", "1.0.8") }, ""},
+		{"jsr_documentation", func() string { return urls.Documentation("@std/path", "") }, "https://jsr.io/@std/path/doc"},
+		{"jsr_purl", func() string { return urls.PURL("@std/path", "1.0.8") }, "pkg:deno/@std/path@1.0.8"},
 	}
 
 	for _, tt := range tests {
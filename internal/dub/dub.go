@@ -4,6 +4,7 @@ package dub
 import (
 	"context"
 	"fmt"
+	pathpkg "path"
 	"sort"
 	"strings"
 	"time"
@@ -21,6 +22,10 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"configuration", "configurations", "documentation_url", "owner",
+		"sub_packages",
+	)
 }
 
 type Registry struct {
@@ -61,12 +66,92 @@ type packageResponse struct {
 }
 
 type versionInfo struct {
-	Version      string    `json:"version"`
-	Date         string    `json:"date"`
-	License      string    `json:"license"`
+	Version        string                 `json:"version"`
+	Date           string                 `json:"date"`
+	License        string                 `json:"license"`
+	Dependencies   map[string]interface{} `json:"dependencies"`
+	SubPackages    []interface{}          `json:"subPackages"`
+	Configurations []configuration        `json:"configurations"`
+}
+
+type configuration struct {
+	Name         string                 `json:"name"`
+	TargetType   string                 `json:"targetType"`
+	Platforms    []string               `json:"platforms"`
 	Dependencies map[string]interface{} `json:"dependencies"`
 }
 
+// subPackage is one entry of a recipe's subPackages. Entries are either an
+// inline recipe or a path to a directory with its own recipe, in which case
+// only the directory name is known.
+type subPackage struct {
+	Name           string
+	Path           string
+	Dependencies   map[string]interface{}
+	Configurations []configuration
+}
+
+func parseSubPackages(entries []interface{}) []subPackage {
+	var subs []subPackage
+	for _, entry := range entries {
+		switch e := entry.(type) {
+		case string:
+			path := strings.TrimSuffix(e, "/")
+			subs = append(subs, subPackage{Name: pathpkg.Base(path), Path: e})
+		case map[string]interface{}:
+			name, _ := e["name"].(string)
+			if name == "" {
+				continue
+			}
+			sub := subPackage{Name: name}
+			sub.Dependencies, _ = e["dependencies"].(map[string]interface{})
+			if configs, ok := e["configurations"].([]interface{}); ok {
+				for _, c := range configs {
+					if m, ok := c.(map[string]interface{}); ok {
+						config := configuration{}
+						config.Name, _ = m["name"].(string)
+						config.TargetType, _ = m["targetType"].(string)
+						config.Dependencies, _ = m["dependencies"].(map[string]interface{})
+						sub.Configurations = append(sub.Configurations, config)
+					}
+				}
+			}
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// splitSubPackage splits "package:subpackage" into its parts.
+func splitSubPackage(name string) (pkg, sub string) {
+	pkg, sub, _ = strings.Cut(name, ":")
+	return pkg, sub
+}
+
+func subPackageNames(subs []subPackage) []string {
+	names := make([]string, 0, len(subs))
+	for _, s := range subs {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func configurationNames(configs []configuration) []string {
+	names := make([]string, 0, len(configs))
+	for _, c := range configs {
+		if c.Name != "" {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// isBranchVersion reports whether v is a branch build like "~master"
+// rather than a tagged release.
+func isBranchVersion(v string) bool {
+	return strings.HasPrefix(v, "~")
+}
+
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
 	url := fmt.Sprintf("%s/api/packages/%s", r.baseURL, name)
 
@@ -84,26 +169,59 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		license = resp.Versions[0].License
 	}
 
+	// Branch builds like ~master are listed alongside tagged releases
+	versions := sortVersions(resp.Versions)
+	var latest string
+	var latestInfo versionInfo
+	for _, v := range versions {
+		if !isBranchVersion(v.Version) {
+			latest = v.Version
+			latestInfo = v
+			break
+		}
+	}
+
 	// Extract repository URL
-	repository := urlparser.Parse(resp.Repository)
+	repository := core.NormalizeRepository(resp.Repository)
 	if repository == "" {
 		repository = urlparser.Parse(resp.Homepage)
 	}
 
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Description,
-		Homepage:    resp.Homepage,
-		Repository:  repository,
-		Licenses:    license,
-		Keywords:    resp.Categories,
+		Name:          resp.Name,
+		Description:   resp.Description,
+		Homepage:      resp.Homepage,
+		Repository:    repository,
+		Licenses:      license,
+		Keywords:      core.NormalizeKeywords(resp.Categories),
+		LatestVersion: latest,
 		Metadata: map[string]any{
-			"owner":            resp.Owner,
+			"owner":             resp.Owner,
 			"documentation_url": resp.DocumentationURL,
+			"sub_packages":      subPackageNames(parseSubPackages(latestInfo.SubPackages)),
+			"configurations":    configurationNames(latestInfo.Configurations),
 		},
 	}, nil
 }
 
+// sortVersions returns the versions newest first. Tagged releases are
+// compared as semver, and branch builds like "~master" follow them in the
+// order the API lists them, so results don't depend on the API's order.
+func sortVersions(infos []versionInfo) []versionInfo {
+	var releases, branches []versionInfo
+	for _, v := range infos {
+		if isBranchVersion(v.Version) {
+			branches = append(branches, v)
+		} else {
+			releases = append(releases, v)
+		}
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		return core.CompareVersions(ecosystem, releases[i].Version, releases[j].Version) > 0
+	})
+	return append(releases, branches...)
+}
+
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
 	url := fmt.Sprintf("%s/api/packages/%s", r.baseURL, name)
 
@@ -116,7 +234,7 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 	}
 
 	versions := make([]core.Version, 0, len(resp.Versions))
-	for _, v := range resp.Versions {
+	for _, v := range sortVersions(resp.Versions) {
 		var publishedAt time.Time
 		if v.Date != "" {
 			// Try parsing ISO format
@@ -130,14 +248,22 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 			Number:      v.Version,
 			PublishedAt: publishedAt,
 			Licenses:    v.License,
+			Metadata: map[string]any{
+				"sub_packages":   subPackageNames(parseSubPackages(v.SubPackages)),
+				"configurations": configurationNames(v.Configurations),
+			},
 		})
 	}
 
 	return versions, nil
 }
 
+// FetchDependencies returns the dependencies of a package, or of a
+// subpackage given as "package:subpackage". Dependencies that only apply to
+// one configuration are included with Metadata["configuration"] set.
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
-	url := fmt.Sprintf("%s/api/packages/%s", r.baseURL, name)
+	pkgName, subName := splitSubPackage(name)
+	url := fmt.Sprintf("%s/api/packages/%s", r.baseURL, pkgName)
 
 	var resp packageResponse
 	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
@@ -160,34 +286,90 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
 	}
 
-	var deps []core.Dependency
-	for depName, constraint := range targetVersion.Dependencies {
-		var requirements string
-		switch c := constraint.(type) {
-		case string:
-			requirements = c
-		case map[string]interface{}:
-			// Complex dependency with version field
-			if v, ok := c["version"].(string); ok {
-				requirements = v
+	dependencies := targetVersion.Dependencies
+	configurations := targetVersion.Configurations
+	if subName != "" {
+		var sub *subPackage
+		for _, s := range parseSubPackages(targetVersion.SubPackages) {
+			if s.Name == subName {
+				sub = &s
+				break
 			}
 		}
+		if sub == nil {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		dependencies = sub.Dependencies
+		configurations = sub.Configurations
+	}
 
+	var deps []core.Dependency
+	seen := make(map[string]bool)
+	for depName, constraint := range dependencies {
+		depName = qualifyDependency(pkgName, depName)
+		seen[depName] = true
 		deps = append(deps, core.Dependency{
 			Name:         depName,
-			Requirements: requirements,
+			Requirements: requirementOf(constraint),
 			Scope:        core.Runtime,
 		})
 	}
 
+	for _, config := range configurations {
+		for depName, constraint := range config.Dependencies {
+			depName = qualifyDependency(pkgName, depName)
+			if seen[depName] {
+				continue
+			}
+			deps = append(deps, core.Dependency{
+				Name:         depName,
+				Requirements: requirementOf(constraint),
+				Scope:        core.Runtime,
+				Metadata:     map[string]any{"configuration": config.Name},
+			})
+		}
+	}
+
 	// Sort for consistent output
 	sort.Slice(deps, func(i, j int) bool {
-		return deps[i].Name < deps[j].Name
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return configurationOf(deps[i]) < configurationOf(deps[j])
 	})
 
 	return deps, nil
 }
 
+// requirementOf returns the version constraint of a dependency, which is
+// either a string or an object with a version field (or a path or
+// repository instead).
+func requirementOf(constraint interface{}) string {
+	switch c := constraint.(type) {
+	case string:
+		return c
+	case map[string]interface{}:
+		if v, ok := c["version"].(string); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// qualifyDependency expands a sibling subpackage reference like ":core" to
+// "vibe-d:core".
+func qualifyDependency(pkgName, depName string) string {
+	if strings.HasPrefix(depName, ":") {
+		return pkgName + depName
+	}
+	return depName
+}
+
+func configurationOf(d core.Dependency) string {
+	config, _ := d.Metadata["configuration"].(string)
+	return config
+}
+
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
 	url := fmt.Sprintf("%s/api/packages/%s", r.baseURL, name)
 
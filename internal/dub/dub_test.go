@@ -54,6 +54,9 @@ func TestFetchPackage(t *testing.T) {
 	if len(pkg.Keywords) != 2 {
 		t.Errorf("expected 2 keywords, got %d", len(pkg.Keywords))
 	}
+	if pkg.LatestVersion != "0.9.5" {
+		t.Errorf("expected latest version '0.9.5', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -135,6 +138,135 @@ func TestFetchDependencies(t *testing.T) {
 	}
 }
 
+const vibeDFixture = `{
+	"name": "vibe-d",
+	"description": "Event driven web and concurrency framework",
+	"repository": "https://github.com/vibe-d/vibe.d",
+	"owner": "sludwig",
+	"versions": [
+		{"version": "~master", "date": "2024-03-01T00:00:00Z"},
+		{"version": "0.9.8", "date": "2024-02-01T00:00:00Z", "license": "MIT",
+			"dependencies": {
+				"vibe-d:http": "*",
+				":core": "*",
+				"vibe-core": "~>2.7"
+			},
+			"configurations": [
+				{"name": "vibe-core", "targetType": "library"},
+				{"name": "libevent", "targetType": "library", "dependencies": {"libevent": "~>2.0.2", "vibe-core": "~>2.7"}},
+				{"name": "win32", "targetType": "library", "platforms": ["windows"]}
+			],
+			"subPackages": [
+				"./utils/",
+				{"name": "core", "dependencies": {"eventcore": "~>0.9.27"}},
+				{"name": "http",
+					"dependencies": {":core": "*", "diet-ng": "~>1.8"},
+					"configurations": [
+						{"name": "tls", "dependencies": {"openssl": {"version": "~>3.3"}}}
+					]
+				}
+			]
+		},
+		{"version": "0.10.0", "date": "2024-05-01T00:00:00Z", "license": "MIT"},
+		{"version": "0.9.10", "date": "2024-04-01T00:00:00Z", "license": "MIT"}
+	]
+}`
+
+func newVibeDServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/packages/vibe-d" {
+			w.WriteHeader(404)
+			return
+		}
+		_, _ = w.Write([]byte(vibeDFixture))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchVersionsSorted(t *testing.T) {
+	reg := New(newVibeDServer(t).URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "vibe-d")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	expected := []string{"0.10.0", "0.9.10", "0.9.8", "~master"}
+	if len(versions) != len(expected) {
+		t.Fatalf("expected %d versions, got %d", len(expected), len(versions))
+	}
+	for i, v := range versions {
+		if v.Number != expected[i] {
+			t.Errorf("expected version %d to be %q, got %q", i, expected[i], v.Number)
+		}
+	}
+
+	configs, _ := versions[2].Metadata["configurations"].([]string)
+	if len(configs) != 3 || configs[0] != "vibe-core" || configs[1] != "libevent" || configs[2] != "win32" {
+		t.Errorf("unexpected configurations: %v", configs)
+	}
+	subs, _ := versions[2].Metadata["sub_packages"].([]string)
+	if len(subs) != 3 || subs[0] != "utils" || subs[1] != "core" || subs[2] != "http" {
+		t.Errorf("unexpected sub packages: %v", subs)
+	}
+}
+
+func TestFetchPackageLatestRelease(t *testing.T) {
+	reg := New(newVibeDServer(t).URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "vibe-d")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "0.10.0" {
+		t.Errorf("expected latest version '0.10.0', got %q", pkg.LatestVersion)
+	}
+}
+
+func TestFetchDependenciesConfigurationsAndSubPackages(t *testing.T) {
+	reg := New(newVibeDServer(t).URL, core.DefaultClient())
+	ctx := context.Background()
+
+	deps, err := reg.FetchDependencies(ctx, "vibe-d", "0.9.8")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	// vibe-core is declared at the top level too, so only libevent is
+	// configuration-specific
+	expected := []struct{ name, config string }{
+		{"libevent", "libevent"},
+		{"vibe-core", ""},
+		{"vibe-d:core", ""},
+		{"vibe-d:http", ""},
+	}
+	if len(deps) != len(expected) {
+		t.Fatalf("expected %d dependencies, got %d: %+v", len(expected), len(deps), deps)
+	}
+	for i, e := range expected {
+		config, _ := deps[i].Metadata["configuration"].(string)
+		if deps[i].Name != e.name || config != e.config {
+			t.Errorf("expected dependency %d to be %s (%q), got %s (%q)", i, e.name, e.config, deps[i].Name, config)
+		}
+	}
+
+	deps, err = reg.FetchDependencies(ctx, "vibe-d:http", "0.9.8")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 3 {
+		t.Fatalf("expected 3 dependencies, got %d: %+v", len(deps), deps)
+	}
+	if deps[0].Name != "diet-ng" || deps[1].Name != "openssl" || deps[2].Name != "vibe-d:core" {
+		t.Errorf("unexpected dependencies: %+v", deps)
+	}
+	if deps[1].Requirements != "~>3.3" || deps[1].Metadata["configuration"] != "tls" {
+		t.Errorf("unexpected openssl dependency: %+v", deps[1])
+	}
+
+	if _, err := reg.FetchDependencies(ctx, "vibe-d:missing", "0.9.8"); err == nil {
+		t.Error("expected an error for an unknown sub package")
+	}
+}
+
 func TestFetchMaintainers(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := packageResponse{
@@ -2,7 +2,10 @@
 package elm
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -21,6 +24,9 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"elm_version", "exposed_modules", "type",
+	)
 }
 
 type Registry struct {
@@ -71,12 +77,107 @@ type elmJson struct {
 	Summary         string            `json:"summary"`
 	License         string            `json:"license"`
 	Version         string            `json:"version"`
-	ExposedModules  interface{}       `json:"exposed-modules"`
+	ExposedModules  json.RawMessage   `json:"exposed-modules"`
 	ElmVersion      string            `json:"elm-version"`
 	Dependencies    map[string]string `json:"dependencies"`
 	TestDependencies map[string]string `json:"test-dependencies"`
 }
 
+// ErrApplication is returned when an elm.json describes an application
+// rather than a package. Applications can't be published, so one turning
+// up means the registry or a mirror served the wrong file; its fields
+// (direct and indirect dependencies, source directories) don't fit a
+// package's.
+var ErrApplication = errors.New("elm.json describes an application, not a package")
+
+// fetchElmJSON reads a version's elm.json, checking its type before
+// decoding the package fields, since an application's dependencies are
+// nested objects that would otherwise fail to decode with a less useful
+// error.
+func (r *Registry) fetchElmJSON(ctx context.Context, author, pkgName, version string) (*elmJson, error) {
+	elmJsonURL := fmt.Sprintf("%s/packages/%s/%s/%s/elm.json", r.baseURL, author, pkgName, version)
+	body, err := r.client.GetBody(ctx, elmJsonURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var kind struct {
+		Type string `json:"type"`
+	}
+	if err := core.DecodeJSON(ecosystem, elmJsonURL, body, &kind); err != nil {
+		return nil, err
+	}
+	if kind.Type == "application" {
+		return nil, fmt.Errorf("%s: %s/%s %s: %w", ecosystem, author, pkgName, version, ErrApplication)
+	}
+
+	var elmInfo elmJson
+	if err := core.DecodeJSON(ecosystem, elmJsonURL, body, &elmInfo); err != nil {
+		return nil, err
+	}
+	return &elmInfo, nil
+}
+
+// exposedModules normalizes the exposed-modules field of elm.json into a
+// flat list of module names. Packages either list modules directly or group
+// them under headings, as in {"Primitives": ["Json.Decode"]}; groups keep
+// the order they appear in. Entries that aren't valid module names are
+// dropped, and nil is returned if the field is missing or malformed.
+func exposedModules(raw json.RawMessage) []string {
+	var flat []string
+	if err := json.Unmarshal(raw, &flat); err == nil {
+		return validModules(flat)
+	}
+
+	// Decode the object token by token, since a map would lose the order of
+	// the groups.
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	var modules []string
+	for dec.More() {
+		if _, err := dec.Token(); err != nil {
+			return nil
+		}
+		var group []string
+		if err := dec.Decode(&group); err != nil {
+			return nil
+		}
+		modules = append(modules, group...)
+	}
+	return validModules(modules)
+}
+
+// validModules keeps the names that look like Elm modules ("Json.Decode"):
+// dot-separated identifiers that each start with an upper case letter.
+func validModules(names []string) []string {
+	var modules []string
+	for _, name := range names {
+		if isModuleName(name) {
+			modules = append(modules, name)
+		}
+	}
+	return modules
+}
+
+func isModuleName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, part := range strings.Split(name, ".") {
+		if part == "" || part[0] < 'A' || part[0] > 'Z' {
+			return false
+		}
+		for _, c := range part {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
 	author, pkgName := parsePackageName(name)
 	if author == "" {
@@ -104,22 +205,24 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	// Get elm.json for the latest version
-	elmJsonURL := fmt.Sprintf("%s/packages/%s/%s/%s/elm.json", r.baseURL, author, pkgName, latestVersion)
-	var elmInfo elmJson
-	if err := r.client.GetJSON(ctx, elmJsonURL, &elmInfo); err != nil {
+	elmInfo, err := r.fetchElmJSON(ctx, author, pkgName, latestVersion)
+	if err != nil {
 		return nil, err
 	}
 
 	return &core.Package{
-		Name:        name,
-		Description: elmInfo.Summary,
-		Homepage:    fmt.Sprintf("https://package.elm-lang.org/packages/%s/%s/latest", author, pkgName),
-		Repository:  urlparser.Parse(fmt.Sprintf("https://github.com/%s/%s", author, pkgName)),
-		Licenses:    elmInfo.License,
-		Namespace:   author,
+		Name:          name,
+		Summary:       elmInfo.Summary,
+		Description:   elmInfo.Summary,
+		Homepage:      fmt.Sprintf("https://package.elm-lang.org/packages/%s/%s/latest", author, pkgName),
+		Repository:    urlparser.Parse(fmt.Sprintf("https://github.com/%s/%s", author, pkgName)),
+		Licenses:      elmInfo.License,
+		Namespace:     author,
+		LatestVersion: latestVersion,
 		Metadata: map[string]any{
-			"elm_version": elmInfo.ElmVersion,
-			"type":        elmInfo.Type,
+			"elm_version":     elmInfo.ElmVersion,
+			"type":            elmInfo.Type,
+			"exposed_modules": exposedModules(elmInfo.ExposedModules),
 		},
 	}, nil
 }
@@ -169,9 +272,8 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		return nil, fmt.Errorf("elm package name must be in format 'author/name'")
 	}
 
-	elmJsonURL := fmt.Sprintf("%s/packages/%s/%s/%s/elm.json", r.baseURL, author, pkgName, version)
-	var elmInfo elmJson
-	if err := r.client.GetJSON(ctx, elmJsonURL, &elmInfo); err != nil {
+	elmInfo, err := r.fetchElmJSON(ctx, author, pkgName, version)
+	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
 		}
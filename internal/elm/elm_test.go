@@ -3,6 +3,7 @@ package elm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -24,12 +25,13 @@ func TestFetchPackage(t *testing.T) {
 
 	mux.HandleFunc("/packages/elm/json/1.1.3/elm.json", func(w http.ResponseWriter, r *http.Request) {
 		elmJson := map[string]interface{}{
-			"type":        "package",
-			"name":        "elm/json",
-			"summary":     "Encode and decode JSON values",
-			"license":     "BSD-3-Clause",
-			"version":     "1.1.3",
-			"elm-version": "0.19.0 <= v < 0.20.0",
+			"type":            "package",
+			"name":            "elm/json",
+			"summary":         "Encode and decode JSON values",
+			"license":         "BSD-3-Clause",
+			"version":         "1.1.3",
+			"elm-version":     "0.19.0 <= v < 0.20.0",
+			"exposed-modules": []string{"Json.Decode", "Json.Encode"},
 			"dependencies": map[string]string{
 				"elm/core": "1.0.0 <= v < 2.0.0",
 			},
@@ -61,6 +63,42 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Repository != "https://github.com/elm/json" {
 		t.Errorf("unexpected repository: %q", pkg.Repository)
 	}
+	if pkg.LatestVersion != "1.1.3" {
+		t.Errorf("expected latest version '1.1.3', got %q", pkg.LatestVersion)
+	}
+	modules, _ := pkg.Metadata["exposed_modules"].([]string)
+	if len(modules) != 2 || modules[0] != "Json.Decode" || modules[1] != "Json.Encode" {
+		t.Errorf("unexpected exposed modules: %v", modules)
+	}
+}
+
+func TestExposedModules(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected []string
+	}{
+		{"array", `["Json.Decode", "Json.Encode"]`, []string{"Json.Decode", "Json.Encode"}},
+		{"object", `{"Primitives": ["Html", "Html.Attributes"], "Events": ["Html.Events"]}`, []string{"Html", "Html.Attributes", "Html.Events"}},
+		{"invalid names", `["Json.Decode", "json.encode", "", "Json..Value"]`, []string{"Json.Decode"}},
+		{"missing", ``, nil},
+		{"malformed", `{"Primitives": "Html"}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := exposedModules(json.RawMessage(tt.raw))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -136,6 +174,38 @@ func TestFetchDependencies(t *testing.T) {
 	}
 }
 
+func TestFetchApplication(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/packages/someone/app/releases.json":
+			_, _ = w.Write([]byte(`{"1.0.0": 1600000000}`))
+		case "/packages/someone/app/1.0.0/elm.json":
+			_, _ = w.Write([]byte(`{
+				"type": "application",
+				"source-directories": ["src"],
+				"elm-version": "0.19.1",
+				"dependencies": {
+					"direct": {"elm/core": "1.0.5"},
+					"indirect": {"elm/json": "1.1.3"}
+				},
+				"test-dependencies": {"direct": {}, "indirect": {}}
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+
+	if _, err := reg.FetchPackage(context.Background(), "someone/app"); !errors.Is(err, ErrApplication) {
+		t.Errorf("expected ErrApplication from FetchPackage, got %v", err)
+	}
+	if _, err := reg.FetchDependencies(context.Background(), "someone/app", "1.0.0"); !errors.Is(err, ErrApplication) {
+		t.Errorf("expected ErrApplication from FetchDependencies, got %v", err)
+	}
+}
+
 func TestFetchMaintainers(t *testing.T) {
 	reg := New("", nil)
 	maintainers, err := reg.FetchMaintainers(context.Background(), "elm/json")
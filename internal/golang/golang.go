@@ -3,7 +3,6 @@ package golang
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -91,20 +90,39 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	// The repository URL is typically derived from the module path
 	repoURL := urlparser.Parse(deriveRepoURL(name))
 
-	parts := strings.Split(name, "/")
-	namespace := ""
-	if len(parts) > 1 {
-		namespace = strings.Join(parts[:len(parts)-1], "/")
-	}
+	namespace, _ := core.SplitName(ecosystem, name)
 
 	return &core.Package{
-		Name:       name,
-		Repository: repoURL,
-		Homepage:   repoURL,
-		Namespace:  namespace,
+		Name:          name,
+		Repository:    repoURL,
+		Homepage:      repoURL,
+		Namespace:     namespace,
+		LatestVersion: latestFromList(r, body),
 	}, nil
 }
 
+// latestFromList picks the highest release from a proxy @v/list body,
+// falling back to the highest prerelease when there are no releases.
+func latestFromList(r *Registry, list string) string {
+	var versions []core.Version
+	for _, line := range strings.Split(list, "\n") {
+		if v := strings.TrimSpace(line); v != "" {
+			versions = append(versions, core.Version{Number: v})
+		}
+	}
+
+	sorted := core.SortedVersions(r, versions)
+	for _, v := range sorted {
+		if !strings.Contains(v.Number, "-") {
+			return v.Number
+		}
+	}
+	if len(sorted) > 0 {
+		return sorted[0].Number
+	}
+	return ""
+}
+
 func deriveRepoURL(modulePath string) string {
 	// Common hosting platforms
 	if strings.HasPrefix(modulePath, "github.com/") ||
@@ -276,33 +294,13 @@ func (u *URLs) Documentation(name, version string) string {
 	return fmt.Sprintf("https://pkg.go.dev/%s#section-documentation", name)
 }
 
+// PURL keeps the module path's case. The "!" escaping of capitals is only
+// for proxy URLs; in a PURL it would name a different module.
 func (u *URLs) PURL(name, version string) string {
-	encoded := encodeForProxy(name)
-	parts := strings.Split(name, "/")
-	namespace := ""
-	var pkgName string
-
-	if len(parts) > 1 {
-		namespace = strings.Join(parts[:len(parts)-1], "/")
-		pkgName = parts[len(parts)-1]
-		// Encode the namespace for PURL
-		namespace = encodeForProxy(namespace)
-		pkgName = encodeForProxy(pkgName)
-	} else {
-		pkgName = encoded
-	}
-
-	if namespace != "" {
-		if version != "" {
-			return fmt.Sprintf("pkg:golang/%s/%s@%s", namespace, pkgName, version)
-		}
-		return fmt.Sprintf("pkg:golang/%s/%s", namespace, pkgName)
-	}
-
 	if version != "" {
-		return fmt.Sprintf("pkg:golang/%s@%s", pkgName, version)
+		return fmt.Sprintf("pkg:golang/%s@%s", name, version)
 	}
-	return fmt.Sprintf("pkg:golang/%s", pkgName)
+	return fmt.Sprintf("pkg:golang/%s", name)
 }
 
 // LatestVersion fetches the latest version of a module.
@@ -316,7 +314,7 @@ func (r *Registry) LatestVersion(ctx context.Context, name string) (string, erro
 	}
 
 	var info versionInfo
-	if err := json.Unmarshal(body, &info); err != nil {
+	if err := core.DecodeJSON(ecosystem, latestURL, body, &info); err != nil {
 		return "", err
 	}
 
@@ -14,7 +14,7 @@ import (
 func TestFetchPackage(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/github.com/gorilla/mux/@v/list" {
-			_, _ = w.Write([]byte("v1.8.0\nv1.7.0\n"))
+			_, _ = w.Write([]byte("v1.7.0\nv1.10.0-rc.1\nv1.8.0\n"))
 			return
 		}
 		w.WriteHeader(404)
@@ -36,6 +36,9 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Namespace != "github.com/gorilla" {
 		t.Errorf("unexpected namespace: %q", pkg.Namespace)
 	}
+	if pkg.LatestVersion != "v1.8.0" {
+		t.Errorf("expected latest version 'v1.8.0', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchPackageNotFound(t *testing.T) {
@@ -11,18 +11,23 @@ import (
 	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
-	"github.com/git-pkgs/registries/internal/urlparser"
 )
 
 const (
 	DefaultURL = "https://hackage.haskell.org"
 	ecosystem  = "hackage"
+
+	// uploadTimeConcurrency limits the per-version upload time lookups.
+	uploadTimeConcurrency = 8
 )
 
 func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"author", "maintainer", "unpreferred",
+	)
 }
 
 type Registry struct {
@@ -116,23 +121,16 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 
 	cabal := parseCabalFile(string(cabalBody))
 
-	repository := urlparser.Parse(cabal.SourceRepository)
-
-	var keywords []string
-	if cabal.Category != "" {
-		keywords = strings.Split(cabal.Category, ",")
-		for i := range keywords {
-			keywords[i] = strings.TrimSpace(keywords[i])
-		}
-	}
+	repository := core.NormalizeRepository(cabal.SourceRepository)
 
 	return &core.Package{
-		Name:        name,
-		Description: cabal.Synopsis,
-		Homepage:    cabal.Homepage,
-		Repository:  repository,
-		Licenses:    cabal.License,
-		Keywords:    keywords,
+		Name:          name,
+		Description:   cabal.Synopsis,
+		Homepage:      cabal.Homepage,
+		Repository:    repository,
+		Licenses:      cabal.License,
+		Keywords:      core.NormalizeKeywords(strings.Split(cabal.Category, ",")),
+		LatestVersion: latestVersion,
 		Metadata: map[string]any{
 			"author":     cabal.Author,
 			"maintainer": cabal.Maintainer,
@@ -153,12 +151,23 @@ type cabalInfo struct {
 	SourceRepository string
 }
 
+// cabalFieldRegex matches a "field-name: value" line.
+var cabalFieldRegex = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*)\s*:(.*)$`)
+
+// indentation returns the number of leading spaces or tabs on a line.
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
 func parseCabalFile(content string) cabalInfo {
 	info := cabalInfo{}
 	lines := strings.Split(content, "\n")
 
+	// Cabal files are indentation-sensitive: top-level fields and section
+	// headers start in column 0, and anything indented belongs to the
+	// previous field or section.
+	var section string
 	var currentField string
-	var inSourceRepo bool
 
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
@@ -166,57 +175,31 @@ func parseCabalFile(content string) cabalInfo {
 			continue
 		}
 
-		// Check for source-repository section
-		if strings.HasPrefix(strings.ToLower(trimmed), "source-repository") {
-			inSourceRepo = true
-			continue
-		}
+		indent := indentation(line)
+		match := cabalFieldRegex.FindStringSubmatch(trimmed)
 
-		// Check for other sections that end source-repo parsing
-		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
-			if inSourceRepo && strings.Contains(trimmed, ":") {
-				field := strings.ToLower(strings.TrimSpace(strings.SplitN(trimmed, ":", 2)[0]))
-				if field != "location" && field != "type" && field != "branch" && field != "tag" {
-					inSourceRepo = false
-				}
+		if indent == 0 {
+			if match == nil {
+				// Section header: library, executable foo, source-repository head...
+				section = strings.ToLower(strings.Fields(trimmed)[0])
+				currentField = ""
+				continue
 			}
+			section = ""
 		}
 
-		if strings.Contains(trimmed, ":") {
-			parts := strings.SplitN(trimmed, ":", 2)
-			field := strings.ToLower(strings.TrimSpace(parts[0]))
-			value := ""
-			if len(parts) > 1 {
-				value = strings.TrimSpace(parts[1])
-			}
-
-			if inSourceRepo && field == "location" {
-				info.SourceRepository = value
-				continue
+		if section == "source-repository" {
+			// Keep the first location, which is normally the head repository
+			if match != nil && strings.ToLower(match[1]) == "location" && info.SourceRepository == "" {
+				info.SourceRepository = strings.TrimSpace(match[2])
 			}
+			continue
+		}
+		if section != "" {
+			continue
+		}
 
-			currentField = field
-			switch field {
-			case "name":
-				info.Name = value
-			case "version":
-				info.Version = value
-			case "synopsis":
-				info.Synopsis = value
-			case "description":
-				info.Description = value
-			case "license":
-				info.License = value
-			case "homepage":
-				info.Homepage = value
-			case "author":
-				info.Author = value
-			case "maintainer":
-				info.Maintainer = value
-			case "category":
-				info.Category = value
-			}
-		} else if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+		if indent > 0 {
 			// Continuation line
 			switch currentField {
 			case "description":
@@ -224,22 +207,54 @@ func parseCabalFile(content string) cabalInfo {
 			case "author":
 				info.Author += " " + trimmed
 			}
+			continue
+		}
+
+		field := strings.ToLower(match[1])
+		value := strings.TrimSpace(match[2])
+		currentField = field
+		switch field {
+		case "name":
+			info.Name = value
+		case "version":
+			info.Version = value
+		case "synopsis":
+			info.Synopsis = value
+		case "description":
+			info.Description = value
+		case "license":
+			info.License = value
+		case "homepage":
+			info.Homepage = value
+		case "author":
+			info.Author = value
+		case "maintainer":
+			info.Maintainer = value
+		case "category":
+			info.Category = value
 		}
 	}
 
 	return info
 }
 
+// parsePreferredVersions returns the normal versions listed in a
+// /preferred response, newest first.
 func parsePreferredVersions(content string) []string {
-	// Parse the preferred versions format
-	// Format: "normal-versions: 1.0.0, 2.0.0, ..."
+	return parseVersionField(content, "normal-versions")
+}
+
+// parseVersionField returns the versions listed on one field of a
+// /preferred response, newest first.
+// Format: "normal-versions: 1.0.0, 2.0.0, ..."
+func parseVersionField(content, field string) []string {
 	var versions []string
 
 	lines := strings.Split(content, "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "normal-versions:") {
-			versionStr := strings.TrimPrefix(line, "normal-versions:")
+		if strings.HasPrefix(line, field+":") {
+			versionStr := strings.TrimPrefix(line, field+":")
 			parts := strings.Split(versionStr, ",")
 			for _, p := range parts {
 				v := strings.TrimSpace(p)
@@ -294,31 +309,67 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		return nil, err
 	}
 
-	versionStrings := parsePreferredVersions(string(body))
+	// Deprecated versions are kept with their status, and unpreferred ones,
+	// which the solver avoids but can still pick, are flagged
+	content := string(body)
+	statuses := make(map[string]core.VersionStatus)
+	unpreferred := make(map[string]bool)
+	versionStrings := parsePreferredVersions(content)
+	for _, v := range parseVersionField(content, "deprecated-versions") {
+		statuses[v] = core.StatusDeprecated
+		versionStrings = append(versionStrings, v)
+	}
+	for _, v := range parseVersionField(content, "unpreferred-versions") {
+		unpreferred[v] = true
+		versionStrings = append(versionStrings, v)
+	}
 	if len(versionStrings) == 0 {
 		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
 	}
+	sort.SliceStable(versionStrings, func(i, j int) bool {
+		return compareVersions(versionStrings[i], versionStrings[j]) > 0
+	})
+
+	// Hackage has no bulk endpoint for upload times, so each version costs
+	// a request; versions whose time can't be read are left undated
+	uploaded, _ := core.ParallelSlice(ctx, versionStrings, uploadTimeConcurrency, func(ctx context.Context, v string) (*time.Time, error) {
+		return r.fetchUploadTime(ctx, name, v)
+	})
 
-	// Fetch upload times for each version
 	versions := make([]core.Version, len(versionStrings))
 	for i, v := range versionStrings {
-		versions[i] = core.Version{Number: v}
-
-		// Try to get upload info
-		uploadURL := fmt.Sprintf("%s/package/%s-%s/upload-time", r.baseURL, name, v)
-		uploadBody, err := r.client.GetBody(ctx, uploadURL)
-		if err == nil {
-			// Parse the upload time (format: "2023-10-15T12:00:00Z")
-			timeStr := strings.TrimSpace(string(uploadBody))
-			if t, err := time.Parse(time.RFC3339, timeStr); err == nil {
-				versions[i].PublishedAt = t
-			}
+		versions[i] = core.Version{Number: v, Status: statuses[v]}
+		if unpreferred[v] {
+			versions[i].Metadata = map[string]any{"unpreferred": true}
+		}
+		if uploaded[i] != nil {
+			versions[i].PublishedAt = *uploaded[i]
 		}
 	}
 
 	return versions, nil
 }
 
+// uploadTimeLayouts are the formats /upload-time has been served in: the
+// "%c" rendering Hackage uses ("Mon Oct 23 08:23:52 UTC 2023") and RFC 3339.
+var uploadTimeLayouts = []string{time.UnixDate, time.RFC3339}
+
+func (r *Registry) fetchUploadTime(ctx context.Context, name, version string) (*time.Time, error) {
+	uploadURL := fmt.Sprintf("%s/package/%s-%s/upload-time", r.baseURL, name, version)
+	body, err := r.client.GetBody(ctx, uploadURL)
+	if err != nil {
+		return nil, err
+	}
+	timeStr := strings.TrimSpace(string(body))
+	for _, layout := range uploadTimeLayouts {
+		if t, err := time.Parse(layout, timeStr); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("unrecognized upload time %q", timeStr)
+}
+
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
 	// Fetch the cabal file
 	cabalURL := fmt.Sprintf("%s/package/%s-%s/%s.cabal", r.baseURL, name, version, name)
@@ -334,98 +385,177 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 	return deps, nil
 }
 
+// sectionScopes maps cabal stanzas to the scope of their build-depends.
+// Stanzas not listed here (flag, source-repository) have no dependencies.
+var sectionScopes = map[string]core.Scope{
+	"":                core.Runtime, // old-style top-level build-depends
+	"library":         core.Runtime,
+	"executable":      core.Runtime,
+	"foreign-library": core.Runtime,
+	"common":          core.Runtime,
+	"test-suite":      core.Test,
+	"benchmark":       core.Development,
+	"custom-setup":    core.Build,
+}
+
+// depItemRegex matches a single dependency such as "text >=1.2 && <2.1" or
+// "aeson:{aeson, attoparsec-aeson} ^>=2.2".
+var depItemRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_-]*)(?::(?:\{[^}]*\}|[A-Za-z0-9_-]+))?\s*(.*)$`)
+
 func parseDependencies(content string) []core.Dependency {
 	var deps []core.Dependency
-	seen := make(map[string]bool)
+	index := make(map[string]int)
 
-	// Regex to match dependency items
-	depItemRegex := regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9_-]*)\s*([<>=^]+[^,]*)?`)
+	var pkgName string
+	var section string
+	var inScope bool
+	var scope core.Scope
 
-	lines := strings.Split(content, "\n")
-	inBuildDepends := false
+	// Dependency fields can span several lines; collect everything indented
+	// deeper than the field itself before splitting it up.
+	var buf strings.Builder
+	fieldIndent := -1
 
-	for _, line := range lines {
-		lowerLine := strings.ToLower(strings.TrimSpace(line))
-
-		// Check for build-depends: line (case insensitive)
-		if strings.HasPrefix(lowerLine, "build-depends:") {
-			inBuildDepends = true
-			// Get the part after build-depends:
-			idx := strings.Index(strings.ToLower(line), "build-depends:")
-			if idx >= 0 {
-				rest := strings.TrimSpace(line[idx+14:])
-				if rest != "" {
-					processDeps(rest, &deps, seen, depItemRegex)
-				}
-			}
-			continue
+	flush := func() {
+		if fieldIndent >= 0 {
+			addDependencies(buf.String(), scope, pkgName, &deps, index)
 		}
+		buf.Reset()
+		fieldIndent = -1
+	}
 
-		// Continue parsing if we're in a build-depends block (continuation lines start with whitespace)
-		if inBuildDepends {
-			trimmed := strings.TrimSpace(line)
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
 
-			// If line doesn't start with whitespace, we're done with this build-depends block
-			if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && line != "" {
-				inBuildDepends = false
-				continue
-			}
+		indent := indentation(line)
+		if fieldIndent >= 0 && indent > fieldIndent {
+			buf.WriteString(" ")
+			buf.WriteString(trimmed)
+			continue
+		}
+		flush()
 
-			// Skip empty lines or comments
-			if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+		match := cabalFieldRegex.FindStringSubmatch(trimmed)
+		if indent == 0 {
+			if match == nil {
+				section = strings.ToLower(strings.Fields(trimmed)[0])
+				scope, inScope = sectionScopes[section]
 				continue
 			}
+			section = ""
+			scope, inScope = sectionScopes[section]
+		}
+		if match == nil {
+			// Conditionals like "if flag(dev)" and "else" keep the current stanza
+			continue
+		}
 
-			// Check if this looks like a new field (has a colon not in version constraint)
-			if strings.Contains(trimmed, ":") {
-				colonIdx := strings.Index(trimmed, ":")
-				beforeColon := trimmed[:colonIdx]
-				// If before colon doesn't look like a version constraint, it's a new field
-				if !strings.ContainsAny(beforeColon, "<>=^") {
-					inBuildDepends = false
-					continue
-				}
-			}
-
-			processDeps(trimmed, &deps, seen, depItemRegex)
+		field := strings.ToLower(match[1])
+		if section == "" && field == "name" {
+			pkgName = strings.TrimSpace(match[2])
+		}
+		if inScope && (field == "build-depends" || field == "setup-depends") {
+			fieldIndent = indent
+			buf.WriteString(match[2])
 		}
 	}
+	flush()
 
 	return deps
 }
 
-func processDeps(line string, deps *[]core.Dependency, seen map[string]bool, depRegex *regexp.Regexp) {
-	// Split by comma
-	parts := strings.Split(line, ",")
-	for _, part := range parts {
+// addDependencies splits a build-depends value and appends each entry. A
+// package that appears in several stanzas is listed once, with runtime
+// taking precedence over test and benchmark scopes.
+func addDependencies(value string, scope core.Scope, pkgName string, deps *[]core.Dependency, index map[string]int) {
+	for _, part := range splitDependencyList(value) {
 		part = strings.TrimSpace(part)
 		if part == "" {
 			continue
 		}
 
-		matches := depRegex.FindStringSubmatch(part)
-		if len(matches) > 1 {
-			name := matches[1]
-			if name == "base" || seen[name] {
-				continue
-			}
-			seen[name] = true
+		matches := depItemRegex.FindStringSubmatch(part)
+		if matches == nil {
+			continue
+		}
+		name := matches[1]
+		// base ships with GHC, and test suites usually depend on the package itself
+		if name == "base" || name == pkgName {
+			continue
+		}
+		requirements := strings.Join(strings.Fields(matches[2]), " ")
 
-			requirements := ""
-			if len(matches) > 2 {
-				requirements = strings.TrimSpace(matches[2])
+		if i, ok := index[name]; ok {
+			if scope == core.Runtime && (*deps)[i].Scope != core.Runtime {
+				(*deps)[i].Scope = scope
+				(*deps)[i].Requirements = requirements
 			}
+			continue
+		}
 
-			*deps = append(*deps, core.Dependency{
-				Name:         name,
-				Requirements: requirements,
-				Scope:        core.Runtime,
-			})
+		index[name] = len(*deps)
+		*deps = append(*deps, core.Dependency{
+			Name:         name,
+			Requirements: requirements,
+			Scope:        scope,
+		})
+	}
+}
+
+// splitDependencyList splits on commas that aren't inside the braces of a
+// sublibrary list like "pkg:{a, b}".
+func splitDependencyList(value string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range value {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, value[start:i])
+				start = i + 1
+			}
 		}
 	}
+	return append(parts, value[start:])
+}
+
+// maintainerGroup is a package's maintainer group as served by
+// /package/{name}/maintainers/.json.
+type maintainerGroup struct {
+	Members []struct {
+		UserID   int    `json:"userid"`
+		Username string `json:"username"`
+	} `json:"members"`
 }
 
+// FetchMaintainers returns the members of the package's maintainer group,
+// the Hackage accounts allowed to upload it. If the group can't be read or
+// is empty, it falls back to the cabal file's maintainer field, which
+// gives a name and email rather than accounts.
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	groupURL := fmt.Sprintf("%s/package/%s/maintainers/.json", r.baseURL, name)
+
+	var group maintainerGroup
+	if err := r.client.GetJSON(ctx, groupURL, &group); err == nil && len(group.Members) > 0 {
+		maintainers := make([]core.Maintainer, len(group.Members))
+		for i, m := range group.Members {
+			maintainers[i] = core.Maintainer{
+				UUID:  strconv.Itoa(m.UserID),
+				Login: m.Username,
+				URL:   fmt.Sprintf("%s/user/%s", r.baseURL, m.Username),
+				Role:  "maintainer",
+			}
+		}
+		return maintainers, nil
+	}
+
 	// Get the cabal file for maintainer info
 	pkg, err := r.FetchPackage(ctx, name)
 	if err != nil {
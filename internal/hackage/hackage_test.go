@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
 )
@@ -54,6 +55,9 @@ source-repository head
 	if pkg.Licenses != "BSD3" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if pkg.LatestVersion != "2.2.0.0" {
+		t.Errorf("expected latest version '2.2.0.0', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -94,6 +98,135 @@ func TestFetchVersions(t *testing.T) {
 	if versions[0].PublishedAt.IsZero() {
 		t.Error("expected non-zero published time")
 	}
+	if want := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC); !versions[2].PublishedAt.Equal(want) {
+		t.Errorf("expected %v, got %v", want, versions[2].PublishedAt)
+	}
+}
+
+func TestFetchVersionsDeprecated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/package/text/preferred" {
+			_, _ = w.Write([]byte("normal-versions: 2.1, 2.0.2\ndeprecated-versions: 2.0.1\nunpreferred-versions: 2.1.1"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "text")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	want := []struct {
+		number      string
+		status      core.VersionStatus
+		unpreferred bool
+	}{
+		{"2.1.1", core.StatusNone, true},
+		{"2.1", core.StatusNone, false},
+		{"2.0.2", core.StatusNone, false},
+		{"2.0.1", core.StatusDeprecated, false},
+	}
+	if len(versions) != len(want) {
+		t.Fatalf("expected %d versions, got %d", len(want), len(versions))
+	}
+	for i, w := range want {
+		v := versions[i]
+		if v.Number != w.number || v.Status != w.status {
+			t.Errorf("position %d: expected %s %q, got %s %q", i, w.number, w.status, v.Number, v.Status)
+		}
+		if unpreferred, _ := v.Metadata["unpreferred"].(bool); unpreferred != w.unpreferred {
+			t.Errorf("%s: expected unpreferred %v, got %v", v.Number, w.unpreferred, v.Metadata)
+		}
+	}
+}
+
+func TestFetchVersionsUploadTimeFormats(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/package/aeson/preferred", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("normal-versions: 2.2.1.0, 2.2.0.0, 2.1.2.1"))
+	})
+	mux.HandleFunc("/package/aeson-2.2.1.0/upload-time", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Mon Oct 23 08:23:52 UTC 2023\n"))
+	})
+	mux.HandleFunc("/package/aeson-2.2.0.0/upload-time", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Thu Jun  8 14:01:02 UTC 2023"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "aeson")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	want := []time.Time{
+		time.Date(2023, 10, 23, 8, 23, 52, 0, time.UTC),
+		time.Date(2023, 6, 8, 14, 1, 2, 0, time.UTC),
+		{},
+	}
+	for i, v := range versions {
+		if !v.PublishedAt.Equal(want[i]) {
+			t.Errorf("%s: expected %v, got %v", v.Number, want[i], v.PublishedAt)
+		}
+	}
+}
+
+func TestFetchMaintainers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/package/lens/maintainers/.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"title": "Maintainers for lens",
+			"description": "Maintainers for a package can upload new versions and adjust other attributes in the package database.",
+			"members": [
+				{"userid": 123, "username": "EdwardKmett"},
+				{"userid": 456, "username": "ryanglscott"}
+			]
+		}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "lens")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+
+	if len(maintainers) != 2 {
+		t.Fatalf("expected 2 maintainers, got %d", len(maintainers))
+	}
+	m := maintainers[0]
+	if m.Login != "EdwardKmett" || m.UUID != "123" || m.Role != "maintainer" {
+		t.Errorf("unexpected maintainer: %+v", m)
+	}
+	if m.URL != server.URL+"/user/EdwardKmett" {
+		t.Errorf("unexpected URL: %q", m.URL)
+	}
+}
+
+func TestFetchMaintainersFallsBackToCabal(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/package/tiny/preferred", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("normal-versions: 1.0"))
+	})
+	mux.HandleFunc("/package/tiny-1.0/tiny.cabal", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("name: tiny\nversion: 1.0\nmaintainer: Jane Doe <jane@example.com>\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "tiny")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+	if len(maintainers) != 1 || maintainers[0].Email != "jane@example.com" || maintainers[0].Name != "Jane Doe" {
+		t.Errorf("expected the cabal maintainer, got %+v", maintainers)
+	}
 }
 
 func TestFetchDependencies(t *testing.T) {
@@ -150,6 +283,103 @@ test-suite tests
 	if nameMap["base"] {
 		t.Error("base should be filtered out")
 	}
+	if nameMap["aeson"] {
+		t.Error("the package itself should be filtered out")
+	}
+
+	scopeMap := make(map[string]core.Scope)
+	for _, d := range deps {
+		scopeMap[d.Name] = d.Scope
+	}
+	if scopeMap["text"] != core.Runtime {
+		t.Errorf("expected runtime scope for text, got %q", scopeMap["text"])
+	}
+	if scopeMap["QuickCheck"] != core.Test {
+		t.Errorf("expected test scope for QuickCheck, got %q", scopeMap["QuickCheck"])
+	}
+}
+
+func TestParseDependencies(t *testing.T) {
+	cabal := `cabal-version: 3.0
+name:          example
+version:       1.0.0
+
+flag dev
+  description: Build with -Werror
+  default:     False
+
+common warnings
+  ghc-options: -Wall
+
+library
+  import:          warnings
+  exposed-modules: Example
+  build-depends:   base >=4.14 && <5
+                 , bytestring >=0.10
+                   && <0.13
+                 , text
+  if flag(dev)
+    build-depends: deepseq ==1.4.*
+  else
+    ghc-options: -O2
+
+executable example
+  main-is:       Main.hs
+  build-depends: example, optparse-applicative ^>=0.18
+
+test-suite spec
+  type:           exitcode-stdio-1.0
+  build-depends:
+    base,
+    example,
+    text,
+    hspec >= 2.10 && < 2.12,
+    mtl:{mtl, mtl-extra} >= 2.3
+
+benchmark bench
+  build-depends: criterion
+
+custom-setup
+  setup-depends: Cabal >=3.0
+
+source-repository head
+  type:     git
+  location: https://github.com/example/example
+`
+
+	deps := parseDependencies(cabal)
+
+	tests := []struct {
+		name         string
+		requirements string
+		scope        core.Scope
+	}{
+		{"bytestring", ">=0.10 && <0.13", core.Runtime},
+		{"text", "", core.Runtime},
+		{"deepseq", "==1.4.*", core.Runtime},
+		{"optparse-applicative", "^>=0.18", core.Runtime},
+		{"hspec", ">= 2.10 && < 2.12", core.Test},
+		{"mtl", ">= 2.3", core.Test},
+		{"criterion", "", core.Development},
+		{"Cabal", ">=3.0", core.Build},
+	}
+
+	if len(deps) != len(tests) {
+		t.Fatalf("expected %d dependencies, got %d: %+v", len(tests), len(deps), deps)
+	}
+
+	for i, tt := range tests {
+		d := deps[i]
+		if d.Name != tt.name {
+			t.Errorf("dependency %d: expected name %q, got %q", i, tt.name, d.Name)
+		}
+		if d.Requirements != tt.requirements {
+			t.Errorf("%s: expected requirements %q, got %q", tt.name, tt.requirements, d.Requirements)
+		}
+		if d.Scope != tt.scope {
+			t.Errorf("%s: expected scope %q, got %q", tt.name, tt.scope, d.Scope)
+		}
+	}
 }
 
 func TestParseCabalFile(t *testing.T) {
@@ -168,6 +398,14 @@ category:       Testing, Development
 source-repository head
   type:     git
   location: https://github.com/example/test
+
+source-repository this
+  type:     git
+  location: https://github.com/example/test-fork
+  tag:      v1.0.0
+
+flag dev
+  description: Development build
 `
 
 	info := parseCabalFile(cabal)
@@ -187,6 +425,12 @@ source-repository head
 	if info.SourceRepository != "https://github.com/example/test" {
 		t.Errorf("unexpected source repository: %q", info.SourceRepository)
 	}
+	if info.Description != "This is a longer description that spans multiple lines." {
+		t.Errorf("unexpected description: %q", info.Description)
+	}
+	if info.Homepage != "https://example.com" {
+		t.Errorf("unexpected homepage: %q", info.Homepage)
+	}
 }
 
 func TestParsePreferredVersions(t *testing.T) {
@@ -4,8 +4,10 @@ package haxelib
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
 	"github.com/git-pkgs/registries/internal/urlparser"
@@ -14,12 +16,20 @@ import (
 const (
 	DefaultURL = "https://lib.haxe.org"
 	ecosystem  = "haxelib"
+	dateLayout = "2006-01-02 15:04:05"
 )
 
+// semverRegex matches the versions haxelib accepts today. Some old releases
+// predate that check and can't be ordered.
+var semverRegex = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
 func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"comments", "contributors", "downloads", "non_semver", "owner",
+	)
 }
 
 type Registry struct {
@@ -81,13 +91,20 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	// Extract repository URL from website
 	repository := urlparser.Parse(resp.Website)
 
+	var latest string
+	if versions := r.sortVersions(resp.Versions, ""); len(versions) > 0 && semverRegex.MatchString(versions[0].Number) {
+		latest = versions[0].Number
+	}
+
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Description,
-		Homepage:    resp.Website,
-		Repository:  repository,
-		Licenses:    resp.License,
-		Keywords:    resp.Tags,
+		Name:          resp.Name,
+		Description:   resp.Description,
+		Homepage:      resp.Website,
+		Repository:    repository,
+		Licenses:      resp.License,
+		Keywords:      core.NormalizeKeywords(resp.Tags),
+		LatestVersion: latest,
+		Downloads:     resp.Downloads,
 		Metadata: map[string]any{
 			"owner":        resp.Owner,
 			"downloads":    resp.Downloads,
@@ -107,23 +124,46 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		return nil, err
 	}
 
-	versions := make([]core.Version, 0, len(resp.Versions))
-	for _, v := range resp.Versions {
-		versions = append(versions, core.Version{
-			Number:   v.Version,
-			Licenses: resp.License,
+	return r.sortVersions(resp.Versions, resp.License), nil
+}
+
+// sortVersions returns the versions newest first. Haxelib lists them oldest
+// first, but that is upload order rather than a guarantee, so versions are
+// compared as semver. Ones that aren't semver are flagged with
+// Metadata["non_semver"] and kept in their original order after the rest.
+func (r *Registry) sortVersions(infos []versionInfo, licenses string) []core.Version {
+	var valid, other []core.Version
+	for _, v := range infos {
+		version := core.Version{
+			Number:      v.Version,
+			PublishedAt: parseDate(v.Date),
+			Licenses:    licenses,
 			Metadata: map[string]any{
 				"comments": v.Comments,
 			},
-		})
+		}
+		if semverRegex.MatchString(v.Version) {
+			valid = append(valid, version)
+		} else {
+			version.Metadata["non_semver"] = true
+			other = append(other, version)
+		}
 	}
 
-	// Reverse to get newest first (Haxelib returns oldest first)
-	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
-		versions[i], versions[j] = versions[j], versions[i]
-	}
+	versions := make([]core.Version, 0, len(infos))
+	versions = append(versions, core.SortedVersions(r, valid)...)
+	return append(versions, other...)
+}
 
-	return versions, nil
+// parseDate reads haxelib's "YYYY-MM-DD HH:MM:SS" upload times. They carry
+// no time zone and are taken as UTC. It returns the zero time for anything
+// else.
+func parseDate(s string) time.Time {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
@@ -188,15 +228,14 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 	}
 
 	for _, c := range resp.Contributors {
-		if c != resp.Owner {
-			maintainers = append(maintainers, core.Maintainer{
-				Login: c,
-				Role:  "contributor",
-			})
-		}
+		maintainers = append(maintainers, core.Maintainer{
+			Login: c,
+			Role:  "contributor",
+		})
 	}
 
-	return maintainers, nil
+	// The owner is usually listed among the contributors too
+	return core.MergeMaintainers(maintainers), nil
 }
 
 type URLs struct {
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
 )
@@ -57,6 +59,9 @@ func TestFetchPackage(t *testing.T) {
 	if len(pkg.Keywords) != 2 {
 		t.Errorf("expected 2 keywords, got %d", len(pkg.Keywords))
 	}
+	if pkg.LatestVersion != "9.2.0" {
+		t.Errorf("expected latest version '9.2.0', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -93,6 +98,57 @@ func TestFetchVersions(t *testing.T) {
 	}
 }
 
+func TestFetchVersionsSorted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := packageResponse{
+			Name: "format",
+			Versions: []versionInfo{
+				{Version: "1.0", Date: "2012-03-01 09:00:00"},
+				{Version: "3.5.0", Date: "2020-06-01 12:00:00"},
+				{Version: "3.10.0", Date: "2023-01-17 10:22:09"},
+				{Version: "4.0.0-alpha.1", Date: "2023-02-01 08:00:00"},
+				{Version: "3.9.1", Date: "2023-03-01 08:00:00"},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "format")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	var numbers []string
+	for _, v := range versions {
+		numbers = append(numbers, v.Number)
+	}
+	want := []string{"4.0.0-alpha.1", "3.10.0", "3.9.1", "3.5.0", "1.0"}
+	if strings.Join(numbers, " ") != strings.Join(want, " ") {
+		t.Errorf("expected versions %v, got %v", want, numbers)
+	}
+
+	published := time.Date(2023, 1, 17, 10, 22, 9, 0, time.UTC)
+	if !versions[1].PublishedAt.Equal(published) {
+		t.Errorf("expected published time %v, got %v", published, versions[1].PublishedAt)
+	}
+	if versions[4].Metadata["non_semver"] != true {
+		t.Errorf("expected '1.0' to be flagged as non-semver, got %v", versions[4].Metadata)
+	}
+	if _, ok := versions[0].Metadata["non_semver"]; ok {
+		t.Error("expected semver versions not to be flagged")
+	}
+
+	pkg, err := reg.FetchPackage(context.Background(), "format")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "4.0.0-alpha.1" {
+		t.Errorf("expected latest version '4.0.0-alpha.1', got %q", pkg.LatestVersion)
+	}
+}
+
 func TestFetchDependencies(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := packageResponse{
@@ -20,6 +20,9 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"downloads", "links", "retirement",
+	)
 }
 
 type Registry struct {
@@ -49,11 +52,13 @@ func (r *Registry) URLs() core.URLBuilder {
 }
 
 type packageResponse struct {
-	Name      string           `json:"name"`
-	Meta      metaInfo         `json:"meta"`
-	Releases  []releaseInfo    `json:"releases"`
-	Downloads downloadsInfo    `json:"downloads"`
-	Owners    []ownerInfo      `json:"owners"`
+	Name                string        `json:"name"`
+	Meta                metaInfo      `json:"meta"`
+	Releases            []releaseInfo `json:"releases"`
+	Downloads           downloadsInfo `json:"downloads"`
+	Owners              []ownerInfo   `json:"owners"`
+	LatestVersion       string        `json:"latest_version"`
+	LatestStableVersion string        `json:"latest_stable_version"`
 }
 
 type metaInfo struct {
@@ -108,7 +113,7 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 
 	var homepage, repository string
 	if gh, ok := links["github"]; ok {
-		repository = urlparser.Parse(gh)
+		repository = core.NormalizeRepository(gh)
 	}
 	for k, v := range links {
 		if k != "github" && homepage == "" {
@@ -119,12 +124,20 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		repository = urlparser.Parse(homepage)
 	}
 
+	latest := resp.LatestStableVersion
+	if latest == "" {
+		latest = resp.LatestVersion
+	}
+
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Meta.Description,
-		Homepage:    homepage,
-		Repository:  repository,
-		Licenses:    strings.Join(resp.Meta.Licenses, ","),
+		Name:          resp.Name,
+		Description:   resp.Meta.Description,
+		Homepage:      homepage,
+		Repository:    repository,
+		Licenses:      strings.Join(resp.Meta.Licenses, ","),
+		LatestVersion: latest,
+		Downloads:     resp.Downloads.All,
+		ProjectURLs:   resp.Meta.Links,
 		Metadata: map[string]any{
 			"downloads": resp.Downloads.All,
 			"links":     resp.Meta.Links,
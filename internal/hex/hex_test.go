@@ -32,6 +32,8 @@ func TestFetchPackage(t *testing.T) {
 			Owners: []ownerInfo{
 				{Username: "chrismccord", Email: "chris@example.com"},
 			},
+			LatestVersion:       "1.8.0-rc.0",
+			LatestStableVersion: "1.7.14",
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -51,9 +53,18 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Repository != "https://github.com/phoenixframework/phoenix" {
 		t.Errorf("unexpected repository: %q", pkg.Repository)
 	}
+	if pkg.ProjectURLs["Website"] != "https://www.phoenixframework.org" {
+		t.Errorf("expected the links in ProjectURLs, got %v", pkg.ProjectURLs)
+	}
 	if pkg.Licenses != "MIT" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if pkg.LatestVersion != "1.7.14" {
+		t.Errorf("expected latest version '1.7.14', got %q", pkg.LatestVersion)
+	}
+	if pkg.Downloads != 50000000 {
+		t.Errorf("expected 50000000 downloads, got %d", pkg.Downloads)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -20,6 +20,10 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"bottle", "deprecation_reason", "formula", "full_name",
+		"source_revision", "source_url", "status", "tap",
+	)
 }
 
 type Registry struct {
@@ -79,12 +83,25 @@ type urlsInfo struct {
 	Stable urlInfo `json:"stable"`
 }
 
+// urlInfo is where a formula's source comes from. Formulae built from a
+// git checkout have a tag and revision instead of a checksum.
 type urlInfo struct {
 	URL      string `json:"url"`
-	Revision int    `json:"revision"`
+	Tag      string `json:"tag"`
+	Revision string `json:"revision"`
 	Checksum string `json:"checksum"`
 }
 
+// addSource records the stable source's URL and git revision in metadata.
+func addSource(metadata map[string]any, source urlInfo) {
+	if source.URL != "" {
+		metadata["source_url"] = source.URL
+	}
+	if source.Revision != "" {
+		metadata["source_revision"] = source.Revision
+	}
+}
+
 type analyticsInfo struct {
 	Install install30d `json:"install"`
 }
@@ -114,18 +131,22 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		status = "disabled"
 	}
 
+	metadata := map[string]any{
+		"tap":               resp.Tap,
+		"full_name":         resp.FullName,
+		"status":            status,
+		"deprecation_reason": resp.DeprecationReason,
+	}
+	addSource(metadata, resp.URLs.Stable)
+
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Desc,
-		Homepage:    resp.Homepage,
-		Repository:  repository,
-		Licenses:    resp.License,
-		Metadata: map[string]any{
-			"tap":               resp.Tap,
-			"full_name":         resp.FullName,
-			"status":            status,
-			"deprecation_reason": resp.DeprecationReason,
-		},
+		Name:          resp.Name,
+		Description:   resp.Desc,
+		Homepage:      resp.Homepage,
+		Repository:    repository,
+		Licenses:      resp.License,
+		LatestVersion: resp.Versions.Stable,
+		Metadata:      metadata,
 	}, nil
 }
 
@@ -149,14 +170,17 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 			status = core.StatusDeprecated
 		}
 
+		metadata := map[string]any{
+			"bottle": resp.Versions.Bottle,
+		}
+		addSource(metadata, resp.URLs.Stable)
+
 		versions = append(versions, core.Version{
 			Number:    resp.Versions.Stable,
 			Licenses:  resp.License,
 			Integrity: formatIntegrity(resp.URLs.Stable.Checksum),
 			Status:    status,
-			Metadata: map[string]any{
-				"bottle": resp.Versions.Bottle,
-			},
+			Metadata:  metadata,
 		})
 	}
 
@@ -257,7 +281,10 @@ func (u *URLs) Documentation(name, version string) string {
 	return fmt.Sprintf("%s/formula/%s", u.baseURL, name)
 }
 
+// PURL percent-encodes the "@" in versioned formula names such as
+// "openssl@3", which would otherwise be read as the version separator.
 func (u *URLs) PURL(name, version string) string {
+	name = strings.ReplaceAll(name, "@", "%40")
 	if version != "" {
 		return fmt.Sprintf("pkg:brew/%s@%s", name, version)
 	}
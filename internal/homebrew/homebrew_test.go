@@ -26,6 +26,9 @@ func TestFetchPackage(t *testing.T) {
 			License:  "GPL-3.0-or-later",
 			Homepage: "https://www.gnu.org/software/wget/",
 			Versions: versionsInfo{Stable: "1.21.4", Bottle: true},
+			URLs: urlsInfo{
+				Stable: urlInfo{URL: "https://ftp.gnu.org/gnu/wget/wget-1.21.4.tar.gz", Checksum: "81542f5c"},
+			},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
 	}))
@@ -46,6 +49,52 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Licenses != "GPL-3.0-or-later" {
 		t.Errorf("unexpected license: %q", pkg.Licenses)
 	}
+	if pkg.LatestVersion != "1.21.4" {
+		t.Errorf("expected latest version '1.21.4', got %q", pkg.LatestVersion)
+	}
+	if pkg.Metadata["source_url"] != "https://ftp.gnu.org/gnu/wget/wget-1.21.4.tar.gz" {
+		t.Errorf("unexpected source_url: %v", pkg.Metadata["source_url"])
+	}
+	if _, ok := pkg.Metadata["source_revision"]; ok {
+		t.Errorf("expected no source_revision for a tarball source, got %v", pkg.Metadata["source_revision"])
+	}
+}
+
+func TestFetchVersionsGitSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"name": "swiftformat",
+			"versions": {"stable": "0.53.0", "bottle": true},
+			"urls": {"stable": {
+				"url": "https://github.com/nicklockwood/SwiftFormat.git",
+				"tag": "0.53.0",
+				"revision": "4a2a2b5b1f8b0d8a0e6c4a0c2b7b6e0e7f9b1c3d",
+				"using": null,
+				"checksum": null
+			}}
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "swiftformat")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 1 {
+		t.Fatalf("expected 1 version, got %d", len(versions))
+	}
+	v := versions[0]
+	if v.Metadata["source_url"] != "https://github.com/nicklockwood/SwiftFormat.git" {
+		t.Errorf("unexpected source_url: %v", v.Metadata["source_url"])
+	}
+	if v.Metadata["source_revision"] != "4a2a2b5b1f8b0d8a0e6c4a0c2b7b6e0e7f9b1c3d" {
+		t.Errorf("unexpected source_revision: %v", v.Metadata["source_revision"])
+	}
+	if v.Integrity != "" {
+		t.Errorf("expected no integrity without a checksum, got %q", v.Integrity)
+	}
 }
 
 func TestFetchPackageWithGitHubRepo(t *testing.T) {
@@ -176,6 +225,7 @@ func TestURLBuilder(t *testing.T) {
 		{"documentation", func() string { return urls.Documentation("wget", "") }, "https://formulae.brew.sh/formula/wget"},
 		{"purl", func() string { return urls.PURL("wget", "1.21.4") }, "pkg:brew/wget@1.21.4"},
 		{"purl_no_version", func() string { return urls.PURL("wget", "") }, "pkg:brew/wget"},
+		{"purl_versioned_formula", func() string { return urls.PURL("openssl@3", "3.2.0") }, "pkg:brew/openssl%403@3.2.0"},
 	}
 
 	for _, tt := range tests {
@@ -22,6 +22,9 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"git-tree-sha1", "subdir", "uuid",
+	)
 }
 
 type Registry struct {
@@ -148,8 +151,13 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 	versions := make([]core.Version, 0, len(versionNumbers))
 	for _, v := range versionNumbers {
 		info := versionMap[v]
+		var status core.VersionStatus
+		if info.yanked {
+			status = core.StatusYanked
+		}
 		versions = append(versions, core.Version{
 			Number: v,
+			Status: status,
 			Metadata: map[string]any{
 				"git-tree-sha1": info.gitTreeSha1,
 			},
@@ -161,6 +169,7 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 
 type versionInfo struct {
 	gitTreeSha1 string
+	yanked      bool
 }
 
 func parseVersionsToml(content string) map[string]versionInfo {
@@ -196,8 +205,11 @@ func parseVersionsToml(content string) map[string]versionInfo {
 		key := strings.TrimSpace(parts[0])
 		value := strings.Trim(strings.TrimSpace(parts[1]), "\"")
 
-		if key == "git-tree-sha1" {
+		switch key {
+		case "git-tree-sha1":
 			currentInfo.gitTreeSha1 = value
+		case "yanked":
+			currentInfo.yanked = value == "true"
 		}
 	}
 
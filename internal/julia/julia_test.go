@@ -19,6 +19,7 @@ git-tree-sha1 = "3043b8e5c7c7f4b6f6f5e3b4b4c5d6e7f8a9b0c1"
 
 ["0.21.3"]
 git-tree-sha1 = "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b"
+yanked = true
 
 ["0.20.0"]
 git-tree-sha1 = "0123456789abcdef0123456789abcdef01234567"
@@ -89,6 +90,9 @@ func TestFetchVersions(t *testing.T) {
 	if versions[0].Metadata["git-tree-sha1"] != "3043b8e5c7c7f4b6f6f5e3b4b4c5d6e7f8a9b0c1" {
 		t.Errorf("unexpected git-tree-sha1: %v", versions[0].Metadata["git-tree-sha1"])
 	}
+	if versions[1].Status != core.StatusYanked || versions[0].Status != core.StatusNone {
+		t.Errorf("expected only 0.21.3 to be yanked, got %q and %q", versions[1].Status, versions[0].Status)
+	}
 }
 
 func TestFetchDependencies(t *testing.T) {
@@ -19,6 +19,9 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"dev",
+	)
 }
 
 type Registry struct {
@@ -66,6 +69,14 @@ type maintainerInfo struct {
 	Name string `json:"name"`
 }
 
+// manifestResponse is a LuaRocks manifest, mapping each rock to its
+// versions and the files (rockspec, src, arch) published for them.
+type manifestResponse struct {
+	Repository map[string]map[string][]struct {
+		Arch string `json:"arch"`
+	} `json:"repository"`
+}
+
 type rockspec struct {
 	Package      string                 `json:"package"`
 	Version      string                 `json:"version"`
@@ -97,12 +108,19 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		return nil, err
 	}
 
+	var latest string
+	if versions := r.sortVersions(resp); len(versions) > 0 && !isDevVersion(versions[0].Number) {
+		latest = versions[0].Number
+	}
+
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Description,
-		Homepage:    resp.Homepage,
-		Licenses:    resp.License,
-		Keywords:    resp.Labels,
+		Name:          resp.Name,
+		Summary:       resp.Description,
+		Description:   resp.Description,
+		Homepage:      resp.Homepage,
+		Licenses:      resp.License,
+		Keywords:      core.NormalizeKeywords(resp.Labels),
+		LatestVersion: latest,
 	}, nil
 }
 
@@ -117,24 +135,32 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		return nil, err
 	}
 
-	// Extract version numbers
-	versionNumbers := make([]string, 0, len(resp.Versions))
-	for v := range resp.Versions {
-		versionNumbers = append(versionNumbers, v)
-	}
-
-	// Sort versions (newest first by string comparison - not perfect for semver)
-	sort.Sort(sort.Reverse(sort.StringSlice(versionNumbers)))
+	return r.sortVersions(resp), nil
+}
 
-	versions := make([]core.Version, 0, len(versionNumbers))
-	for _, v := range versionNumbers {
-		versions = append(versions, core.Version{
+// sortVersions returns the module's versions newest first. Development
+// versions ("scm-1", "dev-1") track a branch, so they're flagged with
+// Metadata["dev"] and sort after every release.
+func (r *Registry) sortVersions(resp moduleResponse) []core.Version {
+	versions := make([]core.Version, 0, len(resp.Versions))
+	for v := range resp.Versions {
+		version := core.Version{
 			Number:   v,
 			Licenses: resp.License,
-		})
+		}
+		if isDevVersion(v) {
+			version.Metadata = map[string]any{"dev": true}
+		}
+		versions = append(versions, version)
 	}
+	return core.SortedVersions(r, versions)
+}
 
-	return versions, nil
+// isDevVersion reports whether v is one of the "scm" or "dev"
+// pseudo-versions, with or without a rockspec revision.
+func isDevVersion(v string) bool {
+	version, _, _ := strings.Cut(strings.ToLower(v), "-")
+	return version == "scm" || version == "dev"
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
@@ -205,9 +231,54 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 		}
 	}
 
+	// Rocks are published under a user's manifest, so a "user/rock" name can
+	// be checked against it when the API doesn't list maintainers
+	if user, rock, ok := strings.Cut(name, "/"); ok && len(maintainers) == 0 {
+		rocks, err := r.FetchManifest(ctx, user)
+		if err != nil {
+			if _, ok := err.(*core.NotFoundError); ok {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if _, ok := rocks[rock]; ok {
+			maintainers = append(maintainers, core.Maintainer{
+				Login: user,
+				URL:   fmt.Sprintf("%s/modules/%s", r.baseURL, user),
+			})
+		}
+	}
+
 	return maintainers, nil
 }
 
+// FetchManifest reads the manifest LuaRocks keeps for each user at
+// /manifests/<user>, returning the rocks it lists and their versions.
+func (r *Registry) FetchManifest(ctx context.Context, user string) (map[string][]string, error) {
+	url := fmt.Sprintf("%s/manifests/%s/manifest.json", r.baseURL, user)
+
+	var resp manifestResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: user}
+		}
+		return nil, err
+	}
+
+	rocks := make(map[string][]string, len(resp.Repository))
+	for rock, versions := range resp.Repository {
+		numbers := make([]string, 0, len(versions))
+		for v := range versions {
+			numbers = append(numbers, v)
+		}
+		sort.Slice(numbers, func(i, j int) bool {
+			return core.CompareVersions(ecosystem, numbers[i], numbers[j]) > 0
+		})
+		rocks[rock] = numbers
+	}
+	return rocks, nil
+}
+
 type URLs struct {
 	baseURL string
 }
@@ -25,6 +25,7 @@ func TestFetchPackage(t *testing.T) {
 			License:     "MIT",
 			Labels:      []string{"networking", "socket"},
 			Versions: map[string][]rockVersion{
+				"scm-3":   {},
 				"3.1.0-1": {},
 				"3.0.0-1": {},
 			},
@@ -52,6 +53,9 @@ func TestFetchPackage(t *testing.T) {
 	if len(pkg.Keywords) != 2 {
 		t.Errorf("expected 2 keywords, got %d", len(pkg.Keywords))
 	}
+	if pkg.LatestVersion != "3.1.0-1" {
+		t.Errorf("expected latest version '3.1.0-1', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -60,9 +64,11 @@ func TestFetchVersions(t *testing.T) {
 			Name:    "lpeg",
 			License: "MIT",
 			Versions: map[string][]rockVersion{
-				"1.0.2-1": {},
-				"1.0.1-1": {},
-				"1.0.0-1": {},
+				"1.0.0-1":  {},
+				"dev-1":    {},
+				"1.0.10-1": {},
+				"1.0.2-1":  {},
+				"1.0.2-2":  {},
 			},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
@@ -75,13 +81,27 @@ func TestFetchVersions(t *testing.T) {
 		t.Fatalf("FetchVersions failed: %v", err)
 	}
 
-	if len(versions) != 3 {
-		t.Fatalf("expected 3 versions, got %d", len(versions))
+	if len(versions) != 5 {
+		t.Fatalf("expected 5 versions, got %d", len(versions))
 	}
 
 	if versions[0].Licenses != "MIT" {
 		t.Errorf("unexpected license: %q", versions[0].Licenses)
 	}
+
+	// Newest first by version then revision, with the dev version last
+	expected := []string{"1.0.10-1", "1.0.2-2", "1.0.2-1", "1.0.0-1", "dev-1"}
+	for i, want := range expected {
+		if versions[i].Number != want {
+			t.Errorf("expected version %d to be %q, got %q", i, want, versions[i].Number)
+		}
+	}
+	if versions[4].Metadata["dev"] != true {
+		t.Error("expected dev-1 to be flagged as a dev version")
+	}
+	if versions[0].Metadata["dev"] != nil {
+		t.Error("expected releases not to be flagged as dev versions")
+	}
 }
 
 func TestFetchDependencies(t *testing.T) {
@@ -147,6 +167,47 @@ func TestFetchMaintainers(t *testing.T) {
 	}
 }
 
+func TestFetchMaintainersFromManifest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/1/mpeterv/luacheck":
+			_ = json.NewEncoder(w).Encode(moduleResponse{Name: "luacheck"})
+		case "/manifests/mpeterv/manifest.json":
+			_, _ = w.Write([]byte(`{"repository": {
+				"luacheck": {"0.26.1-1": [{"arch": "rockspec"}, {"arch": "src"}], "scm-1": [{"arch": "rockspec"}]},
+				"argparse": {"0.7.1-1": [{"arch": "rockspec"}]}
+			}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "mpeterv/luacheck")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+	if len(maintainers) != 1 || maintainers[0].Login != "mpeterv" {
+		t.Fatalf("expected maintainer 'mpeterv' from the manifest, got %+v", maintainers)
+	}
+
+	rocks, err := reg.FetchManifest(context.Background(), "mpeterv")
+	if err != nil {
+		t.Fatalf("FetchManifest failed: %v", err)
+	}
+	if len(rocks) != 2 {
+		t.Fatalf("expected 2 rocks, got %d", len(rocks))
+	}
+	if got := rocks["luacheck"]; len(got) != 2 || got[0] != "0.26.1-1" || got[1] != "scm-1" {
+		t.Errorf("unexpected luacheck versions: %v", got)
+	}
+
+	if _, err := reg.FetchManifest(context.Background(), "nobody"); err == nil {
+		t.Error("expected an error for a missing manifest")
+	}
+}
+
 func TestParseDependency(t *testing.T) {
 	tests := []struct {
 		input string
@@ -4,26 +4,49 @@ package maven
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/url"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
-	"github.com/git-pkgs/registries/internal/urlparser"
 )
 
 const (
-	DefaultURL    = "https://repo1.maven.org/maven2"
-	SearchURL     = "https://search.maven.org"
-	ecosystem     = "maven"
-	maxParentDepth = 5
+	DefaultURL               = "https://repo1.maven.org/maven2"
+	SearchURL                = "https://search.maven.org"
+	ecosystem                = "maven"
+	maxParentDepth           = 5
+	maxRelocationDepth       = 3
+	artifactCheckConcurrency = 8
+	// maxBatchCoordinates and maxBatchQueryLength bound a batched search:
+	// Solr's default maxBooleanClauses allows far more, but
+	// search.maven.org rejects long query strings.
+	maxBatchCoordinates = 20
+	maxBatchQueryLength = 1500
+	// pomCacheTTL and pomCacheEntries bound the POM cache on long-lived
+	// registries. A dependency tree rarely needs more than a few hundred.
+	pomCacheTTL     = time.Hour
+	pomCacheEntries = 1000
 )
 
 func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"artifact_id", "classifiers", "distribution_download_url",
+		"distribution_repository", "distribution_snapshot_repository",
+		"group_id", "maven_scope", "multiplatform", "multiplatform_root",
+		"packaging", "platform_variants", "raw_licenses", "relocated_from",
+		"relocation_message", "resolved_repository", "signed",
+		"system_path", "version_count",
+	)
 }
 
 type Registry struct {
@@ -31,6 +54,13 @@ type Registry struct {
 	searchURL string
 	client    *core.Client
 	urls      *URLs
+
+	checkArtifacts      bool // look up .asc signatures and .sha256 checksums per version
+	probeClassifiers    bool // list the classifiers published for each version
+	detectMultiplatform bool // read Gradle module metadata, see WithMultiplatformDetection
+	poms                *pomCache
+	mirrors             []string // tried in order when baseURL fails, see WithMirrors
+	layout              Layout
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -38,14 +68,136 @@ func New(baseURL string, client *core.Client) *Registry {
 		baseURL = DefaultURL
 	}
 	r := &Registry{
-		baseURL:   strings.TrimSuffix(baseURL, "/"),
-		searchURL: SearchURL,
-		client:    client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+		poms:    newPOMCache(pomCacheTTL, pomCacheEntries),
+		layout:  StandardLayout{},
+	}
+	// search.maven.org only indexes Central. Private repositories such as
+	// Nexus or Artifactory have no Solr search, so they are read from
+	// maven-metadata.xml alone.
+	if r.baseURL == DefaultURL {
+		r.searchURL = SearchURL
 	}
-	r.urls = &URLs{baseURL: r.baseURL}
+	r.urls = &URLs{baseURL: r.baseURL, layout: r.layout}
 	return r
 }
 
+// WithArtifactChecks returns a copy of the registry that, when enabled,
+// checks each version's main artifact (the jar, or the file its POM's
+// packaging names) for a PGP signature and a SHA-256 checksum.
+// FetchVersions then records Metadata["signed"] and fills Integrity where
+// the repository publishes a .sha256 file. This costs up to three extra
+// requests per version, so it is off by default.
+func (r *Registry) WithArtifactChecks(enabled bool) *Registry {
+	copy := *r
+	copy.checkArtifacts = enabled
+	return &copy
+}
+
+// WithClassifierProbing returns a copy of the registry that, when enabled,
+// lists the files published for each version. FetchVersions then records
+// Metadata["classifiers"] (e.g. "sources", "javadoc", "natives-linux") and
+// Metadata["packaging"] (the main artifact's extensions, e.g. "jar", "pom").
+// This costs at least one extra request per version, so it is off by default.
+func (r *Registry) WithClassifierProbing(enabled bool) *Registry {
+	copy := *r
+	copy.probeClassifiers = enabled
+	return &copy
+}
+
+// WithMultiplatformDetection returns a copy of the registry that, when
+// enabled, recognizes Kotlin Multiplatform publications in FetchPackage and
+// FetchPackages. This costs a .module request for every artifact published
+// with Gradle module metadata, and a -jvm POM request for every artifact
+// with pom packaging, so it is off by default.
+func (r *Registry) WithMultiplatformDetection(enabled bool) *Registry {
+	copy := *r
+	copy.detectMultiplatform = enabled
+	return &copy
+}
+
+// WithSearchURL returns a copy of the registry that queries the Solr search
+// API at searchURL (SearchURL, or a mirror of it) before falling back to
+// maven-metadata.xml. An empty URL disables search, so FetchPackage and
+// FetchVersions read maven-metadata.xml and the POM directly, which avoids
+// depending on search.maven.org when it is down or rate limiting. Search
+// is enabled by default only for Maven Central.
+func (r *Registry) WithSearchURL(searchURL string) *Registry {
+	copy := *r
+	copy.searchURL = strings.TrimSuffix(searchURL, "/")
+	return &copy
+}
+
+// WithMirrors returns a copy of the registry that fetches POMs and
+// maven-metadata.xml from each mirror in turn, such as Google's mirror of
+// Central, when the base URL answers 404 or a 5xx or can't be reached.
+// Mirrors mirror the base URL's layout, so the same path is requested from
+// each. The last mirror's error is returned if none succeeds, and
+// FetchPackage records the one that served the package in
+// Metadata["resolved_repository"]. Search, artifact checks and classifier
+// probing still use their own URLs.
+func (r *Registry) WithMirrors(mirrors ...string) *Registry {
+	copy := *r
+	copy.mirrors = make([]string, 0, len(mirrors))
+	for _, m := range mirrors {
+		if m = strings.TrimSuffix(m, "/"); m != "" {
+			copy.mirrors = append(copy.mirrors, m)
+		}
+	}
+	return &copy
+}
+
+// WithLayout returns a copy of the registry that finds files and
+// maven-metadata.xml with layout instead of the standard Maven 2 layout,
+// for repositories that arrange them differently. The mirrors and URLs()
+// use it too. A nil layout restores StandardLayout.
+func (r *Registry) WithLayout(layout Layout) *Registry {
+	if layout == nil {
+		layout = StandardLayout{}
+	}
+	copy := *r
+	copy.layout = layout
+	copy.urls = &URLs{baseURL: r.baseURL, layout: layout}
+	return &copy
+}
+
+// getBody fetches a URL under the base URL, falling back to the mirrors,
+// and returns the base URL or mirror that served it.
+func (r *Registry) getBody(ctx context.Context, fileURL string) ([]byte, string, error) {
+	body, err := r.client.GetBody(ctx, fileURL)
+	if err == nil || len(r.mirrors) == 0 || !strings.HasPrefix(fileURL, r.baseURL+"/") {
+		return body, r.baseURL, err
+	}
+
+	filePath := strings.TrimPrefix(fileURL, r.baseURL)
+	for _, mirror := range r.mirrors {
+		if !shouldTryMirror(ctx, err) {
+			break
+		}
+		if body, err = r.client.GetBody(ctx, mirror+filePath); err == nil {
+			return body, mirror, nil
+		}
+	}
+	return nil, "", err
+}
+
+// shouldTryMirror reports whether err means the repository doesn't have
+// the file or is down, rather than the request being cancelled, refused
+// for rate limiting or answered with something unreadable.
+func shouldTryMirror(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var httpErr *core.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.IsNotFound() || httpErr.StatusCode >= 500
+	}
+	var rateErr *core.RateLimitError
+	var decodeErr *core.DecodeError
+	return !errors.As(err, &rateErr) && !errors.As(err, &decodeErr) && !errors.Is(err, core.ErrClientClosed)
+}
+
 func (r *Registry) Ecosystem() string {
 	return ecosystem
 }
@@ -65,32 +217,44 @@ type searchResponseBody struct {
 }
 
 type searchDoc struct {
-	ID        string `json:"id"`
-	GroupID   string `json:"g"`
-	ArtifactID string `json:"a"`
-	Version    string `json:"latestVersion"`
-	Timestamp  int64  `json:"timestamp"`
-	VersionCount int  `json:"versionCount"`
+	ID           string `json:"id"`
+	GroupID      string `json:"g"`
+	ArtifactID   string `json:"a"`
+	Version      string `json:"latestVersion"`
+	Timestamp    int64  `json:"timestamp"`
+	VersionCount int    `json:"versionCount"`
 }
 
 // POM XML structures
 type pomXML struct {
-	XMLName     xml.Name    `xml:"project"`
-	GroupID     string      `xml:"groupId"`
-	ArtifactID  string      `xml:"artifactId"`
-	Version     string      `xml:"version"`
-	Name        string      `xml:"name"`
-	Description string      `xml:"description"`
-	URL         string      `xml:"url"`
-	Licenses    []pomLicense `xml:"licenses>license"`
-	SCM         pomSCM      `xml:"scm"`
-	Parent      *pomParent  `xml:"parent"`
-	Dependencies []pomDep   `xml:"dependencies>dependency"`
+	XMLName              xml.Name     `xml:"project"`
+	GroupID              string       `xml:"groupId"`
+	ArtifactID           string       `xml:"artifactId"`
+	Version              string       `xml:"version"`
+	Packaging            string       `xml:"packaging"`
+	Name                 string       `xml:"name"`
+	Description          string       `xml:"description"`
+	URL                  string       `xml:"url"`
+	Licenses             []pomLicense `xml:"licenses>license"`
+	SCM                  pomSCM       `xml:"scm"`
+	Parent               *pomParent   `xml:"parent"`
+	Dependencies         []pomDep     `xml:"dependencies>dependency"`
 	DependencyManagement struct {
 		Dependencies []pomDep `xml:"dependencies>dependency"`
 	} `xml:"dependencyManagement"`
-	Developers []pomDeveloper `xml:"developers>developer"`
+	Developers             []pomDeveloper `xml:"developers>developer"`
+	DistributionManagement struct {
+		Relocation         *pomRelocation `xml:"relocation"`
+		Repository         pomRepository  `xml:"repository"`
+		SnapshotRepository pomRepository  `xml:"snapshotRepository"`
+		DownloadURL        string         `xml:"downloadUrl"`
+	} `xml:"distributionManagement"`
 	Properties map[string]string
+	// Comments collects the comments directly under <project>, where Gradle
+	// marks POMs that have a .module file alongside.
+	Comments string `xml:",comment"`
+	// repository is the base URL or mirror the POM was fetched from.
+	repository string
 }
 
 type pomParent struct {
@@ -99,14 +263,30 @@ type pomParent struct {
 	Version    string `xml:"version"`
 }
 
+// pomRelocation points at the coordinates an artifact has moved to.
+// Any element left out keeps the value of the relocated artifact.
+type pomRelocation struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Message    string `xml:"message"`
+}
+
+// pomRepository is a repository an artifact is deployed to, from
+// distributionManagement.
+type pomRepository struct {
+	ID  string `xml:"id"`
+	URL string `xml:"url"`
+}
+
 type pomLicense struct {
 	Name string `xml:"name"`
 	URL  string `xml:"url"`
 }
 
 type pomSCM struct {
-	URL        string `xml:"url"`
-	Connection string `xml:"connection"`
+	URL           string `xml:"url"`
+	Connection    string `xml:"connection"`
 	DevConnection string `xml:"developerConnection"`
 }
 
@@ -117,6 +297,7 @@ type pomDep struct {
 	Scope      string `xml:"scope"`
 	Optional   string `xml:"optional"`
 	Type       string `xml:"type"`
+	SystemPath string `xml:"systemPath"`
 }
 
 type pomDeveloper struct {
@@ -154,28 +335,32 @@ func ParseCoordinates(coord string) (groupID, artifactID, version string) {
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	return r.fetchPackage(ctx, name, 0)
+}
+
+func (r *Registry) fetchPackage(ctx context.Context, name string, depth int) (*core.Package, error) {
 	groupID, artifactID, _ := ParseCoordinates(name)
 	if groupID == "" || artifactID == "" {
 		return nil, fmt.Errorf("invalid Maven coordinate: %s (expected groupId:artifactId)", name)
 	}
 
 	// First try the search API to get basic metadata
-	searchURL := fmt.Sprintf("%s/solrsearch/select?q=g:%s+AND+a:%s&core=gav&rows=1&wt=json",
-		r.searchURL, url.QueryEscape(groupID), url.QueryEscape(artifactID))
-
-	var searchResp searchResponse
-	if err := r.client.GetJSON(ctx, searchURL, &searchResp); err == nil && searchResp.Response.NumFound > 0 {
-		doc := searchResp.Response.Docs[0]
+	if docs := r.search(ctx, groupID, artifactID, 1); len(docs) > 0 {
+		doc := docs[0]
 		// Fetch the POM for more details
 		pom, _ := r.fetchPOM(ctx, groupID, artifactID, doc.Version, 0)
-		return r.packageFromSearchAndPOM(doc, pom), nil
+		if pkg, ok := r.followRelocation(ctx, pom, groupID, artifactID, doc.Version, depth); ok {
+			return pkg, nil
+		}
+		pkg := r.packageFromSearchAndPOM(doc, pom)
+		r.applyMultiplatform(ctx, pkg, pom, groupID, artifactID, doc.Version)
+		return pkg, nil
 	}
 
 	// Fallback: try to get maven-metadata.xml
-	metadataURL := fmt.Sprintf("%s/%s/%s/maven-metadata.xml",
-		r.baseURL, groupIDToPath(groupID), artifactID)
+	metadataURL := r.metadataURL(groupID, artifactID, "")
 
-	body, err := r.client.GetBody(ctx, metadataURL)
+	body, repository, err := r.getBody(ctx, metadataURL)
 	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
@@ -189,13 +374,75 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	// Get the latest version's POM
-	latestVersion := metadata.Versioning.Latest
-	if latestVersion == "" && len(metadata.Versioning.Versions) > 0 {
-		latestVersion = metadata.Versioning.Versions[len(metadata.Versioning.Versions)-1]
-	}
+	latestVersion := metadata.Versioning.keyword("LATEST")
 
 	pom, _ := r.fetchPOM(ctx, groupID, artifactID, latestVersion, 0)
-	return r.packageFromMetadataAndPOM(metadata, pom), nil
+	if pkg, ok := r.followRelocation(ctx, pom, groupID, artifactID, latestVersion, depth); ok {
+		return pkg, nil
+	}
+	pkg := r.packageFromMetadataAndPOM(metadata, pom)
+	if pom == nil {
+		pkg.Metadata["resolved_repository"] = repository
+	}
+	pkg.LatestVersion = metadata.Versioning.keyword("RELEASE")
+	if pkg.LatestVersion == "" {
+		pkg.LatestVersion = latestVersion
+	}
+	r.applyMultiplatform(ctx, pkg, pom, groupID, artifactID, latestVersion)
+	return pkg, nil
+}
+
+// followRelocation fetches the package a relocated POM points to. It returns
+// false if the POM isn't relocated, the relocation depth is exhausted, or the
+// new coordinates can't be fetched, in which case the caller keeps the
+// original metadata.
+func (r *Registry) followRelocation(ctx context.Context, pom *pomXML, groupID, artifactID, version string, depth int) (*core.Package, bool) {
+	newGroupID, newArtifactID, _, ok := relocationTarget(pom, groupID, artifactID, version)
+	if !ok || depth >= maxRelocationDepth {
+		return nil, false
+	}
+
+	pkg, err := r.fetchPackage(ctx, newGroupID+":"+newArtifactID, depth+1)
+	if err != nil {
+		return nil, false
+	}
+
+	if pkg.Metadata == nil {
+		pkg.Metadata = make(map[string]any)
+	}
+	// Callers further up a relocation chain overwrite this on the way out,
+	// so it ends up holding the coordinates that were originally requested.
+	pkg.Metadata["relocated_from"] = groupID + ":" + artifactID
+	if msg := pom.DistributionManagement.Relocation.Message; msg != "" {
+		pkg.Metadata["relocation_message"] = msg
+	}
+	return pkg, true
+}
+
+// relocationTarget returns the coordinates from a POM's relocation element,
+// filling in any omitted parts from the original coordinates. ok is false if
+// the POM has no relocation or it points back at the same coordinates.
+func relocationTarget(pom *pomXML, groupID, artifactID, version string) (newGroupID, newArtifactID, newVersion string, ok bool) {
+	if pom == nil || pom.DistributionManagement.Relocation == nil {
+		return "", "", "", false
+	}
+	rel := pom.DistributionManagement.Relocation
+
+	newGroupID, newArtifactID, newVersion = groupID, artifactID, version
+	if rel.GroupID != "" {
+		newGroupID = rel.GroupID
+	}
+	if rel.ArtifactID != "" {
+		newArtifactID = rel.ArtifactID
+	}
+	if rel.Version != "" {
+		newVersion = rel.Version
+	}
+
+	if newGroupID == groupID && newArtifactID == artifactID && newVersion == version {
+		return "", "", "", false
+	}
+	return newGroupID, newArtifactID, newVersion, true
 }
 
 type mavenMetadata struct {
@@ -208,6 +455,61 @@ type versioning struct {
 	Latest   string   `xml:"latest"`
 	Release  string   `xml:"release"`
 	Versions []string `xml:"versions>version"`
+
+	// Set in the maven-metadata.xml of a SNAPSHOT version's directory
+	Snapshot         snapshotInfo      `xml:"snapshot"`
+	SnapshotVersions []snapshotVersion `xml:"snapshotVersions>snapshotVersion"`
+}
+
+type snapshotInfo struct {
+	Timestamp   string `xml:"timestamp"`
+	BuildNumber string `xml:"buildNumber"`
+	LocalCopy   bool   `xml:"localCopy"`
+}
+
+type snapshotVersion struct {
+	Classifier string `xml:"classifier"`
+	Extension  string `xml:"extension"`
+	Value      string `xml:"value"`
+}
+
+// snapshotFileVersion returns the version used in the file names of a
+// SNAPSHOT's latest build, such as "1.0-20240115.093012-7" for "1.0-SNAPSHOT",
+// read from the maven-metadata.xml in the version's directory. Repositories
+// deploy each snapshot build under its own timestamp and keep the
+// "-SNAPSHOT" name only in the directory. Snapshots deployed without unique
+// versions, and repositories without the metadata, use the version as is.
+func (r *Registry) snapshotFileVersion(ctx context.Context, groupID, artifactID, version, extension string) (string, error) {
+	metadataURL := r.metadataURL(groupID, artifactID, version)
+
+	body, _, err := r.getBody(ctx, metadataURL)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return version, nil
+		}
+		return "", err
+	}
+
+	var metadata mavenMetadata
+	if err := xml.Unmarshal(body, &metadata); err != nil {
+		return "", err
+	}
+	return resolveSnapshot(metadata.Versioning, version, extension), nil
+}
+
+// resolveSnapshot picks the file version for extension from snapshot
+// metadata. Maven 3 lists each file under snapshotVersions; older
+// metadata only has the latest build's timestamp and number.
+func resolveSnapshot(v versioning, version, extension string) string {
+	for _, sv := range v.SnapshotVersions {
+		if sv.Extension == extension && sv.Classifier == "" && sv.Value != "" {
+			return sv.Value
+		}
+	}
+	if v.Snapshot.LocalCopy || v.Snapshot.Timestamp == "" || v.Snapshot.BuildNumber == "" {
+		return version
+	}
+	return fmt.Sprintf("%s-%s-%s", strings.TrimSuffix(version, "-SNAPSHOT"), v.Snapshot.Timestamp, v.Snapshot.BuildNumber)
 }
 
 func (r *Registry) fetchPOM(ctx context.Context, groupID, artifactID, version string, depth int) (*pomXML, error) {
@@ -215,10 +517,17 @@ func (r *Registry) fetchPOM(ctx context.Context, groupID, artifactID, version st
 		return nil, fmt.Errorf("max parent depth exceeded")
 	}
 
-	pomURL := fmt.Sprintf("%s/%s/%s/%s/%s-%s.pom",
-		r.baseURL, groupIDToPath(groupID), artifactID, version, artifactID, version)
+	fileVersion := version
+	if strings.HasSuffix(version, "-SNAPSHOT") {
+		var err error
+		if fileVersion, err = r.snapshotFileVersion(ctx, groupID, artifactID, version, "pom"); err != nil {
+			return nil, err
+		}
+	}
+
+	pomURL := r.fileURL(groupID, artifactID, version, artifactID+"-"+fileVersion+".pom")
 
-	body, err := r.client.GetBody(ctx, pomURL)
+	body, repository, err := r.getPOM(ctx, pomURL, version)
 	if err != nil {
 		return nil, err
 	}
@@ -227,6 +536,7 @@ func (r *Registry) fetchPOM(ctx context.Context, groupID, artifactID, version st
 	if err := xml.Unmarshal(body, &pom); err != nil {
 		return nil, err
 	}
+	pom.repository = repository
 
 	// Resolve parent POM if present
 	if pom.Parent != nil && depth < maxParentDepth {
@@ -247,6 +557,98 @@ func (r *Registry) fetchPOM(ctx context.Context, groupID, artifactID, version st
 	return &pom, nil
 }
 
+// pomCache holds POM files keyed by URL. Released POMs never change, and
+// parents such as spring-boot-starter-parent are shared by most artifacts in
+// a project, so each is fetched once per registry and its copies. Bodies are
+// stored rather than parsed POMs because resolving a parent modifies the
+// child. Entries expire after ttl and at most maxEntries are kept, so a
+// long-lived registry doesn't hold every POM it has ever fetched.
+type pomCache struct {
+	mu         sync.Mutex
+	entries    map[string]cachedPOM
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+}
+
+// cachedPOM is a POM body and the base URL or mirror that served it.
+type cachedPOM struct {
+	body       []byte
+	repository string
+	fetchedAt  time.Time
+}
+
+func newPOMCache(ttl time.Duration, maxEntries int) *pomCache {
+	return &pomCache{
+		entries:    make(map[string]cachedPOM),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// get returns the POM cached for pomURL, unless it has expired.
+func (c *pomCache) get(pomURL string) (cachedPOM, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[pomURL]
+	if !ok {
+		return cachedPOM{}, false
+	}
+	if c.now().Sub(cached.fetchedAt) >= c.ttl {
+		delete(c.entries, pomURL)
+		return cachedPOM{}, false
+	}
+	return cached, true
+}
+
+// put caches a POM, first dropping expired entries and then the oldest ones
+// until there is room.
+func (c *pomCache) put(pomURL string, pom cachedPOM) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= c.maxEntries {
+		for key, cached := range c.entries {
+			if now.Sub(cached.fetchedAt) >= c.ttl {
+				delete(c.entries, key)
+			}
+		}
+	}
+	for len(c.entries) >= c.maxEntries {
+		var oldest string
+		for key, cached := range c.entries {
+			if oldest == "" || cached.fetchedAt.Before(c.entries[oldest].fetchedAt) {
+				oldest = key
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	pom.fetchedAt = now
+	c.entries[pomURL] = pom
+}
+
+// getPOM fetches a POM through the registry's cache. Snapshot POMs are
+// republished in place, so they are always fetched.
+func (r *Registry) getPOM(ctx context.Context, pomURL, version string) ([]byte, string, error) {
+	if r.poms == nil || strings.HasSuffix(version, "-SNAPSHOT") {
+		return r.getBody(ctx, pomURL)
+	}
+
+	if cached, ok := r.poms.get(pomURL); ok {
+		return cached.body, cached.repository, nil
+	}
+
+	body, repository, err := r.getBody(ctx, pomURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r.poms.put(pomURL, cachedPOM{body: body, repository: repository})
+
+	return body, repository, nil
+}
+
 func mergePOMs(child, parent *pomXML) {
 	if child.Description == "" {
 		child.Description = parent.Description
@@ -263,12 +665,26 @@ func mergePOMs(child, parent *pomXML) {
 	if len(child.Developers) == 0 {
 		child.Developers = parent.Developers
 	}
+
+	// Relocation applies only to the POM that declares it, but the
+	// deployment repositories are usually set once in a shared parent.
+	dist, parentDist := &child.DistributionManagement, &parent.DistributionManagement
+	if dist.Repository.URL == "" {
+		dist.Repository = parentDist.Repository
+	}
+	if dist.SnapshotRepository.URL == "" {
+		dist.SnapshotRepository = parentDist.SnapshotRepository
+	}
+	if dist.DownloadURL == "" {
+		dist.DownloadURL = parentDist.DownloadURL
+	}
 }
 
 func (r *Registry) packageFromSearchAndPOM(doc searchDoc, pom *pomXML) *core.Package {
 	pkg := &core.Package{
-		Name:      fmt.Sprintf("%s:%s", doc.GroupID, doc.ArtifactID),
-		Namespace: doc.GroupID,
+		Name:          fmt.Sprintf("%s:%s", doc.GroupID, doc.ArtifactID),
+		Namespace:     doc.GroupID,
+		LatestVersion: doc.Version,
 		Metadata: map[string]any{
 			"group_id":      doc.GroupID,
 			"artifact_id":   doc.ArtifactID,
@@ -276,13 +692,7 @@ func (r *Registry) packageFromSearchAndPOM(doc searchDoc, pom *pomXML) *core.Pac
 		},
 	}
 
-	if pom != nil {
-		pkg.Description = pom.Description
-		pkg.Homepage = pom.URL
-		pkg.Repository = extractRepository(pom)
-		pkg.Licenses = formatLicenses(pom.Licenses)
-	}
-
+	applyPOM(pkg, pom)
 	return pkg
 }
 
@@ -296,42 +706,344 @@ func (r *Registry) packageFromMetadataAndPOM(metadata mavenMetadata, pom *pomXML
 		},
 	}
 
-	if pom != nil {
-		pkg.Description = pom.Description
-		pkg.Homepage = pom.URL
-		pkg.Repository = extractRepository(pom)
-		pkg.Licenses = formatLicenses(pom.Licenses)
+	applyPOM(pkg, pom)
+	return pkg
+}
+
+// applyPOM fills in the package fields that come from the POM.
+func applyPOM(pkg *core.Package, pom *pomXML) {
+	if pom == nil {
+		return
+	}
+	pkg.Description = pom.Description
+	pkg.Homepage = pom.URL
+	pkg.Repository = extractRepository(pom)
+	pkg.Licenses = formatLicenses(pom.Licenses)
+	pkg.Metadata["packaging"] = packagingOf(pom.Packaging)
+	if pom.repository != "" {
+		pkg.Metadata["resolved_repository"] = pom.repository
+	}
+	if len(pom.Licenses) > 0 {
+		pkg.Metadata["raw_licenses"] = rawLicenses(pom.Licenses)
 	}
+	applyDistribution(pkg, pom)
+}
 
-	return pkg
+// applyDistribution records where the POM says the artifact is deployed,
+// which for artifacts mirrored to Central can be a project's own
+// repository. URLs still holding unresolved ${...} properties are skipped.
+func applyDistribution(pkg *core.Package, pom *pomXML) {
+	dist := pom.DistributionManagement
+	for key, value := range map[string]string{
+		"distribution_repository":          dist.Repository.URL,
+		"distribution_snapshot_repository": dist.SnapshotRepository.URL,
+		"distribution_download_url":        dist.DownloadURL,
+	} {
+		value = strings.TrimSpace(value)
+		if value != "" && !strings.Contains(value, "${") {
+			pkg.Metadata[key] = value
+		}
+	}
+}
+
+// packagingOf returns a POM's packaging, which Maven defaults to jar. It
+// isn't inherited from the parent POM.
+func packagingOf(packaging string) string {
+	packaging = strings.TrimSpace(packaging)
+	if packaging == "" {
+		return "jar"
+	}
+	return packaging
+}
+
+// gradleMetadataMarker is the comment Gradle writes into POMs published
+// with Gradle module metadata, telling Gradle to read the .module file.
+const gradleMetadataMarker = "published-with-gradle-metadata"
+
+// Kotlin Gradle plugin attributes that say which platform a variant is for.
+const (
+	kotlinPlatformAttr     = "org.jetbrains.kotlin.platform.type"
+	kotlinNativeTargetAttr = "org.jetbrains.kotlin.native.target"
+	kotlinWasmTargetAttr   = "org.jetbrains.kotlin.wasm.target"
+)
+
+// gradleModule is the part of a Gradle module metadata (.module) file
+// that links a Kotlin Multiplatform library's root and platform modules.
+type gradleModule struct {
+	// Component points a platform module back at its root; the root's own
+	// component has no URL.
+	Component gradleModuleRef `json:"component"`
+	Variants  []gradleVariant `json:"variants"`
+}
+
+type gradleModuleRef struct {
+	URL    string `json:"url"`
+	Group  string `json:"group"`
+	Module string `json:"module"`
+}
+
+type gradleVariant struct {
+	Name        string           `json:"name"`
+	Attributes  map[string]any   `json:"attributes"`
+	AvailableAt *gradleModuleRef `json:"available-at"`
+}
+
+// platformVariants maps each Kotlin platform of the root module's variants
+// to the "group:artifact" published for it, e.g. "jvm", "js" or a native
+// target like "ios_arm64". Variants without a Kotlin platform, and the
+// root's own common metadata, are skipped.
+func (m *gradleModule) platformVariants() map[string]string {
+	platforms := make(map[string]string)
+	for _, v := range m.Variants {
+		if v.AvailableAt == nil || v.AvailableAt.Module == "" {
+			continue
+		}
+		platform, _ := v.Attributes[kotlinPlatformAttr].(string)
+		if target, ok := v.Attributes[kotlinNativeTargetAttr].(string); ok && target != "" {
+			platform = target
+		} else if target, ok := v.Attributes[kotlinWasmTargetAttr].(string); ok && target != "" {
+			platform = "wasm_" + target
+		}
+		if platform == "" || platform == "common" {
+			continue
+		}
+		if _, ok := platforms[platform]; !ok {
+			platforms[platform] = v.AvailableAt.Group + ":" + v.AvailableAt.Module
+		}
+	}
+	return platforms
+}
+
+// applyMultiplatform recognizes Kotlin Multiplatform publications. The
+// root artifact of a KMP library holds no code: its Gradle module metadata
+// redirects each platform to a sibling artifact such as foo-jvm or
+// foo-iosarm64. For a root, Metadata["multiplatform"] is set and
+// Metadata["platform_variants"] maps platform to coordinates; for a
+// platform artifact, Metadata["multiplatform_root"] names its root. It does
+// nothing unless WithMultiplatformDetection is enabled.
+//
+// The .module file is only fetched when the POM carries Gradle's marker
+// comment. A root published without module metadata has pom packaging, so
+// for those a -jvm sibling is looked for instead, which finds the JVM
+// variant only.
+func (r *Registry) applyMultiplatform(ctx context.Context, pkg *core.Package, pom *pomXML, groupID, artifactID, version string) {
+	if !r.detectMultiplatform || pom == nil || version == "" {
+		return
+	}
+
+	if strings.Contains(pom.Comments, gradleMetadataMarker) {
+		moduleURL := r.fileURL(groupID, artifactID, version, artifactID+"-"+version+".module")
+		var module gradleModule
+		if err := r.client.GetJSON(ctx, moduleURL, &module); err == nil {
+			if module.Component.URL != "" && module.Component.Module != "" {
+				pkg.Metadata["multiplatform_root"] = module.Component.Group + ":" + module.Component.Module
+				return
+			}
+			if platforms := module.platformVariants(); len(platforms) > 0 {
+				pkg.Metadata["multiplatform"] = true
+				pkg.Metadata["platform_variants"] = platforms
+			}
+			return
+		}
+	}
+
+	if packagingOf(pom.Packaging) != "pom" || strings.HasSuffix(artifactID, "-jvm") {
+		return
+	}
+	sibling := artifactID + "-jvm"
+	siblingURL := r.fileURL(groupID, sibling, version, sibling+"-"+version+".pom")
+	if _, _, err := r.getPOM(ctx, siblingURL, version); err == nil {
+		pkg.Metadata["multiplatform"] = true
+		pkg.Metadata["platform_variants"] = map[string]string{"jvm": groupID + ":" + sibling}
+	}
+}
+
+// extensionPackagings are the packaging types whose main artifact isn't a
+// jar, and whose name is its extension. Every other packaging, including
+// bundle, maven-plugin and plugin-defined ones like hk2-jar or nbm, is
+// assumed to publish a jar.
+var extensionPackagings = map[string]bool{
+	"pom": true,
+	"aar": true,
+	"war": true,
+	"ear": true,
+	"rar": true,
+}
+
+// packagingExtension returns the file extension of the main artifact for a
+// packaging type: "pom" for BOMs and parents, "aar" for Android libraries,
+// "war" for web apps and "jar" for anything else.
+func packagingExtension(packaging string) string {
+	packaging = packagingOf(packaging)
+	if extensionPackagings[packaging] {
+		return packaging
+	}
+	return "jar"
 }
 
 func extractRepository(pom *pomXML) string {
-	return urlparser.FirstRepoURL(pom.SCM.URL, pom.SCM.Connection)
+	if repo := core.NormalizeRepository(pom.SCM.URL); repo != "" {
+		return repo
+	}
+	return core.NormalizeRepository(pom.SCM.Connection)
 }
 
+// formatLicenses maps each POM license to an SPDX identifier, trying the
+// name first and then the URL, since names like "The Apache Software
+// License, Version 2.0" vary a lot more than the URLs they link to.
+// Licenses that match neither keep their name as written.
 func formatLicenses(licenses []pomLicense) string {
-	names := make([]string, len(licenses))
-	for i, l := range licenses {
-		names[i] = l.Name
+	var names []string
+	seen := make(map[string]bool)
+	for _, l := range licenses {
+		name := core.NormalizeLicense(strings.TrimSpace(l.Name))
+		if name == "" {
+			name = core.NormalizeLicense(strings.TrimSpace(l.URL))
+		}
+		if name == "" {
+			name = strings.TrimSpace(l.Name)
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
 	}
 	return strings.Join(names, ",")
 }
 
+// rawLicenses returns the license names as the POM declares them.
+func rawLicenses(licenses []pomLicense) []string {
+	names := make([]string, 0, len(licenses))
+	for _, l := range licenses {
+		if name := strings.TrimSpace(l.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
 	groupID, artifactID, _ := ParseCoordinates(name)
 	if groupID == "" || artifactID == "" {
 		return nil, fmt.Errorf("invalid Maven coordinate: %s (expected groupId:artifactId)", name)
 	}
 
-	// Use search API to get all versions
-	searchURL := fmt.Sprintf("%s/solrsearch/select?q=g:%s+AND+a:%s&core=gav&rows=200&wt=json",
-		r.searchURL, url.QueryEscape(groupID), url.QueryEscape(artifactID))
+	versions, err := r.fetchVersions(ctx, name, groupID, artifactID)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.checkArtifacts || r.probeClassifiers {
+		r.addArtifactChecks(ctx, groupID, artifactID, versions)
+	}
+	return versions, nil
+}
+
+// search queries the Central search API for an artifact's versions, newest
+// first. It returns nil on any error, and when the registry has no search
+// API, so callers fall back to maven-metadata.xml.
+func (r *Registry) search(ctx context.Context, groupID, artifactID string, rows int) []searchDoc {
+	if r.searchURL == "" {
+		return nil
+	}
+	searchURL := fmt.Sprintf("%s/solrsearch/select?q=g:%s+AND+a:%s&core=gav&rows=%d&wt=json",
+		r.searchURL, url.QueryEscape(groupID), url.QueryEscape(artifactID), rows)
+
+	var searchResp searchResponse
+	if err := r.client.GetJSON(ctx, searchURL, &searchResp); err != nil || searchResp.Response.NumFound == 0 {
+		return nil
+	}
+	return searchResp.Response.Docs
+}
+
+// FetchPackages fetches several packages, looking their coordinates up
+// in the search API a batch at a time with OR queries instead of one query
+// per package. Each package's POM is still fetched separately. Coordinates
+// missing from a batch's results, and every coordinate of a batch whose
+// query fails, are fetched individually with FetchPackage, as is
+// everything when the registry has no search API. Packages that can't be
+// fetched are left out of the map, which is keyed by the names given.
+func (r *Registry) FetchPackages(ctx context.Context, names []string) map[string]*core.Package {
+	docs := make(map[string]searchDoc)
+	for _, batch := range batchCoordinates(names) {
+		for _, doc := range r.searchBatch(ctx, batch) {
+			docs[doc.GroupID+":"+doc.ArtifactID] = doc
+		}
+	}
+
+	return core.ParallelMap(ctx, names, artifactCheckConcurrency, func(ctx context.Context, name string) (*core.Package, error) {
+		groupID, artifactID, _ := ParseCoordinates(name)
+		doc, ok := docs[groupID+":"+artifactID]
+		if !ok {
+			return r.FetchPackage(ctx, name)
+		}
+		pom, _ := r.fetchPOM(ctx, groupID, artifactID, doc.Version, 0)
+		if pkg, ok := r.followRelocation(ctx, pom, groupID, artifactID, doc.Version, 0); ok {
+			return pkg, nil
+		}
+		pkg := r.packageFromSearchAndPOM(doc, pom)
+		r.applyMultiplatform(ctx, pkg, pom, groupID, artifactID, doc.Version)
+		return pkg, nil
+	})
+}
+
+// batchCoordinates splits names into the Solr queries that look them up,
+// each an OR of (g:"..." AND a:"...") clauses within the batch limits.
+// Invalid or duplicate coordinates, and ones that can't be quoted, are
+// left out and fall back to individual lookups.
+func batchCoordinates(names []string) []string {
+	var batches []string
+	var clauses []string
+	length := 0
+	seen := make(map[string]bool)
+	for _, name := range names {
+		groupID, artifactID, _ := ParseCoordinates(name)
+		key := groupID + ":" + artifactID
+		if groupID == "" || artifactID == "" || seen[key] || strings.ContainsAny(key, "\" \t") {
+			continue
+		}
+		seen[key] = true
+
+		clause := fmt.Sprintf(`(g:"%s" AND a:"%s")`, groupID, artifactID)
+		if len(clauses) > 0 && (len(clauses) == maxBatchCoordinates || length+len(clause)+len(" OR ") > maxBatchQueryLength) {
+			batches = append(batches, strings.Join(clauses, " OR "))
+			clauses, length = nil, 0
+		}
+		if len(clauses) > 0 {
+			length += len(" OR ")
+		}
+		clauses = append(clauses, clause)
+		length += len(clause)
+	}
+	if len(clauses) > 0 {
+		batches = append(batches, strings.Join(clauses, " OR "))
+	}
+	return batches
+}
+
+// searchBatch runs one batched query against the search API's artifact
+// core, which returns one document per coordinate with its latest version.
+// Like search it returns nil on any error.
+func (r *Registry) searchBatch(ctx context.Context, query string) []searchDoc {
+	if r.searchURL == "" {
+		return nil
+	}
+	searchURL := fmt.Sprintf("%s/solrsearch/select?q=%s&rows=%d&wt=json",
+		r.searchURL, url.QueryEscape(query), maxBatchCoordinates)
 
 	var searchResp searchResponse
-	if err := r.client.GetJSON(ctx, searchURL, &searchResp); err == nil && searchResp.Response.NumFound > 0 {
-		versions := make([]core.Version, len(searchResp.Response.Docs))
-		for i, doc := range searchResp.Response.Docs {
+	if err := r.client.GetJSON(ctx, searchURL, &searchResp); err != nil {
+		return nil
+	}
+	return searchResp.Response.Docs
+}
+
+func (r *Registry) fetchVersions(ctx context.Context, name, groupID, artifactID string) ([]core.Version, error) {
+	// Use search API to get all versions
+	if docs := r.search(ctx, groupID, artifactID, 200); len(docs) > 0 {
+		versions := make([]core.Version, len(docs))
+		for i, doc := range docs {
 			var publishedAt time.Time
 			if doc.Timestamp > 0 {
 				publishedAt = time.UnixMilli(doc.Timestamp)
@@ -345,10 +1057,9 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 	}
 
 	// Fallback: maven-metadata.xml
-	metadataURL := fmt.Sprintf("%s/%s/%s/maven-metadata.xml",
-		r.baseURL, groupIDToPath(groupID), artifactID)
+	metadataURL := r.metadataURL(groupID, artifactID, "")
 
-	body, err := r.client.GetBody(ctx, metadataURL)
+	body, _, err := r.getBody(ctx, metadataURL)
 	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
@@ -371,7 +1082,328 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 	return versions, nil
 }
 
+// ResolveTag resolves Maven's LATEST and RELEASE version keywords from
+// maven-metadata.xml: LATEST is the most recently deployed version,
+// snapshots included, and RELEASE the most recent non-snapshot one. Other
+// tags return an empty string without a request.
+func (r *Registry) ResolveTag(ctx context.Context, name, tag string) (string, error) {
+	if !isVersionKeyword(tag) {
+		return "", nil
+	}
+	groupID, artifactID, _ := ParseCoordinates(name)
+	if groupID == "" || artifactID == "" {
+		return "", fmt.Errorf("invalid Maven coordinate: %s (expected groupId:artifactId)", name)
+	}
+
+	body, _, err := r.getBody(ctx, r.metadataURL(groupID, artifactID, ""))
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return "", &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return "", err
+	}
+
+	var metadata mavenMetadata
+	if err := xml.Unmarshal(body, &metadata); err != nil {
+		return "", err
+	}
+	return metadata.Versioning.keyword(tag), nil
+}
+
+// isVersionKeyword reports whether version is one of the LATEST and
+// RELEASE keywords Maven 2 accepted in place of a version.
+func isVersionKeyword(version string) bool {
+	return version == "LATEST" || version == "RELEASE"
+}
+
+// keyword returns the version LATEST or RELEASE stands for, falling back
+// to the last listed version (the last non-snapshot one for RELEASE) when
+// the metadata was written without <latest> or <release>.
+func (v versioning) keyword(keyword string) string {
+	if keyword == "LATEST" && v.Latest != "" {
+		return v.Latest
+	}
+	if keyword == "RELEASE" && v.Release != "" {
+		return v.Release
+	}
+	for i := len(v.Versions) - 1; i >= 0; i-- {
+		if keyword == "LATEST" || !strings.HasSuffix(v.Versions[i], "-SNAPSHOT") {
+			return v.Versions[i]
+		}
+	}
+	return ""
+}
+
+type artifactCheck struct {
+	metadata  map[string]any
+	integrity string
+}
+
+// addArtifactChecks runs the opted-in per-version checks: signatures and
+// checksums (WithArtifactChecks) and published classifiers
+// (WithClassifierProbing). A version whose checks fail, because of network
+// errors say, is left without the extra metadata.
+func (r *Registry) addArtifactChecks(ctx context.Context, groupID, artifactID string, versions []core.Version) {
+	numbers := make([]string, len(versions))
+	for i, v := range versions {
+		numbers[i] = v.Number
+	}
+
+	checks := core.ParallelMap(ctx, numbers, artifactCheckConcurrency, func(ctx context.Context, version string) (*artifactCheck, error) {
+		return r.checkArtifact(ctx, groupID, artifactID, version)
+	})
+
+	for i := range versions {
+		check, ok := checks[versions[i].Number]
+		if !ok {
+			continue
+		}
+		if versions[i].Metadata == nil {
+			versions[i].Metadata = make(map[string]any)
+		}
+		for k, v := range check.metadata {
+			versions[i].Metadata[k] = v
+		}
+		if versions[i].Integrity == "" {
+			versions[i].Integrity = check.integrity
+		}
+	}
+}
+
+func (r *Registry) checkArtifact(ctx context.Context, groupID, artifactID, version string) (*artifactCheck, error) {
+	check := &artifactCheck{metadata: make(map[string]any)}
+
+	if r.checkArtifacts {
+		signed, integrity, err := r.checkSignature(ctx, groupID, artifactID, version)
+		if err != nil {
+			return nil, err
+		}
+		check.metadata["signed"] = signed
+		check.integrity = integrity
+	}
+
+	if r.probeClassifiers {
+		artifacts, err := r.listArtifacts(ctx, groupID, artifactID, version)
+		if err != nil {
+			return nil, err
+		}
+		check.metadata["classifiers"] = artifacts.classifiers
+		check.metadata["packaging"] = artifacts.packaging
+	}
+
+	return check, nil
+}
+
+// checkSignature looks for the PGP signature and SHA-256 checksum of a
+// version's main artifact, whose extension follows the POM's packaging (a
+// BOM's .pom, an Android library's .aar). Without a readable POM it
+// assumes a jar.
+func (r *Registry) checkSignature(ctx context.Context, groupID, artifactID, version string) (signed bool, integrity string, err error) {
+	extension := "jar"
+	pomURL := r.fileURL(groupID, artifactID, version, artifactID+"-"+version+".pom")
+	if body, _, err := r.getPOM(ctx, pomURL, version); err == nil {
+		var pom struct {
+			Packaging string `xml:"packaging"`
+		}
+		if xml.Unmarshal(body, &pom) == nil {
+			extension = packagingExtension(pom.Packaging)
+		}
+	}
+	artifact := artifactID + "-" + version + "." + extension
+
+	status, err := r.client.Head(ctx, r.fileURL(groupID, artifactID, version, artifact+".asc"))
+	if err != nil {
+		return false, "", err
+	}
+	signed = status == 200
+
+	// Checksum files hold the hex digest, sometimes followed by the file name
+	body, err := r.client.GetText(ctx, r.fileURL(groupID, artifactID, version, artifact+".sha256"))
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return signed, "", nil
+		}
+		return false, "", err
+	}
+	if fields := strings.Fields(body); len(fields) > 0 && isSHA256(fields[0]) {
+		integrity = "sha256-" + strings.ToLower(fields[0])
+	}
+	return signed, integrity, nil
+}
+
+// fileURL returns the URL of a file published for a version, such as
+// "commons-lang3-3.14.0.pom", or of the version's directory if file is
+// empty.
+func (r *Registry) fileURL(groupID, artifactID, version, file string) string {
+	return r.baseURL + "/" + r.layout.FilePath(groupID, artifactID, version, file)
+}
+
+// metadataURL returns the URL of an artifact's maven-metadata.xml, or of a
+// snapshot version's if version is set.
+func (r *Registry) metadataURL(groupID, artifactID, version string) string {
+	return r.baseURL + "/" + r.layout.MetadataPath(groupID, artifactID, version)
+}
+
+func isSHA256(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, c := range strings.ToLower(s) {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// knownClassifiers are probed with HEAD requests when the repository
+// doesn't serve directory listings.
+var knownClassifiers = []string{"sources", "javadoc"}
+
+// checksumExtensions are the signature and checksum files published next
+// to each artifact, which aren't artifacts themselves.
+var checksumExtensions = []string{".asc", ".md5", ".sha1", ".sha256", ".sha512"}
+
+var hrefRegex = regexp.MustCompile(`href="([^"]+)"`)
+
+type versionArtifacts struct {
+	classifiers []string
+	packaging   []string
+}
+
+// FetchClassifiers returns the classifiers published for a version, such as
+// "sources" and "javadoc", sorted by name.
+func (r *Registry) FetchClassifiers(ctx context.Context, name, version string) ([]string, error) {
+	groupID, artifactID, _ := ParseCoordinates(name)
+	if groupID == "" || artifactID == "" {
+		return nil, fmt.Errorf("invalid Maven coordinate: %s (expected groupId:artifactId)", name)
+	}
+
+	artifacts, err := r.listArtifacts(ctx, groupID, artifactID, version)
+	if err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return nil, err
+	}
+	return artifacts.classifiers, nil
+}
+
+// listArtifacts reads the version directory listing, which Maven Central and
+// most repository managers serve, and falls back to probing the well-known
+// classifiers when there isn't one.
+func (r *Registry) listArtifacts(ctx context.Context, groupID, artifactID, version string) (*versionArtifacts, error) {
+	dirURL := r.fileURL(groupID, artifactID, version, "")
+
+	body, err := r.client.GetText(ctx, dirURL)
+	if err == nil {
+		if artifacts := parseArtifactListing(body, artifactID+"-"+version); len(artifacts.packaging) > 0 || len(artifacts.classifiers) > 0 {
+			return artifacts, nil
+		}
+	} else if httpErr, ok := err.(*core.HTTPError); !ok || (httpErr.StatusCode != 403 && !httpErr.IsNotFound()) {
+		return nil, err
+	}
+
+	// Every version has a POM, so a missing one means a missing version
+	base := artifactID + "-" + version
+	pomURL := r.fileURL(groupID, artifactID, version, base+".pom")
+	status, err := r.client.Head(ctx, pomURL)
+	if err != nil {
+		return nil, err
+	}
+	if status != 200 {
+		return nil, &core.HTTPError{StatusCode: status, URL: pomURL}
+	}
+
+	classifiers := make(map[string]bool)
+	packaging := map[string]bool{"pom": true}
+	probes := map[string]func(){
+		r.fileURL(groupID, artifactID, version, base+".jar"): func() { packaging["jar"] = true },
+	}
+	for _, classifier := range knownClassifiers {
+		probes[r.fileURL(groupID, artifactID, version, base+"-"+classifier+".jar")] = func() { classifiers[classifier] = true }
+	}
+	for probeURL, found := range probes {
+		status, err := r.client.Head(ctx, probeURL)
+		if err != nil {
+			return nil, err
+		}
+		if status == 200 {
+			found()
+		}
+	}
+
+	return &versionArtifacts{
+		classifiers: sortedKeys(classifiers),
+		packaging:   sortedKeys(packaging),
+	}, nil
+}
+
+// parseArtifactListing picks the classifiers and main artifact extensions
+// out of a directory listing. prefix is "artifactId-version", so
+// "lib-1.0-sources.jar" has classifier "sources" and "lib-1.0.jar" has
+// extension "jar".
+func parseArtifactListing(listing, prefix string) *versionArtifacts {
+	classifiers := make(map[string]bool)
+	packaging := make(map[string]bool)
+
+	for _, m := range hrefRegex.FindAllStringSubmatch(listing, -1) {
+		file := path.Base(m[1])
+		if !strings.HasPrefix(file, prefix) || isChecksumFile(file) {
+			continue
+		}
+		rest := strings.TrimPrefix(file, prefix)
+		switch {
+		case strings.HasPrefix(rest, "-"):
+			if classifier, _, ok := strings.Cut(rest[1:], "."); ok && classifier != "" {
+				classifiers[classifier] = true
+			}
+		case strings.HasPrefix(rest, "."):
+			packaging[rest[1:]] = true
+		}
+	}
+
+	return &versionArtifacts{
+		classifiers: sortedKeys(classifiers),
+		packaging:   sortedKeys(packaging),
+	}
+}
+
+func isChecksumFile(file string) bool {
+	for _, ext := range checksumExtensions {
+		if strings.HasSuffix(file, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FetchDependencies resolves a version of LATEST or RELEASE through
+// ResolveTag before reading the POM.
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	if isVersionKeyword(version) {
+		resolved, err := r.ResolveTag(ctx, name, version)
+		if err != nil {
+			return nil, err
+		}
+		if resolved == "" {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		version = resolved
+	}
+	return r.fetchDependencies(ctx, name, version, 0)
+}
+
+func (r *Registry) fetchDependencies(ctx context.Context, name, version string, depth int) ([]core.Dependency, error) {
 	groupID, artifactID, _ := ParseCoordinates(name)
 	if groupID == "" || artifactID == "" {
 		return nil, fmt.Errorf("invalid Maven coordinate: %s (expected groupId:artifactId)", name)
@@ -385,21 +1417,45 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		return nil, err
 	}
 
+	// A relocated POM is a stub; the real dependencies live at the new coordinates
+	if newGroupID, newArtifactID, newVersion, ok := relocationTarget(pom, groupID, artifactID, version); ok && depth < maxRelocationDepth {
+		return r.fetchDependencies(ctx, newGroupID+":"+newArtifactID, newVersion, depth+1)
+	}
+
 	var deps []core.Dependency
 	for _, d := range pom.Dependencies {
-		scope := mapMavenScope(d.Scope)
+		mavenScope := strings.ToLower(strings.TrimSpace(d.Scope))
+
+		// import only has meaning in dependencyManagement, where it pulls in
+		// a BOM's managed versions. It never puts anything on the classpath.
+		if mavenScope == "import" {
+			continue
+		}
+
+		scope := mapMavenScope(mavenScope)
 		optional := d.Optional == "true"
 
 		if optional {
 			scope = core.Optional
 		}
 
-		deps = append(deps, core.Dependency{
+		dep := core.Dependency{
 			Name:         fmt.Sprintf("%s:%s", d.GroupID, d.ArtifactID),
 			Requirements: d.Version,
 			Scope:        scope,
 			Optional:     optional,
-		})
+		}
+
+		// Keep the declared scope so provided and system can be told apart
+		// from other build dependencies
+		if mavenScope != "" {
+			dep.Metadata = map[string]any{"maven_scope": mavenScope}
+			if mavenScope == "system" && d.SystemPath != "" {
+				dep.Metadata["system_path"] = d.SystemPath
+			}
+		}
+
+		deps = append(deps, dep)
 	}
 
 	return deps, nil
@@ -412,6 +1468,8 @@ func mapMavenScope(scope string) core.Scope {
 	case "test":
 		return core.Test
 	case "provided":
+		return core.Provided
+	case "system":
 		return core.Build
 	case "runtime":
 		return core.Runtime
@@ -459,8 +1517,41 @@ func groupIDToPath(groupID string) string {
 	return strings.ReplaceAll(groupID, ".", "/")
 }
 
+// Layout maps coordinates to paths under a repository's base URL, for
+// repositories that don't use the standard layout. Paths have no leading
+// slash.
+type Layout interface {
+	// MetadataPath returns the path of the maven-metadata.xml listing an
+	// artifact's versions, or, when version is set, a snapshot version's
+	// builds.
+	MetadataPath(groupID, artifactID, version string) string
+
+	// FilePath returns the path of a file published for a version. file
+	// is the standard file name, such as "guava-33.0.0-jre.pom",
+	// "guava-33.0.0-jre-sources.jar" or "guava-33.0.0-jre.jar.sha256",
+	// with the timestamped version for snapshot builds. An empty file
+	// means the version's directory, which is read for its listing.
+	FilePath(groupID, artifactID, version, file string) string
+}
+
+// StandardLayout is the Maven 2 repository layout used by Maven Central and
+// repository managers: com/google/guava/guava/33.0.0-jre/guava-33.0.0-jre.pom.
+type StandardLayout struct{}
+
+func (StandardLayout) MetadataPath(groupID, artifactID, version string) string {
+	if version != "" {
+		return fmt.Sprintf("%s/%s/%s/maven-metadata.xml", groupIDToPath(groupID), artifactID, version)
+	}
+	return fmt.Sprintf("%s/%s/maven-metadata.xml", groupIDToPath(groupID), artifactID)
+}
+
+func (StandardLayout) FilePath(groupID, artifactID, version, file string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", groupIDToPath(groupID), artifactID, version, file)
+}
+
 type URLs struct {
 	baseURL string
+	layout  Layout
 }
 
 func (u *URLs) Registry(name, version string) string {
@@ -471,23 +1562,72 @@ func (u *URLs) Registry(name, version string) string {
 	return fmt.Sprintf("https://search.maven.org/artifact/%s/%s", groupID, artifactID)
 }
 
+// Download returns the URL of a version's main artifact. The extension
+// depends on the POM's <packaging>, which a URL builder can't know without
+// a request, so it assumes a jar, or an aar for groups that only publish
+// Android libraries. Use DownloadForPackaging when the packaging is known.
 func (u *URLs) Download(name, version string) string {
+	groupID, _, _ := ParseCoordinates(name)
+	extension := "jar"
+	if androidLibraryGroups[groupID] {
+		extension = "aar"
+	}
+	return u.download(name, version, extension)
+}
+
+// DownloadForPackaging returns the URL of a version's main artifact for the
+// packaging its POM declares, such as Package.Metadata["packaging"] from
+// FetchPackage: the .pom for a BOM, the .aar for an Android library.
+func (u *URLs) DownloadForPackaging(name, version, packaging string) string {
+	return u.download(name, version, packagingExtension(packaging))
+}
+
+func (u *URLs) download(name, version, extension string) string {
 	if version == "" {
 		return ""
 	}
 	groupID, artifactID, _ := ParseCoordinates(name)
-	return fmt.Sprintf("%s/%s/%s/%s/%s-%s.jar",
-		u.baseURL, groupIDToPath(groupID), artifactID, version, artifactID, version)
+	layout := u.layout
+	if layout == nil {
+		layout = StandardLayout{}
+	}
+	return u.baseURL + "/" + layout.FilePath(groupID, artifactID, version, artifactID+"-"+version+"."+extension)
 }
 
+// Documentation returns the javadoc.io page for an artifact, or for an
+// AndroidX library the release notes of its library group on
+// developer.android.com, which link to its API reference.
 func (u *URLs) Documentation(name, version string) string {
 	groupID, artifactID, _ := ParseCoordinates(name)
+	if library, ok := strings.CutPrefix(groupID, "androidx."); ok {
+		docs := "https://developer.android.com/jetpack/androidx/releases/" + strings.ReplaceAll(library, ".", "-")
+		if version != "" {
+			docs += "#" + version
+		}
+		return docs
+	}
 	if version != "" {
 		return fmt.Sprintf("https://javadoc.io/doc/%s/%s/%s", groupID, artifactID, version)
 	}
 	return fmt.Sprintf("https://javadoc.io/doc/%s/%s", groupID, artifactID)
 }
 
+// androidLibraryGroups are groups whose artifacts are all Android libraries
+// packaged as aars. It is deliberately short: most other androidx groups
+// (androidx.lifecycle, androidx.compose.*) mix aars with plain jars or
+// Kotlin Multiplatform artifacts, as do androidx.annotation,
+// androidx.collection and the Gradle plugin, so they are assumed to be jars
+// until the POM's packaging is known.
+var androidLibraryGroups = map[string]bool{
+	"androidx.activity":           true,
+	"androidx.appcompat":          true,
+	"androidx.core":               true,
+	"androidx.fragment":           true,
+	"androidx.recyclerview":       true,
+	"com.google.android.gms":      true,
+	"com.google.android.material": true,
+}
+
 func (u *URLs) PURL(name, version string) string {
 	groupID, artifactID, _ := ParseCoordinates(name)
 	if version != "" {
@@ -3,9 +3,15 @@ package maven
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
 )
@@ -96,9 +102,44 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Repository != "https://github.com/google/guava" {
 		t.Errorf("unexpected repository: %q", pkg.Repository)
 	}
-	if pkg.Licenses != "Apache License, Version 2.0" {
+	if pkg.Licenses != "Apache-2.0" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if raw, _ := pkg.Metadata["raw_licenses"].([]string); len(raw) != 1 || raw[0] != "Apache License, Version 2.0" {
+		t.Errorf("expected raw license name in metadata, got %v", pkg.Metadata["raw_licenses"])
+	}
+	if pkg.LatestVersion != "32.1.0-jre" {
+		t.Errorf("expected latest version '32.1.0-jre', got %q", pkg.LatestVersion)
+	}
+}
+
+func TestFormatLicenses(t *testing.T) {
+	tests := []struct {
+		name     string
+		licenses []pomLicense
+		want     string
+	}{
+		{"apache long name", []pomLicense{{Name: "The Apache Software License, Version 2.0", URL: "http://www.apache.org/licenses/LICENSE-2.0.txt"}}, "Apache-2.0"},
+		{"apache short name", []pomLicense{{Name: "Apache 2.0"}}, "Apache-2.0"},
+		{"apache url only", []pomLicense{{URL: "https://www.apache.org/licenses/LICENSE-2.0"}}, "Apache-2.0"},
+		{"mit", []pomLicense{{Name: "The MIT License", URL: "https://opensource.org/licenses/MIT"}}, "MIT"},
+		{"mit url fallback", []pomLicense{{Name: "Expat-ish", URL: "http://www.opensource.org/licenses/mit-license.php"}}, "MIT"},
+		{"new bsd", []pomLicense{{Name: "New BSD License"}}, "BSD-3-Clause"},
+		{"bsd 2 clause", []pomLicense{{Name: "BSD 2-Clause License"}}, "BSD-2-Clause"},
+		{"epl", []pomLicense{{Name: "Eclipse Public License 1.0"}}, "EPL-1.0"},
+		{"dual licensed", []pomLicense{{Name: "Apache License, Version 2.0"}, {Name: "MIT License"}}, "Apache-2.0,MIT"},
+		{"duplicates", []pomLicense{{Name: "Apache 2.0"}, {Name: "The Apache License, Version 2.0"}}, "Apache-2.0"},
+		{"unknown kept", []pomLicense{{Name: "Acme Commercial License"}}, "Acme Commercial License"},
+		{"none", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatLicenses(tt.licenses); got != tt.want {
+				t.Errorf("formatLicenses() = %q, want %q", got, tt.want)
+			}
+		})
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -184,6 +225,318 @@ func TestFetchVersionsFallback(t *testing.T) {
 	}
 }
 
+func TestResolveTag(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/com/example/test/maven-metadata.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`<metadata>
+  <versioning>
+    <latest>2.1.0-SNAPSHOT</latest>
+    <release>2.0.0</release>
+  </versioning>
+</metadata>`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	for tag, want := range map[string]string{"LATEST": "2.1.0-SNAPSHOT", "RELEASE": "2.0.0"} {
+		got, err := reg.ResolveTag(context.Background(), "com.example:test", tag)
+		if err != nil {
+			t.Fatalf("ResolveTag(%s) failed: %v", tag, err)
+		}
+		if got != want {
+			t.Errorf("ResolveTag(%s) = %q, want %q", tag, got, want)
+		}
+	}
+
+	requests = 0
+	if got, err := reg.ResolveTag(context.Background(), "com.example:test", "1.0.0"); err != nil || got != "" {
+		t.Errorf("expected no resolution for a plain version, got %q, %v", got, err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no requests for a plain version, got %d", requests)
+	}
+
+	if _, err := reg.ResolveTag(context.Background(), "com.example:missing", "RELEASE"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestVersionKeywords(t *testing.T) {
+	tests := []struct {
+		v       versioning
+		latest  string
+		release string
+	}{
+		{versioning{Latest: "2.1.0-SNAPSHOT", Release: "2.0.0"}, "2.1.0-SNAPSHOT", "2.0.0"},
+		{versioning{Versions: []string{"1.0.0", "2.0.0", "2.1.0-SNAPSHOT"}}, "2.1.0-SNAPSHOT", "2.0.0"},
+		{versioning{Versions: []string{"1.0.0-SNAPSHOT"}}, "1.0.0-SNAPSHOT", ""},
+		{versioning{}, "", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.v.keyword("LATEST"); got != tt.latest {
+			t.Errorf("LATEST of %+v = %q, want %q", tt.v, got, tt.latest)
+		}
+		if got := tt.v.keyword("RELEASE"); got != tt.release {
+			t.Errorf("RELEASE of %+v = %q, want %q", tt.v, got, tt.release)
+		}
+	}
+}
+
+func TestFetchDependenciesVersionKeyword(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/com/example/app/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<metadata>
+  <versioning>
+    <versions>
+      <version>1.0.0</version>
+      <version>1.1.0</version>
+      <version>1.2.0-SNAPSHOT</version>
+    </versions>
+  </versioning>
+</metadata>`))
+	})
+	mux.HandleFunc("/com/example/app/1.1.0/app-1.1.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project>
+  <dependencies>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>lib</artifactId>
+      <version>1.0</version>
+    </dependency>
+  </dependencies>
+</project>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "com.example:app", "RELEASE")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Name != "com.example:lib" {
+		t.Errorf("expected the dependencies of 1.1.0, got %v", deps)
+	}
+
+	if _, err := reg.FetchDependencies(context.Background(), "com.example:missing", "LATEST"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFetchVersionsArtifactChecks(t *testing.T) {
+	const sha = "4ec95b60d4e86b5c95a0e919cb172a0af98011ef2cc3a8c6b0c1a8d6f4c3b8a1"
+	var artifactRequests atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/solrsearch/select", func(w http.ResponseWriter, r *http.Request) {
+		resp := searchResponse{
+			Response: searchResponseBody{
+				NumFound: 3,
+				Docs: []searchDoc{
+					{GroupID: "com.example", ArtifactID: "lib", Version: "2.0.0"},
+					{GroupID: "com.example", ArtifactID: "lib", Version: "1.1.0"},
+					{GroupID: "com.example", ArtifactID: "lib", Version: "1.0.0"},
+				},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/com/example/lib/", func(w http.ResponseWriter, r *http.Request) {
+		artifactRequests.Add(1)
+		switch r.URL.Path {
+		case "/com/example/lib/2.0.0/lib-2.0.0.jar.asc":
+			w.WriteHeader(200)
+		case "/com/example/lib/2.0.0/lib-2.0.0.jar.sha256":
+			_, _ = w.Write([]byte(sha + "  lib-2.0.0.jar\n"))
+		case "/com/example/lib/1.1.0/lib-1.1.0.jar.asc":
+			w.WriteHeader(200)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	reg.searchURL = server.URL
+
+	// Off by default
+	versions, err := reg.FetchVersions(context.Background(), "com.example:lib")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if n := artifactRequests.Load(); n != 0 {
+		t.Errorf("expected no artifact requests without opting in, got %d", n)
+	}
+	if _, ok := versions[0].Metadata["signed"]; ok {
+		t.Error("expected no signed metadata without opting in")
+	}
+
+	versions, err = reg.WithArtifactChecks(true).FetchVersions(context.Background(), "com.example:lib")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	tests := []struct {
+		version   string
+		signed    bool
+		integrity string
+	}{
+		{"2.0.0", true, "sha256-" + sha},
+		{"1.1.0", true, ""},
+		{"1.0.0", false, ""},
+	}
+	for i, tt := range tests {
+		v := versions[i]
+		if v.Number != tt.version {
+			t.Fatalf("expected version %q, got %q", tt.version, v.Number)
+		}
+		if v.Metadata["signed"] != tt.signed {
+			t.Errorf("%s: expected signed %v, got %v", tt.version, tt.signed, v.Metadata["signed"])
+		}
+		if v.Integrity != tt.integrity {
+			t.Errorf("%s: expected integrity %q, got %q", tt.version, tt.integrity, v.Integrity)
+		}
+	}
+}
+
+func TestFetchVersionsArtifactChecksPackaging(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/com/example/bom/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/com/example/bom/maven-metadata.xml":
+			_, _ = w.Write([]byte(`<metadata><versioning><release>1.0.0</release><versions><version>1.0.0</version></versions></versioning></metadata>`))
+		case "/com/example/bom/1.0.0/bom-1.0.0.pom":
+			_, _ = w.Write([]byte(`<project><artifactId>bom</artifactId><version>1.0.0</version><packaging>pom</packaging></project>`))
+		case "/com/example/bom/1.0.0/bom-1.0.0.pom.asc":
+			w.WriteHeader(200)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	versions, err := New(server.URL, core.DefaultClient()).WithArtifactChecks(true).FetchVersions(context.Background(), "com.example:bom")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("expected 1 version, got %d", len(versions))
+	}
+	if versions[0].Metadata["signed"] != true {
+		t.Errorf("expected the pom-packaged artifact's .pom.asc to count as signed, got %v", versions[0].Metadata["signed"])
+	}
+}
+
+func TestParseArtifactListing(t *testing.T) {
+	listing := `<html><body><pre>
+<a href="../">../</a>
+<a href="lib-1.0.jar" title="lib-1.0.jar">lib-1.0.jar</a>
+<a href="lib-1.0.jar.asc" title="lib-1.0.jar.asc">lib-1.0.jar.asc</a>
+<a href="lib-1.0.jar.sha1" title="lib-1.0.jar.sha1">lib-1.0.jar.sha1</a>
+<a href="lib-1.0.pom" title="lib-1.0.pom">lib-1.0.pom</a>
+<a href="lib-1.0-sources.jar" title="lib-1.0-sources.jar">lib-1.0-sources.jar</a>
+<a href="lib-1.0-javadoc.jar" title="lib-1.0-javadoc.jar">lib-1.0-javadoc.jar</a>
+<a href="lib-1.0-natives-linux.jar" title="lib-1.0-natives-linux.jar">lib-1.0-natives-linux.jar</a>
+<a href="lib-1.0-natives-linux.jar.md5" title="lib-1.0-natives-linux.jar.md5">lib-1.0-natives-linux.jar.md5</a>
+</pre></body></html>`
+
+	artifacts := parseArtifactListing(listing, "lib-1.0")
+
+	wantClassifiers := []string{"javadoc", "natives-linux", "sources"}
+	if strings.Join(artifacts.classifiers, ",") != strings.Join(wantClassifiers, ",") {
+		t.Errorf("expected classifiers %v, got %v", wantClassifiers, artifacts.classifiers)
+	}
+	wantPackaging := []string{"jar", "pom"}
+	if strings.Join(artifacts.packaging, ",") != strings.Join(wantPackaging, ",") {
+		t.Errorf("expected packaging %v, got %v", wantPackaging, artifacts.packaging)
+	}
+}
+
+func TestFetchVersionsClassifierProbing(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/solrsearch/select", func(w http.ResponseWriter, r *http.Request) {
+		resp := searchResponse{
+			Response: searchResponseBody{
+				NumFound: 1,
+				Docs: []searchDoc{
+					{GroupID: "com.example", ArtifactID: "lib", Version: "1.0"},
+				},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/com/example/lib/1.0/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<a href="lib-1.0.aar">lib-1.0.aar</a>
+<a href="lib-1.0.pom">lib-1.0.pom</a>
+<a href="lib-1.0-sources.jar">lib-1.0-sources.jar</a>`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	reg.searchURL = server.URL
+
+	versions, err := reg.WithClassifierProbing(true).FetchVersions(context.Background(), "com.example:lib")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("expected 1 version, got %d", len(versions))
+	}
+
+	classifiers, _ := versions[0].Metadata["classifiers"].([]string)
+	if strings.Join(classifiers, ",") != "sources" {
+		t.Errorf("expected classifiers [sources], got %v", versions[0].Metadata["classifiers"])
+	}
+	packaging, _ := versions[0].Metadata["packaging"].([]string)
+	if strings.Join(packaging, ",") != "aar,pom" {
+		t.Errorf("expected packaging [aar pom], got %v", versions[0].Metadata["packaging"])
+	}
+	if _, ok := versions[0].Metadata["signed"]; ok {
+		t.Error("signature checks should stay off unless enabled")
+	}
+}
+
+func TestFetchClassifiersWithoutListing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/com/example/lib/1.0/lib-1.0.pom",
+			"/com/example/lib/1.0/lib-1.0.jar",
+			"/com/example/lib/1.0/lib-1.0-javadoc.jar":
+			w.WriteHeader(200)
+		default:
+			// Directory listings are disabled, as on many private repositories
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+
+	classifiers, err := reg.FetchClassifiers(context.Background(), "com.example:lib", "1.0")
+	if err != nil {
+		t.Fatalf("FetchClassifiers failed: %v", err)
+	}
+	if strings.Join(classifiers, ",") != "javadoc" {
+		t.Errorf("expected [javadoc], got %v", classifiers)
+	}
+
+	_, err = reg.FetchClassifiers(context.Background(), "com.example:lib", "9.9")
+	var notFound *core.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError for a missing version, got %v", err)
+	}
+}
+
 func TestFetchDependencies(t *testing.T) {
 	mux := http.NewServeMux()
 
@@ -251,6 +604,105 @@ func TestFetchDependencies(t *testing.T) {
 	}
 }
 
+func TestFetchDependenciesScopes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pom := `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.0.0</version>
+  <dependencies>
+    <dependency>
+      <groupId>com.google.guava</groupId>
+      <artifactId>guava</artifactId>
+      <version>32.1.0-jre</version>
+    </dependency>
+    <dependency>
+      <groupId>org.postgresql</groupId>
+      <artifactId>postgresql</artifactId>
+      <version>42.6.0</version>
+      <scope>runtime</scope>
+    </dependency>
+    <dependency>
+      <groupId>jakarta.servlet</groupId>
+      <artifactId>jakarta.servlet-api</artifactId>
+      <version>6.0.0</version>
+      <scope>provided</scope>
+    </dependency>
+    <dependency>
+      <groupId>com.sun</groupId>
+      <artifactId>tools</artifactId>
+      <version>1.8</version>
+      <scope>system</scope>
+      <systemPath>${java.home}/../lib/tools.jar</systemPath>
+    </dependency>
+    <dependency>
+      <groupId>org.junit.jupiter</groupId>
+      <artifactId>junit-jupiter</artifactId>
+      <version>5.10.0</version>
+      <scope>test</scope>
+    </dependency>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-dependencies</artifactId>
+      <version>3.1.4</version>
+      <type>pom</type>
+      <scope>import</scope>
+    </dependency>
+  </dependencies>
+</project>`
+		_, _ = w.Write([]byte(pom))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "com.example:app", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	byName := make(map[string]core.Dependency)
+	for _, d := range deps {
+		byName[d.Name] = d
+	}
+
+	if len(deps) != 5 {
+		t.Fatalf("expected 5 dependencies (import skipped), got %d", len(deps))
+	}
+	if _, ok := byName["org.springframework.boot:spring-boot-dependencies"]; ok {
+		t.Error("import-scoped BOM should not be a dependency")
+	}
+
+	tests := []struct {
+		name       string
+		scope      core.Scope
+		mavenScope any
+	}{
+		{"com.google.guava:guava", core.Runtime, nil},
+		{"org.postgresql:postgresql", core.Runtime, "runtime"},
+		{"jakarta.servlet:jakarta.servlet-api", core.Provided, "provided"},
+		{"com.sun:tools", core.Build, "system"},
+		{"org.junit.jupiter:junit-jupiter", core.Test, "test"},
+	}
+	for _, tt := range tests {
+		d := byName[tt.name]
+		if d.Scope != tt.scope {
+			t.Errorf("%s: expected scope %q, got %q", tt.name, tt.scope, d.Scope)
+		}
+		if d.Metadata["maven_scope"] != tt.mavenScope {
+			t.Errorf("%s: expected maven_scope %v, got %v", tt.name, tt.mavenScope, d.Metadata["maven_scope"])
+		}
+	}
+
+	if byName["jakarta.servlet:jakarta.servlet-api"].Scope == byName["com.sun:tools"].Scope {
+		t.Error("expected provided and system dependencies to have different scopes")
+	}
+
+	if path := byName["com.sun:tools"].Metadata["system_path"]; path != "${java.home}/../lib/tools.jar" {
+		t.Errorf("unexpected system_path: %v", path)
+	}
+}
+
 func TestFetchMaintainers(t *testing.T) {
 	mux := http.NewServeMux()
 
@@ -372,6 +824,949 @@ func TestParentPOMResolution(t *testing.T) {
 	}
 }
 
+func TestDistributionManagement(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/org/example/lib/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<metadata><groupId>org.example</groupId><artifactId>lib</artifactId><versioning><release>2.0</release><versions><version>2.0</version></versions></versioning></metadata>`))
+	})
+	mux.HandleFunc("/org/example/lib/2.0/lib-2.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project>
+  <parent>
+    <groupId>org.example</groupId>
+    <artifactId>parent</artifactId>
+    <version>1</version>
+  </parent>
+  <artifactId>lib</artifactId>
+  <version>2.0</version>
+  <distributionManagement>
+    <downloadUrl>${project.url}/download</downloadUrl>
+  </distributionManagement>
+</project>`))
+	})
+	mux.HandleFunc("/org/example/parent/1/parent-1.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project>
+  <groupId>org.example</groupId>
+  <artifactId>parent</artifactId>
+  <version>1</version>
+  <distributionManagement>
+    <repository>
+      <id>example-releases</id>
+      <url>https://repo.example.org/releases</url>
+    </repository>
+    <snapshotRepository>
+      <id>example-snapshots</id>
+      <url> https://repo.example.org/snapshots </url>
+    </snapshotRepository>
+    <relocation>
+      <artifactId>moved</artifactId>
+    </relocation>
+  </distributionManagement>
+</project>`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "org.example:lib")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "org.example:lib" {
+		t.Errorf("expected the parent's relocation to be ignored, got %q", pkg.Name)
+	}
+	if pkg.Metadata["distribution_repository"] != "https://repo.example.org/releases" {
+		t.Errorf("unexpected distribution_repository: %v", pkg.Metadata["distribution_repository"])
+	}
+	if pkg.Metadata["distribution_snapshot_repository"] != "https://repo.example.org/snapshots" {
+		t.Errorf("unexpected distribution_snapshot_repository: %v", pkg.Metadata["distribution_snapshot_repository"])
+	}
+	if _, ok := pkg.Metadata["distribution_download_url"]; ok {
+		t.Errorf("expected unresolved download URL to be skipped, got %v", pkg.Metadata["distribution_download_url"])
+	}
+}
+
+func TestPOMCache(t *testing.T) {
+	requests := make(map[string]*atomic.Int32)
+	for _, p := range []string{"child-a", "child-b", "parent", "app"} {
+		requests[p] = &atomic.Int32{}
+	}
+
+	child := func(artifactID, version string) string {
+		return `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>parent</artifactId>
+    <version>1.0.0</version>
+  </parent>
+  <artifactId>` + artifactID + `</artifactId>
+  <version>` + version + `</version>
+  <dependencies>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+      <version>2.0.9</version>
+    </dependency>
+  </dependencies>
+</project>`
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/com/example/child-a/1.0.0/child-a-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		requests["child-a"].Add(1)
+		_, _ = w.Write([]byte(child("child-a", "1.0.0")))
+	})
+	mux.HandleFunc("/com/example/child-b/1.0.0/child-b-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		requests["child-b"].Add(1)
+		_, _ = w.Write([]byte(child("child-b", "1.0.0")))
+	})
+	mux.HandleFunc("/com/example/app/1.0.0-SNAPSHOT/app-1.0.0-SNAPSHOT.pom", func(w http.ResponseWriter, r *http.Request) {
+		requests["app"].Add(1)
+		_, _ = w.Write([]byte(child("app", "1.0.0-SNAPSHOT")))
+	})
+	mux.HandleFunc("/com/example/parent/1.0.0/parent-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		requests["parent"].Add(1)
+		_, _ = w.Write([]byte(`<project>
+  <groupId>com.example</groupId>
+  <artifactId>parent</artifactId>
+  <version>1.0.0</version>
+  <description>Parent project description</description>
+</project>`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	for _, name := range []string{"com.example:child-a", "com.example:child-b", "com.example:child-a"} {
+		deps, err := reg.FetchDependencies(ctx, name, "1.0.0")
+		if err != nil {
+			t.Fatalf("FetchDependencies(%s) failed: %v", name, err)
+		}
+		if len(deps) != 1 {
+			t.Errorf("expected 1 dependency for %s, got %d", name, len(deps))
+		}
+	}
+
+	// Copies share the cache
+	pom, err := reg.WithArtifactChecks(true).fetchPOM(ctx, "com.example", "child-b", "1.0.0", 0)
+	if err != nil {
+		t.Fatalf("fetchPOM failed: %v", err)
+	}
+	if pom.Description != "Parent project description" {
+		t.Errorf("expected inherited description from a cached parent, got %q", pom.Description)
+	}
+
+	// Snapshots are republished in place, so they aren't cached
+	for i := 0; i < 2; i++ {
+		if _, err := reg.FetchDependencies(ctx, "com.example:app", "1.0.0-SNAPSHOT"); err != nil {
+			t.Fatalf("FetchDependencies failed: %v", err)
+		}
+	}
+
+	expected := map[string]int32{"child-a": 1, "child-b": 1, "parent": 1, "app": 2}
+	for name, want := range expected {
+		if got := requests[name].Load(); got != want {
+			t.Errorf("expected %d requests for %s, got %d", want, name, got)
+		}
+	}
+}
+
+func TestPOMCacheBounds(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := newPOMCache(time.Minute, 2)
+	cache.now = func() time.Time { return now }
+
+	cache.put("a", cachedPOM{body: []byte("a")})
+	now = now.Add(time.Second)
+	cache.put("b", cachedPOM{body: []byte("b")})
+	now = now.Add(time.Second)
+	cache.put("c", cachedPOM{body: []byte("c")})
+
+	if _, ok := cache.get("a"); ok {
+		t.Error("expected the oldest POM to be evicted")
+	}
+	if cached, ok := cache.get("b"); !ok || string(cached.body) != "b" {
+		t.Errorf("expected b to be cached, got %q", cached.body)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.get("c"); ok {
+		t.Error("expected c to have expired")
+	}
+}
+
+func TestSnapshotDependencies(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/com/example/app/2.0-SNAPSHOT/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<metadata>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>2.0-SNAPSHOT</version>
+  <versioning>
+    <snapshot>
+      <timestamp>20240115.093012</timestamp>
+      <buildNumber>7</buildNumber>
+    </snapshot>
+    <snapshotVersions>
+      <snapshotVersion>
+        <classifier>sources</classifier>
+        <extension>jar</extension>
+        <value>2.0-20240115.093012-7</value>
+      </snapshotVersion>
+      <snapshotVersion>
+        <extension>pom</extension>
+        <value>2.0-20240115.093012-7</value>
+      </snapshotVersion>
+    </snapshotVersions>
+  </versioning>
+</metadata>`))
+	})
+	mux.HandleFunc("/com/example/app/2.0-SNAPSHOT/app-2.0-20240115.093012-7.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>2.0-SNAPSHOT</version>
+  <dependencies>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>lib</artifactId>
+      <version>1.0</version>
+    </dependency>
+  </dependencies>
+</project>`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "com.example:app", "2.0-SNAPSHOT")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Name != "com.example:lib" {
+		t.Errorf("expected the timestamped POM's dependency, got %v", deps)
+	}
+}
+
+func TestResolveSnapshot(t *testing.T) {
+	tests := []struct {
+		name       string
+		versioning versioning
+		want       string
+	}{
+		{
+			name: "snapshotVersions",
+			versioning: versioning{SnapshotVersions: []snapshotVersion{
+				{Extension: "jar", Value: "1.0-20240101.000000-2"},
+				{Extension: "pom", Value: "1.0-20240101.000000-1"},
+			}},
+			want: "1.0-20240101.000000-1",
+		},
+		{
+			name:       "timestamp only",
+			versioning: versioning{Snapshot: snapshotInfo{Timestamp: "20230505.121212", BuildNumber: "3"}},
+			want:       "1.0-20230505.121212-3",
+		},
+		{
+			name:       "non-unique",
+			versioning: versioning{Snapshot: snapshotInfo{LocalCopy: true}},
+			want:       "1.0-SNAPSHOT",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := resolveSnapshot(tt.versioning, "1.0-SNAPSHOT", "pom"); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRelocation(t *testing.T) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/solrsearch/select", func(w http.ResponseWriter, r *http.Request) {
+		resp := searchResponse{Response: searchResponseBody{NumFound: 0}}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	metadata := func(groupID, artifactID string) string {
+		return `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>` + groupID + `</groupId>
+  <artifactId>` + artifactID + `</artifactId>
+  <versioning>
+    <latest>1.0.0</latest>
+    <versions>
+      <version>1.0.0</version>
+    </versions>
+  </versioning>
+</metadata>`
+	}
+
+	mux.HandleFunc("/com/example/old/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(metadata("com.example", "old")))
+	})
+	mux.HandleFunc("/org/example/new/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(metadata("org.example", "new")))
+	})
+
+	// Relocation stub with no version, so the original version is kept
+	mux.HandleFunc("/com/example/old/1.0.0/old-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		pom := `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <groupId>com.example</groupId>
+  <artifactId>old</artifactId>
+  <version>1.0.0</version>
+  <distributionManagement>
+    <relocation>
+      <groupId>org.example</groupId>
+      <artifactId>new</artifactId>
+      <message>Moved to org.example:new</message>
+    </relocation>
+  </distributionManagement>
+</project>`
+		_, _ = w.Write([]byte(pom))
+	})
+
+	mux.HandleFunc("/org/example/new/1.0.0/new-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		pom := `<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <groupId>org.example</groupId>
+  <artifactId>new</artifactId>
+  <version>1.0.0</version>
+  <description>The renamed library</description>
+  <dependencies>
+    <dependency>
+      <groupId>org.slf4j</groupId>
+      <artifactId>slf4j-api</artifactId>
+      <version>2.0.9</version>
+    </dependency>
+  </dependencies>
+</project>`
+		_, _ = w.Write([]byte(pom))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	reg.searchURL = server.URL
+
+	pkg, err := reg.FetchPackage(context.Background(), "com.example:old")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Name != "org.example:new" {
+		t.Errorf("expected name 'org.example:new', got %q", pkg.Name)
+	}
+	if pkg.Description != "The renamed library" {
+		t.Errorf("unexpected description: %q", pkg.Description)
+	}
+	if pkg.Metadata["relocated_from"] != "com.example:old" {
+		t.Errorf("expected relocated_from 'com.example:old', got %v", pkg.Metadata["relocated_from"])
+	}
+	if pkg.Metadata["relocation_message"] != "Moved to org.example:new" {
+		t.Errorf("unexpected relocation_message: %v", pkg.Metadata["relocation_message"])
+	}
+
+	deps, err := reg.FetchDependencies(context.Background(), "com.example:old", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Name != "org.slf4j:slf4j-api" {
+		t.Errorf("expected dependencies of relocated artifact, got %v", deps)
+	}
+}
+
+func TestRelocationLoop(t *testing.T) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/solrsearch/select", func(w http.ResponseWriter, r *http.Request) {
+		resp := searchResponse{Response: searchResponseBody{NumFound: 0}}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	// a -> b -> a -> ... must stop at the depth guard
+	for _, pair := range [][2]string{{"a", "b"}, {"b", "a"}} {
+		from, to := pair[0], pair[1]
+		mux.HandleFunc("/com/example/"+from+"/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<metadata><groupId>com.example</groupId><artifactId>` + from + `</artifactId><versioning><latest>1.0.0</latest></versioning></metadata>`))
+		})
+		mux.HandleFunc("/com/example/"+from+"/1.0.0/"+from+"-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<project><artifactId>` + from + `</artifactId><distributionManagement><relocation><artifactId>` + to + `</artifactId></relocation></distributionManagement></project>`))
+		})
+	}
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	reg.searchURL = server.URL
+
+	pkg, err := reg.FetchPackage(context.Background(), "com.example:a")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Metadata["relocated_from"] != "com.example:a" {
+		t.Errorf("expected relocated_from 'com.example:a', got %v", pkg.Metadata["relocated_from"])
+	}
+}
+
+func TestPrivateRepository(t *testing.T) {
+	var paths []string
+	var unauthorized atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if user, pass, ok := r.BasicAuth(); !ok || user != "deploy" || pass != "secret" {
+			unauthorized.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/com/example/internal/maven-metadata.xml":
+			_, _ = w.Write([]byte(`<metadata>
+  <groupId>com.example</groupId>
+  <artifactId>internal</artifactId>
+  <versioning>
+    <latest>1.1.0</latest>
+    <release>1.1.0</release>
+    <versions>
+      <version>1.0.0</version>
+      <version>1.1.0</version>
+    </versions>
+  </versioning>
+</metadata>`))
+		case "/com/example/internal/1.1.0/internal-1.1.0.pom":
+			_, _ = w.Write([]byte(`<project>
+  <groupId>com.example</groupId>
+  <artifactId>internal</artifactId>
+  <version>1.1.0</version>
+  <description>Internal library</description>
+</project>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	client := core.NewClient(core.WithCredentials(host, core.Credentials{Username: "deploy", Password: "secret"}))
+	reg := New(server.URL+"/", client)
+
+	pkg, err := reg.FetchPackage(context.Background(), "com.example:internal")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "1.1.0" {
+		t.Errorf("expected latest version '1.1.0', got %q", pkg.LatestVersion)
+	}
+	if pkg.Description != "Internal library" {
+		t.Errorf("expected description from the POM, got %q", pkg.Description)
+	}
+
+	versions, err := reg.FetchVersions(context.Background(), "com.example:internal")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Errorf("expected 2 versions, got %d", len(versions))
+	}
+
+	if n := unauthorized.Load(); n != 0 {
+		t.Errorf("expected every request to be authenticated, %d were not", n)
+	}
+	for _, p := range paths {
+		if strings.Contains(p, "solrsearch") {
+			t.Errorf("expected no search requests for a private repository, got %s", p)
+		}
+	}
+}
+
+// legacyLayout is the Maven 1 layout, which groups files by type under the
+// groupId: com.example/poms/lib-1.0.0.pom.
+type legacyLayout struct{}
+
+func (legacyLayout) MetadataPath(groupID, artifactID, version string) string {
+	return groupID + "/poms/" + artifactID + "-metadata.xml"
+}
+
+func (legacyLayout) FilePath(groupID, artifactID, version, file string) string {
+	ext := file[strings.LastIndex(file, ".")+1:]
+	return groupID + "/" + ext + "s/" + file
+}
+
+func TestWithLayout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/com.example/poms/lib-metadata.xml":
+			_, _ = w.Write([]byte(`<metadata>
+  <groupId>com.example</groupId>
+  <artifactId>lib</artifactId>
+  <versioning><release>1.0.0</release><versions><version>1.0.0</version></versions></versioning>
+</metadata>`))
+		case "/com.example/poms/lib-1.0.0.pom":
+			_, _ = w.Write([]byte(`<project>
+  <groupId>com.example</groupId>
+  <artifactId>lib</artifactId>
+  <version>1.0.0</version>
+  <description>Legacy layout</description>
+  <packaging>war</packaging>
+</project>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithLayout(legacyLayout{})
+	pkg, err := reg.FetchPackage(context.Background(), "com.example:lib")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Description != "Legacy layout" || pkg.LatestVersion != "1.0.0" {
+		t.Errorf("expected the POM from the legacy layout, got %q %q", pkg.Description, pkg.LatestVersion)
+	}
+
+	packaging, _ := pkg.Metadata["packaging"].(string)
+	if got, want := reg.URLs().(*URLs).DownloadForPackaging("com.example:lib", "1.0.0", packaging), server.URL+"/com.example/wars/lib-1.0.0.war"; got != want {
+		t.Errorf("Download = %q, want %q", got, want)
+	}
+
+	standard := reg.WithLayout(nil)
+	if got, want := standard.URLs().Download("com.example:lib", "2.0.0"), server.URL+"/com/example/lib/2.0.0/lib-2.0.0.jar"; got != want {
+		t.Errorf("Download with the standard layout = %q, want %q", got, want)
+	}
+}
+
+func TestWithMirrors(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".pom") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer primary.Close()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	var mirrorPaths []string
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrorPaths = append(mirrorPaths, r.URL.Path)
+		switch r.URL.Path {
+		case "/maven2/com/example/lib/maven-metadata.xml":
+			_, _ = w.Write([]byte(`<metadata>
+  <groupId>com.example</groupId>
+  <artifactId>lib</artifactId>
+  <versioning><release>1.0.0</release><versions><version>1.0.0</version></versions></versioning>
+</metadata>`))
+		case "/maven2/com/example/lib/1.0.0/lib-1.0.0.pom":
+			_, _ = w.Write([]byte(`<project>
+  <groupId>com.example</groupId>
+  <artifactId>lib</artifactId>
+  <version>1.0.0</version>
+  <description>From the mirror</description>
+</project>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mirror.Close()
+
+	client := core.NewClient(core.WithMaxRetries(0))
+	reg := New(primary.URL, client).WithMirrors(down.URL, mirror.URL+"/maven2/")
+	ctx := context.Background()
+
+	pkg, err := reg.FetchPackage(ctx, "com.example:lib")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Description != "From the mirror" {
+		t.Errorf("expected the mirror's POM, got description %q", pkg.Description)
+	}
+	if got := pkg.Metadata["resolved_repository"]; got != mirror.URL+"/maven2" {
+		t.Errorf("expected the mirror as resolved_repository, got %v", got)
+	}
+
+	// The POM is now cached, and still credited to the mirror
+	if pkg, err = reg.FetchPackage(ctx, "com.example:lib"); err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if got := pkg.Metadata["resolved_repository"]; got != mirror.URL+"/maven2" {
+		t.Errorf("expected the mirror as resolved_repository from the cache, got %v", got)
+	}
+
+	direct, err := New(mirror.URL+"/maven2", client).FetchPackage(ctx, "com.example:lib")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if got := direct.Metadata["resolved_repository"]; got != mirror.URL+"/maven2" {
+		t.Errorf("expected the base URL as resolved_repository, got %v", got)
+	}
+
+	versions, err := reg.FetchVersions(ctx, "com.example:lib")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 1 {
+		t.Errorf("expected 1 version from the mirror, got %d", len(versions))
+	}
+
+	if _, err := reg.FetchPackage(ctx, "com.example:missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound when no mirror has the package, got %v", err)
+	}
+
+	// Without mirrors the primary's error stands
+	if _, err := New(primary.URL, client).FetchPackage(ctx, "com.example:lib"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound without mirrors, got %v", err)
+	}
+	if len(mirrorPaths) == 0 {
+		t.Error("expected requests to reach the mirror")
+	}
+}
+
+func TestWithSearchURL(t *testing.T) {
+	var searches atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/solrsearch/select", func(w http.ResponseWriter, r *http.Request) {
+		searches.Add(1)
+		resp := searchResponse{
+			Response: searchResponseBody{
+				NumFound: 1,
+				Docs:     []searchDoc{{GroupID: "com.example", ArtifactID: "lib", Version: "2.0.0"}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/com/example/lib/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<metadata><versioning><versions><version>1.0.0</version><version>1.1.0</version></versions></versioning></metadata>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+
+	versions, err := reg.WithSearchURL(server.URL+"/search/").FetchVersions(context.Background(), "com.example:lib")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Number != "2.0.0" {
+		t.Errorf("expected the search result, got %v", versions)
+	}
+
+	versions, err = reg.WithSearchURL(server.URL + "/search").WithSearchURL("").FetchVersions(context.Background(), "com.example:lib")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Errorf("expected 2 versions from maven-metadata.xml, got %d", len(versions))
+	}
+	if n := searches.Load(); n != 1 {
+		t.Errorf("expected 1 search request, got %d", n)
+	}
+}
+
+func TestFetchPackages(t *testing.T) {
+	var batches, singles atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/solrsearch/select", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		var docs []searchDoc
+		if strings.Contains(q, `a:"`) {
+			batches.Add(1)
+			if !strings.Contains(q, `(g:"com.example" AND a:"one") OR (g:"com.example" AND a:"two")`) {
+				t.Errorf("unexpected batch query: %s", q)
+			}
+			docs = []searchDoc{
+				{GroupID: "com.example", ArtifactID: "one", Version: "1.0.0", VersionCount: 3},
+				{GroupID: "com.example", ArtifactID: "two", Version: "2.0.0", VersionCount: 5},
+			}
+		} else {
+			singles.Add(1)
+		}
+		_ = json.NewEncoder(w).Encode(searchResponse{Response: searchResponseBody{NumFound: len(docs), Docs: docs}})
+	})
+	mux.HandleFunc("/com/example/one/1.0.0/one-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project><description>First</description></project>`))
+	})
+	mux.HandleFunc("/com/example/two/2.0.0/two-2.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project><description>Second</description></project>`))
+	})
+	mux.HandleFunc("/com/example/three/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<metadata><groupId>com.example</groupId><artifactId>three</artifactId><versioning><release>3.0.0</release><versions><version>3.0.0</version></versions></versioning></metadata>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithSearchURL(server.URL)
+	pkgs := reg.FetchPackages(context.Background(), []string{"com.example:one", "com.example:two", "com.example:three", "com.example:missing"})
+
+	if len(pkgs) != 3 {
+		t.Fatalf("expected 3 packages, got %d", len(pkgs))
+	}
+	if pkg := pkgs["com.example:one"]; pkg.LatestVersion != "1.0.0" || pkg.Description != "First" {
+		t.Errorf("unexpected package for one: %+v", pkg)
+	}
+	if pkg := pkgs["com.example:two"]; pkg.Metadata["version_count"] != 5 || pkg.Description != "Second" {
+		t.Errorf("unexpected package for two: %+v", pkg)
+	}
+	if pkg := pkgs["com.example:three"]; pkg.LatestVersion != "3.0.0" {
+		t.Errorf("expected three to fall back to maven-metadata.xml, got %+v", pkg)
+	}
+	if n := batches.Load(); n != 1 {
+		t.Errorf("expected 1 batch query, got %d", n)
+	}
+	if n := singles.Load(); n != 2 {
+		t.Errorf("expected 2 individual queries for the coordinates missing from the batch, got %d", n)
+	}
+}
+
+func TestFetchPackagesBatchFailure(t *testing.T) {
+	var singles atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/solrsearch/select", func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Query().Get("q"), " OR ") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		singles.Add(1)
+		artifactID := strings.TrimPrefix(r.URL.Query().Get("q"), "g:com.example AND a:")
+		resp := searchResponse{Response: searchResponseBody{
+			NumFound: 1,
+			Docs:     []searchDoc{{GroupID: "com.example", ArtifactID: artifactID, Version: "1.0.0"}},
+		}}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithSearchURL(server.URL)
+	pkgs := reg.FetchPackages(context.Background(), []string{"com.example:one", "com.example:two"})
+
+	if len(pkgs) != 2 {
+		t.Fatalf("expected 2 packages, got %d", len(pkgs))
+	}
+	if pkgs["com.example:two"].Name != "com.example:two" {
+		t.Errorf("unexpected package: %+v", pkgs["com.example:two"])
+	}
+	if n := singles.Load(); n != 2 {
+		t.Errorf("expected each coordinate to be looked up individually, got %d queries", n)
+	}
+}
+
+func TestBatchCoordinates(t *testing.T) {
+	var names []string
+	for i := 0; i < maxBatchCoordinates+5; i++ {
+		names = append(names, fmt.Sprintf("com.example:lib%d", i))
+	}
+	names = append(names, "com.example:lib0", "invalid", `com.example:"quoted"`)
+
+	batches := batchCoordinates(names)
+	if len(batches) != 2 {
+		t.Fatalf("expected 2 batches, got %d", len(batches))
+	}
+	if n := strings.Count(batches[0], " OR ") + 1; n != maxBatchCoordinates {
+		t.Errorf("expected %d coordinates in the first batch, got %d", maxBatchCoordinates, n)
+	}
+	if n := strings.Count(batches[1], " OR ") + 1; n != 5 {
+		t.Errorf("expected 5 coordinates in the second batch, got %d", n)
+	}
+
+	long := strings.Repeat("x", maxBatchQueryLength/2)
+	batches = batchCoordinates([]string{"g:" + long, "g:" + long + "y", "g:z"})
+	if len(batches) != 2 {
+		t.Errorf("expected long coordinates to be split by length, got %d batches", len(batches))
+	}
+}
+
+func TestMultiplatform(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/org/example/lib/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<metadata><groupId>org.example</groupId><artifactId>lib</artifactId><versioning><release>1.0.0</release><versions><version>1.0.0</version></versions></versioning></metadata>`))
+	})
+	mux.HandleFunc("/org/example/lib/1.0.0/lib-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project>
+  <!-- This module was also published with a richer model, Gradle metadata,  -->
+  <!-- which should be used instead. Do not delete the following line which  -->
+  <!-- is to indicate to Gradle or any Gradle module metadata file consumer  -->
+  <!-- that they should prefer consuming it instead. -->
+  <!-- do_not_remove: published-with-gradle-metadata -->
+  <groupId>org.example</groupId>
+  <artifactId>lib</artifactId>
+  <version>1.0.0</version>
+</project>`))
+	})
+	mux.HandleFunc("/org/example/lib/1.0.0/lib-1.0.0.module", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+  "formatVersion": "1.1",
+  "component": {"group": "org.example", "module": "lib", "version": "1.0.0"},
+  "variants": [
+    {"name": "metadataApiElements", "attributes": {"org.jetbrains.kotlin.platform.type": "common"}},
+    {"name": "jvmApiElements-published", "attributes": {"org.jetbrains.kotlin.platform.type": "jvm"},
+     "available-at": {"url": "../../lib-jvm/1.0.0/lib-jvm-1.0.0.module", "group": "org.example", "module": "lib-jvm", "version": "1.0.0"}},
+    {"name": "jvmRuntimeElements-published", "attributes": {"org.jetbrains.kotlin.platform.type": "jvm"},
+     "available-at": {"url": "../../lib-jvm/1.0.0/lib-jvm-1.0.0.module", "group": "org.example", "module": "lib-jvm", "version": "1.0.0"}},
+    {"name": "jsApiElements-published", "attributes": {"org.jetbrains.kotlin.platform.type": "js"},
+     "available-at": {"url": "../../lib-js/1.0.0/lib-js-1.0.0.module", "group": "org.example", "module": "lib-js", "version": "1.0.0"}},
+    {"name": "iosArm64ApiElements-published", "attributes": {"org.jetbrains.kotlin.platform.type": "native", "org.jetbrains.kotlin.native.target": "ios_arm64"},
+     "available-at": {"url": "../../lib-iosarm64/1.0.0/lib-iosarm64-1.0.0.module", "group": "org.example", "module": "lib-iosarm64", "version": "1.0.0"}}
+  ]
+}`))
+	})
+	mux.HandleFunc("/org/example/lib-jvm/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<metadata><groupId>org.example</groupId><artifactId>lib-jvm</artifactId><versioning><release>1.0.0</release><versions><version>1.0.0</version></versions></versioning></metadata>`))
+	})
+	mux.HandleFunc("/org/example/lib-jvm/1.0.0/lib-jvm-1.0.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project>
+  <!-- do_not_remove: published-with-gradle-metadata -->
+  <groupId>org.example</groupId>
+  <artifactId>lib-jvm</artifactId>
+  <version>1.0.0</version>
+</project>`))
+	})
+	mux.HandleFunc("/org/example/lib-jvm/1.0.0/lib-jvm-1.0.0.module", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+  "formatVersion": "1.1",
+  "component": {"url": "../../lib/1.0.0/lib-1.0.0.module", "group": "org.example", "module": "lib", "version": "1.0.0"},
+  "variants": [{"name": "jvmApiElements-published", "attributes": {"org.jetbrains.kotlin.platform.type": "jvm"}}]
+}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	plain, err := New(server.URL, core.DefaultClient()).FetchPackage(context.Background(), "org.example:lib")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if _, ok := plain.Metadata["multiplatform"]; ok {
+		t.Error("expected multiplatform detection to be off by default")
+	}
+
+	reg := New(server.URL, core.DefaultClient()).WithMultiplatformDetection(true)
+
+	pkg, err := reg.FetchPackage(context.Background(), "org.example:lib")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Metadata["multiplatform"] != true {
+		t.Errorf("expected multiplatform to be set, got %v", pkg.Metadata)
+	}
+	want := map[string]string{
+		"jvm":       "org.example:lib-jvm",
+		"js":        "org.example:lib-js",
+		"ios_arm64": "org.example:lib-iosarm64",
+	}
+	if got, _ := pkg.Metadata["platform_variants"].(map[string]string); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected platform variants %v, got %v", want, pkg.Metadata["platform_variants"])
+	}
+
+	jvm, err := reg.FetchPackage(context.Background(), "org.example:lib-jvm")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if jvm.Metadata["multiplatform_root"] != "org.example:lib" {
+		t.Errorf("expected multiplatform_root, got %v", jvm.Metadata["multiplatform_root"])
+	}
+	if _, ok := jvm.Metadata["multiplatform"]; ok {
+		t.Error("expected a platform artifact not to be marked as a multiplatform root")
+	}
+}
+
+func TestMultiplatformJVMSibling(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/org/example/old/maven-metadata.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<metadata><groupId>org.example</groupId><artifactId>old</artifactId><versioning><release>0.9.0</release><versions><version>0.9.0</version></versions></versioning></metadata>`))
+	})
+	mux.HandleFunc("/org/example/old/0.9.0/old-0.9.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project><groupId>org.example</groupId><artifactId>old</artifactId><version>0.9.0</version><packaging>pom</packaging></project>`))
+	})
+	mux.HandleFunc("/org/example/old-jvm/0.9.0/old-jvm-0.9.0.pom", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<project><groupId>org.example</groupId><artifactId>old-jvm</artifactId><version>0.9.0</version></project>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	pkg, err := New(server.URL, core.DefaultClient()).WithMultiplatformDetection(true).FetchPackage(context.Background(), "org.example:old")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Metadata["multiplatform"] != true {
+		t.Errorf("expected multiplatform to be set, got %v", pkg.Metadata)
+	}
+	want := map[string]string{"jvm": "org.example:old-jvm"}
+	if got, _ := pkg.Metadata["platform_variants"].(map[string]string); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected platform variants %v, got %v", want, pkg.Metadata["platform_variants"])
+	}
+}
+
+func TestPackaging(t *testing.T) {
+	tests := []struct {
+		name      string
+		packaging string
+		want      string
+		extension string
+	}{
+		{"androidx.core:core", "<packaging>aar</packaging>", "aar", "aar"},
+		{"androidx.annotation:annotation", "", "jar", "jar"},
+		{"com.google.android.gms:strict-version-matcher-plugin", "", "jar", "jar"},
+		{"org.springframework.boot:spring-boot-dependencies", "<packaging>pom</packaging>", "pom", "pom"},
+		{"org.apache.felix:org.apache.felix.scr", "<packaging>bundle</packaging>", "bundle", "jar"},
+		{"com.google.guava:guava", "", "jar", "jar"},
+		{"org.glassfish.hk2:hk2-api", "<packaging>hk2-jar</packaging>", "hk2-jar", "jar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			groupID, artifactID, _ := ParseCoordinates(tt.name)
+			dir := "/" + groupIDToPath(groupID) + "/" + artifactID
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case dir + "/maven-metadata.xml":
+					_, _ = w.Write([]byte(`<metadata><versioning><release>1.0.0</release><versions><version>1.0.0</version></versions></versioning></metadata>`))
+				case dir + "/1.0.0/" + artifactID + "-1.0.0.pom":
+					_, _ = w.Write([]byte(`<project><artifactId>` + artifactID + `</artifactId><version>1.0.0</version>` + tt.packaging + `</project>`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			reg := New(server.URL, core.DefaultClient())
+			wantDownload := server.URL + dir + "/1.0.0/" + artifactID + "-1.0.0." + tt.extension
+			assumed := ".jar"
+			if androidLibraryGroups[groupID] {
+				assumed = ".aar"
+			}
+			before := reg.URLs().Download(tt.name, "1.0.0")
+			if !strings.HasSuffix(before, assumed) {
+				t.Errorf("expected Download to assume %s, got %q", assumed, before)
+			}
+
+			pkg, err := reg.FetchPackage(context.Background(), tt.name)
+			if err != nil {
+				t.Fatalf("FetchPackage failed: %v", err)
+			}
+			if pkg.Metadata["packaging"] != tt.want {
+				t.Errorf("expected packaging %q, got %v", tt.want, pkg.Metadata["packaging"])
+			}
+			if got := reg.URLs().Download(tt.name, "1.0.0"); got != before {
+				t.Errorf("expected Download not to depend on fetched POMs, got %q then %q", before, got)
+			}
+			urls := reg.URLs().(*URLs)
+			if got := urls.DownloadForPackaging(tt.name, "1.0.0", tt.want); got != wantDownload {
+				t.Errorf("expected download URL %q, got %q", wantDownload, got)
+			}
+		})
+	}
+}
+
 func TestURLBuilder(t *testing.T) {
 	reg := New("https://repo1.maven.org/maven2", nil)
 	urls := reg.URLs()
@@ -384,6 +1779,11 @@ func TestURLBuilder(t *testing.T) {
 		{"registry", func() string { return urls.Registry("com.google.guava:guava", "32.1.0") }, "https://search.maven.org/artifact/com.google.guava/guava/32.1.0/jar"},
 		{"download", func() string { return urls.Download("com.google.guava:guava", "32.1.0") }, "https://repo1.maven.org/maven2/com/google/guava/guava/32.1.0/guava-32.1.0.jar"},
 		{"documentation", func() string { return urls.Documentation("com.google.guava:guava", "32.1.0") }, "https://javadoc.io/doc/com.google.guava/guava/32.1.0"},
+		{"android library download", func() string { return urls.Download("androidx.core:core-ktx", "1.12.0") }, "https://repo1.maven.org/maven2/androidx/core/core-ktx/1.12.0/core-ktx-1.12.0.aar"},
+		{"androidx jar download", func() string { return urls.Download("androidx.annotation:annotation", "1.7.0") }, "https://repo1.maven.org/maven2/androidx/annotation/annotation/1.7.0/annotation-1.7.0.jar"},
+		{"android gradle plugin download", func() string { return urls.Download("com.android.tools.build:gradle", "8.2.0") }, "https://repo1.maven.org/maven2/com/android/tools/build/gradle/8.2.0/gradle-8.2.0.jar"},
+		{"androidx documentation", func() string { return urls.Documentation("androidx.compose.ui:ui", "1.6.0") }, "https://developer.android.com/jetpack/androidx/releases/compose-ui#1.6.0"},
+		{"androidx documentation no version", func() string { return urls.Documentation("androidx.core:core", "") }, "https://developer.android.com/jetpack/androidx/releases/core"},
 		{"purl", func() string { return urls.PURL("com.google.guava:guava", "32.1.0") }, "pkg:maven/com.google.guava/guava@32.1.0"},
 	}
 
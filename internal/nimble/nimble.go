@@ -3,9 +3,12 @@ package nimble
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/git-pkgs/registries/internal/core"
 	"github.com/git-pkgs/registries/internal/urlparser"
@@ -14,20 +17,36 @@ import (
 const (
 	DefaultURL = "https://nimble.directory"
 	ecosystem  = "nimble"
+
+	// DefaultPackagesURL is the official package list the directory is
+	// built from, used when the directory can't answer.
+	DefaultPackagesURL = "https://raw.githubusercontent.com/nim-lang/packages/master/packages.json"
 )
 
+// semverRegex matches the numeric versions Nimble packages normally use.
+// Anything else (branch names, "#head" and the like) can't be ordered.
+var semverRegex = regexp.MustCompile(`^v?\d+(\.\d+)*([-+].*)?$`)
+
 func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"alias", "doc", "index_fallback", "method",
+	)
 }
 
 type Registry struct {
-	baseURL string
-	client  *core.Client
-	urls    *URLs
+	baseURL     string
+	packagesURL string
+	client      *core.Client
+	urls        *URLs
+	index       *packageIndex
 }
 
+// New creates a registry for the directory at baseURL. For the default
+// directory, lookups fall back to the official packages.json when the
+// directory fails; use WithPackagesURL to change or disable this.
 func New(baseURL string, client *core.Client) *Registry {
 	if baseURL == "" {
 		baseURL = DefaultURL
@@ -35,11 +54,25 @@ func New(baseURL string, client *core.Client) *Registry {
 	r := &Registry{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		client:  client,
+		index:   &packageIndex{},
+	}
+	if r.baseURL == DefaultURL {
+		r.packagesURL = DefaultPackagesURL
 	}
 	r.urls = &URLs{baseURL: r.baseURL}
 	return r
 }
 
+// WithPackagesURL returns a copy of the registry that falls back to the
+// package list at packagesURL, in the format of nim-lang/packages'
+// packages.json. An empty URL disables the fallback.
+func (r *Registry) WithPackagesURL(packagesURL string) *Registry {
+	copy := *r
+	copy.packagesURL = packagesURL
+	copy.index = &packageIndex{}
+	return &copy
+}
+
 func (r *Registry) Ecosystem() string {
 	return ecosystem
 }
@@ -74,6 +107,8 @@ type packageDetailResponse struct {
 	Web         string   `json:"web"`
 	Doc         string   `json:"doc"`
 	Versions    []versionDetail `json:"versions"`
+
+	fromIndex bool // built from packages.json, see fetchDetail
 }
 
 type versionDetail struct {
@@ -81,14 +116,104 @@ type versionDetail struct {
 	Requires []string `json:"requires"`
 }
 
-func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+// indexEntry is a package in packages.json. Renamed packages are left as
+// an entry with only the name and an alias for the new name.
+type indexEntry struct {
+	Name        string   `json:"name"`
+	Alias       string   `json:"alias"`
+	URL         string   `json:"url"`
+	Method      string   `json:"method"`
+	Tags        []string `json:"tags"`
+	Description string   `json:"description"`
+	License     string   `json:"license"`
+	Web         string   `json:"web"`
+	Doc         string   `json:"doc"`
+}
+
+// packageIndex holds packages.json once it has been fetched. It is shared
+// by a registry's copies and kept for the registry's lifetime.
+type packageIndex struct {
+	mu      sync.Mutex
+	entries map[string]indexEntry // by lowercased name
+}
+
+// lookup returns the packages.json entry for name, following an alias.
+// Nim package names are case-insensitive.
+func (r *Registry) lookup(ctx context.Context, name string) (*indexEntry, error) {
+	r.index.mu.Lock()
+	defer r.index.mu.Unlock()
+
+	if r.index.entries == nil {
+		var entries []indexEntry
+		if err := r.client.GetJSON(ctx, r.packagesURL, &entries); err != nil {
+			return nil, err
+		}
+		r.index.entries = make(map[string]indexEntry, len(entries))
+		for _, e := range entries {
+			r.index.entries[strings.ToLower(e.Name)] = e
+		}
+	}
+
+	entry, ok := r.index.entries[strings.ToLower(name)]
+	if ok && entry.Alias != "" {
+		entry, ok = r.index.entries[strings.ToLower(entry.Alias)]
+	}
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// fetchDetail returns the directory's entry for name. If the directory
+// fails, the package is looked up in packages.json instead, with its
+// versions taken from the git tags of its GitHub or GitLab repository;
+// those versions have no dependencies. The directory's error is returned
+// if the fallback is disabled or can't find the package.
+func (r *Registry) fetchDetail(ctx context.Context, name string) (*packageDetailResponse, error) {
 	url := fmt.Sprintf("%s/api/packages/%s", r.baseURL, name)
 
 	var resp packageDetailResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	err := r.client.GetJSON(ctx, url, &resp)
+	if err == nil {
+		return &resp, nil
+	}
+	if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+		err = &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	}
+	if r.packagesURL == "" || errors.Is(err, core.ErrClientClosed) || ctx.Err() != nil {
+		return nil, err
+	}
+
+	entry, indexErr := r.lookup(ctx, name)
+	if indexErr != nil || entry == nil {
+		return nil, err
+	}
+
+	detail := &packageDetailResponse{
+		Name:        entry.Name,
+		URL:         entry.URL,
+		Method:      entry.Method,
+		Tags:        entry.Tags,
+		Description: entry.Description,
+		License:     entry.License,
+		Web:         entry.Web,
+		Doc:         entry.Doc,
+		fromIndex:   true,
+	}
+	if entry.Method == "git" || entry.Method == "" {
+		tags, _ := core.RepoTags(ctx, entry.URL, r.client)
+		for _, tag := range tags {
+			if semverRegex.MatchString(tag) {
+				detail.Versions = append(detail.Versions, versionDetail{Version: strings.TrimPrefix(tag, "v")})
+			}
 		}
+	}
+	return detail, nil
+}
+
+func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
+	resp, err := r.fetchDetail(ctx, name)
+	if err != nil {
 		return nil, err
 	}
 
@@ -98,47 +223,61 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		homepage = resp.URL
 	}
 
+	var latest string
+	if versions := r.sortVersions(resp.Versions, ""); len(versions) > 0 && semverRegex.MatchString(versions[0].Number) {
+		latest = versions[0].Number
+	}
+
+	metadata := map[string]any{
+		"method": resp.Method,
+		"doc":    resp.Doc,
+		"alias":  resp.Alias,
+	}
+	if resp.fromIndex {
+		metadata["index_fallback"] = true
+	}
+
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Description,
-		Homepage:    homepage,
-		Repository:  urlparser.Parse(resp.URL),
-		Licenses:    resp.License,
-		Keywords:    resp.Tags,
-		Metadata: map[string]any{
-			"method": resp.Method,
-			"doc":    resp.Doc,
-			"alias":  resp.Alias,
-		},
+		Name:          resp.Name,
+		Description:   resp.Description,
+		Homepage:      homepage,
+		Repository:    urlparser.Parse(resp.URL),
+		Licenses:      resp.License,
+		Keywords:      core.NormalizeKeywords(resp.Tags),
+		LatestVersion: latest,
+		Metadata:      metadata,
 	}, nil
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
-	url := fmt.Sprintf("%s/api/packages/%s", r.baseURL, name)
-
-	var resp packageDetailResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
-		}
+	resp, err := r.fetchDetail(ctx, name)
+	if err != nil {
 		return nil, err
 	}
 
-	versions := make([]core.Version, 0, len(resp.Versions))
-	for _, v := range resp.Versions {
-		versions = append(versions, core.Version{
-			Number:   v.Version,
-			Licenses: resp.License,
-		})
-	}
+	return r.sortVersions(resp.Versions, resp.License), nil
+}
 
-	// Sort versions (newest first) - versions are typically already sorted by nimble
-	// but we reverse them to get newest first
-	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
-		versions[i], versions[j] = versions[j], versions[i]
+// sortVersions returns the versions newest first. The API doesn't guarantee
+// any order, so versions are compared as semver, and ones that aren't
+// semver are kept in their original order after the rest.
+func (r *Registry) sortVersions(details []versionDetail, licenses string) []core.Version {
+	var valid, other []core.Version
+	for _, v := range details {
+		version := core.Version{
+			Number:   v.Version,
+			Licenses: licenses,
+		}
+		if semverRegex.MatchString(v.Version) {
+			valid = append(valid, version)
+		} else {
+			other = append(other, version)
+		}
 	}
 
-	return versions, nil
+	versions := make([]core.Version, 0, len(details))
+	versions = append(versions, core.SortedVersions(r, valid)...)
+	return append(versions, other...)
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/git-pkgs/registries/internal/core"
@@ -56,6 +57,9 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Homepage != "https://status-im.github.io/nim-chronicles" {
 		t.Errorf("unexpected homepage: %q", pkg.Homepage)
 	}
+	if pkg.LatestVersion != "0.10.3" {
+		t.Errorf("expected latest version '0.10.3', got %q", pkg.LatestVersion)
+	}
 	if len(pkg.Keywords) != 2 {
 		t.Errorf("expected 2 keywords, got %d", len(pkg.Keywords))
 	}
@@ -67,9 +71,11 @@ func TestFetchVersions(t *testing.T) {
 			Name:    "stew",
 			License: "Apache-2.0",
 			Versions: []versionDetail{
+				{Version: "0.1.10"},
+				{Version: "#head"},
 				{Version: "0.1.0"},
-				{Version: "0.1.1"},
 				{Version: "0.1.2"},
+				{Version: "0.1.2-rc1"},
 			},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
@@ -82,13 +88,16 @@ func TestFetchVersions(t *testing.T) {
 		t.Fatalf("FetchVersions failed: %v", err)
 	}
 
-	if len(versions) != 3 {
-		t.Fatalf("expected 3 versions, got %d", len(versions))
+	if len(versions) != 5 {
+		t.Fatalf("expected 5 versions, got %d", len(versions))
 	}
 
-	// Should be reversed (newest first)
-	if versions[0].Number != "0.1.2" {
-		t.Errorf("expected first version '0.1.2', got %q", versions[0].Number)
+	// Sorted newest first by semver, with non-semver tags last
+	expected := []string{"0.1.10", "0.1.2", "0.1.2-rc1", "0.1.0", "#head"}
+	for i, want := range expected {
+		if versions[i].Number != want {
+			t.Errorf("expected version %d to be %q, got %q", i, want, versions[i].Number)
+		}
 	}
 	if versions[0].Licenses != "Apache-2.0" {
 		t.Errorf("unexpected license: %q", versions[0].Licenses)
@@ -138,6 +147,120 @@ func TestFetchDependencies(t *testing.T) {
 	}
 }
 
+// serverTransport sends every request to one test server, keeping the path,
+// so requests to GitHub's API can be answered locally.
+type serverTransport struct {
+	target *url.URL
+}
+
+func (t serverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newFallbackRegistry(t *testing.T, handler http.HandlerFunc) *Registry {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+
+	client := core.DefaultClient()
+	client.HTTPClient = &http.Client{Transport: serverTransport{target: target}}
+	client.MaxRetries = 0
+	return New(server.URL, client).WithPackagesURL(server.URL + "/packages.json")
+}
+
+const testPackagesJSON = `[
+	{"name": "chronicles", "url": "https://github.com/status-im/nim-chronicles", "method": "git",
+	 "tags": ["logging"], "description": "Structured logging", "license": "Apache-2.0", "web": "https://github.com/status-im/nim-chronicles"},
+	{"name": "oldname", "alias": "chronicles"}
+]`
+
+func TestFetchPackageIndexFallback(t *testing.T) {
+	reg := newFallbackRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/packages/oldname", "/api/packages/chronicles":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/packages.json":
+			_, _ = w.Write([]byte(testPackagesJSON))
+		case "/repos/status-im/nim-chronicles/tags":
+			_, _ = w.Write([]byte(`[{"name": "v0.10.2"}, {"name": "v0.10.3"}, {"name": "nightly"}]`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+		}
+	})
+
+	pkg, err := reg.FetchPackage(context.Background(), "oldname")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Name != "chronicles" {
+		t.Errorf("expected the alias to be followed, got %q", pkg.Name)
+	}
+	if pkg.Repository != "https://github.com/status-im/nim-chronicles" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	if pkg.LatestVersion != "0.10.3" {
+		t.Errorf("expected latest version '0.10.3' from tags, got %q", pkg.LatestVersion)
+	}
+	if pkg.Metadata["index_fallback"] != true {
+		t.Errorf("expected index_fallback to be set, got %v", pkg.Metadata["index_fallback"])
+	}
+
+	versions, err := reg.FetchVersions(context.Background(), "chronicles")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Number != "0.10.3" || versions[1].Number != "0.10.2" {
+		t.Errorf("unexpected versions: %v", versions)
+	}
+	if versions[0].Licenses != "Apache-2.0" {
+		t.Errorf("unexpected licenses: %q", versions[0].Licenses)
+	}
+}
+
+func TestFetchPackageIndexFallbackNotFound(t *testing.T) {
+	var indexFetches int
+	reg := newFallbackRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/packages.json":
+			indexFetches++
+			_, _ = w.Write([]byte(testPackagesJSON))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := reg.FetchPackage(context.Background(), "missing")
+		if _, ok := err.(*core.NotFoundError); !ok {
+			t.Errorf("expected NotFoundError, got %v", err)
+		}
+	}
+	if indexFetches != 1 {
+		t.Errorf("expected packages.json to be fetched once, got %d", indexFetches)
+	}
+}
+
+func TestWithPackagesURLDisabled(t *testing.T) {
+	reg := newFallbackRegistry(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/packages/chronicles" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}).WithPackagesURL("")
+
+	_, err := reg.FetchPackage(context.Background(), "chronicles")
+	if httpErr, ok := err.(*core.HTTPError); !ok || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the directory's error, got %v", err)
+	}
+	if New("", nil).packagesURL != DefaultPackagesURL {
+		t.Error("expected the default directory to fall back to packages.json")
+	}
+}
+
 func TestFetchMaintainers(t *testing.T) {
 	reg := New("", nil)
 	maintainers, err := reg.FetchMaintainers(context.Background(), "chronicles")
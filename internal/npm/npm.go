@@ -20,6 +20,10 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"_npmUser", "bundled", "deprecated", "dist", "dist-tags",
+		"engines", "funding", "tarball", "vcs_ref", "vcs_url",
+	)
 }
 
 type Registry struct {
@@ -58,6 +62,7 @@ type packageResponse struct {
 	Time        map[string]string          `json:"time"`
 	Maintainers []maintainerInfo           `json:"maintainers"`
 	DistTags    map[string]string          `json:"dist-tags"`
+	Readme      string                     `json:"readme"`
 }
 
 type versionInfo struct {
@@ -71,12 +76,17 @@ type versionInfo struct {
 	Dependencies map[string]string      `json:"dependencies"`
 	DevDeps      map[string]string      `json:"devDependencies"`
 	OptionalDeps map[string]string      `json:"optionalDependencies"`
+	PeerDeps     map[string]string      `json:"peerDependencies"`
+	PeerDepsMeta map[string]peerDepMeta `json:"peerDependenciesMeta"`
+	BundledDeps  interface{}            `json:"bundledDependencies"`
+	BundleDeps   interface{}            `json:"bundleDependencies"`
 	Deprecated   string                 `json:"deprecated"`
 	Dist         distInfo               `json:"dist"`
 	Maintainers  []maintainerInfo       `json:"maintainers"`
 	NpmUser      map[string]interface{} `json:"_npmUser"`
 	Engines      map[string]string      `json:"engines"`
 	Funding      interface{}            `json:"funding"`
+	Readme       string                 `json:"readme"`
 }
 
 type distInfo struct {
@@ -85,6 +95,10 @@ type distInfo struct {
 	Integrity string `json:"integrity"`
 }
 
+type peerDepMeta struct {
+	Optional bool `json:"optional"`
+}
+
 type maintainerInfo struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
@@ -119,7 +133,7 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		Homepage:      extractString(resp.Homepage),
 		Repository:    core.ExtractRepoURLWithFallback(latest.Repository, resp.Repository),
 		Licenses:      core.ExtractLicense(latest.License),
-		Keywords:      extractKeywords(latest.Keywords),
+		Keywords:      core.NormalizeKeywords(extractKeywords(latest.Keywords)),
 		Namespace:     extractNamespace(resp.ID),
 		LatestVersion: latestVersion,
 		Metadata: map[string]any{
@@ -131,6 +145,19 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	return pkg, nil
 }
 
+// version looks up a version by number, or by dist-tag such as "latest"
+// or "next" when no version has that number.
+func (resp *packageResponse) version(version string) (versionInfo, bool) {
+	if v, ok := resp.Versions[version]; ok {
+		return v, true
+	}
+	if tagged, ok := resp.DistTags[version]; ok {
+		v, ok := resp.Versions[tagged]
+		return v, ok
+	}
+	return versionInfo{}, false
+}
+
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
 	escapedName := url.PathEscape(name)
 	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
@@ -179,6 +206,8 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 	return versions, nil
 }
 
+// FetchDependencies accepts a dist-tag such as "latest" or "next" in place
+// of a version number.
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
 	escapedName := url.PathEscape(name)
 	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
@@ -191,19 +220,28 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		return nil, err
 	}
 
-	v, ok := resp.Versions[version]
+	v, ok := resp.version(version)
 	if !ok {
 		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
 	}
 
 	var deps []core.Dependency
+	bundled := bundledDependencies(v)
 
 	for depName, req := range v.Dependencies {
-		deps = append(deps, core.Dependency{
+		// npm copies optionalDependencies into dependencies when publishing
+		if _, ok := v.OptionalDeps[depName]; ok {
+			continue
+		}
+		dep := core.Dependency{
 			Name:         depName,
 			Requirements: req,
 			Scope:        core.Runtime,
-		})
+		}
+		if bundled[depName] {
+			dep.Metadata = map[string]any{"bundled": true}
+		}
+		deps = append(deps, dep)
 	}
 
 	for depName, req := range v.DevDeps {
@@ -223,9 +261,112 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		})
 	}
 
+	for depName, req := range v.PeerDeps {
+		deps = append(deps, core.Dependency{
+			Name:         depName,
+			Requirements: req,
+			Scope:        core.Peer,
+			Optional:     v.PeerDepsMeta[depName].Optional,
+		})
+	}
+
+	for i := range deps {
+		core.TagVCSDependency(&deps[i])
+	}
+
 	return deps, nil
 }
 
+// bundledDependencies returns the names listed in bundledDependencies (or
+// its older spelling bundleDependencies). The field is either a list of
+// names or true, which bundles every runtime dependency.
+func bundledDependencies(v versionInfo) map[string]bool {
+	field := v.BundledDeps
+	if field == nil {
+		field = v.BundleDeps
+	}
+
+	bundled := make(map[string]bool)
+	switch b := field.(type) {
+	case bool:
+		if b {
+			for name := range v.Dependencies {
+				bundled[name] = true
+			}
+		}
+	case []interface{}:
+		for _, name := range b {
+			if s, ok := name.(string); ok {
+				bundled[s] = true
+			}
+		}
+	}
+	return bundled
+}
+
+// ResolveTag returns the version a dist-tag such as "latest" or "next"
+// points to, or an empty string if the package has no such tag. It reads
+// the small /-/package/{name}/dist-tags document, and falls back to the
+// packument for registries that don't serve it.
+func (r *Registry) ResolveTag(ctx context.Context, name, tag string) (string, error) {
+	escapedName := url.PathEscape(name)
+
+	var tags map[string]string
+	err := r.client.GetJSON(ctx, fmt.Sprintf("%s/-/package/%s/dist-tags", r.baseURL, escapedName), &tags)
+	if err == nil {
+		return tags[tag], nil
+	}
+	if ctx.Err() != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
+	var resp packageResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return "", &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return "", err
+	}
+	return resp.DistTags[tag], nil
+}
+
+// missingReadme is the placeholder npm stores when a package was published
+// without a README.
+const missingReadme = "ERROR: No README data found!"
+
+// FetchReadme returns the README npm stored for a version, falling back to
+// the packument's README (that of the latest version) when the version has
+// none of its own. An empty version returns the packument's README.
+func (r *Registry) FetchReadme(ctx context.Context, name, version string) (string, error) {
+	escapedName := url.PathEscape(name)
+	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
+
+	var resp packageResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return "", &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return "", err
+	}
+
+	readme := resp.Readme
+	if version != "" {
+		v, ok := resp.version(version)
+		if !ok {
+			return "", &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		if v.Readme != "" {
+			readme = v.Readme
+		}
+	}
+
+	if readme == missingReadme {
+		return "", nil
+	}
+	return readme, nil
+}
+
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
 	escapedName := url.PathEscape(name)
 	url := fmt.Sprintf("%s/%s", r.baseURL, escapedName)
@@ -279,11 +420,8 @@ func extractKeywords(v interface{}) []string {
 }
 
 func extractNamespace(id string) string {
-	if strings.HasPrefix(id, "@") && strings.Contains(id, "/") {
-		parts := strings.SplitN(id, "/", 2)
-		return strings.TrimPrefix(parts[0], "@")
-	}
-	return ""
+	namespace, _ := core.SplitName(ecosystem, id)
+	return namespace
 }
 
 func coalesceString(values ...string) string {
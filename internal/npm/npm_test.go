@@ -3,8 +3,10 @@ package npm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/git-pkgs/registries/internal/core"
@@ -165,6 +167,255 @@ func TestFetchDependencies(t *testing.T) {
 	}
 }
 
+func TestFetchDependenciesDistTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := map[string]interface{}{
+			"_id":       "react",
+			"dist-tags": map[string]string{"latest": "18.3.1", "next": "19.0.0-rc.1", "stale": "0.1.0"},
+			"versions": map[string]interface{}{
+				"18.3.1": map[string]interface{}{
+					"dependencies": map[string]string{"loose-envify": "^1.1.0"},
+				},
+				"19.0.0-rc.1": map[string]interface{}{
+					"dependencies": map[string]string{"scheduler": "0.25.0-rc.1"},
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	for tag, want := range map[string]string{"latest": "loose-envify", "next": "scheduler"} {
+		deps, err := reg.FetchDependencies(context.Background(), "react", tag)
+		if err != nil {
+			t.Fatalf("FetchDependencies(%s) failed: %v", tag, err)
+		}
+		if len(deps) != 1 || deps[0].Name != want {
+			t.Errorf("%s: expected %s, got %v", tag, want, deps)
+		}
+	}
+
+	for _, tag := range []string{"beta", "stale"} {
+		if _, err := reg.FetchDependencies(context.Background(), "react", tag); !errors.Is(err, core.ErrNotFound) {
+			t.Errorf("%s: expected ErrNotFound, got %v", tag, err)
+		}
+	}
+}
+
+func TestResolveTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/react" {
+			http.NotFound(w, r)
+			return
+		}
+		resp := map[string]interface{}{
+			"_id":       "react",
+			"dist-tags": map[string]string{"latest": "18.3.1", "next": "19.0.0-rc.1"},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	for tag, want := range map[string]string{"next": "19.0.0-rc.1", "beta": ""} {
+		got, err := reg.ResolveTag(context.Background(), "react", tag)
+		if err != nil {
+			t.Fatalf("ResolveTag(%s) failed: %v", tag, err)
+		}
+		if got != want {
+			t.Errorf("ResolveTag(%s) = %q, want %q", tag, got, want)
+		}
+	}
+
+	if _, err := reg.ResolveTag(context.Background(), "missing", "latest"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestResolveTagEndpoint(t *testing.T) {
+	var packuments atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/-/package/@babel%2Fcore/dist-tags":
+			_, _ = w.Write([]byte(`{"latest": "7.24.0", "next": "8.0.0-alpha.7"}`))
+		default:
+			packuments.Add(1)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	got, err := New(server.URL, core.DefaultClient()).ResolveTag(context.Background(), "@babel/core", "next")
+	if err != nil {
+		t.Fatalf("ResolveTag failed: %v", err)
+	}
+	if got != "8.0.0-alpha.7" {
+		t.Errorf("expected 8.0.0-alpha.7, got %q", got)
+	}
+	if n := packuments.Load(); n != 0 {
+		t.Errorf("expected no packument requests, got %d", n)
+	}
+}
+
+func TestFetchDependenciesPeerAndBundled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"_id": "react-dom",
+			"versions": {
+				"18.3.1": {
+					"dependencies": {"loose-envify": "^1.1.0", "scheduler": "^0.23.2", "fsevents": "~2.3.2"},
+					"optionalDependencies": {"fsevents": "~2.3.2"},
+					"peerDependencies": {"react": "^18.3.1", "@types/react": "*"},
+					"peerDependenciesMeta": {"@types/react": {"optional": true}},
+					"bundleDependencies": ["scheduler"]
+				}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "react-dom", "18.3.1")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	byName := make(map[string]core.Dependency)
+	for _, d := range deps {
+		byName[d.Name] = d
+	}
+	if len(deps) != 5 {
+		t.Fatalf("expected 5 dependencies, got %d: %+v", len(deps), deps)
+	}
+
+	if d := byName["fsevents"]; d.Scope != core.Optional {
+		t.Errorf("expected fsevents to be listed once as optional, got %q", d.Scope)
+	}
+	if d := byName["react"]; d.Scope != core.Peer || d.Optional {
+		t.Errorf("expected react to be a required peer, got %+v", d)
+	}
+	if d := byName["@types/react"]; d.Scope != core.Peer || !d.Optional {
+		t.Errorf("expected @types/react to be an optional peer, got %+v", d)
+	}
+	if !byName["scheduler"].IsBundled() {
+		t.Error("expected scheduler to be marked as bundled")
+	}
+	if byName["loose-envify"].IsBundled() {
+		t.Error("expected loose-envify not to be bundled")
+	}
+}
+
+func TestFetchDependenciesGitSources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"_id": "app",
+			"versions": {
+				"1.0.0": {
+					"dependencies": {
+						"left-pad": "^1.3.0",
+						"cli": "git+https://github.com/npm/cli.git#v10.2.0",
+						"express": "expressjs/express#4.18.2"
+					},
+					"devDependencies": {"tool": "github:example/tool"}
+				}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "app", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+
+	byName := make(map[string]core.Dependency)
+	for _, d := range deps {
+		byName[d.Name] = d
+	}
+
+	if byName["left-pad"].Metadata != nil {
+		t.Errorf("expected no metadata for a registry dependency, got %v", byName["left-pad"].Metadata)
+	}
+	if cli := byName["cli"]; cli.Metadata["vcs_url"] != "https://github.com/npm/cli" || cli.Metadata["vcs_ref"] != "v10.2.0" {
+		t.Errorf("unexpected vcs metadata for cli: %v", cli.Metadata)
+	}
+	if cli := byName["cli"]; cli.Requirements != "git+https://github.com/npm/cli.git#v10.2.0" {
+		t.Errorf("expected requirement to be kept, got %q", cli.Requirements)
+	}
+	if express := byName["express"]; express.Metadata["vcs_url"] != "https://github.com/expressjs/express" || express.Metadata["vcs_ref"] != "4.18.2" {
+		t.Errorf("unexpected vcs metadata for express: %v", express.Metadata)
+	}
+	if tool := byName["tool"]; tool.Metadata["vcs_url"] != "https://github.com/example/tool" {
+		t.Errorf("unexpected vcs metadata for tool: %v", tool.Metadata)
+	}
+}
+
+func TestBundledDependencies(t *testing.T) {
+	v := versionInfo{
+		Dependencies: map[string]string{"a": "1", "b": "2"},
+		BundledDeps:  true,
+	}
+	if bundled := bundledDependencies(v); !bundled["a"] || !bundled["b"] {
+		t.Errorf("expected all dependencies to be bundled, got %v", bundled)
+	}
+
+	v.BundledDeps = false
+	if bundled := bundledDependencies(v); len(bundled) != 0 {
+		t.Errorf("expected nothing bundled, got %v", bundled)
+	}
+}
+
+func TestFetchReadme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/lodash":
+			_, _ = w.Write([]byte(`{
+				"readme": "# lodash v4",
+				"versions": {
+					"4.17.21": {},
+					"3.0.0": {"readme": "# lodash v3"}
+				}
+			}`))
+		case "/empty":
+			_, _ = w.Write([]byte(`{"readme": "ERROR: No README data found!", "versions": {"1.0.0": {}}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		version  string
+		expected string
+	}{
+		{"lodash", "", "# lodash v4"},
+		{"lodash", "4.17.21", "# lodash v4"},
+		{"lodash", "3.0.0", "# lodash v3"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		readme, err := reg.FetchReadme(ctx, tt.name, tt.version)
+		if err != nil {
+			t.Fatalf("FetchReadme(%q, %q) failed: %v", tt.name, tt.version, err)
+		}
+		if readme != tt.expected {
+			t.Errorf("FetchReadme(%q, %q) = %q, expected %q", tt.name, tt.version, readme, tt.expected)
+		}
+	}
+
+	if _, err := reg.FetchReadme(ctx, "lodash", "9.9.9"); err == nil {
+		t.Error("expected an error for a missing version")
+	}
+}
+
 func TestFetchMaintainers(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resp := map[string]interface{}{
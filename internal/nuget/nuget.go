@@ -20,6 +20,9 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"deprecation", "icon_url", "license_url", "listed",
+	)
 }
 
 type Registry struct {
@@ -120,11 +123,6 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
 	}
 
-	var keywords []string
-	if len(latest.Tags) > 0 {
-		keywords = latest.Tags
-	}
-
 	description := latest.Description
 	if description == "" {
 		description = latest.Summary
@@ -136,12 +134,13 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	return &core.Package{
-		Name:        latest.ID,
-		Description: description,
-		Homepage:    latest.ProjectURL,
-		Repository:  extractRepository(latest.ProjectURL),
-		Licenses:    licenses,
-		Keywords:    keywords,
+		Name:          latest.ID,
+		Description:   description,
+		Homepage:      latest.ProjectURL,
+		Repository:    extractRepository(latest.ProjectURL),
+		Licenses:      licenses,
+		Keywords:      core.NormalizeKeywords(latest.Tags),
+		LatestVersion: latest.Version,
 		Metadata: map[string]any{
 			"icon_url":    latest.IconURL,
 			"license_url": latest.LicenseURL,
@@ -58,6 +58,9 @@ func TestFetchPackage(t *testing.T) {
 	if len(pkg.Keywords) != 1 || pkg.Keywords[0] != "json" {
 		t.Errorf("unexpected keywords: %v", pkg.Keywords)
 	}
+	if pkg.LatestVersion != "13.0.3" {
+		t.Errorf("expected latest version '13.0.3', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchPackageWithGitHubRepository(t *testing.T) {
@@ -160,6 +163,11 @@ func TestFetchVersions(t *testing.T) {
 	if statusMap["2.4.0"] != core.StatusDeprecated {
 		t.Errorf("expected deprecated status for 2.4.0, got %q", statusMap["2.4.0"])
 	}
+
+	kept := core.ExcludeVersions(versions, core.StatusYanked)
+	if len(kept) != 2 || kept[0].Number != "2.6.0" || kept[1].Number != "2.4.0" {
+		t.Errorf("expected the unlisted version to be excluded, got %v", kept)
+	}
 }
 
 func TestFetchDependencies(t *testing.T) {
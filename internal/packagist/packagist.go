@@ -2,13 +2,17 @@
 package packagist
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
-	"github.com/git-pkgs/registries/internal/urlparser"
 )
 
 const (
@@ -20,12 +24,17 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"abandoned", "dist_reference", "dist_type", "dist_url",
+		"repository", "source_reference", "source_type", "type",
+	)
 }
 
 type Registry struct {
 	baseURL string
 	client  *core.Client
 	urls    *URLs
+	repo    *repositoryIndex
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -35,6 +44,7 @@ func New(baseURL string, client *core.Client) *Registry {
 	r := &Registry{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		client:  client,
+		repo:    &repositoryIndex{includes: make(map[string]*includeFile)},
 	}
 	r.urls = &URLs{baseURL: r.baseURL}
 	return r
@@ -81,6 +91,8 @@ type versionInfo struct {
 	Dist             distInfo          `json:"dist"`
 	Require          map[string]string `json:"require"`
 	RequireDev       map[string]string `json:"require-dev"`
+	Type             string            `json:"type"`
+	Abandoned        interface{}       `json:"abandoned"`
 }
 
 type sourceInfo struct {
@@ -97,23 +109,18 @@ type distInfo struct {
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
-	url := fmt.Sprintf("%s/packages/%s.json", r.baseURL, name)
-
-	var resp packageResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+	info, err := r.fetchPackageInfo(ctx, name)
+	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
 		}
 		return nil, err
 	}
 
-	pkg := resp.Package
+	pkg := info
 
 	// Extract namespace (vendor) from name
-	var namespace string
-	if parts := strings.SplitN(name, "/", 2); len(parts) == 2 {
-		namespace = parts[0]
-	}
+	namespace, _ := core.SplitName(ecosystem, name)
 
 	// Find the latest stable version for homepage/repository
 	var homepage, repository, licenses string
@@ -122,7 +129,7 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 			homepage = v.Homepage
 		}
 		if v.Source.URL != "" && repository == "" {
-			repository = urlparser.Parse(v.Source.URL)
+			repository = core.NormalizeRepository(v.Source.URL)
 		}
 		if len(v.License) > 0 && licenses == "" {
 			licenses = strings.Join(v.License, ",")
@@ -131,16 +138,17 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 
 	// Fallback to package-level repository
 	if repository == "" && pkg.Repository != "" {
-		repository = urlparser.Parse(pkg.Repository)
+		repository = core.NormalizeRepository(pkg.Repository)
 	}
 
 	return &core.Package{
-		Name:        pkg.Name,
-		Description: pkg.Description,
-		Homepage:    homepage,
-		Repository:  repository,
-		Licenses:    licenses,
-		Namespace:   namespace,
+		Name:          pkg.Name,
+		Description:   pkg.Description,
+		Homepage:      homepage,
+		Repository:    repository,
+		Licenses:      licenses,
+		Namespace:     namespace,
+		LatestVersion: latestVersion(pkg.Versions),
 		Metadata: map[string]any{
 			"type":      pkg.Type,
 			"abandoned": pkg.Abandoned,
@@ -149,18 +157,16 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
-	url := fmt.Sprintf("%s/packages/%s.json", r.baseURL, name)
-
-	var resp packageResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+	info, err := r.fetchPackageInfo(ctx, name)
+	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
 		}
 		return nil, err
 	}
 
-	versions := make([]core.Version, 0, len(resp.Package.Versions))
-	for _, v := range resp.Package.Versions {
+	versions := make([]core.Version, 0, len(info.Versions))
+	for _, v := range info.Versions {
 		var publishedAt time.Time
 		if v.Time != "" {
 			publishedAt, _ = time.Parse(time.RFC3339, v.Time)
@@ -172,7 +178,7 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		}
 
 		var status core.VersionStatus
-		if resp.Package.Abandoned != nil {
+		if info.Abandoned != nil {
 			status = core.StatusDeprecated
 		}
 
@@ -183,8 +189,12 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 			Integrity:   integrity,
 			Status:      status,
 			Metadata: map[string]any{
-				"dist_url":  v.Dist.URL,
-				"dist_type": v.Dist.Type,
+				"dist_url":         v.Dist.URL,
+				"dist_type":        v.Dist.Type,
+				"dist_reference":   v.Dist.Reference,
+				"source_type":      v.Source.Type,
+				"source_reference": v.Source.Reference,
+				"repository":       core.NormalizeRepository(v.Source.URL),
 			},
 		})
 	}
@@ -193,20 +203,18 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
-	url := fmt.Sprintf("%s/packages/%s.json", r.baseURL, name)
-
-	var resp packageResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+	info, err := r.fetchPackageInfo(ctx, name)
+	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
 		}
 		return nil, err
 	}
 
-	versionInfo, ok := resp.Package.Versions[version]
+	versionInfo, ok := info.Versions[version]
 	if !ok {
 		// Try with 'v' prefix
-		versionInfo, ok = resp.Package.Versions["v"+version]
+		versionInfo, ok = info.Versions["v"+version]
 		if !ok {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
 		}
@@ -219,10 +227,14 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 		if depName == "php" || strings.HasPrefix(depName, "ext-") {
 			continue
 		}
+		scope := core.Runtime
+		if hostProvided[depName] {
+			scope = core.Peer
+		}
 		deps = append(deps, core.Dependency{
 			Name:         depName,
 			Requirements: req,
-			Scope:        core.Runtime,
+			Scope:        scope,
 		})
 	}
 
@@ -237,19 +249,26 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 	return deps, nil
 }
 
-func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	url := fmt.Sprintf("%s/packages/%s.json", r.baseURL, name)
+// hostProvided lists the packages Composer itself provides to the packages
+// it installs, such as the plugin API. Requirements on them constrain the
+// host Composer rather than pulling in a package, like npm peers.
+var hostProvided = map[string]bool{
+	"composer":             true,
+	"composer-plugin-api":  true,
+	"composer-runtime-api": true,
+}
 
-	var resp packageResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
+	info, err := r.fetchPackageInfo(ctx, name)
+	if err != nil {
 		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
 			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
 		}
 		return nil, err
 	}
 
-	maintainers := make([]core.Maintainer, len(resp.Package.Maintainers))
-	for i, m := range resp.Package.Maintainers {
+	maintainers := make([]core.Maintainer, len(info.Maintainers))
+	for i, m := range info.Maintainers {
 		maintainers[i] = core.Maintainer{
 			Login: m.Name,
 			Name:  m.Name,
@@ -260,6 +279,360 @@ func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Ma
 	return maintainers, nil
 }
 
+// repositoryIndex holds the root packages.json of a Composer repository,
+// as served by Satis, Private Packagist or a plain file server, and the
+// include files read from it. It is shared by a registry's copies and kept
+// for the registry's lifetime.
+type repositoryIndex struct {
+	mu       sync.Mutex
+	root     *rootFile
+	includes map[string]*includeFile // by resolved URL
+
+	// active is set once the repository protocol has served a package, so
+	// later lookups skip the Packagist API that the repository doesn't have.
+	active bool
+}
+
+// rootFile is a repository's packages.json. A repository lists its
+// packages in one of three ways: a metadata-url template (Composer 2), a
+// providers-url template with a hash per package (Composer 1), or the
+// packages themselves, inline or in include files (Satis).
+type rootFile struct {
+	Packages         json.RawMessage     `json:"packages"`
+	Includes         map[string]hashInfo `json:"includes"`
+	MetadataURL      string              `json:"metadata-url"`
+	ProvidersURL     string              `json:"providers-url"`
+	Providers        map[string]hashInfo `json:"providers"`
+	ProviderIncludes map[string]hashInfo `json:"provider-includes"`
+}
+
+type hashInfo struct {
+	SHA1   string `json:"sha1"`
+	SHA256 string `json:"sha256"`
+}
+
+// includeFile is a file listed under includes or provider-includes.
+type includeFile struct {
+	Packages  json.RawMessage     `json:"packages"`
+	Providers map[string]hashInfo `json:"providers"`
+}
+
+// metadataFile is a Composer 2 metadata file, whose versions are a list
+// that may be minified.
+type metadataFile struct {
+	Packages map[string][]map[string]json.RawMessage `json:"packages"`
+	Minified string                                  `json:"minified"`
+}
+
+// unsetMarker removes a field inherited from the previous version in
+// minified metadata.
+const unsetMarker = `"__unset"`
+
+// fetchPackageInfo reads a package from the Packagist API. For a registry
+// pointed at another host, a package the API can't find is looked up
+// through the Composer repository protocol instead, starting from the
+// repository's packages.json.
+func (r *Registry) fetchPackageInfo(ctx context.Context, name string) (*packageInfo, error) {
+	r.repo.mu.Lock()
+	active := r.repo.active
+	r.repo.mu.Unlock()
+	if active {
+		return r.fetchFromRepository(ctx, name)
+	}
+
+	url := fmt.Sprintf("%s/packages/%s.json", r.baseURL, name)
+	var resp packageResponse
+	err := r.client.GetJSON(ctx, url, &resp)
+	if err == nil {
+		return &resp.Package, nil
+	}
+	httpErr, ok := err.(*core.HTTPError)
+	if r.baseURL == DefaultURL || !ok || !httpErr.IsNotFound() {
+		return nil, err
+	}
+
+	info, repoErr := r.fetchFromRepository(ctx, name)
+	if repoErr != nil {
+		if _, ok := repoErr.(*core.HTTPError); ok {
+			return nil, err
+		}
+		return nil, repoErr
+	}
+	r.repo.mu.Lock()
+	r.repo.active = true
+	r.repo.mu.Unlock()
+	return info, nil
+}
+
+// fetchFromRepository looks a package up through the Composer repository
+// protocol. A package the repository doesn't list is reported as a 404
+// HTTPError.
+func (r *Registry) fetchFromRepository(ctx context.Context, name string) (*packageInfo, error) {
+	root, err := r.repositoryRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions map[string]versionInfo
+	switch {
+	case root.MetadataURL != "":
+		versions, err = r.fetchMetadata(ctx, root.MetadataURL, name)
+	case root.ProvidersURL != "":
+		versions, err = r.fetchProvider(ctx, root, name)
+	default:
+		versions, err = r.fetchListed(ctx, root, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, &core.HTTPError{StatusCode: 404, URL: r.repositoryURL("packages.json")}
+	}
+	return packageFromVersions(name, versions), nil
+}
+
+// repositoryRoot returns the repository's packages.json, fetching it on
+// first use. The lock isn't held across the request, so one slow fetch
+// doesn't block callers reading other files; concurrent fetches of the same
+// URL are coalesced by the client, and the first result stored wins.
+func (r *Registry) repositoryRoot(ctx context.Context) (*rootFile, error) {
+	r.repo.mu.Lock()
+	cached := r.repo.root
+	r.repo.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	var root rootFile
+	if err := r.client.GetJSON(ctx, r.repositoryURL("packages.json"), &root); err != nil {
+		return nil, err
+	}
+
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if r.repo.root == nil {
+		r.repo.root = &root
+	}
+	return r.repo.root, nil
+}
+
+// include returns an include file, fetching it on first use. Like
+// repositoryRoot, it doesn't hold the lock while fetching.
+func (r *Registry) include(ctx context.Context, url string) (*includeFile, error) {
+	r.repo.mu.Lock()
+	cached, ok := r.repo.includes[url]
+	r.repo.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	var file includeFile
+	if err := r.client.GetJSON(ctx, url, &file); err != nil {
+		return nil, err
+	}
+
+	r.repo.mu.Lock()
+	defer r.repo.mu.Unlock()
+	if existing, ok := r.repo.includes[url]; ok {
+		return existing, nil
+	}
+	r.repo.includes[url] = &file
+	return &file, nil
+}
+
+// fetchMetadata reads a package from a Composer 2 metadata-url, expanding
+// minified version lists.
+func (r *Registry) fetchMetadata(ctx context.Context, template, name string) (map[string]versionInfo, error) {
+	url := r.repositoryURL(strings.ReplaceAll(template, "%package%", name))
+
+	var file metadataFile
+	if err := r.client.GetJSON(ctx, url, &file); err != nil {
+		return nil, err
+	}
+
+	entries := file.Packages[name]
+	if file.Minified != "" {
+		entries = expandMinified(entries)
+	}
+
+	versions := make(map[string]versionInfo, len(entries))
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		var v versionInfo
+		if err := core.DecodeJSON(ecosystem, url, data, &v); err != nil {
+			return nil, err
+		}
+		versions[v.Version] = v
+	}
+	return versions, nil
+}
+
+// expandMinified undoes Composer's metadata minification, in which each
+// version lists only the fields that differ from the version before it.
+func expandMinified(entries []map[string]json.RawMessage) []map[string]json.RawMessage {
+	expanded := make([]map[string]json.RawMessage, 0, len(entries))
+	var current map[string]json.RawMessage
+	for _, entry := range entries {
+		next := make(map[string]json.RawMessage, len(current)+len(entry))
+		for k, v := range current {
+			next[k] = v
+		}
+		for k, v := range entry {
+			if string(bytes.TrimSpace(v)) == unsetMarker {
+				delete(next, k)
+				continue
+			}
+			next[k] = v
+		}
+		expanded = append(expanded, next)
+		current = next
+	}
+	return expanded
+}
+
+// fetchProvider reads a package from a Composer 1 providers-url, finding
+// its hash in packages.json or one of its provider-includes.
+func (r *Registry) fetchProvider(ctx context.Context, root *rootFile, name string) (map[string]versionInfo, error) {
+	hash, ok := root.Providers[name]
+	if !ok {
+		for _, path := range sortedKeys(root.ProviderIncludes) {
+			includeURL := r.repositoryURL(strings.ReplaceAll(path, "%hash%", root.ProviderIncludes[path].SHA256))
+			file, err := r.include(ctx, includeURL)
+			if err != nil {
+				return nil, err
+			}
+			if hash, ok = file.Providers[name]; ok {
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	url := strings.ReplaceAll(root.ProvidersURL, "%package%", name)
+	url = r.repositoryURL(strings.ReplaceAll(url, "%hash%", hash.SHA256))
+	var file includeFile
+	if err := r.client.GetJSON(ctx, url, &file); err != nil {
+		return nil, err
+	}
+	return listedVersions(url, file.Packages, name)
+}
+
+// fetchListed finds a package among those listed in packages.json itself
+// or in its include files, as Satis writes them.
+func (r *Registry) fetchListed(ctx context.Context, root *rootFile, name string) (map[string]versionInfo, error) {
+	rootURL := r.repositoryURL("packages.json")
+	versions, err := listedVersions(rootURL, root.Packages, name)
+	if err != nil || versions != nil {
+		return versions, err
+	}
+
+	for _, path := range sortedKeys(root.Includes) {
+		includeURL := r.repositoryURL(path)
+		file, err := r.include(ctx, includeURL)
+		if err != nil {
+			return nil, err
+		}
+		versions, err := listedVersions(includeURL, file.Packages, name)
+		if err != nil || versions != nil {
+			return versions, err
+		}
+	}
+	return nil, nil
+}
+
+// listedVersions decodes name's versions from a "packages" object keyed by
+// package name and then version. Repositories with no packages write it
+// as an empty list.
+func listedVersions(url string, packages json.RawMessage, name string) (map[string]versionInfo, error) {
+	if len(packages) == 0 || packages[0] != '{' {
+		return nil, nil
+	}
+	var byName map[string]map[string]versionInfo
+	if err := core.DecodeJSON(ecosystem, url, packages, &byName); err != nil {
+		return nil, err
+	}
+	return byName[name], nil
+}
+
+// packageFromVersions builds the package-level fields the Packagist API
+// provides from a repository's versions, taking the description, type and
+// abandonment from the most recent release.
+func packageFromVersions(name string, versions map[string]versionInfo) *packageInfo {
+	info := &packageInfo{Name: name, Versions: versions}
+
+	var latest *versionInfo
+	var latestTime time.Time
+	for _, key := range sortedKeys(versions) {
+		v := versions[key]
+		if strings.HasPrefix(v.Version, "dev-") || strings.HasSuffix(v.Version, "-dev") {
+			continue
+		}
+		t, _ := time.Parse(time.RFC3339, v.Time)
+		if latest == nil || t.After(latestTime) {
+			latest, latestTime = &v, t
+		}
+	}
+	if latest != nil {
+		info.Description = latest.Description
+		info.Type = latest.Type
+		info.Abandoned = latest.Abandoned
+	}
+	return info
+}
+
+// latestVersion returns the highest stable version by Composer ordering, so
+// a backport released after a new major doesn't count as the latest. It
+// falls back to the highest prerelease when there is no stable version.
+// Branch versions (dev-main, 2.x-dev) aren't releases and are skipped.
+func latestVersion(versions map[string]versionInfo) string {
+	var stable, prerelease string
+	for _, key := range sortedKeys(versions) {
+		v := versions[key].Version
+		if strings.HasPrefix(v, "dev-") || strings.HasSuffix(v, "-dev") {
+			continue
+		}
+		latest := &stable
+		if core.IsPrerelease(ecosystem, v) {
+			latest = &prerelease
+		}
+		if *latest == "" || core.CompareVersions(ecosystem, v, *latest) > 0 {
+			*latest = v
+		}
+	}
+	if stable == "" {
+		return prerelease
+	}
+	return stable
+}
+
+// repositoryURL resolves a path from packages.json, which may be absolute
+// or relative to the repository root.
+func (r *Registry) repositoryURL(path string) string {
+	base, err := url.Parse(r.baseURL + "/")
+	if err != nil {
+		return r.baseURL + "/" + strings.TrimPrefix(path, "/")
+	}
+	ref, err := url.Parse(path)
+	if err != nil {
+		return r.baseURL + "/" + strings.TrimPrefix(path, "/")
+	}
+	return base.ResolveReference(ref).String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 type URLs struct {
 	baseURL string
 }
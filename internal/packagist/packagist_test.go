@@ -3,6 +3,7 @@ package packagist
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -59,6 +60,37 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Licenses != "MIT" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if pkg.LatestVersion != "v11.0.0" {
+		t.Errorf("expected latest version 'v11.0.0', got %q", pkg.LatestVersion)
+	}
+}
+
+func TestFetchPackageLatestVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := packageResponse{
+			Package: packageInfo{
+				Name: "symfony/console",
+				Versions: map[string]versionInfo{
+					"v5.4.2":     {Version: "v5.4.2", Time: "2024-03-01T00:00:00+00:00"},
+					"v6.0.0":     {Version: "v6.0.0", Time: "2024-01-01T00:00:00+00:00"},
+					"v6.1.0-RC1": {Version: "v6.1.0-RC1", Time: "2024-04-01T00:00:00+00:00"},
+					"6.x-dev":    {Version: "6.x-dev", Time: "2024-05-01T00:00:00+00:00"},
+					"dev-main":   {Version: "dev-main", Time: "2024-05-01T00:00:00+00:00"},
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	pkg, err := New(server.URL, core.DefaultClient()).FetchPackage(context.Background(), "symfony/console")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "v6.0.0" {
+		t.Errorf("expected the highest stable version v6.0.0, got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -71,8 +103,16 @@ func TestFetchVersions(t *testing.T) {
 						Version: "3.5.0",
 						Time:    "2024-01-15T12:00:00+00:00",
 						License: []string{"MIT"},
+						Source: sourceInfo{
+							Type:      "git",
+							URL:       "https://github.com/Seldaek/monolog.git",
+							Reference: "c915e2634718dbc8a4a15c61b0e62e7a44e14448",
+						},
 						Dist: distInfo{
-							Shasum: "abc123",
+							Type:      "zip",
+							URL:       "https://api.github.com/repos/Seldaek/monolog/zipball/c915e2634718dbc8a4a15c61b0e62e7a44e14448",
+							Reference: "c915e2634718dbc8a4a15c61b0e62e7a44e14448",
+							Shasum:    "abc123",
 						},
 					},
 					"3.4.0": {
@@ -110,6 +150,55 @@ func TestFetchVersions(t *testing.T) {
 	if !hasIntegrity {
 		t.Error("expected at least one version with integrity")
 	}
+
+	for _, v := range versions {
+		if v.Number != "3.5.0" {
+			continue
+		}
+		if v.Metadata["source_reference"] != "c915e2634718dbc8a4a15c61b0e62e7a44e14448" {
+			t.Errorf("unexpected source_reference: %v", v.Metadata["source_reference"])
+		}
+		if v.Metadata["dist_reference"] != "c915e2634718dbc8a4a15c61b0e62e7a44e14448" {
+			t.Errorf("unexpected dist_reference: %v", v.Metadata["dist_reference"])
+		}
+		if v.Metadata["source_type"] != "git" {
+			t.Errorf("unexpected source_type: %v", v.Metadata["source_type"])
+		}
+		if v.Metadata["repository"] != "https://github.com/Seldaek/monolog" {
+			t.Errorf("unexpected repository: %v", v.Metadata["repository"])
+		}
+	}
+}
+
+func TestFetchVersionsAbandoned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"package": {
+			"name": "old/package",
+			"abandoned": "new/package",
+			"versions": {
+				"1.1.0": {"version": "1.1.0"},
+				"1.0.0": {"version": "1.0.0"}
+			}
+		}}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "old/package")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected abandoned versions to be kept, got %d", len(versions))
+	}
+	for _, v := range versions {
+		if v.Status != core.StatusDeprecated {
+			t.Errorf("%s: expected deprecated status, got %q", v.Number, v.Status)
+		}
+	}
+	if kept := core.ExcludeVersions(versions); len(kept) != 0 {
+		t.Errorf("expected ExcludeVersions to drop them, got %v", kept)
+	}
 }
 
 func TestFetchDependencies(t *testing.T) {
@@ -124,6 +213,7 @@ func TestFetchDependencies(t *testing.T) {
 							"php":                      ">=8.2",
 							"symfony/polyfill-mbstring": "~1.0",
 							"symfony/string":           "^6.4|^7.0",
+							"composer-runtime-api":     "^2.2",
 						},
 						RequireDev: map[string]string{
 							"phpunit/phpunit": "^10.5",
@@ -143,13 +233,14 @@ func TestFetchDependencies(t *testing.T) {
 		t.Fatalf("FetchDependencies failed: %v", err)
 	}
 
-	// Should have 4 deps (2 runtime excluding php, 2 dev)
-	if len(deps) != 4 {
-		t.Fatalf("expected 4 dependencies, got %d", len(deps))
+	// Should have 5 deps (2 runtime excluding php, 1 peer, 2 dev)
+	if len(deps) != 5 {
+		t.Fatalf("expected 5 dependencies, got %d", len(deps))
 	}
 
 	runtimeCount := 0
 	devCount := 0
+	peerCount := 0
 	for _, d := range deps {
 		// php should be filtered out
 		if d.Name == "php" {
@@ -160,6 +251,11 @@ func TestFetchDependencies(t *testing.T) {
 			runtimeCount++
 		case core.Development:
 			devCount++
+		case core.Peer:
+			peerCount++
+			if d.Name != "composer-runtime-api" {
+				t.Errorf("unexpected peer dependency %q", d.Name)
+			}
 		}
 	}
 
@@ -169,6 +265,9 @@ func TestFetchDependencies(t *testing.T) {
 	if devCount != 2 {
 		t.Errorf("expected 2 dev deps, got %d", devCount)
 	}
+	if peerCount != 1 {
+		t.Errorf("expected 1 peer dep, got %d", peerCount)
+	}
 }
 
 func TestFetchMaintainers(t *testing.T) {
@@ -201,6 +300,176 @@ func TestFetchMaintainers(t *testing.T) {
 	}
 }
 
+func TestComposerRepositoryMetadataURL(t *testing.T) {
+	var apiRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/packages.json":
+			_, _ = w.Write([]byte(`{"packages": [], "metadata-url": "/p2/%package%.json"}`))
+		case "/p2/acme/widgets.json":
+			_, _ = w.Write([]byte(`{
+				"minified": "composer/2.0",
+				"packages": {"acme/widgets": [
+					{
+						"name": "acme/widgets",
+						"description": "Widgets for Acme",
+						"version": "2.0.0",
+						"time": "2024-05-01T10:00:00+00:00",
+						"license": ["MIT"],
+						"type": "library",
+						"source": {"type": "git", "url": "https://git.acme.test/acme/widgets.git", "reference": "bbb"},
+						"require": {"php": ">=8.1", "psr/log": "^3.0"}
+					},
+					{
+						"version": "1.0.0",
+						"time": "2023-01-01T10:00:00+00:00",
+						"source": {"type": "git", "url": "https://git.acme.test/acme/widgets.git", "reference": "aaa"},
+						"require": "__unset"
+					}
+				]}
+			}`))
+		case "/p2/acme/missing.json":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			apiRequests++
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	pkg, err := reg.FetchPackage(ctx, "acme/widgets")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.Description != "Widgets for Acme" {
+		t.Errorf("unexpected description: %q", pkg.Description)
+	}
+	if pkg.LatestVersion != "2.0.0" {
+		t.Errorf("expected latest version 2.0.0, got %q", pkg.LatestVersion)
+	}
+	if pkg.Licenses != "MIT" {
+		t.Errorf("unexpected licenses: %q", pkg.Licenses)
+	}
+
+	versions, err := reg.FetchVersions(ctx, "acme/widgets")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+
+	deps, err := reg.FetchDependencies(ctx, "acme/widgets", "2.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Name != "psr/log" {
+		t.Errorf("expected psr/log dependency, got %v", deps)
+	}
+
+	deps, err = reg.FetchDependencies(ctx, "acme/widgets", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 0 {
+		t.Errorf("expected the unset requirements to be dropped, got %v", deps)
+	}
+
+	_, err = reg.FetchPackage(ctx, "acme/missing")
+	if !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if apiRequests != 1 {
+		t.Errorf("expected the Packagist API to be tried once, got %d requests", apiRequests)
+	}
+}
+
+func TestComposerRepositoryProviders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/packages.json":
+			_, _ = w.Write([]byte(`{
+				"packages": [],
+				"providers-url": "/p/%package%$%hash%.json",
+				"provider-includes": {"p/provider-latest$%hash%.json": {"sha256": "abc"}}
+			}`))
+		case "/p/provider-latest$abc.json":
+			_, _ = w.Write([]byte(`{"providers": {"acme/widgets": {"sha256": "def"}}}`))
+		case "/p/acme/widgets$def.json":
+			_, _ = w.Write([]byte(`{"packages": {"acme/widgets": {
+				"1.2.0": {"name": "acme/widgets", "version": "1.2.0", "require-dev": {"phpunit/phpunit": "^10"}}
+			}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	deps, err := reg.FetchDependencies(context.Background(), "acme/widgets", "1.2.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Scope != core.Development {
+		t.Errorf("expected one development dependency, got %v", deps)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "acme/missing")
+	if !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestComposerRepositorySatis(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repo/packages.json":
+			_, _ = w.Write([]byte(`{"packages": [], "includes": {"include/all$123.json": {"sha1": "123"}}}`))
+		case "/repo/include/all$123.json":
+			_, _ = w.Write([]byte(`{"packages": {"acme/widgets": {
+				"dev-main": {"name": "acme/widgets", "version": "dev-main", "time": "2024-06-01T00:00:00+00:00"},
+				"1.0.0": {"name": "acme/widgets", "version": "1.0.0", "description": "Widgets", "time": "2024-01-01T00:00:00+00:00"}
+			}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL+"/repo", core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "acme/widgets")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+	if pkg.LatestVersion != "1.0.0" {
+		t.Errorf("expected latest version 1.0.0, got %q", pkg.LatestVersion)
+	}
+	if pkg.Description != "Widgets" {
+		t.Errorf("unexpected description: %q", pkg.Description)
+	}
+}
+
+func TestExpandMinified(t *testing.T) {
+	entries := []map[string]json.RawMessage{
+		{"version": json.RawMessage(`"2.0.0"`), "homepage": json.RawMessage(`"https://acme.test"`)},
+		{"version": json.RawMessage(`"1.0.0"`), "homepage": json.RawMessage(`"__unset"`)},
+	}
+	expanded := expandMinified(entries)
+	if len(expanded) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(expanded))
+	}
+	if _, ok := expanded[1]["homepage"]; ok {
+		t.Error("expected homepage to be unset on the second entry")
+	}
+	if string(expanded[0]["homepage"]) != `"https://acme.test"` {
+		t.Errorf("expected the first entry to keep its homepage, got %s", expanded[0]["homepage"])
+	}
+}
+
 func TestURLBuilder(t *testing.T) {
 	reg := New("https://packagist.org", nil)
 	urls := reg.URLs()
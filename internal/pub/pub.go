@@ -20,6 +20,11 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"flutter_constraint", "git_path", "git_ref", "git_url",
+		"hosted_url", "path", "repository", "sdk", "sdk_constraint",
+		"vcs_ref", "vcs_url",
+	)
 }
 
 type Registry struct {
@@ -58,6 +63,7 @@ type versionInfo struct {
 	Version   string    `json:"version"`
 	Published time.Time `json:"published"`
 	Pubspec   pubspec   `json:"pubspec"`
+	Retracted bool      `json:"retracted"`
 }
 
 type pubspec struct {
@@ -69,6 +75,7 @@ type pubspec struct {
 	License      string                 `json:"license"`
 	Dependencies map[string]interface{} `json:"dependencies"`
 	DevDeps      map[string]interface{} `json:"dev_dependencies"`
+	Environment  map[string]string      `json:"environment"`
 }
 
 func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package, error) {
@@ -83,19 +90,32 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	latest := resp.Latest.Pubspec
-	repository := urlparser.Parse(latest.Repository)
+	repository := core.NormalizeRepository(latest.Repository)
 	if repository == "" {
 		repository = urlparser.Parse(latest.Homepage)
 	}
 
-	return &core.Package{
+	pkg := &core.Package{
 		Name:          resp.Name,
 		Description:   latest.Description,
 		Homepage:      latest.Homepage,
 		Repository:    repository,
 		Licenses:      latest.License,
 		LatestVersion: resp.Latest.Version,
-	}, nil
+	}
+
+	// environment holds the Dart SDK constraint and, for Flutter packages, the Flutter one
+	if sdk := latest.Environment["sdk"]; sdk != "" {
+		pkg.Metadata = map[string]any{"sdk_constraint": sdk}
+	}
+	if flutter := latest.Environment["flutter"]; flutter != "" {
+		if pkg.Metadata == nil {
+			pkg.Metadata = make(map[string]any)
+		}
+		pkg.Metadata["flutter_constraint"] = flutter
+	}
+
+	return pkg, nil
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
@@ -111,10 +131,15 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 
 	versions := make([]core.Version, len(resp.Versions))
 	for i, v := range resp.Versions {
+		var status core.VersionStatus
+		if v.Retracted {
+			status = core.StatusRetracted
+		}
 		versions[i] = core.Version{
 			Number:      v.Version,
 			PublishedAt: v.Published,
 			Licenses:    v.Pubspec.License,
+			Status:      status,
 		}
 	}
 
@@ -139,6 +164,7 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 			Name:         depName,
 			Requirements: formatRequirement(req),
 			Scope:        core.Runtime,
+			Metadata:     sourceMetadata(req),
 		})
 	}
 
@@ -147,12 +173,79 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 			Name:         depName,
 			Requirements: formatRequirement(req),
 			Scope:        core.Development,
+			Metadata:     sourceMetadata(req),
 		})
 	}
 
 	return deps, nil
 }
 
+// sourceMetadata describes where a non-hosted dependency comes from.
+// Returns nil for plain version constraints.
+//
+//	foo: {git: {url: https://github.com/x/foo.git, ref: main, path: pkgs/foo}}
+//	bar: {path: ../bar}
+//	baz: {hosted: https://pub.example.com, version: ^1.0.0}
+//	flutter_test: {sdk: flutter}
+func sourceMetadata(req interface{}) map[string]any {
+	v, ok := req.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	meta := make(map[string]any)
+	switch git := v["git"].(type) {
+	case string:
+		meta["git_url"] = git
+	case map[string]interface{}:
+		if url, ok := git["url"].(string); ok {
+			meta["git_url"] = url
+		}
+		if ref, ok := git["ref"].(string); ok && ref != "" {
+			meta["git_ref"] = ref
+		}
+		if path, ok := git["path"].(string); ok && path != "" {
+			meta["git_path"] = path
+		}
+	}
+	if url, ok := meta["git_url"].(string); ok {
+		if repo := urlparser.Parse(url); repo != "" {
+			meta["repository"] = repo
+		}
+		// ParseVCSReference keeps GitLab subgroups that NormalizeRepository
+		// would drop; plain URLs on unknown hosts aren't references to it.
+		vcsURL, _, ok := core.ParseVCSReference(url)
+		if !ok {
+			vcsURL = core.NormalizeRepository(url)
+		}
+		meta["vcs_url"] = vcsURL
+		if ref, ok := meta["git_ref"].(string); ok {
+			meta["vcs_ref"] = ref
+		}
+	}
+
+	switch hosted := v["hosted"].(type) {
+	case string:
+		meta["hosted_url"] = hosted
+	case map[string]interface{}:
+		if url, ok := hosted["url"].(string); ok {
+			meta["hosted_url"] = url
+		}
+	}
+
+	if path, ok := v["path"].(string); ok {
+		meta["path"] = path
+	}
+	if sdk, ok := v["sdk"].(string); ok {
+		meta["sdk"] = sdk
+	}
+
+	if len(meta) == 0 {
+		return nil
+	}
+	return meta
+}
+
 func formatRequirement(req interface{}) string {
 	switch v := req.(type) {
 	case string:
@@ -185,9 +278,58 @@ func formatRequirement(req interface{}) string {
 	return ""
 }
 
+type publisherResponse struct {
+	PublisherID string `json:"publisherId"`
+}
+
+type uploadersResponse struct {
+	Uploaders []string `json:"uploaders"`
+}
+
+// FetchMaintainers returns the package's verified publisher, whose ID is the
+// domain it was verified against. Packages without a publisher fall back to
+// their uploaders' emails from the legacy package listing.
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
-	// pub.dev API doesn't expose maintainers in the standard package endpoint
-	return nil, nil
+	url := fmt.Sprintf("%s/api/packages/%s/publisher", r.baseURL, name)
+
+	var publisher publisherResponse
+	if err := r.client.GetJSON(ctx, url, &publisher); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	if publisher.PublisherID != "" {
+		return []core.Maintainer{{
+			Login: publisher.PublisherID,
+			Name:  publisher.PublisherID,
+			URL:   "https://" + publisher.PublisherID,
+			Role:  "publisher",
+		}}, nil
+	}
+
+	url = fmt.Sprintf("%s/packages/%s.json", r.baseURL, name)
+
+	var resp uploadersResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var maintainers []core.Maintainer
+	for _, email := range resp.Uploaders {
+		if email == "" {
+			continue
+		}
+		maintainers = append(maintainers, core.Maintainer{
+			Email: email,
+			Role:  "uploader",
+		})
+	}
+	return maintainers, nil
 }
 
 type URLs struct {
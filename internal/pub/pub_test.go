@@ -28,6 +28,10 @@ func TestFetchPackage(t *testing.T) {
 					Homepage:    "https://flutter.dev",
 					Repository:  "https://github.com/flutter/flutter",
 					License:     "BSD-3-Clause",
+					Environment: map[string]string{
+						"sdk":     ">=3.0.0 <4.0.0",
+						"flutter": ">=3.10.0",
+					},
 				},
 			},
 		}
@@ -52,6 +56,12 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Licenses != "BSD-3-Clause" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if pkg.Metadata["sdk_constraint"] != ">=3.0.0 <4.0.0" {
+		t.Errorf("unexpected sdk_constraint: %v", pkg.Metadata["sdk_constraint"])
+	}
+	if pkg.Metadata["flutter_constraint"] != ">=3.10.0" {
+		t.Errorf("unexpected flutter_constraint: %v", pkg.Metadata["flutter_constraint"])
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -60,7 +70,7 @@ func TestFetchVersions(t *testing.T) {
 			Name: "provider",
 			Versions: []versionInfo{
 				{Version: "6.1.0", Pubspec: pubspec{License: "MIT"}},
-				{Version: "6.0.0", Pubspec: pubspec{License: "MIT"}},
+				{Version: "6.0.0", Pubspec: pubspec{License: "MIT"}, Retracted: true},
 				{Version: "5.0.0", Pubspec: pubspec{License: "MIT"}},
 			},
 		}
@@ -84,6 +94,9 @@ func TestFetchVersions(t *testing.T) {
 	if versions[0].Licenses != "MIT" {
 		t.Errorf("expected MIT license, got %q", versions[0].Licenses)
 	}
+	if versions[1].Status != core.StatusRetracted {
+		t.Errorf("expected 6.0.0 to be retracted, got %q", versions[1].Status)
+	}
 }
 
 func TestFetchDependencies(t *testing.T) {
@@ -161,6 +174,14 @@ func TestFetchDependenciesGit(t *testing.T) {
 						"path": "../local_pkg",
 					},
 				},
+				DevDeps: map[string]interface{}{
+					"dev_tool": map[string]interface{}{
+						"git": map[string]interface{}{
+							"url":  "https://github.com/example/tools.git",
+							"path": "dev_tool",
+						},
+					},
+				},
 			},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
@@ -173,13 +194,15 @@ func TestFetchDependenciesGit(t *testing.T) {
 		t.Fatalf("FetchDependencies failed: %v", err)
 	}
 
-	if len(deps) != 3 {
-		t.Fatalf("expected 3 dependencies, got %d", len(deps))
+	if len(deps) != 4 {
+		t.Fatalf("expected 4 dependencies, got %d", len(deps))
 	}
 
 	reqMap := make(map[string]string)
+	depMap := make(map[string]core.Dependency)
 	for _, d := range deps {
 		reqMap[d.Name] = d.Requirements
+		depMap[d.Name] = d
 	}
 
 	if reqMap["some_pkg"] != "git:https://github.com/example/some_pkg.git" {
@@ -191,6 +214,101 @@ func TestFetchDependenciesGit(t *testing.T) {
 	if reqMap["local_pkg"] != "path:../local_pkg" {
 		t.Errorf("unexpected path requirement: %q", reqMap["local_pkg"])
 	}
+
+	another := depMap["another_pkg"]
+	if another.Metadata["git_url"] != "https://github.com/example/another.git" {
+		t.Errorf("unexpected git_url: %v", another.Metadata["git_url"])
+	}
+	if another.Metadata["git_ref"] != "main" {
+		t.Errorf("unexpected git_ref: %v", another.Metadata["git_ref"])
+	}
+	if another.Metadata["repository"] != "https://github.com/example/another" {
+		t.Errorf("unexpected repository: %v", another.Metadata["repository"])
+	}
+	if another.Metadata["vcs_url"] != "https://github.com/example/another" || another.Metadata["vcs_ref"] != "main" {
+		t.Errorf("unexpected vcs metadata: %v", another.Metadata)
+	}
+	if depMap["local_pkg"].Metadata["path"] != "../local_pkg" {
+		t.Errorf("unexpected path metadata: %v", depMap["local_pkg"].Metadata)
+	}
+
+	devTool := depMap["dev_tool"]
+	if devTool.Scope != core.Development {
+		t.Errorf("expected development scope for dev_tool, got %q", devTool.Scope)
+	}
+	if devTool.Metadata["git_path"] != "dev_tool" {
+		t.Errorf("unexpected git_path: %v", devTool.Metadata["git_path"])
+	}
+}
+
+func TestFetchMaintainersPublisher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/packages/http/publisher" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+			return
+		}
+		_, _ = w.Write([]byte(`{"publisherId": "dart.dev"}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "http")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+
+	if len(maintainers) != 1 {
+		t.Fatalf("expected 1 maintainer, got %d", len(maintainers))
+	}
+	m := maintainers[0]
+	if m.Login != "dart.dev" || m.Role != "publisher" {
+		t.Errorf("unexpected maintainer: %+v", m)
+	}
+	if m.URL != "https://dart.dev" {
+		t.Errorf("expected URL 'https://dart.dev', got %q", m.URL)
+	}
+}
+
+func TestFetchMaintainersUploaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/packages/left_pad/publisher":
+			_, _ = w.Write([]byte(`{"publisherId": null}`))
+		case "/packages/left_pad.json":
+			_, _ = w.Write([]byte(`{"name": "left_pad", "uploaders": ["alice@example.com", "bob@example.com"]}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	maintainers, err := reg.FetchMaintainers(context.Background(), "left_pad")
+	if err != nil {
+		t.Fatalf("FetchMaintainers failed: %v", err)
+	}
+
+	if len(maintainers) != 2 {
+		t.Fatalf("expected 2 maintainers, got %d", len(maintainers))
+	}
+	if maintainers[0].Email != "alice@example.com" || maintainers[0].Role != "uploader" {
+		t.Errorf("unexpected maintainer: %+v", maintainers[0])
+	}
+}
+
+func TestFetchMaintainersNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	_, err := reg.FetchMaintainers(context.Background(), "missing")
+	if _, ok := err.(*core.NotFoundError); !ok {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
 }
 
 func TestURLBuilder(t *testing.T) {
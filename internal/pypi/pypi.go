@@ -21,12 +21,17 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"classifiers", "documentation", "download_url", "normalized_name",
+		"packagetype", "requires_python", "size", "yanked_reason",
+	)
 }
 
 type Registry struct {
-	baseURL string
-	client  *core.Client
-	urls    *URLs
+	baseURL   string
+	client    *core.Client
+	urls      *URLs
+	simpleURL string // empty unless versions come from the Simple index
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -41,6 +46,21 @@ func New(baseURL string, client *core.Client) *Registry {
 	return r
 }
 
+// WithSimpleIndex returns a new Registry that reads versions from the PEP 691
+// JSON Simple index at simpleURL (the registry's /simple if empty) instead
+// of the JSON API. The Simple index is what pip installs from, so it is
+// authoritative for mirrors and private indexes, and it lists the hash and
+// yank state of every file. Package metadata and dependencies still come
+// from the JSON API.
+func (r *Registry) WithSimpleIndex(simpleURL string) *Registry {
+	if simpleURL == "" {
+		simpleURL = r.baseURL + "/simple"
+	}
+	copy := *r
+	copy.simpleURL = strings.TrimSuffix(simpleURL, "/")
+	return &copy
+}
+
 func (r *Registry) Ecosystem() string {
 	return ecosystem
 }
@@ -96,61 +116,25 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		return nil, err
 	}
 
-	repoURL := extractRepoURL(resp.Info.ProjectURLs, resp.Info.HomePage)
-	homepage := extractHomepage(resp.Info.ProjectURLs, resp.Info.HomePage)
-
-	return &core.Package{
-		Name:        strings.ToLower(resp.Info.Name),
-		Description: resp.Info.Summary,
-		Homepage:    homepage,
-		Repository:  repoURL,
-		Licenses:    extractLicense(resp.Info),
-		Keywords:    parseKeywords(resp.Info.Keywords),
+	pkg := &core.Package{
+		Name:          strings.ToLower(resp.Info.Name),
+		Description:   resp.Info.Summary,
+		Homepage:      resp.Info.HomePage,
+		Licenses:      extractLicense(resp.Info),
+		Keywords:      core.NormalizeKeywords(parseKeywords(resp.Info.Keywords)),
+		LatestVersion: resp.Info.Version,
+		ProjectURLs:   resp.Info.ProjectURLs,
 		Metadata: map[string]any{
 			"classifiers":      resp.Info.Classifiers,
 			"documentation":    resp.Info.ProjectURLs["Documentation"],
 			"normalized_name":  normalizeName(resp.Info.Name),
 		},
-	}, nil
-}
-
-func extractRepoURL(projectURLs map[string]string, homePage string) string {
-	priorityKeys := []string{"Repository", "Source", "Source Code", "Code"}
-	for _, key := range priorityKeys {
-		if url, ok := projectURLs[key]; ok && url != "" {
-			if parsed := urlparser.Parse(url); parsed != "" {
-				return parsed
-			}
-		}
 	}
-
-	for _, url := range projectURLs {
-		if strings.Contains(url, "/sponsors") {
-			continue
-		}
-		if parsed := urlparser.Parse(url); parsed != "" {
-			return parsed
-		}
+	core.ApplyProjectURLs(pkg)
+	if pkg.Repository == "" {
+		pkg.Repository = urlparser.Parse(resp.Info.HomePage)
 	}
-
-	if parsed := urlparser.Parse(homePage); parsed != "" {
-		return parsed
-	}
-
-	return ""
-}
-
-func extractHomepage(projectURLs map[string]string, homePage string) string {
-	if homePage != "" {
-		return homePage
-	}
-	if url, ok := projectURLs["Homepage"]; ok {
-		return url
-	}
-	if url, ok := projectURLs["Home"]; ok {
-		return url
-	}
-	return ""
+	return pkg, nil
 }
 
 func extractLicense(info infoBlock) string {
@@ -190,6 +174,10 @@ func normalizeName(name string) string {
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	if r.simpleURL != "" {
+		return r.fetchSimpleVersions(ctx, name)
+	}
+
 	url := fmt.Sprintf("%s/pypi/%s/json", r.baseURL, name)
 
 	var resp packageResponse
@@ -243,6 +231,176 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 	return versions, nil
 }
 
+// simpleAccept requests version 1 of the PEP 691 JSON Simple API.
+const simpleAccept = "application/vnd.pypi.simple.v1+json"
+
+type simpleResponse struct {
+	Name     string       `json:"name"`
+	Files    []simpleFile `json:"files"`
+	Versions []string     `json:"versions"` // API version 1.1 and later
+}
+
+type simpleFile struct {
+	Filename       string            `json:"filename"`
+	URL            string            `json:"url"`
+	Hashes         map[string]string `json:"hashes"`
+	RequiresPython string            `json:"requires-python"`
+	Yanked         interface{}       `json:"yanked"` // false, true or the reason
+	Size           int               `json:"size"`
+	UploadTime     string            `json:"upload-time"`
+}
+
+// yanked reports whether the file is yanked and the reason given, if any.
+func (f simpleFile) yanked() (bool, string) {
+	switch y := f.Yanked.(type) {
+	case bool:
+		return y, ""
+	case string:
+		return true, y
+	}
+	return false, ""
+}
+
+func (r *Registry) fetchSimpleVersions(ctx context.Context, name string) ([]core.Version, error) {
+	url := fmt.Sprintf("%s/%s/", r.simpleURL, normalizeName(name))
+
+	var resp simpleResponse
+	if err := r.client.GetJSONWithAccept(ctx, url, simpleAccept, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+		}
+		return nil, err
+	}
+
+	// Files aren't tagged with their version, so group them by the version
+	// in the filename, in the order the index lists them.
+	var order []string
+	files := make(map[string][]simpleFile)
+	for _, f := range resp.Files {
+		v := versionFromFilename(f.Filename, name)
+		if v == "" {
+			continue
+		}
+		if _, ok := files[v]; !ok {
+			order = append(order, v)
+		}
+		files[v] = append(files[v], f)
+	}
+	for _, v := range resp.Versions {
+		if _, ok := files[v]; !ok {
+			order = append(order, v)
+			files[v] = nil
+		}
+	}
+
+	versions := make([]core.Version, 0, len(order))
+	for _, num := range order {
+		versions = append(versions, simpleVersion(num, files[num]))
+	}
+	return versions, nil
+}
+
+// simpleVersion builds a version from its files on the Simple index. A
+// version is yanked only when every one of its files is, since PEP 592
+// yanks files rather than releases.
+func simpleVersion(num string, files []simpleFile) core.Version {
+	if len(files) == 0 {
+		return core.Version{Number: num}
+	}
+
+	file := files[0]
+	var publishedAt time.Time
+	yankedFiles := 0
+	var yankedReason string
+	for _, f := range files {
+		if f.UploadTime != "" {
+			if t, err := time.Parse(time.RFC3339, f.UploadTime); err == nil && (publishedAt.IsZero() || t.Before(publishedAt)) {
+				publishedAt = t
+			}
+		}
+		if yanked, reason := f.yanked(); yanked {
+			yankedFiles++
+			if yankedReason == "" {
+				yankedReason = reason
+			}
+		}
+	}
+
+	var status core.VersionStatus
+	if yankedFiles == len(files) {
+		status = core.StatusYanked
+	}
+
+	var integrity string
+	if sha256, ok := file.Hashes["sha256"]; ok {
+		integrity = "sha256-" + sha256
+	}
+
+	return core.Version{
+		Number:      num,
+		PublishedAt: publishedAt,
+		Integrity:   integrity,
+		Status:      status,
+		Metadata: map[string]any{
+			"download_url":    file.URL,
+			"requires_python": file.RequiresPython,
+			"yanked_reason":   yankedReason,
+			"packagetype":     packageType(file.Filename),
+			"size":            file.Size,
+		},
+	}
+}
+
+// versionFromFilename extracts the version from a wheel, egg or sdist
+// filename. Wheels and eggs have it as the second "-" separated field. For
+// sdists, everything after the project name is the version.
+func versionFromFilename(filename, name string) string {
+	switch {
+	case strings.HasSuffix(filename, ".whl"), strings.HasSuffix(filename, ".egg"):
+		parts := strings.Split(filename, "-")
+		if len(parts) < 3 {
+			return ""
+		}
+		return parts[1]
+	}
+
+	base := filename
+	for _, ext := range []string{".tar.gz", ".tar.bz2", ".tar.xz", ".tgz", ".zip"} {
+		if strings.HasSuffix(base, ext) {
+			base = strings.TrimSuffix(base, ext)
+			break
+		}
+	}
+	if base == filename {
+		return ""
+	}
+
+	// Project names may themselves contain "-", so find the split that
+	// matches the name before falling back to the last "-".
+	normalized := normalizeName(name)
+	for i, c := range base {
+		if c == '-' && normalizeName(base[:i]) == normalized {
+			return base[i+1:]
+		}
+	}
+	if i := strings.LastIndex(base, "-"); i > 0 {
+		return base[i+1:]
+	}
+	return ""
+}
+
+// packageType returns the JSON API's packagetype for a distribution file.
+func packageType(filename string) string {
+	switch {
+	case strings.HasSuffix(filename, ".whl"):
+		return "bdist_wheel"
+	case strings.HasSuffix(filename, ".egg"):
+		return "bdist_egg"
+	default:
+		return "sdist"
+	}
+}
+
 var pep508NameRegex = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9._]*[A-Za-z0-9]|[A-Za-z0-9])(\s*\[.*?\])?`)
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
@@ -314,6 +472,30 @@ func parsePEP508(dep string) (name, requirements, envMarker string) {
 	return
 }
 
+// FetchReadme returns the long description uploaded with a release, which
+// is the project's README for nearly every package. Its format (Markdown,
+// reStructuredText or plain text) is given by description_content_type.
+func (r *Registry) FetchReadme(ctx context.Context, name, version string) (string, error) {
+	url := fmt.Sprintf("%s/pypi/%s/json", r.baseURL, name)
+	if version != "" {
+		url = fmt.Sprintf("%s/pypi/%s/%s/json", r.baseURL, name, version)
+	}
+
+	var resp versionInfoResponse
+	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
+		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+			return "", &core.NotFoundError{Ecosystem: ecosystem, Name: name, Version: version}
+		}
+		return "", err
+	}
+
+	// Releases uploaded without a description report "UNKNOWN"
+	if resp.Info.Description == "UNKNOWN" {
+		return "", nil
+	}
+	return resp.Info.Description, nil
+}
+
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
 	// PyPI doesn't expose maintainers through JSON API
 	// Would require scraping or XML-RPC
@@ -3,9 +3,11 @@ package pypi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/git-pkgs/registries/internal/core"
 )
@@ -20,11 +22,12 @@ func TestFetchPackage(t *testing.T) {
 
 		resp := packageResponse{
 			Info: infoBlock{
-				Name:              "requests",
-				Summary:           "Python HTTP for Humans.",
-				License:           "Apache 2.0",
-				HomePage:          "https://requests.readthedocs.io",
-				Keywords:          "http,web,client",
+				Name:     "requests",
+				Summary:  "Python HTTP for Humans.",
+				Version:  "2.31.0",
+				License:  "Apache 2.0",
+				HomePage: "https://requests.readthedocs.io",
+				Keywords: "http,web,client",
 				ProjectURLs: map[string]string{
 					"Source":        "https://github.com/psf/requests",
 					"Documentation": "https://requests.readthedocs.io",
@@ -57,12 +60,18 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Repository != "https://github.com/psf/requests" {
 		t.Errorf("unexpected repository: %q", pkg.Repository)
 	}
+	if pkg.ProjectURLs["Documentation"] != "https://requests.readthedocs.io" || len(pkg.ProjectURLs) != 2 {
+		t.Errorf("expected every project URL to be kept, got %v", pkg.ProjectURLs)
+	}
 	if pkg.Licenses != "Apache-2.0" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
 	if len(pkg.Keywords) != 3 {
 		t.Errorf("expected 3 keywords, got %d", len(pkg.Keywords))
 	}
+	if pkg.LatestVersion != "2.31.0" {
+		t.Errorf("expected latest version '2.31.0', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchPackageWithLicenseExpression(t *testing.T) {
@@ -144,6 +153,124 @@ func TestFetchVersions(t *testing.T) {
 	}
 }
 
+func TestFetchVersionsSimpleIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/simple/python-dateutil/" {
+			w.WriteHeader(404)
+			return
+		}
+		if accept := r.Header.Get("Accept"); accept != "application/vnd.pypi.simple.v1+json" {
+			t.Errorf("unexpected Accept header %q", accept)
+		}
+		w.Header().Set("Content-Type", "application/vnd.pypi.simple.v1+json")
+		_, _ = w.Write([]byte(`{
+			"meta": {"api-version": "1.1"},
+			"name": "python-dateutil",
+			"versions": ["2.8.0", "2.8.1", "2.9.0"],
+			"files": [
+				{
+					"filename": "python-dateutil-2.8.0.tar.gz",
+					"url": "https://files.example.com/python-dateutil-2.8.0.tar.gz",
+					"hashes": {"sha256": "aaa"},
+					"yanked": "broken metadata",
+					"size": 100,
+					"upload-time": "2019-02-05T14:00:00.000000Z"
+				},
+				{
+					"filename": "python_dateutil-2.8.0-py2.py3-none-any.whl",
+					"url": "https://files.example.com/python_dateutil-2.8.0-py2.py3-none-any.whl",
+					"hashes": {"sha256": "bbb"},
+					"yanked": true,
+					"size": 90,
+					"upload-time": "2019-02-05T13:00:00.000000Z"
+				},
+				{
+					"filename": "python-dateutil-2.8.1.tar.gz",
+					"url": "https://files.example.com/python-dateutil-2.8.1.tar.gz",
+					"hashes": {"sha256": "ccc"},
+					"requires-python": ">=2.7",
+					"yanked": false,
+					"size": 110,
+					"upload-time": "2019-11-03T12:00:00.000000Z"
+				},
+				{
+					"filename": "python_dateutil-2.8.1-py2.py3-none-any.whl",
+					"url": "https://files.example.com/python_dateutil-2.8.1-py2.py3-none-any.whl",
+					"hashes": {"sha256": "ddd"},
+					"yanked": true,
+					"size": 95,
+					"upload-time": "2019-11-03T12:01:00.000000Z"
+				}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithSimpleIndex("")
+	versions, err := reg.FetchVersions(context.Background(), "Python_Dateutil")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
+	}
+
+	v := versions[0]
+	if v.Number != "2.8.0" {
+		t.Fatalf("expected first version '2.8.0', got %q", v.Number)
+	}
+	if v.Status != core.StatusYanked {
+		t.Errorf("expected 2.8.0 to be yanked, got %q", v.Status)
+	}
+	if v.Metadata["yanked_reason"] != "broken metadata" {
+		t.Errorf("unexpected yanked reason: %v", v.Metadata["yanked_reason"])
+	}
+	if v.Integrity != "sha256-aaa" {
+		t.Errorf("expected integrity 'sha256-aaa', got %q", v.Integrity)
+	}
+	if !v.PublishedAt.Equal(time.Date(2019, 2, 5, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the earliest upload time, got %v", v.PublishedAt)
+	}
+
+	// Only one of 2.8.1's files is yanked, so the release isn't
+	v = versions[1]
+	if v.Number != "2.8.1" || v.Status != core.StatusNone {
+		t.Errorf("expected 2.8.1 not to be yanked, got %q %q", v.Number, v.Status)
+	}
+	if v.Metadata["requires_python"] != ">=2.7" || v.Metadata["packagetype"] != "sdist" {
+		t.Errorf("unexpected metadata: %v", v.Metadata)
+	}
+
+	// Versions without files are still listed
+	if versions[2].Number != "2.9.0" || versions[2].Integrity != "" {
+		t.Errorf("unexpected version: %+v", versions[2])
+	}
+}
+
+func TestVersionFromFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		name     string
+		expected string
+	}{
+		{"requests-2.31.0.tar.gz", "requests", "2.31.0"},
+		{"requests-2.31.0-py3-none-any.whl", "requests", "2.31.0"},
+		{"python-dateutil-2.8.2.tar.gz", "python-dateutil", "2.8.2"},
+		{"python_dateutil-2.8.2-py2.py3-none-any.whl", "python-dateutil", "2.8.2"},
+		{"Django-1.0-rc-1.tar.gz", "django", "1.0-rc-1"},
+		{"zope.interface-5.0.zip", "zope-interface", "5.0"},
+		{"setuptools-0.6c11-py2.7.egg", "setuptools", "0.6c11"},
+		{"requests-2.31.0.exe", "requests", ""},
+	}
+
+	for _, tt := range tests {
+		if got := versionFromFilename(tt.filename, tt.name); got != tt.expected {
+			t.Errorf("versionFromFilename(%q) = %q, expected %q", tt.filename, got, tt.expected)
+		}
+	}
+}
+
 func TestFetchDependencies(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/pypi/requests/2.31.0/json" {
@@ -198,6 +325,49 @@ func TestFetchDependencies(t *testing.T) {
 	}
 }
 
+func TestFetchReadme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pypi/requests/json":
+			_, _ = w.Write([]byte(`{"info": {"description": "# Requests\n\nlatest", "description_content_type": "text/markdown"}}`))
+		case "/pypi/requests/2.0.0/json":
+			_, _ = w.Write([]byte(`{"info": {"description": "Requests\n========", "description_content_type": "text/x-rst"}}`))
+		case "/pypi/requests/0.1.0/json":
+			_, _ = w.Write([]byte(`{"info": {"description": "UNKNOWN"}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	ctx := context.Background()
+
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"", "# Requests\n\nlatest"},
+		{"2.0.0", "Requests\n========"},
+		{"0.1.0", ""},
+	}
+	for _, tt := range tests {
+		readme, err := reg.FetchReadme(ctx, "requests", tt.version)
+		if err != nil {
+			t.Fatalf("FetchReadme(%q) failed: %v", tt.version, err)
+		}
+		if readme != tt.expected {
+			t.Errorf("FetchReadme(%q) = %q, expected %q", tt.version, readme, tt.expected)
+		}
+	}
+
+	_, err := reg.FetchReadme(ctx, "requests", "9.9.9")
+	var notFound *core.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
 func TestParsePEP508(t *testing.T) {
 	tests := []struct {
 		input        string
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/git-pkgs/registries/internal/core"
 	"github.com/git-pkgs/registries/internal/urlparser"
@@ -20,12 +21,17 @@ func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"downloads", "funding_uri", "platform", "prerelease",
+		"ruby_version", "rubygems_version",
+	)
 }
 
 type Registry struct {
-	baseURL string
-	client  *core.Client
-	urls    *URLs
+	baseURL      string
+	client       *core.Client
+	urls         *URLs
+	compactIndex bool
 }
 
 func New(baseURL string, client *core.Client) *Registry {
@@ -40,6 +46,19 @@ func New(baseURL string, client *core.Client) *Registry {
 	return r
 }
 
+// WithCompactIndex returns a copy of the registry that, when enabled, reads
+// versions and dependencies from the compact index at /info/{name}, the
+// plain-text listing Bundler resolves from. One response holds every
+// version of a gem with its runtime dependencies and checksum, but no
+// publish dates, download counts, licenses or development dependencies,
+// so it is off by default. The JSON API is used when the compact index
+// can't be read, and for versions it doesn't list.
+func (r *Registry) WithCompactIndex(enabled bool) *Registry {
+	copy := *r
+	copy.compactIndex = enabled
+	return &copy
+}
+
 func (r *Registry) Ecosystem() string {
 	return ecosystem
 }
@@ -86,6 +105,7 @@ type versionResponse struct {
 	RubyVersion     string            `json:"ruby_version"`
 	RubygemsVersion string            `json:"rubygems_version"`
 	Prerelease      bool              `json:"prerelease"`
+	Yanked          bool              `json:"yanked"`
 	Metadata        map[string]string `json:"metadata"`
 }
 
@@ -109,17 +129,27 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 		return nil, err
 	}
 
-	repoURL := extractRepoURL(resp.SourceCodeURI, resp.WikiURI, resp.DocumentURI, resp.BugTrackerURI, resp.ChangelogURI, resp.HomepageURI)
+	// The gemspec metadata is what gem authors actually set; the top-level
+	// *_uri fields are filled from it but can lag behind or be empty.
+	repoURL := extractRepoURL(resp.Metadata["source_code_uri"], resp.SourceCodeURI, resp.WikiURI, resp.DocumentURI,
+		resp.BugTrackerURI, resp.ChangelogURI, resp.Metadata["homepage_uri"], resp.HomepageURI)
+
+	fundingURI := resp.FundingURI
+	if fundingURI == "" {
+		fundingURI = resp.Metadata["funding_uri"]
+	}
 
 	return &core.Package{
-		Name:        resp.Name,
-		Description: resp.Info,
-		Homepage:    resp.HomepageURI,
-		Repository:  repoURL,
-		Licenses:    strings.Join(resp.Licenses, ","),
+		Name:          resp.Name,
+		Description:   resp.Info,
+		Homepage:      resp.HomepageURI,
+		Repository:    repoURL,
+		Licenses:      strings.Join(resp.Licenses, ","),
+		LatestVersion: resp.Version,
+		Downloads:     resp.Downloads,
 		Metadata: map[string]any{
 			"downloads":   resp.Downloads,
-			"funding_uri": resp.FundingURI,
+			"funding_uri": fundingURI,
 		},
 	}, nil
 }
@@ -129,6 +159,16 @@ func extractRepoURL(urls ...string) string {
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
+	if r.compactIndex {
+		entries, err := r.fetchCompactIndex(ctx, name)
+		if err == nil {
+			return compactVersions(entries), nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+
 	url := fmt.Sprintf("%s/api/v1/versions/%s.json", r.baseURL, name)
 
 	var resp []versionResponse
@@ -156,11 +196,17 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 			integrity = "sha256-" + v.SHA
 		}
 
+		var status core.VersionStatus
+		if v.Yanked {
+			status = core.StatusYanked
+		}
+
 		versions[i] = core.Version{
 			Number:      number,
 			PublishedAt: publishedAt,
 			Licenses:    strings.Join(v.Licenses, ","),
 			Integrity:   integrity,
+			Status:      status,
 			Metadata: map[string]any{
 				"platform":         v.Platform,
 				"downloads":        v.Downloads,
@@ -175,6 +221,19 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 }
 
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
+	if r.compactIndex {
+		entries, err := r.fetchCompactIndex(ctx, name)
+		if err == nil {
+			for _, e := range entries {
+				if e.number == version {
+					return e.deps, nil
+				}
+			}
+		} else if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+
 	url := fmt.Sprintf("%s/api/v2/rubygems/%s/versions/%s.json", r.baseURL, name, version)
 
 	var resp dependencyVersionResponse
@@ -206,6 +265,112 @@ func (r *Registry) FetchDependencies(ctx context.Context, name, version string)
 	return deps, nil
 }
 
+// compactEntry is one version line of the compact index:
+//
+//	1.16.0-x86_64-linux racc:~> 1.4|checksum:4c3a...,ruby:>= 3.0&< 3.4.dev
+//
+// Multiple requirements on a dependency or on ruby are joined with "&".
+type compactEntry struct {
+	number   string // version, with "-platform" for platform gems
+	platform string
+	deps     []core.Dependency
+	checksum string
+	ruby     string
+	rubygems string
+}
+
+func (r *Registry) fetchCompactIndex(ctx context.Context, name string) ([]compactEntry, error) {
+	url := fmt.Sprintf("%s/info/%s", r.baseURL, name)
+	body, err := r.client.GetText(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return parseCompactIndex(body)
+}
+
+func parseCompactIndex(body string) ([]compactEntry, error) {
+	var entries []compactEntry
+	for i, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "---" {
+			continue
+		}
+
+		number, rest, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("compact index line %d: missing dependencies: %q", i+1, line)
+		}
+		depList, reqList, _ := strings.Cut(rest, "|")
+
+		e := compactEntry{number: number, platform: "ruby"}
+		if _, platform, ok := strings.Cut(number, "-"); ok {
+			e.platform = platform
+		}
+
+		for _, dep := range strings.Split(depList, ",") {
+			depName, requirements, ok := strings.Cut(dep, ":")
+			if !ok {
+				continue
+			}
+			e.deps = append(e.deps, core.Dependency{
+				Name:         depName,
+				Requirements: strings.ReplaceAll(requirements, "&", ", "),
+				Scope:        core.Runtime,
+			})
+		}
+
+		for _, req := range strings.Split(reqList, ",") {
+			key, value, _ := strings.Cut(req, ":")
+			value = strings.ReplaceAll(value, "&", ", ")
+			switch key {
+			case "checksum":
+				e.checksum = value
+			case "ruby":
+				e.ruby = value
+			case "rubygems":
+				e.rubygems = value
+			}
+		}
+
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// compactVersions converts compact index entries, which are listed oldest
+// first, to versions newest first as the JSON API returns them. The compact
+// index leaves yanked versions out rather than flagging them, and filling
+// them in would cost the JSON API request it exists to avoid, so they are
+// missing from FetchVersions in this mode.
+func compactVersions(entries []compactEntry) []core.Version {
+	versions := make([]core.Version, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+
+		var integrity string
+		if e.checksum != "" {
+			integrity = "sha256-" + e.checksum
+		}
+
+		// Gem::Version treats any version containing a letter as a
+		// prerelease.
+		release, _, _ := strings.Cut(e.number, "-")
+		prerelease := strings.IndexFunc(release, unicode.IsLetter) >= 0
+
+		versions = append(versions, core.Version{
+			Number:    e.number,
+			Integrity: integrity,
+			Metadata: map[string]any{
+				"platform":         e.platform,
+				"ruby_version":     e.ruby,
+				"rubygems_version": e.rubygems,
+				"prerelease":       prerelease,
+			},
+		})
+	}
+	return versions
+}
+
 func (r *Registry) FetchMaintainers(ctx context.Context, name string) ([]core.Maintainer, error) {
 	url := fmt.Sprintf("%s/api/v1/gems/%s/owners.json", r.baseURL, name)
 
@@ -3,6 +3,7 @@ package rubygems
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -48,6 +49,9 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Licenses != "MIT" {
 		t.Errorf("unexpected licenses: %q", pkg.Licenses)
 	}
+	if pkg.LatestVersion != "7.1.0" {
+		t.Errorf("expected latest version '7.1.0', got %q", pkg.LatestVersion)
+	}
 }
 
 func TestFetchVersions(t *testing.T) {
@@ -73,6 +77,12 @@ func TestFetchVersions(t *testing.T) {
 				Licenses:  []string{"MIT"},
 				SHA:       "3fa37b0c3b5744af45f9da3e4ae9cbd89480b35e12ae36b5e87a0452e0b38335",
 			},
+			{
+				Number:    "1.13.5",
+				Platform:  "ruby",
+				CreatedAt: "2022-05-04T18:12:01.000Z",
+				Yanked:    true,
+			},
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -86,8 +96,8 @@ func TestFetchVersions(t *testing.T) {
 		t.Fatalf("FetchVersions failed: %v", err)
 	}
 
-	if len(versions) != 2 {
-		t.Fatalf("expected 2 versions, got %d", len(versions))
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
 	}
 
 	if versions[0].Number != "1.13.6" {
@@ -99,6 +109,41 @@ func TestFetchVersions(t *testing.T) {
 	if versions[0].Integrity != "sha256-b1512fdc0aba446e1ee30de3e0671518eb363e75fab53486e99e8891d44b8587" {
 		t.Errorf("unexpected integrity: %q", versions[0].Integrity)
 	}
+	if versions[0].Status != core.StatusNone {
+		t.Errorf("expected no status for 1.13.6, got %q", versions[0].Status)
+	}
+	if versions[2].Status != core.StatusYanked {
+		t.Errorf("expected 1.13.5 to be yanked, got %q", versions[2].Status)
+	}
+}
+
+func TestFetchPackageMetadataURIs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := gemResponse{
+			Name:        "sidekiq",
+			Version:     "7.2.0",
+			HomepageURI: "https://sidekiq.org",
+			Metadata: map[string]string{
+				"source_code_uri": "https://github.com/sidekiq/sidekiq/tree/v7.2.0",
+				"funding_uri":     "https://github.com/sponsors/mperham",
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	pkg, err := reg.FetchPackage(context.Background(), "sidekiq")
+	if err != nil {
+		t.Fatalf("FetchPackage failed: %v", err)
+	}
+
+	if pkg.Repository != "https://github.com/sidekiq/sidekiq" {
+		t.Errorf("unexpected repository: %q", pkg.Repository)
+	}
+	if pkg.Metadata["funding_uri"] != "https://github.com/sponsors/mperham" {
+		t.Errorf("unexpected funding_uri: %v", pkg.Metadata["funding_uri"])
+	}
 }
 
 func TestFetchDependencies(t *testing.T) {
@@ -155,6 +200,95 @@ func TestFetchDependencies(t *testing.T) {
 	}
 }
 
+func TestCompactIndex(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/info/nokogiri":
+			_, _ = w.Write([]byte("---\n" +
+				"1.15.0 racc:~> 1.4|checksum:aaa111,ruby:>= 2.7&< 3.3.dev,rubygems:>= 3.3.22\n" +
+				"1.16.0.rc1 mini_portile2:~> 2.8.2,racc:~> 1.4|checksum:bbb222,ruby:>= 3.0\n" +
+				"1.16.0-x86_64-linux racc:>= 1.4&< 2|checksum:ccc333,ruby:>= 3.0&< 3.4.dev\n"))
+		case "/api/v2/rubygems/nokogiri/versions/1.14.0.json":
+			_ = json.NewEncoder(w).Encode(dependencyVersionResponse{
+				Dependencies: dependenciesBlock{Runtime: []gemDep{{Name: "racc", Requirements: "~> 1.4"}}},
+			})
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithCompactIndex(true)
+
+	versions, err := reg.FetchVersions(context.Background(), "nokogiri")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
+	}
+	if versions[0].Number != "1.16.0-x86_64-linux" || versions[2].Number != "1.15.0" {
+		t.Errorf("expected newest first, got %s ... %s", versions[0].Number, versions[2].Number)
+	}
+	if versions[0].Integrity != "sha256-ccc333" {
+		t.Errorf("unexpected integrity: %q", versions[0].Integrity)
+	}
+	if versions[0].Metadata["platform"] != "x86_64-linux" || versions[2].Metadata["platform"] != "ruby" {
+		t.Errorf("unexpected platforms: %v, %v", versions[0].Metadata["platform"], versions[2].Metadata["platform"])
+	}
+	if versions[0].Metadata["ruby_version"] != ">= 3.0, < 3.4.dev" {
+		t.Errorf("unexpected ruby_version: %v", versions[0].Metadata["ruby_version"])
+	}
+	if versions[1].Metadata["prerelease"] != true || versions[0].Metadata["prerelease"] != false {
+		t.Errorf("expected only 1.16.0.rc1 to be a prerelease")
+	}
+
+	deps, err := reg.FetchDependencies(context.Background(), "nokogiri", "1.16.0-x86_64-linux")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || deps[0].Name != "racc" || deps[0].Requirements != ">= 1.4, < 2" || deps[0].Scope != core.Runtime {
+		t.Errorf("unexpected dependencies: %v", deps)
+	}
+
+	// 1.14.0 isn't in the compact index (yanked, say), so it comes from the
+	// JSON API
+	requests = nil
+	deps, err = reg.FetchDependencies(context.Background(), "nokogiri", "1.14.0")
+	if err != nil {
+		t.Fatalf("FetchDependencies failed: %v", err)
+	}
+	if len(deps) != 1 || len(requests) != 2 {
+		t.Errorf("expected a JSON API fallback, got %v from %v", deps, requests)
+	}
+}
+
+func TestCompactIndexUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/versions/rake.json" {
+			w.WriteHeader(404)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]versionResponse{{Number: "13.1.0", Platform: "ruby", SHA: "abc"}})
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient()).WithCompactIndex(true)
+	versions, err := reg.FetchVersions(context.Background(), "rake")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Number != "13.1.0" {
+		t.Errorf("expected versions from the JSON API, got %v", versions)
+	}
+
+	if _, err := reg.FetchVersions(context.Background(), "missing"); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
 func TestFetchMaintainers(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/v1/gems/rails/owners.json" {
@@ -4,22 +4,29 @@ package terraform
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"sort"
 	"strings"
 
 	"github.com/git-pkgs/registries/internal/core"
-	"github.com/git-pkgs/registries/internal/urlparser"
 )
 
 const (
 	DefaultURL = "https://registry.terraform.io"
 	ecosystem  = "terraform"
+
+	// maxVersionPages caps how many pages of the versions endpoint are
+	// followed, in case a registry keeps handing out next_url.
+	maxVersionPages = 50
 )
 
 func init() {
 	core.Register(ecosystem, DefaultURL, func(baseURL string, client *core.Client) core.Registry {
 		return New(baseURL, client)
 	})
+	core.RegisterMetadataKeys(ecosystem,
+		"downloads", "provider", "terraform_required_version", "verified",
+	)
 }
 
 type Registry struct {
@@ -58,20 +65,26 @@ func parseModuleName(name string) (namespace, moduleName, provider string, ok bo
 }
 
 type moduleResponse struct {
-	ID          string `json:"id"`
-	Namespace   string `json:"namespace"`
-	Name        string `json:"name"`
-	Provider    string `json:"provider"`
-	Description string `json:"description"`
-	Source      string `json:"source"`
-	Version     string `json:"version"`
-	PublishedAt string `json:"published_at"`
-	Downloads   int    `json:"downloads"`
-	Verified    bool   `json:"verified"`
+	ID          string     `json:"id"`
+	Namespace   string     `json:"namespace"`
+	Name        string     `json:"name"`
+	Provider    string     `json:"provider"`
+	Description string     `json:"description"`
+	Source      string     `json:"source"`
+	Version     string     `json:"version"`
+	PublishedAt string     `json:"published_at"`
+	Downloads   int        `json:"downloads"`
+	Verified    bool       `json:"verified"`
+	Root        rootModule `json:"root"`
 }
 
 type moduleVersionsResponse struct {
 	Modules []moduleVersionsEntry `json:"modules"`
+	Meta    paginationMeta        `json:"meta"`
+}
+
+type paginationMeta struct {
+	NextURL string `json:"next_url"`
 }
 
 type moduleVersionsEntry struct {
@@ -92,6 +105,9 @@ type submoduleEntry struct {
 type rootModule struct {
 	Dependencies []dependencyEntry `json:"dependencies"`
 	Providers    []providerEntry   `json:"providers"`
+	// RequiredVersion is the module's required_version constraint on
+	// Terraform itself, e.g. ">= 1.3.0", when the registry reports it.
+	RequiredVersion string `json:"required_version"`
 }
 
 type dependencyEntry struct {
@@ -124,20 +140,26 @@ func (r *Registry) FetchPackage(ctx context.Context, name string) (*core.Package
 	}
 
 	// Extract repository from source
-	repository := urlparser.Parse(resp.Source)
-
-	return &core.Package{
-		Name:        fmt.Sprintf("%s/%s/%s", resp.Namespace, resp.Name, resp.Provider),
-		Description: resp.Description,
-		Homepage:    fmt.Sprintf("https://registry.terraform.io/modules/%s/%s/%s", namespace, moduleName, provider),
-		Repository:  repository,
-		Namespace:   resp.Namespace,
+	repository := core.NormalizeRepository(resp.Source)
+
+	pkg := &core.Package{
+		Name:          fmt.Sprintf("%s/%s/%s", resp.Namespace, resp.Name, resp.Provider),
+		Description:   resp.Description,
+		Homepage:      fmt.Sprintf("https://registry.terraform.io/modules/%s/%s/%s", namespace, moduleName, provider),
+		Repository:    repository,
+		Namespace:     resp.Namespace,
+		LatestVersion: resp.Version,
+		Downloads:     resp.Downloads,
 		Metadata: map[string]any{
 			"provider":  resp.Provider,
 			"downloads": resp.Downloads,
 			"verified":  resp.Verified,
 		},
-	}, nil
+	}
+	if resp.Root.RequiredVersion != "" {
+		pkg.Metadata["terraform_required_version"] = resp.Root.RequiredVersion
+	}
+	return pkg, nil
 }
 
 func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Version, error) {
@@ -146,25 +168,43 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 		return nil, fmt.Errorf("terraform module name must be in format 'namespace/name/provider'")
 	}
 
-	url := fmt.Sprintf("%s/v1/modules/%s/%s/%s/versions", r.baseURL, namespace, moduleName, provider)
+	pageURL := fmt.Sprintf("%s/v1/modules/%s/%s/%s/versions", r.baseURL, namespace, moduleName, provider)
 
-	var resp moduleVersionsResponse
-	if err := r.client.GetJSON(ctx, url, &resp); err != nil {
-		if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
-			return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+	var versions []core.Version
+	seen := make(map[string]bool)
+	for pageURL != "" && !seen[pageURL] {
+		if len(seen) == maxVersionPages {
+			return nil, fmt.Errorf("terraform: %s has more than %d pages of versions", name, maxVersionPages)
+		}
+		seen[pageURL] = true
+
+		var resp moduleVersionsResponse
+		if err := r.client.GetJSON(ctx, pageURL, &resp); err != nil {
+			if httpErr, ok := err.(*core.HTTPError); ok && httpErr.IsNotFound() {
+				return nil, &core.NotFoundError{Ecosystem: ecosystem, Name: name}
+			}
+			return nil, err
 		}
-		return nil, err
-	}
 
-	if len(resp.Modules) == 0 {
-		return nil, nil
+		if len(resp.Modules) > 0 {
+			for _, v := range resp.Modules[0].Versions {
+				version := core.Version{Number: v.Version}
+				if v.Root.RequiredVersion != "" {
+					version.Metadata = map[string]any{"terraform_required_version": v.Root.RequiredVersion}
+				}
+				versions = append(versions, version)
+			}
+		}
+
+		next, err := resolveNextURL(pageURL, resp.Meta.NextURL)
+		if err != nil {
+			return nil, err
+		}
+		pageURL = next
 	}
 
-	versions := make([]core.Version, 0, len(resp.Modules[0].Versions))
-	for _, v := range resp.Modules[0].Versions {
-		versions = append(versions, core.Version{
-			Number: v.Version,
-		})
+	if len(versions) == 0 {
+		return nil, nil
 	}
 
 	// Sort newest first (versions come oldest first from API)
@@ -175,6 +215,23 @@ func (r *Registry) FetchVersions(ctx context.Context, name string) ([]core.Versi
 	return versions, nil
 }
 
+// resolveNextURL resolves a meta.next_url, which registries usually return
+// as a path, against the URL of the page it came from.
+func resolveNextURL(pageURL, next string) (string, error) {
+	if next == "" {
+		return "", nil
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(next)
+	if err != nil {
+		return "", fmt.Errorf("terraform: invalid next_url %q: %w", next, err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
 func (r *Registry) FetchDependencies(ctx context.Context, name, version string) ([]core.Dependency, error) {
 	namespace, moduleName, provider, ok := parseModuleName(name)
 	if !ok {
@@ -267,8 +324,15 @@ func (u *URLs) PURL(name, version string) string {
 	if !ok {
 		return ""
 	}
+	purl := fmt.Sprintf("pkg:terraform/%s/%s/%s", namespace, moduleName, provider)
 	if version != "" {
-		return fmt.Sprintf("pkg:terraform/%s/%s/%s@%s", namespace, moduleName, provider, version)
+		purl += "@" + version
+	}
+	// Modules in a private registry (app.terraform.io, Terraform
+	// Enterprise) share names with the public one, so the registry goes in
+	// repository_url, which NewFromPURL reads back as the base URL.
+	if u.baseURL != DefaultURL {
+		purl += "?repository_url=" + url.QueryEscape(u.baseURL)
 	}
-	return fmt.Sprintf("pkg:terraform/%s/%s/%s", namespace, moduleName, provider)
+	return purl
 }
@@ -3,8 +3,11 @@ package terraform
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/git-pkgs/registries/internal/core"
@@ -28,6 +31,7 @@ func TestFetchPackage(t *testing.T) {
 			Version:     "0.11.0",
 			Downloads:   150000,
 			Verified:    true,
+			Root:        rootModule{RequiredVersion: ">= 1.3.0"},
 		}
 		_ = json.NewEncoder(w).Encode(resp)
 	}))
@@ -51,6 +55,12 @@ func TestFetchPackage(t *testing.T) {
 	if pkg.Namespace != "hashicorp" {
 		t.Errorf("expected namespace 'hashicorp', got %q", pkg.Namespace)
 	}
+	if pkg.LatestVersion != "0.11.0" {
+		t.Errorf("expected latest version '0.11.0', got %q", pkg.LatestVersion)
+	}
+	if got := pkg.Metadata["terraform_required_version"]; got != ">= 1.3.0" {
+		t.Errorf("expected terraform_required_version '>= 1.3.0', got %v", got)
+	}
 }
 
 func TestFetchPackageInvalidName(t *testing.T) {
@@ -74,7 +84,7 @@ func TestFetchVersions(t *testing.T) {
 					Versions: []versionEntry{
 						{Version: "0.9.0"},
 						{Version: "0.10.0"},
-						{Version: "0.11.0"},
+						{Version: "0.11.0", Root: rootModule{RequiredVersion: ">= 1.3.0"}},
 					},
 				},
 			},
@@ -97,6 +107,92 @@ func TestFetchVersions(t *testing.T) {
 	if versions[0].Number != "0.9.0" {
 		t.Errorf("expected first version '0.9.0', got %q", versions[0].Number)
 	}
+
+	for _, v := range versions {
+		want := map[string]any(nil)
+		if v.Number == "0.11.0" {
+			want = map[string]any{"terraform_required_version": ">= 1.3.0"}
+		}
+		if !reflect.DeepEqual(v.Metadata, want) {
+			t.Errorf("%s: expected metadata %v, got %v", v.Number, want, v.Metadata)
+		}
+	}
+}
+
+func TestFetchVersionsPaginated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/modules/hashicorp/consul/aws/versions" {
+			w.WriteHeader(404)
+			return
+		}
+
+		if r.URL.Query().Get("offset") == "2" {
+			_, _ = w.Write([]byte(`{
+				"modules": [{"versions": [{"version": "0.3.0"}]}],
+				"meta": {"limit": 2, "current_offset": 2}
+			}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"modules": [{"versions": [{"version": "0.1.0"}, {"version": "0.2.0"}]}],
+			"meta": {"limit": 2, "current_offset": 0, "next_offset": 2, "next_url": "/v1/modules/hashicorp/consul/aws/versions?offset=2"}
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "hashicorp/consul/aws")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
+	}
+	if versions[0].Number != "0.3.0" {
+		t.Errorf("expected first version '0.3.0', got %q", versions[0].Number)
+	}
+}
+
+func TestFetchVersionsPaginationLoop(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// next_url points back at the same page
+		_, _ = w.Write([]byte(`{
+			"modules": [{"versions": [{"version": "0.1.0"}]}],
+			"meta": {"next_url": "/v1/modules/hashicorp/consul/aws/versions"}
+		}`))
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	versions, err := reg.FetchVersions(context.Background(), "hashicorp/consul/aws")
+	if err != nil {
+		t.Fatalf("FetchVersions failed: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+	if len(versions) != 1 {
+		t.Errorf("expected 1 version, got %d", len(versions))
+	}
+}
+
+func TestFetchVersionsPageLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		_, _ = fmt.Fprintf(w, `{
+			"modules": [{"versions": [{"version": "0.%d.0"}]}],
+			"meta": {"next_url": "/v1/modules/hashicorp/consul/aws/versions?offset=%d"}
+		}`, offset, offset+1)
+	}))
+	defer server.Close()
+
+	reg := New(server.URL, core.DefaultClient())
+	if _, err := reg.FetchVersions(context.Background(), "hashicorp/consul/aws"); err == nil {
+		t.Error("expected an error when the page limit is exceeded")
+	}
 }
 
 func TestFetchDependencies(t *testing.T) {
@@ -211,6 +307,31 @@ func TestURLBuilder(t *testing.T) {
 	}
 }
 
+func TestPrivateRegistryPURL(t *testing.T) {
+	baseURL := "https://app.terraform.io/api/registry"
+	reg := New(baseURL+"/", nil)
+
+	got := reg.URLs().PURL("acme/vpc/aws", "1.2.0")
+	want := "pkg:terraform/acme/vpc/aws@1.2.0?repository_url=https%3A%2F%2Fapp.terraform.io%2Fapi%2Fregistry"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	parsed, name, version, err := core.NewFromPURL(got, nil)
+	if err != nil {
+		t.Fatalf("NewFromPURL failed: %v", err)
+	}
+	if name != "acme/vpc/aws" || version != "1.2.0" {
+		t.Errorf("expected acme/vpc/aws@1.2.0, got %s@%s", name, version)
+	}
+	if parsed.(*Registry).baseURL != baseURL {
+		t.Errorf("expected base URL %q, got %q", baseURL, parsed.(*Registry).baseURL)
+	}
+	if again := parsed.URLs().PURL(name, version); again != got {
+		t.Errorf("expected PURL to round-trip, got %q", again)
+	}
+}
+
 func TestEcosystem(t *testing.T) {
 	reg := New("", nil)
 	if reg.Ecosystem() != "terraform" {
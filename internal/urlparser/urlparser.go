@@ -28,6 +28,28 @@ var knownHosts = map[string]string{
 	"sourceforge.net":       "https://sourceforge.net",
 }
 
+// REST API bases for known hosts, keyed by canonical domain
+var apiBases = map[string]string{
+	"https://github.com":    "https://api.github.com",
+	"https://gitlab.com":    "https://gitlab.com/api/v4",
+	"https://bitbucket.org": "https://api.bitbucket.org/2.0",
+	"https://codeberg.org":  "https://codeberg.org/api/v1",
+}
+
+// Raw file URLs per canonical host: prefix + owner/repo + separator + ref/path,
+// or prefix + owner/repo + separator + path?ref=ref for hosts whose ref is a
+// query parameter. Codeberg's web raw route needs a branch, tag or commit,
+// so its API is used, which serves the default branch when ref is left out.
+var rawFileBases = map[string]struct {
+	prefix, separator string
+	refQuery          bool
+}{
+	"https://github.com":    {"https://raw.githubusercontent.com/", "/", false},
+	"https://gitlab.com":    {"https://gitlab.com/", "/-/raw/", false},
+	"https://bitbucket.org": {"https://bitbucket.org/", "/raw/", false},
+	"https://codeberg.org":  {"https://codeberg.org/api/v1/repos/", "/raw/", true},
+}
+
 // Subdomains to strip only for known hosts
 var knownSubdomains = map[string]bool{
 	"www":  true,
@@ -446,6 +468,49 @@ func CanonicalURL(rawURL string) string {
 	return Parse(rawURL)
 }
 
+// APIBaseURL returns the REST API base URL for the host of rawURL, e.g.
+// "https://api.github.com" for a GitHub repository. Returns empty string for
+// unknown and self-hosted hosts, where the API path can't be inferred.
+func APIBaseURL(rawURL string) string {
+	host := ExtractHost(rawURL)
+	if host == "" {
+		return ""
+	}
+
+	canonical, _ := canonicalizeHost(host)
+	return apiBases[canonical]
+}
+
+// RawFileURL returns the URL serving the raw contents of path at ref in the
+// repository at rawURL, e.g. "https://raw.githubusercontent.com/o/r/HEAD/README.md"
+// for path "README.md" and ref "HEAD". Returns empty string for hosts
+// without a known raw file URL.
+func RawFileURL(rawURL, ref, path string) string {
+	host := ExtractHost(rawURL)
+	if host == "" {
+		return ""
+	}
+
+	canonical, _ := canonicalizeHost(host)
+	base, ok := rawFileBases[canonical]
+	if !ok {
+		return ""
+	}
+
+	ownerRepo := ExtractOwnerRepo(rawURL)
+	if ownerRepo == "" {
+		return ""
+	}
+	path = strings.TrimPrefix(path, "/")
+	if base.refQuery {
+		if ref == "HEAD" {
+			return base.prefix + ownerRepo + base.separator + path
+		}
+		return base.prefix + ownerRepo + base.separator + path + "?ref=" + url.QueryEscape(ref)
+	}
+	return base.prefix + ownerRepo + base.separator + ref + "/" + path
+}
+
 // ParseURL is like Parse but returns structured data.
 func ParseURL(rawURL string) *RepoURL {
 	ownerRepo := ExtractOwnerRepo(rawURL)
@@ -176,6 +176,64 @@ func TestIsKnownHost(t *testing.T) {
 	}
 }
 
+func TestAPIBaseURL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://github.com/foo/bar", "https://api.github.com"},
+		{"git@github.com:foo/bar.git", "https://api.github.com"},
+		{"https://foo.github.io/bar", "https://api.github.com"},
+		{"https://www.github.com/foo/bar", "https://api.github.com"},
+		{"https://gitlab.com/foo/bar", "https://gitlab.com/api/v4"},
+		{"https://bitbucket.org/foo/bar", "https://api.bitbucket.org/2.0"},
+		{"https://bitbucket.com/foo/bar", "https://api.bitbucket.org/2.0"},
+		{"https://codeberg.org/foo/bar", "https://codeberg.org/api/v1"},
+		{"https://sourceforge.net/projects/foo", ""},
+		{"https://git.example.com/foo/bar", ""},
+		{"https://gitlab.mycompany.com/foo/bar", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := APIBaseURL(tt.input)
+			if got != tt.want {
+				t.Errorf("APIBaseURL(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawFileURL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://github.com/foo/bar", "https://raw.githubusercontent.com/foo/bar/HEAD/README.md"},
+		{"git@github.com:foo/bar.git", "https://raw.githubusercontent.com/foo/bar/HEAD/README.md"},
+		{"https://github.com/foo/bar/tree/main/docs", "https://raw.githubusercontent.com/foo/bar/HEAD/README.md"},
+		{"https://gitlab.com/foo/bar", "https://gitlab.com/foo/bar/-/raw/HEAD/README.md"},
+		{"https://bitbucket.org/foo/bar", "https://bitbucket.org/foo/bar/raw/HEAD/README.md"},
+		{"https://codeberg.org/foo/bar", "https://codeberg.org/api/v1/repos/foo/bar/raw/README.md"},
+		{"https://git.example.com/foo/bar", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := RawFileURL(tt.input, "HEAD", "README.md")
+			if got != tt.want {
+				t.Errorf("RawFileURL(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	if got := RawFileURL("https://codeberg.org/foo/bar", "v1.0", "/docs/index.md"); got != "https://codeberg.org/api/v1/repos/foo/bar/raw/docs/index.md?ref=v1.0" {
+		t.Errorf("expected the ref as a query parameter, got %q", got)
+	}
+}
+
 func TestNormalize(t *testing.T) {
 	tests := []struct {
 		input string
@@ -34,6 +34,9 @@ package registries
 
 import (
 	"context"
+	"io"
+	"log/slog"
+	"net/http"
 
 	"github.com/git-pkgs/purl"
 	"github.com/git-pkgs/registries/internal/core"
@@ -59,6 +62,9 @@ type (
 	// Client is an HTTP client with retry logic for registry APIs.
 	Client = core.Client
 
+	// Credentials authenticate requests to a private registry.
+	Credentials = core.Credentials
+
 	// URLBuilder constructs URLs for a registry.
 	URLBuilder = core.URLBuilder
 
@@ -70,6 +76,22 @@ type (
 
 	// RateLimiter controls request pacing.
 	RateLimiter = core.RateLimiter
+
+	// Sleeper waits between retries.
+	Sleeper = core.Sleeper
+
+	// PackageDetail combines a package with its versions and maintainers.
+	PackageDetail = core.PackageDetail
+
+	// URLSet holds every URL a registry builds for a package version.
+	URLSet = core.URLSet
+
+	// ReadmeFetcher is implemented by registries that serve READMEs.
+	ReadmeFetcher = core.ReadmeFetcher
+
+	// TagResolver is implemented by registries with symbolic versions,
+	// such as npm dist-tags and Maven's LATEST and RELEASE.
+	TagResolver = core.TagResolver
 )
 
 // Re-export constants
@@ -79,6 +101,8 @@ const (
 	Test        = core.Test
 	Build       = core.Build
 	Optional    = core.Optional
+	Peer        = core.Peer
+	Provided    = core.Provided
 
 	StatusNone       = core.StatusNone
 	StatusYanked     = core.StatusYanked
@@ -88,7 +112,12 @@ const (
 
 // Re-export errors
 var (
-	ErrNotFound = core.ErrNotFound
+	ErrNotFound              = core.ErrNotFound
+	ErrClientClosed          = core.ErrClientClosed
+	ErrReadmeUnsupported     = core.ErrReadmeUnsupported
+	ErrUnsupportedConstraint = core.ErrUnsupportedConstraint
+	ErrNoDownloadURL         = core.ErrNoDownloadURL
+	ErrDependencyLimit       = core.ErrDependencyLimit
 )
 
 // Error types
@@ -96,11 +125,13 @@ type (
 	HTTPError     = core.HTTPError
 	NotFoundError = core.NotFoundError
 	RateLimitError = core.RateLimitError
+	DecodeError    = core.DecodeError
 )
 
 // New creates a new registry for the given ecosystem.
 // If baseURL is empty, the default registry URL is used.
-// If client is nil, DefaultClient() is used.
+// If client is nil, DefaultClient() is used. The registry keeps its own
+// copy of client, so set the client's fields before calling New.
 //
 // Supported ecosystems: "cargo", "npm", "gem", "pypi", "golang"
 func New(ecosystem string, baseURL string, client *Client) (Registry, error) {
@@ -111,6 +142,7 @@ func New(ecosystem string, baseURL string, client *Client) (Registry, error) {
 // - 30s timeout
 // - 5 retries with exponential backoff
 // - Retry on 429 and 5xx responses
+// - Credentials dropped on cross-host redirects
 func DefaultClient() *Client {
 	return core.DefaultClient()
 }
@@ -129,6 +161,49 @@ var WithTimeout = core.WithTimeout
 // WithMaxRetries sets the maximum number of retries.
 var WithMaxRetries = core.WithMaxRetries
 
+// WithSleeper replaces the timer the client waits on between retries, so
+// backoff and Retry-After delays can be tested without sleeping.
+var WithSleeper = core.WithSleeper
+
+// WithLogger sets a logger for debug output about failed and retried
+// requests, coalesced requests and dropped bulk entries. Logging is off
+// unless a logger is set.
+var WithLogger = core.WithLogger
+
+// WithCoalescing enables or disables sharing one request between concurrent
+// GETs of the same URL. It is enabled by default.
+var WithCoalescing = core.WithCoalescing
+
+// WithProxy sends every request through the proxy at proxyURL instead of
+// the one named by HTTP_PROXY, HTTPS_PROXY and NO_PROXY, which are honored
+// by default. An empty URL connects directly.
+var WithProxy = core.WithProxy
+
+// WithRawResponses keeps the first response body read by each FetchPackage
+// call, cut to limit bytes, in Package.Metadata["_raw"], for debugging
+// registries whose responses have changed shape. Off by default.
+var WithRawResponses = core.WithRawResponses
+
+// WithMinTLSVersion refuses TLS connections below version, such as
+// tls.VersionTLS12.
+var WithMinTLSVersion = core.WithMinTLSVersion
+
+// WithHTTP2 enables or disables HTTP/2. Disabling it forces HTTP/1.1, for
+// proxies and load balancers that mishandle HTTP/2.
+var WithHTTP2 = core.WithHTTP2
+
+// WithCredentials authenticates every request to host, which may include a
+// port, with basic auth or a bearer token. Credentials are never sent to
+// other hosts.
+var WithCredentials = core.WithCredentials
+
+// CheckRedirect is the redirect policy used by DefaultClient. It drops
+// Authorization, Proxy-Authorization and Cookie headers when a redirect goes
+// to a different host. Set it on custom http.Clients to get the same behavior.
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	return core.CheckRedirect(req, via)
+}
+
 // SupportedEcosystems returns all registered ecosystem types.
 // Note: ecosystems must be imported to be registered.
 func SupportedEcosystems() []string {
@@ -140,6 +215,18 @@ func DefaultURL(ecosystem string) string {
 	return core.DefaultURL(ecosystem)
 }
 
+// PURLType returns the PURL type used for a registered ecosystem's
+// packages, or empty string if no registry for it is registered.
+func PURLType(ecosystem string) string {
+	return core.PURLType(ecosystem)
+}
+
+// MetadataKeys returns the Metadata keys a registered ecosystem's registry
+// may set on packages, versions and dependencies, sorted.
+func MetadataKeys(ecosystem string) []string {
+	return core.MetadataKeys(ecosystem)
+}
+
 // PURL represents a parsed Package URL.
 type PURL = purl.PURL
 
@@ -155,6 +242,56 @@ func NewFromPURL(purl string, client *Client) (Registry, string, string, error)
 	return core.NewFromPURL(purl, client)
 }
 
+// PartitionPURLs groups valid PURLs by ecosystem and returns the reason
+// each invalid or unsupported PURL was rejected.
+func PartitionPURLs(purls []string) (map[string][]string, map[string]error) {
+	return core.PartitionPURLs(purls)
+}
+
+// DocumentationURL returns the registry's documentation URL for a version
+// of pkg, or DocumentationFallback(pkg) if the registry has none.
+func DocumentationURL(reg Registry, pkg *Package, version string) string {
+	return core.DocumentationURL(reg, pkg, version)
+}
+
+// CanonicalHomepage returns pkg's homepage, falling back to its repository
+// and then to its page on the registry, without modifying pkg.
+func CanonicalHomepage(pkg *Package, urls URLBuilder, name, version string) string {
+	return core.CanonicalHomepage(pkg, urls, name, version)
+}
+
+// ApplyProjectURLs fills pkg's empty Repository and Homepage from its
+// labeled ProjectURLs.
+func ApplyProjectURLs(pkg *Package) {
+	core.ApplyProjectURLs(pkg)
+}
+
+// DocumentationFallback guesses a documentation URL from a package's
+// homepage (when it's on a docs host like GitHub Pages or Read the Docs) or
+// its repository on a known host, without making any requests.
+func DocumentationFallback(pkg *Package) string {
+	return core.DocumentationFallback(pkg)
+}
+
+// ResolveURLs returns the registry, download, documentation and PURL URLs
+// for a PURL without making any requests.
+func ResolveURLs(purl string) (*URLSet, error) {
+	return core.ResolveURLs(purl)
+}
+
+// ArtifactSize returns the size in bytes of a version's download, read
+// from a HEAD request, or ErrNoDownloadURL if the registry doesn't build
+// download URLs.
+func ArtifactSize(ctx context.Context, reg Registry, name, version string, client *Client) (int64, error) {
+	return core.ArtifactSize(ctx, reg, name, version, client)
+}
+
+// ValidatePURL checks that the PURL the ecosystem's registry builds for
+// name and version parses back to the same type, name and version.
+func ValidatePURL(ecosystem, name, version string) error {
+	return core.ValidatePURL(ecosystem, name, version)
+}
+
 // FetchPackageFromPURL fetches package metadata using a PURL.
 func FetchPackageFromPURL(ctx context.Context, purl string, client *Client) (*Package, error) {
 	return core.FetchPackageFromPURL(ctx, purl, client)
@@ -183,11 +320,174 @@ func FetchLatestVersion(ctx context.Context, reg Registry, name string) (*Versio
 	return core.FetchLatestVersion(ctx, reg, name)
 }
 
+// FetchLatestVersionIncludingDeprecated is like FetchLatestVersion but also
+// considers deprecated versions. Yanked and retracted versions are still
+// skipped.
+func FetchLatestVersionIncludingDeprecated(ctx context.Context, reg Registry, name string) (*Version, error) {
+	return core.FetchLatestVersionIncludingDeprecated(ctx, reg, name)
+}
+
+// RawFileURL returns the URL of a file on the default branch of a GitHub,
+// GitLab, Bitbucket or Codeberg repository, or "" for other hosts.
+func RawFileURL(repoURL, path string) string {
+	return core.RawFileURL(repoURL, path)
+}
+
+// FetchReadme returns the README for a package from its registry, or
+// ErrReadmeUnsupported if the registry doesn't serve READMEs.
+func FetchReadme(ctx context.Context, reg Registry, name, version string) (string, error) {
+	return core.FetchReadme(ctx, reg, name, version)
+}
+
+// ResolveTag returns the version a symbolic tag points to for a package,
+// or tag unchanged if the registry doesn't resolve tags or has no such tag.
+func ResolveTag(ctx context.Context, reg Registry, name, tag string) (string, error) {
+	return core.ResolveTag(ctx, reg, name, tag)
+}
+
+// FetchReadmeWithRepositoryFallback is like FetchReadme, but falls back to
+// the README on the default branch of the package's GitHub, GitLab,
+// Bitbucket or Codeberg repository, at the cost of extra requests.
+func FetchReadmeWithRepositoryFallback(ctx context.Context, reg Registry, name, version string, client *Client) (string, error) {
+	return core.FetchReadmeWithRepositoryFallback(ctx, reg, name, version, client)
+}
+
+// LatestStable returns the highest version of a package that is neither a
+// prerelease nor yanked, deprecated or retracted, given its ecosystem and
+// name. Returns nil if there is none.
+func LatestStable(ctx context.Context, ecosystem, name string, client *Client) (*Version, error) {
+	return core.LatestStable(ctx, ecosystem, name, client)
+}
+
+// SortedVersions returns a copy of versions ordered newest first, using the
+// version ordering rules of the registry's ecosystem.
+func SortedVersions(reg Registry, versions []Version) []Version {
+	return core.SortedVersions(reg, versions)
+}
+
+// CompareVersions compares two version strings using the ordering rules of
+// the ecosystem, returning -1 if a is older than b, 1 if it is newer and 0
+// if they are equivalent.
+func CompareVersions(ecosystem, a, b string) int {
+	return core.CompareVersions(ecosystem, a, b)
+}
+
+// IsPrerelease reports whether version is a prerelease under the
+// conventions of the ecosystem.
+func IsPrerelease(ecosystem, version string) bool {
+	return core.IsPrerelease(ecosystem, version)
+}
+
+// BackfillDatesFromRepo returns a copy of versions where those without a
+// publish date take the commit date of the matching git tag in the GitHub or
+// GitLab repository at repoURL. It makes several API requests, so it is
+// opt-in, for ecosystems whose registries don't record dates.
+func BackfillDatesFromRepo(ctx context.Context, versions []Version, repoURL string, client *Client) ([]Version, error) {
+	return core.BackfillDatesFromRepo(ctx, versions, repoURL, client)
+}
+
+// RepoTags returns the tag names of a GitHub or GitLab repository, reading
+// at most 1,000. Other hosts return nil.
+func RepoTags(ctx context.Context, repoURL string, client *Client) ([]string, error) {
+	return core.RepoTags(ctx, repoURL, client)
+}
+
+// SatisfiesRequirement reports whether version satisfies a dependency
+// requirement such as "^1.2.3", "~> 3.0" or "[1.0,2.0)" under the constraint
+// grammar of ecosystem. npm, Composer, RubyGems, CocoaPods, Maven and
+// Clojars are supported; other ecosystems return ErrUnsupportedConstraint.
+func SatisfiesRequirement(ecosystem, version, requirement string) (bool, error) {
+	return core.SatisfiesRequirement(ecosystem, version, requirement)
+}
+
+// ResolveVersion returns the highest stable version of a package that
+// satisfies constraint, falling back to prereleases only when no stable
+// version does. Returns ErrNotFound if nothing matches.
+func ResolveVersion(ctx context.Context, reg Registry, name, constraint string) (*Version, error) {
+	return core.ResolveVersion(ctx, reg, name, constraint)
+}
+
+// FetchDependenciesRecursiveFlat returns every package a version depends
+// on at runtime, directly or transitively, once each, with the resolved
+// version, depth and path recorded in Metadata. Zero maxDepth and maxNodes
+// use the defaults of 10 levels and 1,000 packages.
+func FetchDependenciesRecursiveFlat(ctx context.Context, reg Registry, name, version string, maxDepth, maxNodes int) ([]Dependency, error) {
+	return core.FetchDependenciesRecursiveFlat(ctx, reg, name, version, maxDepth, maxNodes)
+}
+
+// NormalizeKeywords trims and lowercases keywords, dropping empty and
+// duplicate entries while keeping the original order. Registries apply it
+// to Package.Keywords.
+func NormalizeKeywords(keywords []string) []string {
+	return core.NormalizeKeywords(keywords)
+}
+
+// ParseVCSReference reports whether a dependency requirement points at a
+// git repository, such as "git+https://github.com/o/r.git#v1.0.0" or
+// "github:o/r", and returns the normalized repository URL and pinned ref.
+func ParseVCSReference(requirement string) (repoURL, ref string, ok bool) {
+	return core.ParseVCSReference(requirement)
+}
+
+// TagVCSDependency sets Metadata["vcs_url"] and Metadata["vcs_ref"] on a
+// dependency whose requirement is a git reference, reporting whether it did.
+func TagVCSDependency(dep *Dependency) bool {
+	return core.TagVCSDependency(dep)
+}
+
+// MergeMaintainers removes duplicate maintainers, matching entries by
+// email, then login, then name, and merging their fields so the entry
+// with the most set wins.
+func MergeMaintainers(maintainers []Maintainer) []Maintainer {
+	return core.MergeMaintainers(maintainers)
+}
+
+// SplitName splits a package name into its namespace and bare name using
+// the naming rules of ecosystem. Namespaces never include a leading "@".
+func SplitName(ecosystem, name string) (namespace, bare string) {
+	return core.SplitName(ecosystem, name)
+}
+
+// StableVersions returns only the versions that are neither prereleases, by
+// the conventions of the registry's ecosystem, nor yanked, deprecated or
+// retracted. Order is preserved.
+func StableVersions(reg Registry, versions []Version) []Version {
+	return core.StableVersions(reg, versions)
+}
+
+// ExcludeVersions returns versions without those whose Status is one of
+// statuses, or without any yanked, deprecated or retracted version when
+// none are given. Order is preserved and prereleases are kept.
+func ExcludeVersions(versions []Version, statuses ...VersionStatus) []Version {
+	return core.ExcludeVersions(versions, statuses...)
+}
+
 // FetchLatestVersionFromPURL returns the latest non-yanked version for a PURL.
 func FetchLatestVersionFromPURL(ctx context.Context, purl string, client *Client) (*Version, error) {
 	return core.FetchLatestVersionFromPURL(ctx, purl, client)
 }
 
+// FetchAll fetches a package, its versions and its maintainers concurrently.
+// Only a failure to fetch the package is returned as an error; missing
+// versions or maintainers are recorded on the PackageDetail.
+func FetchAll(ctx context.Context, reg Registry, name string) (*PackageDetail, error) {
+	return core.FetchAll(ctx, reg, name)
+}
+
+// WithEcosystemConcurrency returns a context that makes the bulk helpers
+// schedule each ecosystem's PURLs independently, with at most limits[eco]
+// fetches in flight for that ecosystem. Ecosystems not in limits use the
+// helper's own concurrency.
+func WithEcosystemConcurrency(ctx context.Context, limits map[string]int) context.Context {
+	return core.WithEcosystemConcurrency(ctx, limits)
+}
+
+// WithLogAttrs returns a context whose requests are logged with attrs, such
+// as a request ID or tenant, by any client with a Logger.
+func WithLogAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	return core.WithLogAttrs(ctx, attrs...)
+}
+
 // BulkFetchPackages fetches package metadata for multiple PURLs in parallel.
 // Individual fetch errors are silently ignored - those PURLs are omitted from results.
 // Returns a map of PURL to Package.
@@ -200,6 +500,14 @@ func BulkFetchPackagesWithConcurrency(ctx context.Context, purls []string, clien
 	return core.BulkFetchPackagesWithConcurrency(ctx, purls, client, concurrency)
 }
 
+// BulkFetchPackagesPartial is BulkFetchPackagesWithConcurrency, also
+// returning ctx.Err() if ctx was cancelled, or ErrClientClosed if the
+// client was closed, before every PURL was fetched. The map holds what was
+// fetched either way. A concurrency below 1 uses the default.
+func BulkFetchPackagesPartial(ctx context.Context, purls []string, client *Client, concurrency int) (map[string]*Package, error) {
+	return core.BulkFetchPackagesPartial(ctx, purls, client, concurrency)
+}
+
 // BulkFetchVersions fetches version metadata for multiple versioned PURLs in parallel.
 // PURLs without versions are silently skipped.
 // Individual fetch errors are silently ignored - those PURLs are omitted from results.
@@ -213,6 +521,12 @@ func BulkFetchVersionsWithConcurrency(ctx context.Context, purls []string, clien
 	return core.BulkFetchVersionsWithConcurrency(ctx, purls, client, concurrency)
 }
 
+// BulkFetchVersionsPartial is BulkFetchVersionsWithConcurrency with the
+// error reporting of BulkFetchPackagesPartial.
+func BulkFetchVersionsPartial(ctx context.Context, purls []string, client *Client, concurrency int) (map[string]*Version, error) {
+	return core.BulkFetchVersionsPartial(ctx, purls, client, concurrency)
+}
+
 // BulkFetchLatestVersions fetches the latest version for multiple PURLs in parallel.
 // Returns a map of PURL to the latest non-yanked Version.
 func BulkFetchLatestVersions(ctx context.Context, purls []string, client *Client) map[string]*Version {
@@ -223,3 +537,21 @@ func BulkFetchLatestVersions(ctx context.Context, purls []string, client *Client
 func BulkFetchLatestVersionsWithConcurrency(ctx context.Context, purls []string, client *Client, concurrency int) map[string]*Version {
 	return core.BulkFetchLatestVersionsWithConcurrency(ctx, purls, client, concurrency)
 }
+
+// BulkFetchLatestVersionsPartial is BulkFetchLatestVersionsWithConcurrency
+// with the error reporting of BulkFetchPackagesPartial.
+func BulkFetchLatestVersionsPartial(ctx context.Context, purls []string, client *Client, concurrency int) (map[string]*Version, error) {
+	return core.BulkFetchLatestVersionsPartial(ctx, purls, client, concurrency)
+}
+
+// WritePackagesNDJSON writes bulk package results as newline-delimited JSON,
+// one {"purl": ..., "package": {...}} object per line, sorted by PURL.
+func WritePackagesNDJSON(w io.Writer, results map[string]*Package) error {
+	return core.WritePackagesNDJSON(w, results)
+}
+
+// WriteVersionsNDJSON writes bulk version results as newline-delimited JSON,
+// one {"purl": ..., "version": {...}} object per line, sorted by PURL.
+func WriteVersionsNDJSON(w io.Writer, results map[string]*Version) error {
+	return core.WriteVersionsNDJSON(w, results)
+}
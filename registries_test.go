@@ -3,6 +3,7 @@ package registries_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"sort"
@@ -114,6 +115,39 @@ func TestDefaultURL(t *testing.T) {
 	}
 }
 
+func TestPURLType(t *testing.T) {
+	// TestPURLRoundTrip checks that each registry's URLs().PURL uses its
+	// ecosystem as the type
+	for _, eco := range registries.SupportedEcosystems() {
+		if got := registries.PURLType(eco); got != eco {
+			t.Errorf("PURLType(%q) = %q", eco, got)
+		}
+	}
+
+	if got := registries.PURLType("bitnami"); got != "" {
+		t.Errorf("expected no PURL type for an unregistered ecosystem, got %q", got)
+	}
+}
+
+func TestMetadataKeys(t *testing.T) {
+	// golang's registry doesn't set Metadata
+	for _, eco := range registries.SupportedEcosystems() {
+		keys := registries.MetadataKeys(eco)
+		if eco == "golang" {
+			if keys != nil {
+				t.Errorf("expected no metadata keys for golang, got %v", keys)
+			}
+			continue
+		}
+		if len(keys) == 0 {
+			t.Errorf("expected metadata keys for %s", eco)
+		}
+		if !sort.StringsAreSorted(keys) {
+			t.Errorf("expected sorted metadata keys for %s, got %v", eco, keys)
+		}
+	}
+}
+
 func TestIntegration(t *testing.T) {
 	// Test with a mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -174,6 +208,146 @@ func TestIntegration(t *testing.T) {
 	}
 }
 
+func TestBulkFetchAfterClose(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	client := registries.DefaultClient()
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	purls := []string{
+		"pkg:cargo/serde?repository_url=" + server.URL,
+		"pkg:cargo/tokio?repository_url=" + server.URL,
+	}
+	results := registries.BulkFetchPackages(context.Background(), purls, client)
+
+	if len(results) != 0 {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+	if hits != 0 {
+		t.Errorf("expected no requests after Close, got %d", hits)
+	}
+}
+
+func TestDecodeErrorNamesEcosystem(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>maintenance</html>"))
+	}))
+	defer server.Close()
+
+	reg, err := registries.New("cargo", server.URL, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	_, err = reg.FetchPackage(context.Background(), "serde")
+
+	var decodeErr *registries.DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected a DecodeError, got %v", err)
+	}
+	if decodeErr.Ecosystem != "cargo" {
+		t.Errorf("expected ecosystem %q, got %q", "cargo", decodeErr.Ecosystem)
+	}
+	if decodeErr.URL != server.URL+"/api/v1/crates/serde" {
+		t.Errorf("unexpected URL: %q", decodeErr.URL)
+	}
+	if decodeErr.Body != "<html>maintenance</html>" {
+		t.Errorf("unexpected body: %q", decodeErr.Body)
+	}
+}
+
+func TestPURLRoundTrip(t *testing.T) {
+	names := map[string][]string{
+		"brew":      {"wget", "openssl@3"},
+		"cargo":     {"serde"},
+		"clojars":   {"ring/ring-core", "hiccup"},
+		"cocoapods": {"AFNetworking"},
+		"composer":  {"symfony/console"},
+		"conda":     {"numpy", "conda-forge/numpy"},
+		"cpan":      {"Moose"},
+		"cran":      {"ggplot2"},
+		"deno":      {"oak"},
+		"dub":       {"vibe-d"},
+		"elm":       {"elm/core"},
+		"gem":       {"rails"},
+		"golang":    {"github.com/gorilla/mux", "github.com/Azure/go-autorest"},
+		"hackage":   {"aeson"},
+		"haxelib":   {"openfl"},
+		"hex":       {"phoenix"},
+		"julia":     {"DataFrames"},
+		"luarocks":  {"luasocket"},
+		"maven":     {"org.apache.commons:commons-lang3"},
+		"nimble":    {"jester"},
+		"npm":       {"lodash", "@babel/core"},
+		"nuget":     {"Newtonsoft.Json"},
+		"pub":       {"http"},
+		"pypi":      {"Django", "zope.interface"},
+		"terraform": {"hashicorp/consul/aws"},
+	}
+
+	for _, eco := range registries.SupportedEcosystems() {
+		if len(names[eco]) == 0 {
+			t.Errorf("%s: no names to round-trip", eco)
+			continue
+		}
+		for _, name := range names[eco] {
+			for _, version := range []string{"1.0.0", ""} {
+				if err := registries.ValidatePURL(eco, name, version); err != nil {
+					t.Error(err)
+				}
+			}
+		}
+	}
+}
+
+func TestResolveURLs(t *testing.T) {
+	urls, err := registries.ResolveURLs("pkg:cargo/serde@1.0.0")
+	if err != nil {
+		t.Fatalf("ResolveURLs failed: %v", err)
+	}
+
+	if urls.Ecosystem != "cargo" || urls.Name != "serde" || urls.Version != "1.0.0" {
+		t.Errorf("unexpected components: %+v", urls)
+	}
+	if urls.Registry != "https://crates.io/crates/serde/1.0.0" {
+		t.Errorf("unexpected registry URL: %q", urls.Registry)
+	}
+	if urls.Download != "https://static.crates.io/crates/serde/serde-1.0.0.crate" {
+		t.Errorf("unexpected download URL: %q", urls.Download)
+	}
+	if urls.Documentation != "https://docs.rs/serde/1.0.0" {
+		t.Errorf("unexpected documentation URL: %q", urls.Documentation)
+	}
+	if urls.PURL != "pkg:cargo/serde@1.0.0" {
+		t.Errorf("unexpected PURL: %q", urls.PURL)
+	}
+
+	// Namespaced names and repository_url are carried through
+	urls, err = registries.ResolveURLs("pkg:npm/%40babel/core?repository_url=https://npm.example.com")
+	if err != nil {
+		t.Fatalf("ResolveURLs failed: %v", err)
+	}
+	if urls.Name != "@babel/core" || urls.Version != "" {
+		t.Errorf("unexpected components: %+v", urls)
+	}
+	if urls.Registry == "" || urls.PURL == "" {
+		t.Errorf("expected registry and PURL URLs, got %+v", urls)
+	}
+
+	if _, err := registries.ResolveURLs("pkg:unknown/foo"); err == nil {
+		t.Error("expected an error for an unknown ecosystem")
+	}
+	if _, err := registries.ResolveURLs("not a purl"); err == nil {
+		t.Error("expected an error for an invalid PURL")
+	}
+}
+
 func TestConstants(t *testing.T) {
 	// Verify constants are exported correctly
 	if registries.Runtime != "runtime" {
@@ -182,6 +356,9 @@ func TestConstants(t *testing.T) {
 	if registries.Development != "development" {
 		t.Errorf("Development constant mismatch")
 	}
+	if registries.Peer != "peer" {
+		t.Errorf("Peer constant mismatch")
+	}
 	if registries.StatusYanked != "yanked" {
 		t.Errorf("StatusYanked constant mismatch")
 	}